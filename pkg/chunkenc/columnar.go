@@ -0,0 +1,276 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// Blocks of ChunkFormatV6 chunks store the entries in columns, each of them compressed on its own:
+//
+//	┌───────────────────────────────────────────────────────────────────────┐
+//	│ for each column: len(compressed column) uvarint | len(column) uvarint │
+//	├───────────────────────────────────────────────────────────────────────┤
+//	│ timestamps column: ts delta varint | len(line) uvarint | hash(line) 8b │
+//	├───────────────────────────────────────────────────────────────────────┤
+//	│ lines column: line bytes                                              │
+//	├───────────────────────────────────────────────────────────────────────┤
+//	│ structured metadata column: #symbols uvarint | symbols uvarint pairs  │
+//	└───────────────────────────────────────────────────────────────────────┘
+//
+// The timestamps column holds the size and the hash of every line, so that
+// samples not depending on the content of the lines can be extracted
+// without decompressing the lines column.
+const (
+	columnTimestamps = iota
+	columnLines
+	columnStructuredMetadata
+
+	numColumns
+)
+
+// SerialiseColumns serialises the head block into a columnar block of ChunkFormatV6 chunks.
+func (hb *unorderedHeadBlock) SerialiseColumns(pool WriterPool) ([]byte, error) {
+	var columns [numColumns]*bytes.Buffer
+	for i := range columns {
+		columns[i] = serializeBytesBufferPool.Get().(*bytes.Buffer)
+	}
+	defer func() {
+		for _, c := range columns {
+			c.Reset()
+			serializeBytesBufferPool.Put(c)
+		}
+	}()
+
+	encBuf := make([]byte, binary.MaxVarintLen64)
+	var prevTs int64
+	_ = hb.forEntries(
+		context.Background(),
+		logproto.FORWARD,
+		0,
+		math.MaxInt64,
+		func(_ *stats.Context, ts int64, line string, structuredMetadataSymbols symbols) error {
+			n := binary.PutVarint(encBuf, ts-prevTs)
+			columns[columnTimestamps].Write(encBuf[:n])
+			prevTs = ts
+
+			n = binary.PutUvarint(encBuf, uint64(len(line)))
+			columns[columnTimestamps].Write(encBuf[:n])
+
+			binary.BigEndian.PutUint64(encBuf, xxhash.Sum64String(line))
+			columns[columnTimestamps].Write(encBuf[:8])
+
+			columns[columnLines].WriteString(line)
+
+			n = binary.PutUvarint(encBuf, uint64(len(structuredMetadataSymbols)))
+			columns[columnStructuredMetadata].Write(encBuf[:n])
+			for _, l := range structuredMetadataSymbols {
+				n = binary.PutUvarint(encBuf, uint64(l.Name))
+				columns[columnStructuredMetadata].Write(encBuf[:n])
+
+				n = binary.PutUvarint(encBuf, uint64(l.Value))
+				columns[columnStructuredMetadata].Write(encBuf[:n])
+			}
+			return nil
+		},
+	)
+
+	var compressed [numColumns]bytes.Buffer
+	for i, c := range columns {
+		compressedWriter := pool.GetWriter(&compressed[i])
+		if _, err := compressedWriter.Write(c.Bytes()); err != nil {
+			pool.PutWriter(compressedWriter)
+			return nil, errors.Wrap(err, "appending column")
+		}
+		if err := compressedWriter.Close(); err != nil {
+			pool.PutWriter(compressedWriter)
+			return nil, errors.Wrap(err, "flushing pending compress buffer")
+		}
+		pool.PutWriter(compressedWriter)
+	}
+
+	out := make([]byte, 0, numColumns*2*binary.MaxVarintLen64+compressed[0].Len()+compressed[1].Len()+compressed[2].Len())
+	for i, c := range columns {
+		out = binary.AppendUvarint(out, uint64(compressed[i].Len()))
+		out = binary.AppendUvarint(out, uint64(c.Len()))
+	}
+	for i := range compressed {
+		out = append(out, compressed[i].Bytes()...)
+	}
+	return out, nil
+}
+
+// columnReader reads the entries of a columnar block.
+type columnReader struct {
+	columns [numColumns][]byte
+	// pos is the read position within each decompressed column.
+	pos    [numColumns]int
+	prevTs int64
+
+	symbolsBuf []symbol // The buffer for a single entry's symbols.
+}
+
+// newColumnReader decompresses the columns of the block b. The lines column is
+// only decompressed if withLines is set.
+func newColumnReader(pool ReaderPool, b []byte, withLines bool) (*columnReader, error) {
+	db := decbuf{b: b}
+	var compressedLens, lens [numColumns]int
+	for i := 0; i < numColumns; i++ {
+		compressedLens[i] = db.uvarint()
+		lens[i] = db.uvarint()
+	}
+	if db.err() != nil {
+		return nil, errors.Wrap(db.err(), "reading column lengths")
+	}
+
+	r := &columnReader{}
+	offset := len(b) - len(db.b)
+	for i := 0; i < numColumns; i++ {
+		if offset+compressedLens[i] > len(b) {
+			return nil, fmt.Errorf("invalid data in chunk")
+		}
+		compressed := b[offset : offset+compressedLens[i]]
+		offset += compressedLens[i]
+		if i == columnLines && !withLines {
+			continue
+		}
+		if lens[i] >= maxLineLength {
+			return nil, fmt.Errorf("column too long %d, maximum %d", lens[i], maxLineLength)
+		}
+
+		column, err := decompressColumn(pool, compressed, lens[i])
+		if err != nil {
+			r.close()
+			return nil, err
+		}
+		r.columns[i] = column
+	}
+	return r, nil
+}
+
+func decompressColumn(pool ReaderPool, b []byte, size int) ([]byte, error) {
+	reader, err := pool.GetReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer pool.PutReader(reader)
+
+	column := BytesBufferPool.Get(size).([]byte)[:size]
+	if _, err := io.ReadFull(reader, column); err != nil {
+		BytesBufferPool.Put(column)
+		return nil, errors.Wrap(err, "decompressing column")
+	}
+	return column, nil
+}
+
+// next reads the next entry of the block. The line is nil if the lines column was not decompressed.
+func (r *columnReader) next() (ts int64, line []byte, size int, hash uint64, syms []symbol, err error) {
+	timestamps := r.columns[columnTimestamps][r.pos[columnTimestamps]:]
+	delta, tWidth := binary.Varint(timestamps)
+	if tWidth <= 0 {
+		return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+	}
+	l, lWidth := binary.Uvarint(timestamps[tWidth:])
+	if lWidth <= 0 || len(timestamps) < tWidth+lWidth+8 {
+		return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+	}
+	size = int(l)
+	hash = binary.BigEndian.Uint64(timestamps[tWidth+lWidth:])
+	r.pos[columnTimestamps] += tWidth + lWidth + 8
+	r.prevTs += delta
+
+	if lines := r.columns[columnLines]; lines != nil {
+		start := r.pos[columnLines]
+		if start+size > len(lines) {
+			return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+		}
+		line = lines[start : start+size]
+		r.pos[columnLines] += size
+	}
+
+	metadata := r.columns[columnStructuredMetadata][r.pos[columnStructuredMetadata]:]
+	n, width := binary.Uvarint(metadata)
+	if width <= 0 {
+		return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+	}
+	offset := width
+	// If not enough space for the symbols, get a new buffer and put the old one back in the pool.
+	if int(n) > cap(r.symbolsBuf) {
+		if r.symbolsBuf != nil {
+			SymbolsPool.Put(r.symbolsBuf)
+		}
+		r.symbolsBuf = SymbolsPool.Get(int(n)).([]symbol)
+	}
+	syms = r.symbolsBuf[:0]
+	for i := uint64(0); i < n; i++ {
+		name, nWidth := binary.Uvarint(metadata[offset:])
+		if nWidth <= 0 {
+			return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+		}
+		value, vWidth := binary.Uvarint(metadata[offset+nWidth:])
+		if vWidth <= 0 {
+			return 0, nil, 0, 0, nil, fmt.Errorf("invalid data in chunk")
+		}
+		offset += nWidth + vWidth
+		syms = append(syms, symbol{Name: uint32(name), Value: uint32(value)})
+	}
+	r.pos[columnStructuredMetadata] += offset
+
+	return r.prevTs, line, size, hash, syms, nil
+}
+
+func (r *columnReader) done() bool {
+	return r.pos[columnTimestamps] >= len(r.columns[columnTimestamps])
+}
+
+func (r *columnReader) close() {
+	for i, c := range r.columns {
+		if c != nil {
+			BytesBufferPool.Put(c)
+			r.columns[i] = nil
+		}
+	}
+	if r.symbolsBuf != nil {
+		SymbolsPool.Put(r.symbolsBuf)
+		r.symbolsBuf = nil
+	}
+}
+
+// moveNextColumn moves the iterator to the next entry of a columnar block.
+func (si *bufferedIterator) moveNextColumn() (int64, []byte, labels.Labels, bool) {
+	if si.columns.done() {
+		return 0, nil, nil, false
+	}
+
+	ts, line, size, hash, syms, err := si.columns.next()
+	if err != nil {
+		si.err = err
+		return 0, nil, nil, false
+	}
+	si.currLineSize = size
+	si.currLineHash = hash
+
+	// TS and line length
+	decompressedBytes := int64(2 * binary.MaxVarintLen64)
+	if line != nil {
+		decompressedBytes += int64(size)
+	}
+	// Number of labels and label symbols
+	decompressedStructuredMetadataBytes := int64(binary.MaxVarintLen64 + len(syms)*2*binary.MaxVarintLen64)
+
+	si.stats.AddDecompressedLines(1)
+	si.stats.AddDecompressedStructuredMetadataBytes(decompressedStructuredMetadataBytes)
+	si.stats.AddDecompressedBytes(decompressedBytes + decompressedStructuredMetadataBytes)
+
+	return ts, line, si.symbolizer.Lookup(syms, si.currStructuredMetadata), true
+}
@@ -1,6 +1,7 @@
 package chunkenc
 
 import (
+	"fmt"
 	"io"
 	"time"
 
@@ -118,6 +119,23 @@ func (f Facade) Rebound(start, end model.Time, filter filter.Func) (chunk.Data,
 	}, nil
 }
 
+// Recompress returns a copy of the chunk compressed with enc.
+func (f Facade) Recompress(enc Encoding) (*Facade, error) {
+	c, ok := f.c.(*MemChunk)
+	if !ok {
+		return nil, fmt.Errorf("cannot recompress chunk of type %T", f.c)
+	}
+	newChunk, err := c.Recompress(enc)
+	if err != nil {
+		return nil, err
+	}
+	return &Facade{
+		c:          newChunk,
+		blockSize:  f.blockSize,
+		targetSize: f.targetSize,
+	}, nil
+}
+
 // UncompressedSize is a helper function to hide the type assertion kludge when wanting the uncompressed size of the Cortex interface encoding.Chunk.
 func UncompressedSize(c chunk.Data) (int, bool) {
 	f, ok := c.(*Facade)
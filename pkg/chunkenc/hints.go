@@ -0,0 +1,256 @@
+package chunkenc
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/grafana/regexp"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+const (
+	// trigramLen is the length of the substrings of the lines added to the bloom filter of the block hints.
+	trigramLen = 3
+	// bloomBitsPerTrigram gives a false positive rate of about 2% with bloomHashes hashes.
+	bloomBitsPerTrigram = 8
+	bloomHashes         = 4
+	// maxBloomRatio bounds the size of the bloom filter of a block relatively to the size
+	// of its entries. Blocks of mostly random content have about as many distinct trigrams
+	// as bytes, and don't get a bloom filter.
+	maxBloomRatio = 4
+)
+
+// blockHints summarise the entries of a block of a ChunkFormatV7 chunk. They allow
+// skipping the blocks none of whose entries can pass the filters of a query,
+// without decompressing them.
+//
+// They implement log.Sketch.
+type blockHints struct {
+	// bloom is a bloom filter of the trigrams of the lines, with ASCII letters lowercased,
+	// sized from the number of distinct trigrams. It is nil if the block has too many of them.
+	bloom []byte
+	// nonASCII is set if some lines of the block are not ASCII. Case insensitive filters
+	// match some of their letters with ASCII letters, which the bloom filter can't tell.
+	nonASCII bool
+	// structuredMetadataNames are the sorted names of the structured metadata of the entries.
+	structuredMetadataNames []string
+}
+
+var _ log.Sketch = &blockHints{}
+
+// newBlockHints builds the hints of the entries of the head block.
+func newBlockHints(hb HeadBlock, s *symbolizer) *blockHints {
+	trigrams := map[uint32]struct{}{}
+	names := map[uint32]struct{}{}
+	nonASCII := false
+	_ = hb.(*unorderedHeadBlock).forEntries(
+		context.Background(),
+		logproto.FORWARD,
+		0,
+		math.MaxInt64,
+		func(_ *stats.Context, _ int64, line string, structuredMetadataSymbols symbols) error {
+			for i := 0; i+trigramLen <= len(line); i++ {
+				trigrams[trigram(line[i], line[i+1], line[i+2])] = struct{}{}
+			}
+			if !nonASCII {
+				for i := 0; i < len(line); i++ {
+					if line[i] >= utf8.RuneSelf {
+						nonASCII = true
+						break
+					}
+				}
+			}
+			for _, sym := range structuredMetadataSymbols {
+				names[sym.Name] = struct{}{}
+			}
+			return nil
+		},
+	)
+
+	h := &blockHints{nonASCII: nonASCII}
+	if bits := len(trigrams) * bloomBitsPerTrigram; bits/8 <= hb.UncompressedSize()/maxBloomRatio {
+		h.bloom = make([]byte, bits/8+1)
+		for t := range trigrams {
+			h.add(t)
+		}
+	}
+	for name := range names {
+		h.structuredMetadataNames = append(h.structuredMetadataNames, s.lookup(name))
+	}
+	sort.Strings(h.structuredMetadataNames)
+	return h
+}
+
+// trigram packs three bytes of a line, lowercasing ASCII letters.
+func trigram(a, b, c byte) uint32 {
+	return uint32(lowerASCII(a))<<16 | uint32(lowerASCII(b))<<8 | uint32(lowerASCII(c))
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// bloomLocations returns the hashes locating the trigram t in the bloom filter,
+// derived from a single 64 bits hash by double hashing.
+func bloomLocations(t uint32) (uint32, uint32) {
+	// splitmix64 finalizer
+	h := uint64(t) + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return uint32(h), uint32(h>>32) | 1
+}
+
+func (h *blockHints) add(t uint32) {
+	bits := uint32(len(h.bloom) * 8)
+	h1, h2 := bloomLocations(t)
+	for i := uint32(0); i < bloomHashes; i++ {
+		loc := (h1 + i*h2) % bits
+		h.bloom[loc/8] |= 1 << (loc % 8)
+	}
+}
+
+func (h *blockHints) has(t uint32) bool {
+	bits := uint32(len(h.bloom) * 8)
+	h1, h2 := bloomLocations(t)
+	for i := uint32(0); i < bloomHashes; i++ {
+		loc := (h1 + i*h2) % bits
+		if h.bloom[loc/8]&(1<<(loc%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Test implements log.Checker. It returns false if none of the lines of the block contains match.
+func (h *blockHints) Test(match []byte, caseInsensitive bool, _ bool) bool {
+	// only ASCII letters are lowercased in the bloom filter, while case insensitive
+	// filters match other letters regardless of their case, and some of them with
+	// ASCII letters, like the Kelvin sign with k.
+	if h.bloom == nil || caseInsensitive && h.nonASCII {
+		return true
+	}
+	for i := 0; i+trigramLen <= len(match); i++ {
+		if caseInsensitive && (match[i]|match[i+1]|match[i+2]) >= utf8.RuneSelf {
+			continue
+		}
+		if !h.has(trigram(match[i], match[i+1], match[i+2])) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestRegex implements log.Checker. Regular expressions are not matched against the hints.
+func (h *blockHints) TestRegex(_ *regexp.Regexp) bool {
+	return true
+}
+
+// MayHaveStructuredMetadata implements log.Sketch.
+func (h *blockHints) MayHaveStructuredMetadata(name string) bool {
+	i := sort.SearchStrings(h.structuredMetadataNames, name)
+	return i < len(h.structuredMetadataNames) && h.structuredMetadataNames[i] == name
+}
+
+// mayMatch returns false if none of the entries of the block with hints h can
+// pass v, a log.StreamPipeline or a log.StreamSampleExtractor.
+func (h *blockHints) mayMatch(v interface{}) bool {
+	if h == nil {
+		return true
+	}
+	m, ok := v.(log.SketchMatcher)
+	return !ok || m.MatchesSketch(h)
+}
+
+const (
+	hintsFlagPresent byte = 1 << iota
+	hintsFlagNonASCII
+)
+
+// The block hints section of ChunkFormatV7 chunks holds the hints of every block, in the order of the blocks:
+//
+//	┌───────────────────────────────────────────────────────────────────────┐
+//	│ #blocks uvarint                                                       │
+//	├───────────────────────────────────────────────────────────────────────┤
+//	│ for each block: flags 1b | len(bloom) uvarint | bloom                 │
+//	│                 #names uvarint | for each name: len uvarint | name    │
+//	└───────────────────────────────────────────────────────────────────────┘
+//
+// The flags tell whether the block has hints, and whether it has non-ASCII lines.
+// Blocks without hints only store the flags, and match every query.
+func encodeBlockHints(blocks []block) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(blocks)))
+	for _, b := range blocks {
+		if b.hints == nil {
+			out = append(out, 0)
+			continue
+		}
+		flags := hintsFlagPresent
+		if b.hints.nonASCII {
+			flags |= hintsFlagNonASCII
+		}
+		out = append(out, flags)
+		out = binary.AppendUvarint(out, uint64(len(b.hints.bloom)))
+		out = append(out, b.hints.bloom...)
+		out = binary.AppendUvarint(out, uint64(len(b.hints.structuredMetadataNames)))
+		for _, name := range b.hints.structuredMetadataNames {
+			out = binary.AppendUvarint(out, uint64(len(name)))
+			out = append(out, name...)
+		}
+	}
+	return out
+}
+
+// decodeBlockHints decodes the block hints section b.
+func decodeBlockHints(b []byte) ([]*blockHints, error) {
+	db := decbuf{b: b}
+	num := db.uvarint()
+	hints := make([]*blockHints, 0, num)
+	for i := 0; i < num && db.err() == nil; i++ {
+		flags := db.byte()
+		if flags&hintsFlagPresent == 0 {
+			hints = append(hints, nil)
+			continue
+		}
+		h := &blockHints{nonASCII: flags&hintsFlagNonASCII != 0}
+		if bloom := db.bytes(db.uvarint()); len(bloom) > 0 {
+			h.bloom = bloom
+		}
+		numNames := db.uvarint()
+		for j := 0; j < numNames && db.err() == nil; j++ {
+			h.structuredMetadataNames = append(h.structuredMetadataNames, string(db.bytes(db.uvarint())))
+		}
+		hints = append(hints, h)
+	}
+	if db.err() != nil {
+		return nil, errors.Wrap(db.err(), "decoding block hints")
+	}
+	return hints, nil
+}
+
+// hintsSize returns the size of the block hints section of the blocks.
+func hintsSize(blocks []block) int {
+	size := binary.MaxVarintLen32 // #blocks
+	for _, b := range blocks {
+		size++ // flags
+		if b.hints == nil {
+			continue
+		}
+		size += binary.MaxVarintLen32 + len(b.hints.bloom)
+		size += binary.MaxVarintLen32
+		for _, name := range b.hints.structuredMetadataNames {
+			size += binary.MaxVarintLen32 + len(name)
+		}
+	}
+	return size
+}
@@ -64,6 +64,7 @@ const (
 	EncLZ4_4M
 	EncFlate
 	EncZstd
+	EncZstdDict
 )
 
 var supportedEncoding = []Encoding{
@@ -76,6 +77,7 @@ var supportedEncoding = []Encoding{
 	EncLZ4_4M,
 	EncFlate,
 	EncZstd,
+	EncZstdDict,
 }
 
 func (e Encoding) String() string {
@@ -100,6 +102,8 @@ func (e Encoding) String() string {
 		return "flate"
 	case EncZstd:
 		return "zstd"
+	case EncZstdDict:
+		return "zstd-dict"
 	default:
 		return "unknown"
 	}
@@ -31,6 +31,12 @@ const (
 	ChunkFormatV2
 	ChunkFormatV3
 	ChunkFormatV4
+	// ChunkFormatV5 adds the compressed dictionary of the chunk, used by EncZstdDict.
+	ChunkFormatV5
+	// ChunkFormatV6 stores the timestamps, lines and structured metadata of the blocks in separate columns.
+	ChunkFormatV6
+	// ChunkFormatV7 adds hints about the entries of every block to ChunkFormatV6, to skip the blocks which can't match a query.
+	ChunkFormatV7
 
 	blocksPerChunk = 10
 	maxLineLength  = 1024 * 1024 * 1024
@@ -42,6 +48,13 @@ const (
 
 	chunkMetasSectionIdx              = 1
 	chunkStructuredMetadataSectionIdx = 2
+	chunkDictionarySectionIdx         = 3
+	chunkBlockHintsSectionIdx         = 4
+
+	// maxDictionarySize is the maximum size of the dictionary of EncZstdDict chunks.
+	// The dictionary is used as the history preceding every block, so there is no
+	// point in making it larger than the window of the compressor.
+	maxDictionarySize = 64 * 1024
 )
 
 var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
@@ -134,8 +147,17 @@ type MemChunk struct {
 	encoding Encoding
 	headFmt  HeadBlockFmt
 
+	// dictionary of EncZstdDict chunks, built from the first block cut.
+	dict []byte
+	// dictionary compressed with zstd, as written in the chunk.
+	encodedDict []byte
+	dictPool    *ZstdDictPool
+
 	// compressed size of chunk. Set when chunk is cut or while decoding chunk from storage.
 	compressedSize int
+
+	// number of blocks dropped while decoding the chunk because their checksum did not match.
+	invalidBlocks int
 }
 
 type block struct {
@@ -147,6 +169,8 @@ type block struct {
 
 	offset           int // The offset of the block in the chunk.
 	uncompressedSize int // Total uncompressed size in bytes when the chunk is cut.
+
+	hints *blockHints // Set for blocks of ChunkFormatV7+ chunks.
 }
 
 // This block holds the un-compressed entries. Once it has enough data, this is
@@ -362,9 +386,9 @@ func panicIfInvalidFormat(chunkFmt byte, head HeadBlockFmt) {
 	if chunkFmt == ChunkFormatV2 && head != OrderedHeadBlockFmt {
 		panic("only OrderedHeadBlockFmt is supported for V2 chunks")
 	}
-	if chunkFmt == ChunkFormatV4 && head != UnorderedWithStructuredMetadataHeadBlockFmt {
+	if chunkFmt >= ChunkFormatV4 && head != UnorderedWithStructuredMetadataHeadBlockFmt {
 		fmt.Println("received head fmt", head.String())
-		panic("only UnorderedWithStructuredMetadataHeadBlockFmt is supported for V4 chunks")
+		panic("only UnorderedWithStructuredMetadataHeadBlockFmt is supported for V4+ chunks")
 	}
 }
 
@@ -372,6 +396,17 @@ func panicIfInvalidFormat(chunkFmt byte, head HeadBlockFmt) {
 func newMemChunkWithFormat(format byte, enc Encoding, head HeadBlockFmt, blockSize, targetSize int) *MemChunk {
 	panicIfInvalidFormat(format, head)
 
+	// Only ChunkFormatV5+ chunks store a dictionary. V4 chunks are upgraded to V5,
+	// while older formats do not support the head block format of V5 and fall back to plain zstd.
+	if enc == EncZstdDict {
+		switch {
+		case format == ChunkFormatV4:
+			format = ChunkFormatV5
+		case format < ChunkFormatV4:
+			enc = EncZstd
+		}
+	}
+
 	symbolizer := newSymbolizer()
 	return &MemChunk{
 		blockSize:  blockSize,  // The blockSize in bytes.
@@ -414,7 +449,7 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 	switch version {
 	case ChunkFormatV1:
 		bc.encoding = EncGZIP
-	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4:
+	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4, ChunkFormatV5, ChunkFormatV6, ChunkFormatV7:
 		// format v2+ has a byte for block encoding.
 		enc := Encoding(db.byte())
 		if db.err() != nil {
@@ -439,6 +474,38 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		return binary.BigEndian.Uint64(lenAndOffset[:8]), binary.BigEndian.Uint64(lenAndOffset[8:])
 	}
 
+	if version >= ChunkFormatV5 {
+		dictLen, dictOffset := readSectionLenAndOffset(chunkDictionarySectionIdx)
+		if dictLen > 0 {
+			encodedDict := b[dictOffset : dictOffset+dictLen]
+			expCRC := binary.BigEndian.Uint32(b[dictOffset+dictLen:])
+			if expCRC != crc32.Checksum(encodedDict, castagnoliTable) {
+				return nil, ErrInvalidChecksum
+			}
+			dict, err := zstdPlainCodecs.decode(nil, encodedDict)
+			if err != nil {
+				return nil, errors.Wrap(err, "decompressing dictionary")
+			}
+			bc.dict = dict
+			bc.encodedDict = encodedDict
+			bc.dictPool = NewZstdDictPool(dict)
+		}
+	}
+
+	var hints []*blockHints
+	if version >= ChunkFormatV7 {
+		hintsLen, hintsOffset := readSectionLenAndOffset(chunkBlockHintsSectionIdx)
+		hb := b[hintsOffset : hintsOffset+hintsLen]
+		expCRC := binary.BigEndian.Uint32(b[hintsOffset+hintsLen:])
+		if expCRC != crc32.Checksum(hb, castagnoliTable) {
+			return nil, ErrInvalidChecksum
+		}
+		var err error
+		if hints, err = decodeBlockHints(hb); err != nil {
+			return nil, err
+		}
+	}
+
 	metasOffset := uint64(0)
 	metasLen := uint64(0)
 	if version >= ChunkFormatV4 {
@@ -477,11 +544,15 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		}
 		l := db.uvarint()
 		blk.b = b[blk.offset : blk.offset+l]
+		if i < len(hints) {
+			blk.hints = hints[i]
+		}
 
 		// Verify checksums.
 		expCRC := binary.BigEndian.Uint32(b[blk.offset+l:])
 		if expCRC != crc32.Checksum(blk.b, castagnoliTable) {
 			_ = level.Error(util_log.Logger).Log("msg", "Checksum does not match for a block in chunk, this block will be skipped", "err", ErrInvalidChecksum)
+			bc.invalidBlocks++
 			continue
 		}
 
@@ -508,7 +579,7 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		if fromCheckpoint {
 			bc.symbolizer = symbolizerFromCheckpoint(lb)
 		} else {
-			symbolizer, err := symbolizerFromEnc(lb, GetReaderPool(bc.encoding))
+			symbolizer, err := symbolizerFromEnc(lb, bc.readerPool())
 			if err != nil {
 				return nil, err
 			}
@@ -572,6 +643,16 @@ func (c *MemChunk) BytesSize() int {
 
 		size += 8 + 8 // structured metadata offset and length
 	}
+
+	if c.format >= ChunkFormatV5 {
+		size += len(c.encodedDict) + crc32.Size // compressed dictionary and its crc
+		size += 8 + 8                           // dictionary offset and length
+	}
+
+	if c.format >= ChunkFormatV7 {
+		size += hintsSize(c.blocks) + crc32.Size // block hints and their crc
+		size += 8 + 8                            // block hints offset and length
+	}
 	return size
 }
 
@@ -610,6 +691,36 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 		return offset, errors.Wrap(err, "write blockMeta #entries")
 	}
 	offset += int64(n)
+
+	dictOffset := offset
+	if c.format >= ChunkFormatV5 {
+		crc32Hash.Reset()
+		if _, err := crc32Hash.Write(c.encodedDict); err != nil {
+			return offset, errors.Wrap(err, "write dictionary")
+		}
+		n, err := w.Write(crc32Hash.Sum(c.encodedDict))
+		if err != nil {
+			return offset, errors.Wrap(err, "write dictionary")
+		}
+		offset += int64(n)
+	}
+
+	hintsOffset := offset
+	hintsLength := 0
+	if c.format >= ChunkFormatV7 {
+		hints := encodeBlockHints(c.blocks)
+		hintsLength = len(hints)
+		crc32Hash.Reset()
+		if _, err := crc32Hash.Write(hints); err != nil {
+			return offset, errors.Wrap(err, "write block hints")
+		}
+		n, err := w.Write(crc32Hash.Sum(hints))
+		if err != nil {
+			return offset, errors.Wrap(err, "write block hints")
+		}
+		offset += int64(n)
+	}
+
 	structuredMetadataOffset := offset
 	structuredMetadataLength := 0
 
@@ -626,7 +737,7 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 			}
 		} else {
 			var err error
-			n, crcHash, err = c.symbolizer.SerializeTo(w, GetWriterPool(c.encoding))
+			n, crcHash, err = c.symbolizer.SerializeTo(w, c.writerPool())
 			if err != nil {
 				return offset, errors.Wrap(err, "write structured metadata")
 			}
@@ -683,6 +794,30 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 	}
 	offset += int64(n)
 
+	if c.format >= ChunkFormatV7 {
+		// Write block hints offset and length
+		eb.reset()
+		eb.putBE64int(hintsLength)
+		eb.putBE64int(int(hintsOffset))
+		n, err = w.Write(eb.get())
+		if err != nil {
+			return offset, errors.Wrap(err, "write block hints offset and length")
+		}
+		offset += int64(n)
+	}
+
+	if c.format >= ChunkFormatV5 {
+		// Write dictionary offset and length
+		eb.reset()
+		eb.putBE64int(len(c.encodedDict))
+		eb.putBE64int(int(dictOffset))
+		n, err = w.Write(eb.get())
+		if err != nil {
+			return offset, errors.Wrap(err, "write dictionary offset and length")
+		}
+		offset += int64(n)
+	}
+
 	if c.format >= ChunkFormatV4 {
 		// Write structured metadata offset and length
 		eb.reset()
@@ -914,11 +1049,22 @@ func (c *MemChunk) cut() error {
 		return nil
 	}
 
-	b, err := c.head.Serialise(GetWriterPool(c.encoding))
+	if c.encoding == EncZstdDict && c.format >= ChunkFormatV5 && c.dictPool == nil {
+		if err := c.buildDictionary(); err != nil {
+			return err
+		}
+	}
+
+	b, err := c.serialiseHead()
 	if err != nil {
 		return err
 	}
 
+	var hints *blockHints
+	if c.format >= ChunkFormatV7 {
+		hints = newBlockHints(c.head, c.symbolizer)
+	}
+
 	mint, maxt := c.head.Bounds()
 	c.blocks = append(c.blocks, block{
 		b:                b,
@@ -926,6 +1072,7 @@ func (c *MemChunk) cut() error {
 		mint:             mint,
 		maxt:             maxt,
 		uncompressedSize: c.head.UncompressedSize(),
+		hints:            hints,
 	})
 
 	c.cutBlockSize += len(b)
@@ -934,6 +1081,56 @@ func (c *MemChunk) cut() error {
 	return nil
 }
 
+// serialiseHead serialises the head block into a compressed block.
+func (c *MemChunk) serialiseHead() ([]byte, error) {
+	if c.format < ChunkFormatV6 {
+		return c.head.Serialise(c.writerPool())
+	}
+	hb, ok := c.head.(*unorderedHeadBlock)
+	if !ok {
+		return nil, fmt.Errorf("head block format %s is not supported by columnar chunks", c.head.Format())
+	}
+	return hb.SerialiseColumns(c.writerPool())
+}
+
+// buildDictionary builds the dictionary of the chunk from the uncompressed
+// content of the head block, before it is cut as the first block. Entries of
+// a stream tend to repeat the same content, which makes the first block a
+// good dictionary for the following ones. This matters the most for small
+// blocks, which otherwise lack the history to compress well.
+// The dictionary is stored compressed, so that chunks with few blocks do not
+// grow by the uncompressed size of the dictionary.
+func (c *MemChunk) buildDictionary() error {
+	raw, err := c.head.Serialise(&Noop)
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxDictionarySize {
+		// copy the tail so that the rest of the block can be garbage collected
+		raw = append([]byte(nil), raw[len(raw)-maxDictionarySize:]...)
+	}
+	c.dict = raw
+	c.encodedDict = zstdPlainCodecs.encode(nil, raw)
+	c.dictPool = NewZstdDictPool(raw)
+	return nil
+}
+
+// readerPool returns the pool to decompress the blocks of the chunk with.
+func (c *MemChunk) readerPool() ReaderPool {
+	if c.dictPool != nil {
+		return c.dictPool
+	}
+	return GetReaderPool(c.encoding)
+}
+
+// writerPool returns the pool to compress the blocks of the chunk with.
+func (c *MemChunk) writerPool() WriterPool {
+	if c.dictPool != nil {
+		return c.dictPool
+	}
+	return GetWriterPool(c.encoding)
+}
+
 // Bounds implements Chunk.
 func (c *MemChunk) Bounds() (fromT, toT time.Time) {
 	from, to := c.head.Bounds()
@@ -979,7 +1176,7 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 		}
 		lastMax = b.maxt
 
-		blockItrs = append(blockItrs, encBlock{c.encoding, c.format, c.symbolizer, b}.Iterator(ctx, pipeline))
+		blockItrs = append(blockItrs, encBlock{c.readerPool(), c.format, c.symbolizer, b}.Iterator(ctx, pipeline))
 	}
 
 	if !c.head.IsEmpty() {
@@ -1061,7 +1258,7 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 			ordered = false
 		}
 		lastMax = b.maxt
-		its = append(its, encBlock{c.encoding, c.format, c.symbolizer, b}.SampleIterator(ctx, extractor))
+		its = append(its, encBlock{c.readerPool(), c.format, c.symbolizer, b}.SampleIterator(ctx, extractor))
 	}
 
 	if !c.head.IsEmpty() {
@@ -1093,12 +1290,40 @@ func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 
 	for _, b := range c.blocks {
 		if maxt >= b.mint && b.maxt >= mint {
-			blocks = append(blocks, encBlock{c.encoding, c.format, c.symbolizer, b})
+			blocks = append(blocks, encBlock{c.readerPool(), c.format, c.symbolizer, b})
 		}
 	}
 	return blocks
 }
 
+// Verify checks that a chunk decoded from bytes is intact.
+// Unlike the iterators, which skip the blocks whose checksum does not match, it
+// fails if any block was dropped while decoding the chunk. Every block is also
+// fully decompressed and must hold the entries recorded in its metadata.
+func (c *MemChunk) Verify(ctx context.Context) error {
+	if c.invalidBlocks > 0 {
+		return errors.Wrapf(ErrInvalidChecksum, "%d blocks of the chunk have an invalid checksum", c.invalidBlocks)
+	}
+
+	pipeline := log.NewNoopPipeline().ForStream(labels.EmptyLabels())
+	for i, b := range c.blocks {
+		it := newEntryIterator(ctx, c.readerPool(), b.b, pipeline, c.format, c.symbolizer)
+		entries := 0
+		for it.Next() {
+			entries++
+		}
+		err := it.Err()
+		it.Close()
+		if err != nil {
+			return errors.Wrapf(err, "reading block %d", i)
+		}
+		if entries != b.numEntries {
+			return errors.Errorf("block %d has %d entries but %d are expected", i, entries, b.numEntries)
+		}
+	}
+	return nil
+}
+
 // Rebound builds a smaller chunk with logs having timestamp from start and end(both inclusive)
 func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, error) {
 	// add a millisecond to end time because the Chunk.Iterator considers end time to be non-inclusive.
@@ -1140,29 +1365,64 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 	return newChunk, nil
 }
 
+// Recompress returns a copy of the chunk with all its entries compressed with enc.
+// The chunk format, head block format and block sizes are preserved.
+func (c *MemChunk) Recompress(enc Encoding) (*MemChunk, error) {
+	from, through := c.Bounds()
+	// add a millisecond to end time because the Chunk.Iterator considers end time to be non-inclusive.
+	itr, err := c.Iterator(context.Background(), from, through.Add(time.Millisecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(labels.Labels{}))
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+
+	var newChunk *MemChunk
+	if c.blockSize > 0 {
+		newChunk = NewMemChunk(c.format, enc, c.headFmt, c.blockSize, c.targetSize)
+	} else {
+		newChunk = NewMemChunk(c.format, enc, c.headFmt, defaultBlockSize, c.CompressedSize())
+	}
+
+	for itr.Next() {
+		entry := itr.At()
+		if _, err := newChunk.Append(&entry); err != nil {
+			return nil, err
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := newChunk.Close(); err != nil {
+		return nil, err
+	}
+	return newChunk, nil
+}
+
 // encBlock is an internal wrapper for a block, mainly to avoid binding an encoding in a block itself.
 // This may seem roundabout, but the encoding is already a field on the parent MemChunk type. encBlock
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
 // chances of chunk<>block encoding drift in the codebase as the latter is parameterized by the former.
+// The pool is the one of the chunk, as chunks with a dictionary have their own pool.
 type encBlock struct {
-	enc        Encoding
+	pool       ReaderPool
 	format     byte
 	symbolizer *symbolizer
 	block
 }
 
 func (b encBlock) Iterator(ctx context.Context, pipeline log.StreamPipeline) iter.EntryIterator {
-	if len(b.b) == 0 {
+	if len(b.b) == 0 || !b.hints.mayMatch(pipeline) {
 		return iter.NoopEntryIterator
 	}
-	return newEntryIterator(ctx, GetReaderPool(b.enc), b.b, pipeline, b.format, b.symbolizer)
+	return newEntryIterator(ctx, b.pool, b.b, pipeline, b.format, b.symbolizer)
 }
 
 func (b encBlock) SampleIterator(ctx context.Context, extractor log.StreamSampleExtractor) iter.SampleIterator {
-	if len(b.b) == 0 {
+	if len(b.b) == 0 || !b.hints.mayMatch(extractor) {
 		return iter.NoopSampleIterator
 	}
-	return newSampleIterator(ctx, GetReaderPool(b.enc), b.b, b.format, extractor, b.symbolizer)
+	return newSampleIterator(ctx, b.pool, b.b, b.format, extractor, b.symbolizer)
 }
 
 func (b block) Offset() int {
@@ -1328,6 +1588,13 @@ type bufferedIterator struct {
 	symbolsBuf             []symbol      // The buffer for a single entry's symbols.
 	currStructuredMetadata labels.Labels // The current labels.
 
+	// columns reads the entries of ChunkFormatV6 blocks, instead of reader.
+	columns *columnReader
+	// skipLines is set when the lines column of ChunkFormatV6 blocks is not needed.
+	skipLines    bool
+	currLineSize int
+	currLineHash uint64
+
 	closed bool
 }
 
@@ -1349,17 +1616,31 @@ func (si *bufferedIterator) Next() bool {
 		return false
 	}
 
-	if !si.closed && si.reader == nil {
+	if !si.closed && si.reader == nil && si.columns == nil {
 		// initialize reader now, hopefully reusing one of the previous readers
 		var err error
-		si.reader, err = si.pool.GetReader(bytes.NewBuffer(si.origBytes))
+		if si.format >= ChunkFormatV6 {
+			si.columns, err = newColumnReader(si.pool, si.origBytes, !si.skipLines)
+		} else {
+			si.reader, err = si.pool.GetReader(bytes.NewBuffer(si.origBytes))
+		}
 		if err != nil {
 			si.err = err
 			return false
 		}
 	}
 
-	ts, line, structuredMetadata, ok := si.moveNext()
+	var (
+		ts                 int64
+		line               []byte
+		structuredMetadata labels.Labels
+		ok                 bool
+	)
+	if si.columns != nil {
+		ts, line, structuredMetadata, ok = si.moveNextColumn()
+	} else {
+		ts, line, structuredMetadata, ok = si.moveNext()
+	}
 	if !ok {
 		si.Close()
 		return false
@@ -1574,6 +1855,11 @@ func (si *bufferedIterator) close() {
 		si.reader = nil
 	}
 
+	if si.columns != nil {
+		si.columns.close()
+		si.columns = nil
+	}
+
 	if si.buf != nil {
 		BytesBufferPool.Put(si.buf)
 		si.buf = nil
@@ -1645,18 +1931,25 @@ func (e *entryBufferedIterator) Close() error {
 }
 
 func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, extractor log.StreamSampleExtractor, symbolizer *symbolizer) iter.SampleIterator {
-	return &sampleBufferedIterator{
+	it := &sampleBufferedIterator{
 		bufferedIterator: newBufferedIterator(ctx, pool, b, format, symbolizer),
 		extractor:        extractor,
 		stats:            stats.FromContext(ctx),
 	}
+	// columnar blocks allow to extract samples without decompressing the lines.
+	if sizeExtractor, ok := extractor.(log.LineSizeSampleExtractor); ok && format >= ChunkFormatV6 && !sizeExtractor.RequiresLine() {
+		it.sizeExtractor = sizeExtractor
+		it.skipLines = true
+	}
+	return it
 }
 
 type sampleBufferedIterator struct {
 	*bufferedIterator
 
-	extractor log.StreamSampleExtractor
-	stats     *stats.Context
+	extractor     log.StreamSampleExtractor
+	sizeExtractor log.LineSizeSampleExtractor
+	stats         *stats.Context
 
 	cur        logproto.Sample
 	currLabels log.LabelsResult
@@ -1664,14 +1957,27 @@ type sampleBufferedIterator struct {
 
 func (e *sampleBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		val, labels, ok := e.extractor.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
+		var (
+			val    float64
+			labels log.LabelsResult
+			ok     bool
+		)
+		if e.sizeExtractor != nil {
+			val, labels, ok = e.sizeExtractor.ProcessLineSize(e.currTs, e.currLineSize, e.currStructuredMetadata...)
+		} else {
+			val, labels, ok = e.extractor.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
+		}
 		if !ok {
 			continue
 		}
 		e.stats.AddPostFilterLines(1)
 		e.currLabels = labels
 		e.cur.Value = val
-		e.cur.Hash = xxhash.Sum64(e.currLine)
+		if e.columns != nil {
+			e.cur.Hash = e.currLineHash
+		} else {
+			e.cur.Hash = xxhash.Sum64(e.currLine)
+		}
 		e.cur.Timestamp = e.currTs
 		return true
 	}
@@ -40,6 +40,7 @@ var testEncoding = []Encoding{
 	EncSnappy,
 	EncFlate,
 	EncZstd,
+	EncZstdDict,
 }
 
 var (
@@ -73,6 +74,18 @@ var (
 			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
 			chunkFormat:  ChunkFormatV4,
 		},
+		{
+			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
+			chunkFormat:  ChunkFormatV5,
+		},
+		{
+			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
+			chunkFormat:  ChunkFormatV6,
+		},
+		{
+			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
+			chunkFormat:  ChunkFormatV7,
+		},
 	}
 )
 
@@ -294,6 +307,32 @@ func TestCorruptChunk(t *testing.T) {
 	}
 }
 
+func TestMemChunk_Verify(t *testing.T) {
+	for _, format := range allPossibleFormats {
+		format := format
+		t.Run(fmt.Sprintf("chunkFormat:%v_headBlockFmt:%v", format.chunkFormat, format.headBlockFmt), func(t *testing.T) {
+			t.Parallel()
+
+			c := NewMemChunk(format.chunkFormat, EncSnappy, format.headBlockFmt, testBlockSize, testTargetSize)
+			fillChunk(c)
+			require.NoError(t, c.Close())
+			b, err := c.Bytes()
+			require.NoError(t, err)
+
+			intact, err := NewByteChunk(b, testBlockSize, testTargetSize)
+			require.NoError(t, err)
+			require.NoError(t, intact.Verify(context.Background()))
+
+			// the corrupted block is skipped when decoding the chunk but fails its verification
+			b[intact.blocks[0].offset] ^= 0xff
+			corrupted, err := NewByteChunk(b, testBlockSize, testTargetSize)
+			require.NoError(t, err)
+			require.Len(t, corrupted.blocks, len(intact.blocks)-1)
+			require.ErrorIs(t, corrupted.Verify(context.Background()), ErrInvalidChecksum)
+		})
+	}
+}
+
 func TestReadFormatV1(t *testing.T) {
 	t.Parallel()
 
@@ -1480,6 +1519,395 @@ func TestMemChunk_Rebound(t *testing.T) {
 	}
 }
 
+func TestMemChunk_Recompress(t *testing.T) {
+	chkFrom := time.Unix(1, 0)
+	chkThrough := chkFrom.Add(time.Hour)
+	originalChunk := NewMemChunk(ChunkFormatV4, EncGZIP, UnorderedWithStructuredMetadataHeadBlockFmt, defaultBlockSize, 0)
+	for ts := chkFrom; ts.Before(chkThrough); ts = ts.Add(time.Second) {
+		_, err := originalChunk.Append(&logproto.Entry{
+			Line:               ts.String(),
+			Timestamp:          ts,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(labels.FromStrings("foo", ts.String())),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, originalChunk.Close())
+
+	newChunk, err := originalChunk.Recompress(EncZstd)
+	require.NoError(t, err)
+	require.Equal(t, EncZstd, newChunk.Encoding())
+	require.Equal(t, ChunkFormatV4, newChunk.format)
+	require.Equal(t, originalChunk.Size(), newChunk.Size())
+
+	// the recompressed chunk must survive a round trip through its serialized form
+	b, err := newChunk.Bytes()
+	require.NoError(t, err)
+	newChunk, err = NewByteChunk(b, defaultBlockSize, 0)
+	require.NoError(t, err)
+	require.Equal(t, EncZstd, newChunk.Encoding())
+
+	originalChunkItr, err := originalChunk.Iterator(context.Background(), chkFrom, chkThrough, logproto.FORWARD, log.NewNoopPipeline().ForStream(labels.Labels{}))
+	require.NoError(t, err)
+	newChunkItr, err := newChunk.Iterator(context.Background(), chkFrom, chkThrough, logproto.FORWARD, log.NewNoopPipeline().ForStream(labels.Labels{}))
+	require.NoError(t, err)
+	for originalChunkItr.Next() {
+		require.True(t, newChunkItr.Next())
+		require.Equal(t, originalChunkItr.At(), newChunkItr.At())
+	}
+	require.False(t, newChunkItr.Next())
+}
+
+func TestMemChunk_ZstdDict(t *testing.T) {
+	fill := func(chk *MemChunk) {
+		for i := 0; i < 5000; i++ {
+			_, err := chk.Append(&logproto.Entry{
+				Timestamp: time.Unix(0, int64(i+1)),
+				Line:      fmt.Sprintf(`level=info ts=2024-01-01T00:00:%02d.000Z caller=handler.go:123 msg="request completed" method=GET path=/api/v1/users/%d status=200 duration=%dms`, i%60, i, i%97),
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, chk.Close())
+	}
+
+	// small blocks benefit the most from the dictionary
+	dictChunk := NewMemChunk(ChunkFormatV4, EncZstdDict, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	fill(dictChunk)
+	zstdChunk := NewMemChunk(ChunkFormatV4, EncZstd, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	fill(zstdChunk)
+
+	require.Equal(t, ChunkFormatV5, dictChunk.format)
+	require.NotEmpty(t, dictChunk.dict)
+	require.LessOrEqual(t, len(dictChunk.dict), maxDictionarySize)
+
+	dictBytes, err := dictChunk.Bytes()
+	require.NoError(t, err)
+	zstdBytes, err := zstdChunk.Bytes()
+	require.NoError(t, err)
+	require.Less(t, len(dictBytes), len(zstdBytes))
+	require.LessOrEqual(t, len(dictBytes), dictChunk.BytesSize())
+
+	decoded, err := NewByteChunk(dictBytes, 4*1024, 0)
+	require.NoError(t, err)
+	require.Equal(t, EncZstdDict, decoded.Encoding())
+	require.Equal(t, dictChunk.dict, decoded.dict)
+
+	expectedItr, err := zstdChunk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+	require.NoError(t, err)
+	actualItr, err := decoded.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+	require.NoError(t, err)
+	for expectedItr.Next() {
+		require.True(t, actualItr.Next())
+		require.Equal(t, expectedItr.At(), actualItr.At())
+	}
+	require.False(t, actualItr.Next())
+
+	// a corrupted dictionary is detected
+	dictBytes[6] ^= 0xff
+	_, err = NewByteChunk(dictBytes, 4*1024, 0)
+	require.Equal(t, ErrInvalidChecksum, err)
+
+	// formats older than V4 do not support dictionaries
+	v3Chunk := NewMemChunk(ChunkFormatV3, EncZstdDict, UnorderedHeadBlockFmt, 4*1024, 0)
+	require.Equal(t, ChunkFormatV3, v3Chunk.format)
+	require.Equal(t, EncZstd, v3Chunk.Encoding())
+}
+
+func TestMemChunk_ZstdDictSize(t *testing.T) {
+	// chunks with few blocks get little from the dictionary, and must not pay for storing it
+	for _, entries := range []int{100, 1000} {
+		t.Run(fmt.Sprintf("%d entries", entries), func(t *testing.T) {
+			size := func(enc Encoding) int {
+				r := rand.New(rand.NewSource(42))
+				chk := NewMemChunk(ChunkFormatV4, enc, UnorderedWithStructuredMetadataHeadBlockFmt, defaultBlockSize, 0)
+				for i := 0; i < entries; i++ {
+					_, err := chk.Append(&logproto.Entry{
+						Timestamp: time.Unix(0, int64(i+1)),
+						Line:      fmt.Sprintf(`level=info caller=handler.go:123 msg="request completed" path=/api/v1/users/%d trace_id=%x status=200`, i, r.Int63()),
+					})
+					require.NoError(t, err)
+				}
+				require.NoError(t, chk.Close())
+				require.Len(t, chk.blocks, 1)
+
+				b, err := chk.Bytes()
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(b), chk.BytesSize())
+				return len(b)
+			}
+
+			dictSize, zstdSize := size(EncZstdDict), size(EncZstd)
+			require.LessOrEqual(t, dictSize, zstdSize+zstdSize/10+64)
+		})
+	}
+}
+
+func TestMemChunk_Columnar(t *testing.T) {
+	fill := func(chk *MemChunk) {
+		for i := 0; i < 5000; i++ {
+			var structuredMetadata push.LabelsAdapter
+			if i%2 == 0 {
+				structuredMetadata = push.LabelsAdapter{{Name: "user", Value: fmt.Sprintf("user-%d", i%10)}}
+			}
+			_, err := chk.Append(&logproto.Entry{
+				Timestamp:          time.Unix(0, int64(i+1)),
+				Line:               fmt.Sprintf(`level=info msg="request completed" path=/api/v1/users/%d duration=%dms`, i, i%97),
+				StructuredMetadata: structuredMetadata,
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, chk.Close())
+	}
+
+	for _, enc := range []Encoding{EncSnappy, EncZstdDict} {
+		t.Run(enc.String(), func(t *testing.T) {
+			rowChunk := NewMemChunk(ChunkFormatV4, enc, UnorderedWithStructuredMetadataHeadBlockFmt, 16*1024, 0)
+			fill(rowChunk)
+			columnarChunk := NewMemChunk(ChunkFormatV6, enc, UnorderedWithStructuredMetadataHeadBlockFmt, 16*1024, 0)
+			fill(columnarChunk)
+			require.Equal(t, ChunkFormatV6, columnarChunk.format)
+
+			b, err := columnarChunk.Bytes()
+			require.NoError(t, err)
+			decoded, err := NewByteChunk(b, 16*1024, 0)
+			require.NoError(t, err)
+			require.Equal(t, ChunkFormatV6, decoded.format)
+
+			expectedItr, err := rowChunk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+			require.NoError(t, err)
+			actualItr, err := decoded.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+			require.NoError(t, err)
+			for expectedItr.Next() {
+				require.True(t, actualItr.Next())
+				require.Equal(t, expectedItr.At(), actualItr.At())
+			}
+			require.False(t, actualItr.Next())
+			require.NoError(t, actualItr.Err())
+
+			for _, tc := range []struct {
+				name      string
+				ex        log.LineExtractor
+				stages    []log.Stage
+				skipLines bool
+			}{
+				{"count_over_time", log.CountExtractor, nil, true},
+				{"bytes_over_time", log.BytesExtractor, nil, true},
+				{"structured metadata filter", log.BytesExtractor, []log.Stage{
+					log.NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "user", "user-4")),
+				}, true},
+				{"line filter", log.CountExtractor, []log.Stage{
+					mustNewLineFilter(t, "users/42"),
+				}, false},
+			} {
+				t.Run(tc.name, func(t *testing.T) {
+					ex, err := log.NewLineSampleExtractor(tc.ex, tc.stages, nil, false, false)
+					require.NoError(t, err)
+
+					statsCtx, ctx := stats.NewContext(context.Background())
+					actual := decoded.SampleIterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), ex.ForStream(labels.Labels{}))
+					expected := rowChunk.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), ex.ForStream(labels.Labels{}))
+					var count int
+					for expected.Next() {
+						require.True(t, actual.Next())
+						require.Equal(t, expected.At(), actual.At())
+						require.Equal(t, expected.Labels(), actual.Labels())
+						count++
+					}
+					require.False(t, actual.Next())
+					require.NoError(t, actual.Err())
+					require.NoError(t, actual.Close())
+					require.NotZero(t, count)
+
+					// only the size of the lines is accounted for when they are skipped
+					chunkStats := statsCtx.Result(0, 0, 0).Querier.Store.Chunk
+					lineBytes := chunkStats.DecompressedBytes - chunkStats.DecompressedStructuredMetadataBytes
+					if tc.skipLines {
+						require.Equal(t, int64(5000*2*binary.MaxVarintLen64), lineBytes)
+					} else {
+						require.Greater(t, lineBytes, int64(5000*2*binary.MaxVarintLen64))
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestMemChunk_BlockHints(t *testing.T) {
+	fill := func(chk *MemChunk) {
+		for i := 0; i < 2000; i++ {
+			component := "api"
+			var structuredMetadata push.LabelsAdapter
+			if i >= 1000 {
+				component = "db"
+				structuredMetadata = push.LabelsAdapter{{Name: "query_id", Value: fmt.Sprintf("%d", i%10)}}
+			}
+			_, err := chk.Append(&logproto.Entry{
+				Timestamp:          time.Unix(0, int64(i+1)),
+				Line:               fmt.Sprintf(`level=info component=%s msg="request completed" duration=%dms`, component, i%97),
+				StructuredMetadata: structuredMetadata,
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, chk.Close())
+	}
+
+	withoutHints := NewMemChunk(ChunkFormatV6, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	fill(withoutHints)
+	withHints := NewMemChunk(ChunkFormatV7, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	fill(withHints)
+	b, err := withHints.Bytes()
+	require.NoError(t, err)
+	decoded, err := NewByteChunk(b, 4*1024, 0)
+	require.NoError(t, err)
+	require.Equal(t, ChunkFormatV7, decoded.format)
+	require.Greater(t, len(decoded.blocks), 10)
+	for i := range decoded.blocks {
+		require.Equal(t, withHints.blocks[i].hints, decoded.blocks[i].hints)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		stages []log.Stage
+		// skips is set when the blocks of the other component are expected to be skipped.
+		skips bool
+	}{
+		{"line filter", []log.Stage{mustNewLineFilter(t, "component=db")}, true},
+		{"case insensitive line filter", []log.Stage{mustNewFilterStage(t, "(?i)COMPONENT=API", log.LineMatchRegexp)}, true},
+		{"negative line filter", []log.Stage{mustNewFilterStage(t, "component=db", log.LineMatchNotEqual)}, false},
+		{"regexp line filter", []log.Stage{mustNewFilterStage(t, "component=(db|ap)", log.LineMatchRegexp)}, false},
+		{"structured metadata filter", []log.Stage{
+			log.NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "query_id", "4")),
+		}, true},
+		{"missing structured metadata filter", []log.Stage{
+			log.NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "query_id", "")),
+		}, false},
+		{"filter after parser", []log.Stage{log.NewLogfmtParser(false, false), mustNewLineFilter(t, "component=db")}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pipeline := log.NewPipeline(tc.stages).ForStream(labels.Labels{})
+			expected, err := withoutHints.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, pipeline)
+			require.NoError(t, err)
+			statsCtx, ctx := stats.NewContext(context.Background())
+			actual, err := decoded.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, pipeline)
+			require.NoError(t, err)
+			for expected.Next() {
+				require.True(t, actual.Next())
+				require.Equal(t, expected.At(), actual.At())
+			}
+			require.False(t, actual.Next())
+			require.NoError(t, actual.Err())
+
+			decompressedLines := statsCtx.Result(0, 0, 0).Querier.Store.Chunk.DecompressedLines
+			if tc.skips {
+				require.Less(t, decompressedLines, int64(1500))
+			} else {
+				require.Equal(t, int64(2000), decompressedLines)
+			}
+
+			ex, err := log.NewLineSampleExtractor(log.CountExtractor, tc.stages, nil, false, false)
+			require.NoError(t, err)
+			expectedSamples := withoutHints.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), ex.ForStream(labels.Labels{}))
+			actualSamples := decoded.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), ex.ForStream(labels.Labels{}))
+			for expectedSamples.Next() {
+				require.True(t, actualSamples.Next())
+				require.Equal(t, expectedSamples.At(), actualSamples.At())
+			}
+			require.False(t, actualSamples.Next())
+			require.NoError(t, actualSamples.Err())
+		})
+	}
+}
+
+func TestMemChunk_BlockHintsDefaultBlockSize(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	word := func() string {
+		b := make([]byte, 4+r.Intn(8))
+		for i := range b {
+			b[i] = byte('a' + r.Intn(26))
+		}
+		return string(b)
+	}
+	chk := NewMemChunk(ChunkFormatV7, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, defaultBlockSize, 0)
+	for i := 0; len(chk.blocks) < 3; i++ {
+		component := "api"
+		if len(chk.blocks) == 1 {
+			component = "db"
+		}
+		_, err := chk.Append(&logproto.Entry{
+			Timestamp: time.Unix(0, int64(i+1)),
+			Line:      fmt.Sprintf(`level=info component=%s user=%s msg="%s %s %s" duration=%dms`, component, word(), word(), word(), word(), r.Intn(1000)),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, chk.Close())
+
+	for _, b := range chk.blocks {
+		require.GreaterOrEqual(t, b.uncompressedSize, defaultBlockSize)
+		require.NotNil(t, b.hints.bloom)
+		// the blocks have many more distinct trigrams than a fixed size bloom filter would hold
+		require.Greater(t, len(b.hints.bloom)*8/bloomBitsPerTrigram, 8192)
+	}
+
+	// only the second block has component=db
+	require.False(t, chk.blocks[0].hints.Test([]byte("component=db"), false, false))
+	require.True(t, chk.blocks[1].hints.Test([]byte("component=db"), false, false))
+	require.False(t, chk.blocks[2].hints.Test([]byte("component=db"), false, false))
+}
+
+func TestMemChunk_BlockHintsNonASCII(t *testing.T) {
+	chk := NewMemChunk(ChunkFormatV7, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	// the Kelvin sign matches k case insensitively
+	for i := 0; i < 100; i++ {
+		line := "temperature=20C"
+		if i == 50 {
+			line = "temperature=300\u212a"
+		}
+		_, err := chk.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(i+1)), Line: line})
+		require.NoError(t, err)
+	}
+	require.NoError(t, chk.Close())
+	b, err := chk.Bytes()
+	require.NoError(t, err)
+	decoded, err := NewByteChunk(b, 4*1024, 0)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.blocks[0].hints.bloom)
+	require.True(t, decoded.blocks[0].hints.nonASCII)
+
+	for _, tc := range []struct {
+		filter string
+		lines  int
+	}{
+		{"(?i)300K", 1},
+		{"(?i)300kelvin", 0},
+		{"(?i)20C", 99},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			pipeline := log.NewPipeline([]log.Stage{mustNewFilterStage(t, tc.filter, log.LineMatchRegexp)}).ForStream(labels.Labels{})
+			it, err := decoded.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, pipeline)
+			require.NoError(t, err)
+			lines := 0
+			for it.Next() {
+				lines++
+			}
+			require.NoError(t, it.Err())
+			require.Equal(t, tc.lines, lines)
+		})
+	}
+
+	// ASCII filters are still checked against the bloom filter
+	require.False(t, decoded.blocks[0].hints.Test([]byte("300kelvin"), false, false))
+	require.True(t, decoded.blocks[0].hints.Test([]byte("300kelvin"), true, false))
+}
+
+func mustNewLineFilter(t *testing.T, match string) log.Stage {
+	return mustNewFilterStage(t, match, log.LineMatchEqual)
+}
+
+func mustNewFilterStage(t *testing.T, match string, mt log.LineMatchType) log.Stage {
+	f, err := log.NewFilter(match, mt)
+	require.NoError(t, err)
+	return log.NewLineFilterStage(f)
+}
+
 func buildTestMemChunk(t *testing.T, from, through time.Time) *MemChunk {
 	chk := NewMemChunk(ChunkFormatV3, EncGZIP, DefaultTestHeadBlockFmt, defaultBlockSize, 0)
 	for ; from.Before(through); from = from.Add(time.Second) {
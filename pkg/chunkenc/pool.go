@@ -104,7 +104,9 @@ func GetReaderPool(enc Encoding) ReaderPool {
 		return &Noop
 	case EncFlate:
 		return &Flate
-	case EncZstd:
+	case EncZstd, EncZstdDict:
+		// EncZstdDict chunks compress with the ZstdDictPool of their dictionary, see MemChunk.
+		// Without a dictionary they are compressed with plain zstd.
 		return &Zstd
 	default:
 		panic("unknown encoding")
@@ -273,6 +275,118 @@ func (pool *ZstdPool) PutWriter(writer io.WriteCloser) {
 	pool.writers.Put(writer)
 }
 
+// zstdDictID is the ID of the raw content dictionaries of ZstdDictPools.
+// Every pool has a single dictionary, so they can all use the same ID.
+const zstdDictID = 1
+
+// zstdCodecs pools the zstd encoders and decoders compressing whole buffers with
+// EncodeAll and DecodeAll. They are not shared across goroutines, so they run
+// without concurrency, which makes them cheap to create.
+type zstdCodecs struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func newZstdCodecs(dict []byte) *zstdCodecs {
+	c := &zstdCodecs{}
+	c.encoders.New = func() interface{} {
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if dict != nil {
+			opts = append(opts, zstd.WithEncoderDictRaw(zstdDictID, dict))
+		}
+		e, err := zstd.NewWriter(nil, opts...)
+		if err != nil {
+			panic(err) // never happens, dictionaries are much smaller than the maximum size.
+		}
+		return e
+	}
+	c.decoders.New = func() interface{} {
+		opts := []zstd.DOption{zstd.WithDecoderConcurrency(1)}
+		if dict != nil {
+			opts = append(opts, zstd.WithDecoderDictRaw(zstdDictID, dict))
+		}
+		d, err := zstd.NewReader(nil, opts...)
+		if err != nil {
+			panic(err) // never happens, dictionaries are much smaller than the maximum size.
+		}
+		runtime.SetFinalizer(d, (*zstd.Decoder).Close)
+		return d
+	}
+	return c
+}
+
+// encode appends the compressed src to dst.
+func (c *zstdCodecs) encode(dst, src []byte) []byte {
+	e := c.encoders.Get().(*zstd.Encoder)
+	defer c.encoders.Put(e)
+	return e.EncodeAll(src, dst)
+}
+
+// decode appends the decompressed src to dst.
+func (c *zstdCodecs) decode(dst, src []byte) ([]byte, error) {
+	d := c.decoders.Get().(*zstd.Decoder)
+	defer c.decoders.Put(d)
+	return d.DecodeAll(src, dst)
+}
+
+// zstdPlainCodecs compress without dictionary.
+var zstdPlainCodecs = newZstdCodecs(nil)
+
+// ZstdDictPool is a zstd compression pool using a raw content dictionary.
+// Unlike the other pools it is not shared: every chunk compressed with
+// EncZstdDict carries its own dictionary, and has its own pool.
+// Blocks are compressed and decompressed at once, so the codecs of the pool
+// are only created when the chunk is used, and do not start goroutines.
+type ZstdDictPool struct {
+	codecs *zstdCodecs
+}
+
+// NewZstdDictPool returns a pool compressing with the given dictionary.
+func NewZstdDictPool(dict []byte) *ZstdDictPool {
+	return &ZstdDictPool{codecs: newZstdCodecs(dict)}
+}
+
+// GetReader decompresses src, and returns a reader of its decompressed content.
+func (pool *ZstdDictPool) GetReader(src io.Reader) (io.Reader, error) {
+	b, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := pool.codecs.decode(nil, b)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(decompressed), nil
+}
+
+// PutReader is a no-op, the decoder is back in the pool once the content is decompressed.
+func (pool *ZstdDictPool) PutReader(_ io.Reader) {}
+
+// GetWriter returns a writer compressing to dst everything written to it once closed.
+func (pool *ZstdDictPool) GetWriter(dst io.Writer) io.WriteCloser {
+	return &zstdDictWriter{codecs: pool.codecs, dst: dst}
+}
+
+// PutWriter is a no-op, the encoder is back in the pool once the writer is closed.
+func (pool *ZstdDictPool) PutWriter(_ io.WriteCloser) {}
+
+type zstdDictWriter struct {
+	codecs *zstdCodecs
+	dst    io.Writer
+	buf    []byte
+}
+
+func (w *zstdDictWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *zstdDictWriter) Close() error {
+	_, err := w.dst.Write(w.codecs.encode(nil, w.buf))
+	w.buf = w.buf[:0]
+	return err
+}
+
 type LZ4Pool struct {
 	readers    sync.Pool
 	writers    sync.Pool
@@ -58,3 +58,36 @@ func TestPool(t *testing.T) {
 		_ = pprof.Lookup("goroutine").WriteTo(os.Stdout, 1)
 	}
 }
+
+func TestZstdDictPool(t *testing.T) {
+	dict := bytes.Repeat([]byte("level=info msg=\"request completed\" status=200 "), 100)
+	pool := NewZstdDictPool(dict)
+	goroutines := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			content := []byte("level=info msg=\"request completed\" status=500")
+
+			buf := bytes.NewBuffer(nil)
+			w := pool.GetWriter(buf)
+			defer pool.PutWriter(w)
+			_, err := w.Write(content)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			r, err := pool.GetReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			defer pool.PutReader(r)
+			b, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, content, b)
+		}()
+	}
+	wg.Wait()
+
+	// the codecs compress and decompress without goroutines
+	require.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/loki/v3/pkg/analytics"
+	"github.com/grafana/loki/v3/pkg/chunkenc"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
@@ -88,6 +89,12 @@ type Config struct {
 	RunOnce                     bool                `yaml:"_" doc:"hidden"`
 	TablesToCompact             int                 `yaml:"tables_to_compact"`
 	SkipLatestNTables           int                 `yaml:"skip_latest_n_tables"`
+	RecompressionEncoding       string              `yaml:"recompression_encoding" category:"experimental"`
+	RecompressionRateLimit      float64             `yaml:"recompression_rate_limit" category:"experimental"`
+	ConditionalWrites           bool                `yaml:"conditional_writes" category:"experimental"`
+	ScrubSampleRatio            float64             `yaml:"scrub_sample_ratio" category:"experimental"`
+	ScrubRateLimit              float64             `yaml:"scrub_rate_limit" category:"experimental"`
+	ScrubQuarantine             bool                `yaml:"scrub_quarantine" category:"experimental"`
 }
 
 // RegisterFlags registers flags.
@@ -109,6 +116,12 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.RunOnce, "compactor.run-once", false, "Run the compactor one time to cleanup and compact index files only (no retention applied)")
 	f.IntVar(&cfg.TablesToCompact, "compactor.tables-to-compact", 0, "Number of tables that compactor will try to compact. Newer tables are chosen when this is less than the number of tables available.")
 	f.IntVar(&cfg.SkipLatestNTables, "compactor.skip-latest-n-tables", 0, "Do not compact N latest tables. Together with -compactor.run-once and -compactor.tables-to-compact, this is useful when clearing compactor backlogs.")
+	f.StringVar(&cfg.RecompressionEncoding, "compactor.recompression-encoding", "", fmt.Sprintf("Rewrite the chunks of tables older than a day with this encoding while applying retention, so that changing the chunk encoding of the ingesters also applies to historical data. The original chunks are deleted after the retention delete delay. Chunks indexed in more than one table are not rewritten. Requires retention to be enabled. Empty disables recompression. Supported values are: %s.", chunkenc.SupportedEncoding()))
+	f.Float64Var(&cfg.RecompressionRateLimit, "compactor.recompression-rate-limit", 100, "Maximum number of chunks rewritten per second by each period of the schema when recompression is enabled.")
+	f.BoolVar(&cfg.ConditionalWrites, "compactor.conditional-writes", false, "Use conditional writes, so that the compactor fails instead of overwriting the files written concurrently by another compactor. A table compaction claims the <table>.compaction-lease object next to the table before uploading or deleting files, and fails if another compactor claimed it since the files were listed. The delete requests file is only uploaded if it wasn't modified since the compactor read or uploaded it; after a failed upload its version is read again, so that a conflict only fails one upload. Supported by the s3, gcs, azure and filesystem object stores.")
+	f.Float64Var(&cfg.ScrubSampleRatio, "compactor.scrub-sample-ratio", 0, "Ratio of the chunks of every table older than a day that are downloaded and verified once while applying retention, to find the chunks that can't be read back from the object store. Corrupted and missing chunks are logged and recorded in the scrub/corrupted file of the retention working directory of their period. Requires retention to be enabled. 0 disables scrubbing, 1 verifies all chunks.")
+	f.Float64Var(&cfg.ScrubRateLimit, "compactor.scrub-rate-limit", 100, "Maximum number of chunks verified per second by each period of the schema when scrubbing is enabled.")
+	f.BoolVar(&cfg.ScrubQuarantine, "compactor.scrub-quarantine", false, "Remove the corrupted and missing chunks found by the scrubber from the index, so that queries skip them instead of failing. The chunks are left in the object store.")
 
 	// Ring
 	skipFlags := []string{
@@ -153,6 +166,30 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.RecompressionEncoding != "" {
+		if !cfg.RetentionEnabled {
+			return errors.New("compactor.recompression-encoding requires retention to be enabled")
+		}
+		if _, err := chunkenc.ParseEncoding(cfg.RecompressionEncoding); err != nil {
+			return fmt.Errorf("invalid recompression encoding: %w", err)
+		}
+		if cfg.RecompressionRateLimit <= 0 {
+			return errors.New("compactor.recompression-rate-limit must be > 0")
+		}
+	}
+
+	if cfg.ScrubSampleRatio != 0 {
+		if !cfg.RetentionEnabled {
+			return errors.New("compactor.scrub-sample-ratio requires retention to be enabled")
+		}
+		if cfg.ScrubSampleRatio < 0 || cfg.ScrubSampleRatio > 1 {
+			return errors.New("compactor.scrub-sample-ratio must be between 0 and 1")
+		}
+		if cfg.ScrubRateLimit <= 0 {
+			return errors.New("compactor.scrub-rate-limit must be > 0")
+		}
+	}
+
 	return nil
 }
 
@@ -189,18 +226,22 @@ type Compactor struct {
 }
 
 type storeContainer struct {
-	tableMarker        retention.TableMarker
-	sweeper            *retention.Sweeper
-	indexStorageClient storage.Client
+	tableMarker         retention.TableMarker
+	tieringMarker       *retention.TieringMarker
+	recompressionMarker *retention.RecompressionMarker
+	scrubMarker         *retention.ScrubMarker
+	sweeper             *retention.Sweeper
+	indexStorageClient  storage.Client
 }
 
 type Limits interface {
 	deletion.Limits
 	retention.Limits
+	retention.TieringLimits
 	DefaultLimits() *validation.Limits
 }
 
-func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer, metricsNamespace string) (*Compactor, error) {
+func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, tieringStore string, tieringStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer, metricsNamespace string) (*Compactor, error) {
 	retentionEnabledStats.Set("false")
 	if cfg.RetentionEnabled {
 		retentionEnabledStats.Set("true")
@@ -256,7 +297,7 @@ func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.Objec
 	compactor.subservicesWatcher = services.NewFailureWatcher()
 	compactor.subservicesWatcher.WatchManager(compactor.subservices)
 
-	if err := compactor.init(objectStoreClients, deleteStoreClient, schemaConfig, limits, r); err != nil {
+	if err := compactor.init(objectStoreClients, deleteStoreClient, tieringStore, tieringStoreClient, schemaConfig, limits, r); err != nil {
 		return nil, fmt.Errorf("init compactor: %w", err)
 	}
 
@@ -264,7 +305,7 @@ func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.Objec
 	return compactor, nil
 }
 
-func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer) error {
+func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, tieringStore string, tieringStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer) error {
 	err := chunk_util.EnsureDirectory(c.cfg.WorkingDirectory)
 	if err != nil {
 		return err
@@ -293,8 +334,6 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 
 		if c.cfg.RetentionEnabled {
 			var (
-				raw              client.ObjectClient
-				encoder          client.KeyEncoder
 				name             = fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())
 				retentionWorkDir = filepath.Join(c.cfg.WorkingDirectory, "retention", name)
 				r                = prometheus.WrapRegistererWith(prometheus.Labels{"from": name}, r)
@@ -313,15 +352,19 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			// remove markers from the store dir after copying them to period specific dirs.
 			legacyMarkerDirs[period.ObjectType] = struct{}{}
 
-			if casted, ok := objectClient.(client.PrefixedObjectClient); ok {
-				raw = casted.GetDownstream()
-			} else {
-				raw = objectClient
-			}
-			if _, ok := raw.(*local.FSObjectClient); ok {
-				encoder = client.FSEncoder
+			var (
+				hotChunkClient  = client.NewClient(objectClient, chunkKeyEncoder(objectClient), schemaConfig)
+				coldChunkClient client.Client
+				chunkClient     client.Client = hotChunkClient
+			)
+			// Periods stored in the tiering store already have nothing to move. Moving their chunks
+			// would delete them, since the hot and the cold copy would be the same object.
+			if tieringStoreClient != nil && tieringStore != period.ObjectType {
+				// chunks older than the tiering age of their tenant are moved to the tiering store,
+				// so retention has to look for chunks in both stores.
+				coldChunkClient = client.NewClient(tieringStoreClient, chunkKeyEncoder(tieringStoreClient), schemaConfig)
+				chunkClient = client.NewTieredClient(hotChunkClient, coldChunkClient, nil)
 			}
-			chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
 
 			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
 			if err != nil {
@@ -332,6 +375,31 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			if err != nil {
 				return fmt.Errorf("failed to init table marker: %w", err)
 			}
+
+			if coldChunkClient != nil {
+				sc.tieringMarker = retention.NewTieringMarker(sc.tableMarker, hotChunkClient, coldChunkClient, limits, r)
+				sc.tableMarker = sc.tieringMarker
+			}
+
+			if c.cfg.RecompressionEncoding != "" {
+				encoding, err := chunkenc.ParseEncoding(c.cfg.RecompressionEncoding)
+				if err != nil {
+					return err
+				}
+				sc.recompressionMarker, err = retention.NewRecompressionMarker(sc.tableMarker, retentionWorkDir, chunkClient, encoding, c.cfg.RecompressionRateLimit, r)
+				if err != nil {
+					return fmt.Errorf("failed to init recompression marker: %w", err)
+				}
+				sc.tableMarker = sc.recompressionMarker
+			}
+
+			if c.cfg.ScrubSampleRatio > 0 {
+				sc.scrubMarker, err = retention.NewScrubMarker(sc.tableMarker, retentionWorkDir, chunkClient, c.cfg.ScrubSampleRatio, c.cfg.ScrubQuarantine, c.cfg.ScrubRateLimit, r)
+				if err != nil {
+					return fmt.Errorf("failed to init scrub marker: %w", err)
+				}
+				sc.tableMarker = sc.scrubMarker
+			}
 		}
 
 		c.storeContainers[from] = sc
@@ -354,9 +422,25 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 	return nil
 }
 
+// chunkKeyEncoder returns the encoder for the keys of chunks stored with the given object client.
+func chunkKeyEncoder(objectClient client.ObjectClient) client.KeyEncoder {
+	raw := objectClient
+	for {
+		wrapper, ok := raw.(interface{ GetDownstream() client.ObjectClient })
+		if !ok {
+			break
+		}
+		raw = wrapper.GetDownstream()
+	}
+	if _, ok := raw.(*local.FSObjectClient); ok {
+		return client.FSEncoder
+	}
+	return nil
+}
+
 func (c *Compactor) initDeletes(objectClient client.ObjectClient, r prometheus.Registerer, limits Limits) error {
 	deletionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "deletion")
-	store, err := deletion.NewDeleteStore(deletionWorkDir, storage.NewIndexStorageClient(objectClient, c.cfg.DeleteRequestStoreKeyPrefix))
+	store, err := deletion.NewDeleteStore(deletionWorkDir, storage.NewIndexStorageClient(objectClient, c.cfg.DeleteRequestStoreKeyPrefix), c.cfg.ConditionalWrites)
 	if err != nil {
 		return err
 	}
@@ -641,7 +725,7 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 	defer c.tableLocker.unlockTable(tableName)
 
 	table, err := newTable(ctx, filepath.Join(c.cfg.WorkingDirectory, tableName), sc.indexStorageClient, indexCompactor,
-		schemaCfg, sc.tableMarker, c.expirationChecker, c.cfg.UploadParallelism)
+		schemaCfg, sc.tableMarker, c.expirationChecker, c.cfg.UploadParallelism, c.cfg.ConditionalWrites)
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
 		return err
@@ -653,12 +737,32 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 		intervalMayHaveExpiredChunks = c.expirationChecker.IntervalMayHaveExpiredChunks(interval, "")
 	}
 
-	err = table.compact(intervalMayHaveExpiredChunks)
+	tableMayHaveChunksToMove := applyRetention && sc.tieringMarker != nil && sc.tieringMarker.TableMayHaveChunksToMove(tableName)
+
+	tableMayHaveChunksToRecompress := applyRetention && sc.recompressionMarker != nil && sc.recompressionMarker.TableMayHaveChunksToRecompress(tableName)
+
+	tableMayHaveChunksToScrub := applyRetention && sc.scrubMarker != nil && sc.scrubMarker.TableMayHaveChunksToScrub(tableName)
+
+	err = table.compact(intervalMayHaveExpiredChunks || tableMayHaveChunksToMove || tableMayHaveChunksToRecompress || tableMayHaveChunksToScrub)
+	if tableMayHaveChunksToRecompress {
+		if err := sc.recompressionMarker.TableProcessed(tableName, err); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to finish recompression of table", "table", tableName, "err", err)
+		}
+	}
+	if tableMayHaveChunksToScrub {
+		if err := sc.scrubMarker.TableProcessed(tableName, err); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to finish scrubbing of table", "table", tableName, "err", err)
+		}
+	}
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to compact files", "table", tableName, "err", err)
 		return err
 	}
 
+	if tableMayHaveChunksToMove {
+		sc.tieringMarker.TableProcessed(tableName)
+	}
+
 	if !applyRetention {
 		c.metrics.skippedCompactingLockedTables.WithLabelValues(tableName).Set(0)
 	}
@@ -675,6 +779,11 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) (err
 
 	if applyRetention {
 		c.expirationChecker.MarkPhaseStarted()
+		for _, sc := range c.storeContainers {
+			if sc.tieringMarker != nil {
+				sc.tieringMarker.MarkPhaseStarted()
+			}
+		}
 	}
 
 	defer func() {
@@ -61,7 +61,7 @@ func setupTestCompactor(t *testing.T, objectClients map[config.DayTime]client.Ob
 	overrides, err := validation.NewOverrides(defaultLimits, nil)
 	require.NoError(t, err)
 
-	c, err := NewCompactor(cfg, objectClients, objectClients[periodConfigs[len(periodConfigs)-1].From], config.SchemaConfig{
+	c, err := NewCompactor(cfg, objectClients, objectClients[periodConfigs[len(periodConfigs)-1].From], "", nil, config.SchemaConfig{
 		Configs: periodConfigs,
 	}, overrides, prometheus.NewPedanticRegistry(), constants.Loki)
 	require.NoError(t, err)
@@ -209,6 +209,40 @@ func TestCompactor_RunCompactionMultipleStores(t *testing.T) {
 	}
 }
 
+func TestCompactor_TieringStore(t *testing.T) {
+	tempDir := t.TempDir()
+
+	periodConfigs := []config.PeriodConfig{
+		{From: config.DayTime{Time: model.Time(0)}, IndexType: "dummy", ObjectType: "fs_01"},
+		{From: config.DayTime{Time: model.Time(1000)}, IndexType: "dummy", ObjectType: "fs_02"},
+	}
+	objectClients := map[config.DayTime]client.ObjectClient{}
+	for _, period := range periodConfigs {
+		objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: filepath.Join(tempDir, period.ObjectType)})
+		require.NoError(t, err)
+		objectClients[period.From] = objectClient
+	}
+
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.WorkingDirectory = filepath.Join(tempDir, workingDirName)
+	cfg.RetentionEnabled = true
+	cfg.DeleteRequestStore = "fs_02"
+
+	overrides, err := validation.NewOverrides(validation.Limits{}, nil)
+	require.NoError(t, err)
+
+	// chunks are moved to the object store of the second period
+	c, err := NewCompactor(cfg, objectClients, objectClients[periodConfigs[1].From], "fs_02", objectClients[periodConfigs[1].From], config.SchemaConfig{
+		Configs: periodConfigs,
+	}, overrides, prometheus.NewPedanticRegistry(), constants.Loki)
+	require.NoError(t, err)
+
+	require.NotNil(t, c.storeContainers[periodConfigs[0].From].tieringMarker)
+	// the chunks of the second period are in the tiering store already, moving them would delete them
+	require.Nil(t, c.storeContainers[periodConfigs[1].From].tieringMarker)
+}
+
 func Test_schemaPeriodForTable(t *testing.T) {
 	indexFromTime := func(t time.Time) string {
 		return fmt.Sprintf("%d", t.Unix()/int64(24*time.Hour/time.Second))
@@ -59,8 +59,8 @@ type deleteRequestsStore struct {
 }
 
 // NewDeleteStore creates a store for managing delete requests.
-func NewDeleteStore(workingDirectory string, indexStorageClient storage.Client) (DeleteRequestsStore, error) {
-	indexClient, err := newDeleteRequestsTable(workingDirectory, indexStorageClient)
+func NewDeleteStore(workingDirectory string, indexStorageClient storage.Client, conditionalWrites bool) (DeleteRequestsStore, error) {
+	indexClient, err := newDeleteRequestsTable(workingDirectory, indexStorageClient, conditionalWrites)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +248,7 @@ func setup(t *testing.T) *testContext {
 		Directory: objectStorePath,
 	})
 	require.NoError(t, err)
-	ds, err := NewDeleteStore(workingDir, storage.NewIndexStorageClient(objectClient, ""))
+	ds, err := NewDeleteStore(workingDir, storage.NewIndexStorageClient(objectClient, ""), false)
 	require.NoError(t, err)
 
 	store := ds.(*deleteRequestsStore)
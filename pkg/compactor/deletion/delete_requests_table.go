@@ -14,6 +14,7 @@ import (
 	"go.etcd.io/bbolt"
 
 	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
@@ -25,6 +26,14 @@ type deleteRequestsTable struct {
 	indexStorageClient storage.Client
 	dbPath             string
 
+	// conditionalWrites makes the uploads fail if the file was modified in
+	// the object store since it was downloaded or last uploaded, which happens
+	// when several compactors handle the delete requests.
+	conditionalWrites bool
+	// version is the version of the file in the object store when conditionalWrites is set,
+	// empty if the file doesn't exist yet.
+	version string
+
 	boltdbIndexClient *local.BoltIndexClient
 	db                *bbolt.DB
 	done              chan struct{}
@@ -33,7 +42,7 @@ type deleteRequestsTable struct {
 
 const deleteRequestsIndexFileName = DeleteRequestsTableName + ".gz"
 
-func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client) (index.Client, error) {
+func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client, conditionalWrites bool) (index.Client, error) {
 	dbPath := filepath.Join(workingDirectory, DeleteRequestsTableName, DeleteRequestsTableName)
 	boltdbIndexClient, err := local.NewBoltDBIndexClient(local.BoltDBConfig{Directory: filepath.Dir(dbPath)})
 	if err != nil {
@@ -43,6 +52,7 @@ func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.
 	table := &deleteRequestsTable{
 		indexStorageClient: indexStorageClient,
 		dbPath:             dbPath,
+		conditionalWrites:  conditionalWrites,
 		boltdbIndexClient:  boltdbIndexClient,
 		done:               make(chan struct{}),
 	}
@@ -64,6 +74,14 @@ func (t *deleteRequestsTable) init() error {
 		level.Error(util_log.Logger).Log("msg", fmt.Sprintf("failed to remove temp file %s", tempFilePath), "err", err)
 	}
 
+	if t.conditionalWrites {
+		// the version is read before the file, so that the uploads fail if the file
+		// is modified after its version is read.
+		if err := t.reloadVersion(); err != nil {
+			return err
+		}
+	}
+
 	_, err := os.Stat(t.dbPath)
 	if err != nil {
 		err = storage.DownloadFileFromStorage(t.dbPath, true,
@@ -79,6 +97,16 @@ func (t *deleteRequestsTable) init() error {
 	return err
 }
 
+// reloadVersion reads the version of the file in the object store.
+func (t *deleteRequestsTable) reloadVersion() error {
+	version, err := t.indexStorageClient.FileVersion(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName)
+	if err != nil && !t.indexStorageClient.IsFileNotFoundErr(err) {
+		return err
+	}
+	t.version = version
+	return nil
+}
+
 func (t *deleteRequestsTable) loop() {
 	uploadTicker := time.NewTicker(5 * time.Minute)
 	defer uploadTicker.Stop()
@@ -143,7 +171,24 @@ func (t *deleteRequestsTable) uploadFile() error {
 		return err
 	}
 
-	return t.indexStorageClient.PutFile(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName, f)
+	if !t.conditionalWrites {
+		return t.indexStorageClient.PutFile(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName, f)
+	}
+
+	cond := client.WriteCondition{IfMatch: t.version, IfNoneMatch: t.version == ""}
+	version, err := t.indexStorageClient.PutFileIf(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName, f, cond)
+	if errors.Is(err, client.ErrPreconditionFailed) {
+		// reload the version, so that the next upload doesn't fail because of this conflict again.
+		if verr := t.reloadVersion(); verr != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to reload the version of the delete requests file", "err", verr)
+		}
+		return fmt.Errorf("delete requests file was modified by another compactor: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	t.version = version
+	return nil
 }
 
 func (t *deleteRequestsTable) Stop() {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
@@ -26,7 +27,7 @@ func TestDeleteRequestsTable(t *testing.T) {
 		Directory: objectStorePath,
 	})
 	require.NoError(t, err)
-	indexClient, err := newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""))
+	indexClient, err := newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""), false)
 	require.NoError(t, err)
 
 	// see if delete requests db was created
@@ -65,7 +66,7 @@ func TestDeleteRequestsTable(t *testing.T) {
 	require.NoError(t, err)
 
 	// re-create table to see if the db gets downloaded locally since it does not exist anymore
-	indexClient, err = newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""))
+	indexClient, err = newDeleteRequestsTable(workingDir, storage.NewIndexStorageClient(objectClient, ""), false)
 	require.NoError(t, err)
 	defer indexClient.Stop()
 
@@ -76,6 +77,45 @@ func TestDeleteRequestsTable(t *testing.T) {
 	testutil.VerifySingleIndexFile(t, index.Query{}, testDeleteRequestsTable.db, local.IndexBucketName, 0, 20)
 }
 
+func TestDeleteRequestsTable_ConditionalWrites(t *testing.T) {
+	tempDir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{
+		Directory: filepath.Join(tempDir, "object-store"),
+	})
+	require.NoError(t, err)
+
+	newTable := func(workingDir string) *deleteRequestsTable {
+		indexClient, err := newDeleteRequestsTable(filepath.Join(tempDir, workingDir), storage.NewIndexStorageClient(objectClient, ""), true)
+		require.NoError(t, err)
+		t.Cleanup(indexClient.Stop)
+		return indexClient.(*deleteRequestsTable)
+	}
+
+	// the first upload creates the file
+	first := newTable("first")
+	batch := first.NewWriteBatch()
+	testutil.AddRecordsToBatch(batch, DeleteRequestsTableName, 0, 10)
+	require.NoError(t, first.BatchWrite(context.Background(), batch))
+	require.NoError(t, first.uploadFile())
+	require.NoError(t, first.uploadFile())
+
+	// another compactor downloads the file and uploads it with more records
+	second := newTable("second")
+	testutil.VerifySingleIndexFile(t, index.Query{}, second.db, local.IndexBucketName, 0, 10)
+	batch = second.NewWriteBatch()
+	testutil.AddRecordsToBatch(batch, DeleteRequestsTableName, 10, 10)
+	require.NoError(t, second.BatchWrite(context.Background(), batch))
+	require.NoError(t, second.uploadFile())
+
+	// which makes the next upload of the first compactor fail instead of overwriting it
+	require.ErrorIs(t, first.uploadFile(), client.ErrPreconditionFailed)
+
+	// the failure reloads the version, so that a conflict doesn't fail all the later uploads
+	require.NoError(t, first.uploadFile())
+	require.ErrorIs(t, second.uploadFile(), client.ErrPreconditionFailed)
+	require.NoError(t, second.uploadFile())
+}
+
 func checkRecordsInStorage(t *testing.T, storageFilePath string, start, numRecords int) {
 	tempDir := t.TempDir()
 	tempFilePath := filepath.Join(tempDir, DeleteRequestsTableName)
@@ -0,0 +1,354 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	logql_log "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+const (
+	recompressionFolder = "recompression"
+
+	// recompressionMinTableAge is how long after the end of a table its chunks are recompressed,
+	// so that chunks flushed late by the ingesters are recompressed as well.
+	recompressionMinTableAge = 24 * time.Hour
+
+	statusSkipped = "skipped"
+)
+
+type recompressionMetrics struct {
+	chunksTotal       *prometheus.CounterVec
+	readBytesTotal    prometheus.Counter
+	writtenBytesTotal prometheus.Counter
+}
+
+func newRecompressionMetrics(r prometheus.Registerer) *recompressionMetrics {
+	return &recompressionMetrics{
+		chunksTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_recompression_chunks_total",
+			Help:      "Total number of chunks processed by the recompression job.",
+		}, []string{"status"}),
+		readBytesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_recompression_read_bytes_total",
+			Help:      "Total number of bytes of chunks replaced by the recompression job.",
+		}),
+		writtenBytesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_recompression_written_bytes_total",
+			Help:      "Total number of bytes of chunks written by the recompression job.",
+		}),
+	}
+}
+
+// RecompressionMarker is a TableMarker rewriting the chunks of historical
+// tables with another chunk encoding.
+//
+// Every chunk is rewritten with the new encoding, verified to hold the same
+// entries as the original chunk and uploaded. Its index entry then replaces
+// the one of the original chunk. The original chunks are only marked for
+// deletion once the table has been compacted and its index uploaded, see
+// TableProcessed, so readers never end up with references to deleted chunks.
+//
+// Tables whose chunks have all been rewritten are recorded in the working
+// directory, so that the job resumes where it left off after a restart.
+// Chunks already using the new encoding are skipped, which makes reprocessing
+// a partially rewritten table cheap.
+type RecompressionMarker struct {
+	TableMarker
+
+	workingDirectory string
+	chunkClient      client.Client
+	encoding         chunkenc.Encoding
+	limiter          *rate.Limiter
+	metrics          *recompressionMetrics
+
+	mtx sync.Mutex
+	// tables with all their chunks rewritten
+	done map[string]struct{}
+	// tables with chunks that failed to be rewritten
+	incomplete map[string]struct{}
+	// original chunks to delete once the index of their table is uploaded
+	replaced map[string][]string
+}
+
+// NewRecompressionMarker returns a RecompressionMarker rewriting up to chunksPerSecond chunks per second with the given encoding.
+func NewRecompressionMarker(next TableMarker, workingDirectory string, chunkClient client.Client, encoding chunkenc.Encoding, chunksPerSecond float64, r prometheus.Registerer) (*RecompressionMarker, error) {
+	m := &RecompressionMarker{
+		TableMarker:      next,
+		workingDirectory: workingDirectory,
+		chunkClient:      chunkClient,
+		encoding:         encoding,
+		limiter:          rate.NewLimiter(rate.Limit(chunksPerSecond), 1),
+		metrics:          newRecompressionMetrics(r),
+		incomplete:       map[string]struct{}{},
+		replaced:         map[string][]string{},
+	}
+
+	var err error
+	m.done, err = m.loadProgress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recompression progress: %w", err)
+	}
+	return m, nil
+}
+
+// progressFile holds the names of the tables fully rewritten with the encoding, one per line.
+func (t *RecompressionMarker) progressFile() string {
+	return filepath.Join(t.workingDirectory, recompressionFolder, t.encoding.String())
+}
+
+func (t *RecompressionMarker) loadProgress() (map[string]struct{}, error) {
+	return readLines(t.progressFile())
+}
+
+func (t *RecompressionMarker) saveProgress(tableName string) error {
+	return appendLine(t.progressFile(), tableName)
+}
+
+// TableMayHaveChunksToRecompress returns whether the table is old enough to be
+// recompressed and has not been fully recompressed yet.
+func (t *RecompressionMarker) TableMayHaveChunksToRecompress(tableName string) bool {
+	t.mtx.Lock()
+	_, done := t.done[tableName]
+	t.mtx.Unlock()
+	if done {
+		return false
+	}
+
+	interval := ExtractIntervalFromTableName(tableName)
+	return interval.End.Before(model.Now().Add(-recompressionMinTableAge))
+}
+
+// MarkForDelete applies retention to the table and then rewrites its chunks with the new encoding.
+// Failing to rewrite chunks does not fail retention, the table is processed again in the next run.
+func (t *RecompressionMarker) MarkForDelete(ctx context.Context, tableName, userID string, indexProcessor IndexProcessor, logger log.Logger) (bool, bool, error) {
+	empty, modified, err := t.TableMarker.MarkForDelete(ctx, tableName, userID, indexProcessor, logger)
+	if err != nil || empty || !t.TableMayHaveChunksToRecompress(tableName) {
+		return empty, modified, err
+	}
+
+	replaced, err := t.recompressChunks(ctx, tableName, indexProcessor, logger)
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to recompress chunks", "err", err)
+		t.markIncomplete(tableName)
+	}
+	if len(replaced) == 0 {
+		return empty, modified, nil
+	}
+
+	t.mtx.Lock()
+	t.replaced[tableName] = append(t.replaced[tableName], replaced...)
+	t.mtx.Unlock()
+	return empty, true, nil
+}
+
+// TableProcessed must be called after all the index sets of a table have been
+// processed, with the error of uploading the index of the table, if any.
+// Only once the index referencing the rewritten chunks is uploaded are the
+// original chunks marked for deletion.
+func (t *RecompressionMarker) TableProcessed(tableName string, uploadErr error) error {
+	t.mtx.Lock()
+	replaced := t.replaced[tableName]
+	_, incomplete := t.incomplete[tableName]
+	delete(t.replaced, tableName)
+	delete(t.incomplete, tableName)
+	t.mtx.Unlock()
+
+	if uploadErr != nil {
+		// the index still references the original chunks, the rewritten ones are written again in the next run
+		return nil
+	}
+
+	if len(replaced) > 0 {
+		markerWriter, err := NewMarkerStorageWriter(t.workingDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to create marker writer: %w", err)
+		}
+		for _, chunkID := range replaced {
+			if err := markerWriter.Put([]byte(chunkID)); err != nil {
+				markerWriter.Close()
+				return err
+			}
+		}
+		if err := markerWriter.Close(); err != nil {
+			return fmt.Errorf("failed to close marker writer: %w", err)
+		}
+	}
+
+	if incomplete {
+		return nil
+	}
+	if err := t.saveProgress(tableName); err != nil {
+		return fmt.Errorf("failed to save recompression progress: %w", err)
+	}
+	t.mtx.Lock()
+	t.done[tableName] = struct{}{}
+	t.mtx.Unlock()
+	return nil
+}
+
+func (t *RecompressionMarker) markIncomplete(tableName string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.incomplete[tableName] = struct{}{}
+}
+
+// recompressChunks rewrites the chunks of the table and replaces their index entries.
+// It returns the IDs of the chunks that were replaced.
+func (t *RecompressionMarker) recompressChunks(ctx context.Context, tableName string, indexProcessor IndexProcessor, logger log.Logger) ([]string, error) {
+	tableInterval := ExtractIntervalFromTableName(tableName)
+
+	var replaced []string
+	err := indexProcessor.ForEachChunk(ctx, func(c ChunkEntry) (bool, error) {
+		// Chunks indexed in multiple tables would have to be replaced in all of them at once.
+		// Those are left as they are.
+		if c.From < tableInterval.Start || c.Through > tableInterval.End {
+			t.metrics.chunksTotal.WithLabelValues(statusSkipped).Inc()
+			return false, nil
+		}
+
+		if err := t.limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+
+		rewritten, err := t.recompressChunk(ctx, c, indexProcessor)
+		if err != nil {
+			t.metrics.chunksTotal.WithLabelValues(statusFailure).Inc()
+			level.Warn(logger).Log("msg", "failed to recompress chunk", "chunk", string(c.ChunkID), "err", err)
+			t.markIncomplete(tableName)
+			return false, nil
+		}
+		if !rewritten {
+			t.metrics.chunksTotal.WithLabelValues(statusSkipped).Inc()
+			return false, nil
+		}
+
+		t.metrics.chunksTotal.WithLabelValues(statusSuccess).Inc()
+		replaced = append(replaced, string(c.ChunkID))
+		return true, nil
+	})
+	if len(replaced) > 0 {
+		level.Info(logger).Log("msg", "recompressed chunks", "chunks", len(replaced), "encoding", t.encoding)
+	}
+	return replaced, err
+}
+
+// recompressChunk writes a copy of the chunk with the new encoding and indexes it.
+// It returns false if the chunk already uses the new encoding.
+func (t *RecompressionMarker) recompressChunk(ctx context.Context, ce ChunkEntry, indexer chunkIndexer) (bool, error) {
+	chk, err := chunk.ParseExternalKey(string(ce.UserID), string(ce.ChunkID))
+	if err != nil {
+		return false, err
+	}
+
+	chks, err := t.chunkClient.GetChunks(ctx, []chunk.Chunk{chk})
+	if err != nil {
+		return false, err
+	}
+	if len(chks) != 1 {
+		return false, fmt.Errorf("expected 1 entry for chunk %s but found %d in storage", ce.ChunkID, len(chks))
+	}
+
+	facade, ok := chks[0].Data.(*chunkenc.Facade)
+	if !ok {
+		return false, fmt.Errorf("invalid chunk type %T", chks[0].Data)
+	}
+	if facade.LokiChunk().Encoding() == t.encoding {
+		return false, nil
+	}
+
+	newFacade, err := facade.Recompress(t.encoding)
+	if err != nil {
+		return false, err
+	}
+	if err := verifyEntriesEqual(facade.LokiChunk(), newFacade.LokiChunk()); err != nil {
+		return false, err
+	}
+
+	newChunk := chunk.NewChunk(
+		chks[0].UserID, chks[0].FingerprintModel(), chks[0].Metric,
+		newFacade,
+		chks[0].From,
+		chks[0].Through,
+	)
+	if err := newChunk.Encode(); err != nil {
+		return false, err
+	}
+
+	// upload the chunk before indexing it, the index must never reference a missing chunk
+	if err := t.chunkClient.PutChunks(ctx, []chunk.Chunk{newChunk}); err != nil {
+		return false, err
+	}
+	indexed, err := indexer.IndexChunk(newChunk)
+	if err != nil {
+		return false, err
+	}
+	if !indexed {
+		return false, fmt.Errorf("recompressed chunk %s was not indexed", ce.ChunkID)
+	}
+
+	if oldBuf, err := chks[0].Encoded(); err == nil {
+		t.metrics.readBytesTotal.Add(float64(len(oldBuf)))
+	}
+	if newBuf, err := newChunk.Encoded(); err == nil {
+		t.metrics.writtenBytesTotal.Add(float64(len(newBuf)))
+	}
+	return true, nil
+}
+
+// verifyEntriesEqual checks that both chunks hold exactly the same entries, in the same order.
+func verifyEntriesEqual(expected, actual chunkenc.Chunk) error {
+	from, through := expected.Bounds()
+	// add a millisecond to end time because the Chunk.Iterator considers end time to be non-inclusive.
+	through = through.Add(time.Millisecond)
+	pipeline := logql_log.NewNoopPipeline().ForStream(labels.Labels{})
+
+	expectedItr, err := expected.Iterator(context.Background(), from, through, logproto.FORWARD, pipeline)
+	if err != nil {
+		return err
+	}
+	defer expectedItr.Close()
+	actualItr, err := actual.Iterator(context.Background(), from, through, logproto.FORWARD, pipeline)
+	if err != nil {
+		return err
+	}
+	defer actualItr.Close()
+
+	for i := 0; ; i++ {
+		hasExpected, hasActual := expectedItr.Next(), actualItr.Next()
+		if hasExpected != hasActual {
+			return fmt.Errorf("recompressed chunk has a different number of entries than the original chunk")
+		}
+		if !hasExpected {
+			break
+		}
+
+		e, a := expectedItr.At(), actualItr.At()
+		if !e.Timestamp.Equal(a.Timestamp) || e.Line != a.Line || !labels.Equal(logproto.FromLabelAdaptersToLabels(e.StructuredMetadata), logproto.FromLabelAdaptersToLabels(a.StructuredMetadata)) {
+			return fmt.Errorf("entry %d of recompressed chunk differs from the original chunk", i)
+		}
+	}
+
+	if err := expectedItr.Err(); err != nil {
+		return err
+	}
+	return actualItr.Err()
+}
@@ -0,0 +1,136 @@
+package retention
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+// chunkList is an IndexProcessor over a list of chunks which supports indexing chunks while iterating.
+type chunkList struct {
+	chunks []chunk.Chunk
+}
+
+func (l *chunkList) ForEachChunk(_ context.Context, callback ChunkEntryCallback) error {
+	chunks := l.chunks
+	l.chunks = nil
+	for _, c := range chunks {
+		deleteChunk, err := callback(entryFromChunk(c))
+		if err != nil {
+			return err
+		}
+		if !deleteChunk {
+			l.chunks = append(l.chunks, c)
+		}
+	}
+	return nil
+}
+
+func (l *chunkList) IndexChunk(c chunk.Chunk) (bool, error) {
+	l.chunks = append(l.chunks, c)
+	return true, nil
+}
+
+func (l *chunkList) CleanupSeries(_ []byte, _ labels.Labels) error {
+	return nil
+}
+
+func countMarkers(t *testing.T, workingDir string) int {
+	entries, err := os.ReadDir(filepath.Join(workingDir, MarkersFolder))
+	if os.IsNotExist(err) {
+		return 0
+	}
+	require.NoError(t, err)
+	return len(entries)
+}
+
+func TestRecompressionMarker(t *testing.T) {
+	workingDir := t.TempDir()
+	chunkClient := client.NewClient(newTestObjectClient(filepath.Join(workingDir, "chunks")), client.FSEncoder, schemaCfg)
+
+	tableName := tablesInInterval(model.Now().Add(-3*24*time.Hour), model.Now().Add(-3*24*time.Hour))[0]
+	tableInterval := ExtractIntervalFromTableName(tableName)
+	from := tableInterval.Start.Add(time.Hour)
+	original := createChunk(t, "1", labels.FromStrings("foo", "bar"), from, from.Add(time.Hour))
+	spanning := createChunk(t, "1", labels.FromStrings("foo", "baz"), tableInterval.End.Add(-time.Hour), tableInterval.End.Add(time.Hour))
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{original, spanning}))
+	index := &chunkList{chunks: []chunk.Chunk{original, spanning}}
+
+	marker, err := NewRecompressionMarker(noopTableMarker{}, workingDir, chunkClient, chunkenc.EncZstd, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.True(t, marker.TableMayHaveChunksToRecompress(tableName))
+
+	empty, modified, err := marker.MarkForDelete(context.Background(), tableName, "", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.False(t, empty)
+	require.True(t, modified)
+
+	// the chunk spanning two tables is left as is, the other one is replaced in the index
+	require.Len(t, index.chunks, 2)
+	recompressed, kept := index.chunks[0], index.chunks[1]
+	require.Equal(t, spanning.ChunkRef, kept.ChunkRef)
+	require.Equal(t, original.Fingerprint, recompressed.Fingerprint)
+	require.NotEqual(t, original.ChunkRef, recompressed.ChunkRef)
+
+	chks, err := chunkClient.GetChunks(context.Background(), []chunk.Chunk{recompressed})
+	require.NoError(t, err)
+	require.Len(t, chks, 1)
+	require.Equal(t, chunkenc.EncZstd, chks[0].Data.(*chunkenc.Facade).LokiChunk().Encoding())
+	require.NoError(t, verifyEntriesEqual(original.Data.(*chunkenc.Facade).LokiChunk(), chks[0].Data.(*chunkenc.Facade).LokiChunk()))
+
+	// the original chunk is marked for deletion only after the index is uploaded
+	require.Equal(t, 0, countMarkers(t, workingDir))
+	require.NoError(t, marker.TableProcessed(tableName, nil))
+	require.Equal(t, 1, countMarkers(t, workingDir))
+	require.False(t, marker.TableMayHaveChunksToRecompress(tableName))
+
+	// progress survives restarts
+	marker, err = NewRecompressionMarker(noopTableMarker{}, workingDir, chunkClient, chunkenc.EncZstd, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.False(t, marker.TableMayHaveChunksToRecompress(tableName))
+
+	// but is tracked per encoding
+	marker, err = NewRecompressionMarker(noopTableMarker{}, workingDir, chunkClient, chunkenc.EncLZ4_64k, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.True(t, marker.TableMayHaveChunksToRecompress(tableName))
+}
+
+func TestRecompressionMarker_FailedUpload(t *testing.T) {
+	workingDir := t.TempDir()
+	chunkClient := client.NewClient(newTestObjectClient(filepath.Join(workingDir, "chunks")), client.FSEncoder, schemaCfg)
+
+	tableName := tablesInInterval(model.Now().Add(-3*24*time.Hour), model.Now().Add(-3*24*time.Hour))[0]
+	from := ExtractIntervalFromTableName(tableName).Start.Add(time.Hour)
+	original := createChunk(t, "1", labels.FromStrings("foo", "bar"), from, from.Add(time.Hour))
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{original}))
+	index := &chunkList{chunks: []chunk.Chunk{original}}
+
+	marker, err := NewRecompressionMarker(noopTableMarker{}, workingDir, chunkClient, chunkenc.EncZstd, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	_, modified, err := marker.MarkForDelete(context.Background(), tableName, "", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, modified)
+
+	// the index referencing the new chunk was not uploaded, so the original chunk must be kept
+	require.NoError(t, marker.TableProcessed(tableName, context.Canceled))
+	require.Equal(t, 0, countMarkers(t, workingDir))
+	require.True(t, marker.TableMayHaveChunksToRecompress(tableName))
+}
+
+func TestRecompressionMarker_RecentTable(t *testing.T) {
+	marker, err := NewRecompressionMarker(noopTableMarker{}, t.TempDir(), nil, chunkenc.EncZstd, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.False(t, marker.TableMayHaveChunksToRecompress(tablesInInterval(model.Now(), model.Now())[0]))
+}
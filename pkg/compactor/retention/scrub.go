@@ -0,0 +1,265 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+const (
+	scrubFolder = "scrub"
+
+	// scrubMinTableAge is how long after the end of a table its chunks are verified,
+	// so that chunks flushed late by the ingesters are verified as well.
+	scrubMinTableAge = 24 * time.Hour
+
+	statusCorrupted = "corrupted"
+)
+
+type scrubMetrics struct {
+	chunksTotal            *prometheus.CounterVec
+	quarantinedChunksTotal prometheus.Counter
+}
+
+func newScrubMetrics(r prometheus.Registerer) *scrubMetrics {
+	return &scrubMetrics{
+		chunksTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_scrub_chunks_total",
+			Help:      "Total number of chunks processed by the scrubber.",
+		}, []string{"status"}),
+		quarantinedChunksTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_scrub_quarantined_chunks_total",
+			Help:      "Total number of corrupted chunks removed from the index by the scrubber.",
+		}),
+	}
+}
+
+// ScrubMarker is a TableMarker verifying that the chunks of historical tables
+// can be read back from the object store.
+//
+// A sample of the chunks of every table is downloaded once, which verifies the
+// checksum of the chunk and that it matches its reference. The blocks of the
+// chunk are then fully decoded, with their checksums verified, and the labels
+// of the chunk are compared to the labels of its series in the index.
+// Chunks are sampled by hashing their ID, so that a chunk indexed in multiple
+// tables is either verified in all of them or in none.
+//
+// Corrupted and missing chunks are recorded in the working directory, one per
+// line. When quarantine is enabled, they are also removed from the index so
+// that queries skip them instead of failing. Quarantined chunks are not marked
+// for deletion and are left in the object store for investigation.
+type ScrubMarker struct {
+	TableMarker
+
+	workingDirectory string
+	chunkClient      client.Client
+	sampleRatio      float64
+	quarantine       bool
+	limiter          *rate.Limiter
+	metrics          *scrubMetrics
+
+	mtx sync.Mutex
+	// tables with their sampled chunks verified
+	done map[string]struct{}
+	// tables with chunks that failed to be fetched
+	incomplete map[string]struct{}
+}
+
+// NewScrubMarker returns a ScrubMarker verifying the given ratio of the chunks, up to chunksPerSecond chunks per second.
+func NewScrubMarker(next TableMarker, workingDirectory string, chunkClient client.Client, sampleRatio float64, quarantine bool, chunksPerSecond float64, r prometheus.Registerer) (*ScrubMarker, error) {
+	m := &ScrubMarker{
+		TableMarker:      next,
+		workingDirectory: workingDirectory,
+		chunkClient:      chunkClient,
+		sampleRatio:      sampleRatio,
+		quarantine:       quarantine,
+		limiter:          rate.NewLimiter(rate.Limit(chunksPerSecond), 1),
+		metrics:          newScrubMetrics(r),
+		incomplete:       map[string]struct{}{},
+	}
+
+	var err error
+	m.done, err = readLines(m.progressFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scrub progress: %w", err)
+	}
+	return m, nil
+}
+
+// progressFile holds the names of the scrubbed tables, one per line.
+func (t *ScrubMarker) progressFile() string {
+	return filepath.Join(t.workingDirectory, scrubFolder, "tables")
+}
+
+// CorruptedChunksFile returns the file recording the corrupted chunks found by the scrubber.
+// Every line holds the table name, tenant, chunk ID and reason, separated by tabs.
+func (t *ScrubMarker) CorruptedChunksFile() string {
+	return filepath.Join(t.workingDirectory, scrubFolder, "corrupted")
+}
+
+// TableMayHaveChunksToScrub returns whether the table is old enough to be
+// scrubbed and has not been scrubbed yet.
+func (t *ScrubMarker) TableMayHaveChunksToScrub(tableName string) bool {
+	t.mtx.Lock()
+	_, done := t.done[tableName]
+	t.mtx.Unlock()
+	if done {
+		return false
+	}
+
+	interval := ExtractIntervalFromTableName(tableName)
+	return interval.End.Before(model.Now().Add(-scrubMinTableAge))
+}
+
+// MarkForDelete applies retention to the table and then verifies a sample of its chunks.
+// Failing to fetch chunks does not fail retention, the table is scrubbed again in the next run.
+func (t *ScrubMarker) MarkForDelete(ctx context.Context, tableName, userID string, indexProcessor IndexProcessor, logger log.Logger) (bool, bool, error) {
+	empty, modified, err := t.TableMarker.MarkForDelete(ctx, tableName, userID, indexProcessor, logger)
+	if err != nil || empty || !t.TableMayHaveChunksToScrub(tableName) {
+		return empty, modified, err
+	}
+
+	quarantined, err := t.scrubChunks(ctx, tableName, indexProcessor, logger)
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to scrub chunks", "err", err)
+		t.markIncomplete(tableName)
+	}
+	return empty, modified || quarantined > 0, nil
+}
+
+// TableProcessed must be called after all the index sets of a table have been
+// processed, with the error of uploading the index of the table, if any.
+// The table is only recorded as scrubbed once its chunks could all be verified
+// and the index without the quarantined chunks is uploaded.
+func (t *ScrubMarker) TableProcessed(tableName string, uploadErr error) error {
+	t.mtx.Lock()
+	_, incomplete := t.incomplete[tableName]
+	delete(t.incomplete, tableName)
+	t.mtx.Unlock()
+
+	if uploadErr != nil || incomplete {
+		return nil
+	}
+
+	if err := appendLine(t.progressFile(), tableName); err != nil {
+		return fmt.Errorf("failed to save scrub progress: %w", err)
+	}
+	t.mtx.Lock()
+	t.done[tableName] = struct{}{}
+	t.mtx.Unlock()
+	return nil
+}
+
+func (t *ScrubMarker) markIncomplete(tableName string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.incomplete[tableName] = struct{}{}
+}
+
+// sampled returns whether the chunk is part of the sample of chunks to verify.
+func (t *ScrubMarker) sampled(chunkID []byte) bool {
+	return t.sampleRatio >= 1 || float64(xxhash.Sum64(chunkID)) < t.sampleRatio*math.MaxUint64
+}
+
+// scrubChunks verifies the sampled chunks of the table and returns the number of chunks removed from the index.
+func (t *ScrubMarker) scrubChunks(ctx context.Context, tableName string, indexProcessor IndexProcessor, logger log.Logger) (int, error) {
+	var corrupted, quarantined int
+	err := indexProcessor.ForEachChunk(ctx, func(c ChunkEntry) (bool, error) {
+		if !t.sampled(c.ChunkID) {
+			t.metrics.chunksTotal.WithLabelValues(statusSkipped).Inc()
+			return false, nil
+		}
+
+		if err := t.limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+
+		reason, err := t.verifyChunk(ctx, c)
+		if err != nil {
+			t.metrics.chunksTotal.WithLabelValues(statusFailure).Inc()
+			level.Warn(logger).Log("msg", "failed to fetch chunk to verify", "chunk", string(c.ChunkID), "err", err)
+			t.markIncomplete(tableName)
+			return false, nil
+		}
+		if reason == "" {
+			t.metrics.chunksTotal.WithLabelValues(statusSuccess).Inc()
+			return false, nil
+		}
+
+		t.metrics.chunksTotal.WithLabelValues(statusCorrupted).Inc()
+		level.Error(logger).Log("msg", "found corrupted chunk", "chunk", string(c.ChunkID), "reason", reason, "quarantine", t.quarantine)
+		corrupted++
+		record := strings.Join([]string{tableName, string(c.UserID), string(c.ChunkID), reason}, "\t")
+		if err := appendLine(t.CorruptedChunksFile(), record); err != nil {
+			return false, fmt.Errorf("failed to record corrupted chunk: %w", err)
+		}
+
+		if !t.quarantine {
+			return false, nil
+		}
+		t.metrics.quarantinedChunksTotal.Inc()
+		quarantined++
+		return true, nil
+	})
+	if corrupted > 0 {
+		level.Info(logger).Log("msg", "found corrupted chunks", "chunks", corrupted, "quarantined", quarantined)
+	}
+	return quarantined, err
+}
+
+// verifyChunk fetches the chunk and returns why it is corrupted, or an empty reason if it is intact.
+// An error is only returned if the chunk could not be fetched.
+func (t *ScrubMarker) verifyChunk(ctx context.Context, ce ChunkEntry) (string, error) {
+	chk, err := chunk.ParseExternalKey(string(ce.UserID), string(ce.ChunkID))
+	if err != nil {
+		return fmt.Sprintf("invalid chunk ID: %s", err), nil
+	}
+
+	chks, err := t.chunkClient.GetChunks(ctx, []chunk.Chunk{chk})
+	switch {
+	case err != nil && t.chunkClient.IsChunkNotFoundErr(err):
+		return "chunk not found", nil
+	case err != nil && chunk.IsDecodeError(err):
+		return fmt.Sprintf("failed to decode chunk: %s", err), nil
+	case err != nil:
+		return "", err
+	case len(chks) != 1:
+		return "", fmt.Errorf("expected 1 entry for chunk %s but found %d in storage", ce.ChunkID, len(chks))
+	}
+
+	if lbls := labels.NewBuilder(chks[0].Metric).Del(labels.MetricName).Labels(); !labels.Equal(lbls, ce.Labels) {
+		return fmt.Sprintf("chunk labels %s do not match the labels %s of its series", lbls, ce.Labels), nil
+	}
+
+	facade, ok := chks[0].Data.(*chunkenc.Facade)
+	if !ok {
+		return fmt.Sprintf("invalid chunk type %T", chks[0].Data), nil
+	}
+	memChunk, ok := facade.LokiChunk().(*chunkenc.MemChunk)
+	if !ok {
+		return fmt.Sprintf("invalid chunk type %T", facade.LokiChunk()), nil
+	}
+	if err := memChunk.Verify(ctx); err != nil {
+		return fmt.Sprintf("failed to verify chunk: %s", err), nil
+	}
+	return "", nil
+}
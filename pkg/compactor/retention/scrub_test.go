@@ -0,0 +1,132 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+func TestScrubMarker(t *testing.T) {
+	for _, quarantine := range []bool{false, true} {
+		t.Run(map[bool]string{false: "record", true: "quarantine"}[quarantine], func(t *testing.T) {
+			workingDir := t.TempDir()
+			objectClient := newTestObjectClient(filepath.Join(workingDir, "chunks"))
+			chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaCfg)
+
+			tableName := tablesInInterval(model.Now().Add(-3*24*time.Hour), model.Now().Add(-3*24*time.Hour))[0]
+			from := ExtractIntervalFromTableName(tableName).Start.Add(time.Hour)
+			intact := createChunk(t, "1", labels.FromStrings("foo", "intact"), from, from.Add(time.Hour))
+			corrupted := createChunk(t, "1", labels.FromStrings("foo", "corrupted"), from, from.Add(time.Hour))
+			missing := createChunk(t, "1", labels.FromStrings("foo", "missing"), from, from.Add(time.Hour))
+			mislabeled := createChunk(t, "1", labels.FromStrings("foo", "mislabeled"), from, from.Add(time.Hour))
+			require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{intact, corrupted, mislabeled}))
+
+			buf, err := corrupted.Encoded()
+			require.NoError(t, err)
+			buf = bytes.Clone(buf)
+			buf[len(buf)/2] ^= 0xff
+			require.NoError(t, objectClient.PutObject(context.Background(), client.FSEncoder(schemaCfg, corrupted), bytes.NewReader(buf)))
+
+			// the series of the chunk in the index has other labels than the chunk
+			mislabeledIndex := mislabeled
+			mislabeledIndex.Metric = labels.FromStrings(labels.MetricName, "logs", "foo", "other")
+
+			index := &chunkList{chunks: []chunk.Chunk{intact, corrupted, missing, mislabeledIndex}}
+
+			marker, err := NewScrubMarker(noopTableMarker{}, workingDir, chunkClient, 1, quarantine, 1000, prometheus.NewRegistry())
+			require.NoError(t, err)
+			require.True(t, marker.TableMayHaveChunksToScrub(tableName))
+
+			empty, modified, err := marker.MarkForDelete(context.Background(), tableName, "", index, log.NewNopLogger())
+			require.NoError(t, err)
+			require.False(t, empty)
+			require.Equal(t, quarantine, modified)
+
+			if quarantine {
+				require.Len(t, index.chunks, 1)
+				require.Equal(t, intact.ChunkRef, index.chunks[0].ChunkRef)
+			} else {
+				require.Len(t, index.chunks, 4)
+			}
+
+			records, err := os.ReadFile(marker.CorruptedChunksFile())
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(records)), "\n")
+			require.Len(t, lines, 3)
+			for i, c := range []chunk.Chunk{corrupted, missing, mislabeled} {
+				require.True(t, strings.HasPrefix(lines[i], strings.Join([]string{tableName, "1", getChunkID(c.ChunkRef)}, "\t")+"\t"), lines[i])
+			}
+
+			// quarantined chunks are left in the object store
+			require.Equal(t, 0, countMarkers(t, workingDir))
+
+			require.NoError(t, marker.TableProcessed(tableName, nil))
+			require.False(t, marker.TableMayHaveChunksToScrub(tableName))
+
+			// progress survives restarts
+			marker, err = NewScrubMarker(noopTableMarker{}, workingDir, chunkClient, 1, quarantine, 1000, prometheus.NewRegistry())
+			require.NoError(t, err)
+			require.False(t, marker.TableMayHaveChunksToScrub(tableName))
+		})
+	}
+}
+
+func TestScrubMarker_Sampling(t *testing.T) {
+	workingDir := t.TempDir()
+	chunkClient := client.NewClient(newTestObjectClient(filepath.Join(workingDir, "chunks")), client.FSEncoder, schemaCfg)
+
+	tableName := tablesInInterval(model.Now().Add(-3*24*time.Hour), model.Now().Add(-3*24*time.Hour))[0]
+	from := ExtractIntervalFromTableName(tableName).Start.Add(time.Hour)
+	var chunks []chunk.Chunk
+	for i := 0; i < 100; i++ {
+		// none of the chunks exist in the store, all the sampled ones are reported missing
+		chunks = append(chunks, createChunk(t, "1", labels.FromStrings("foo", "bar"), from.Add(time.Duration(i)*time.Minute), from.Add(2*time.Hour)))
+	}
+	index := &chunkList{chunks: chunks}
+
+	marker, err := NewScrubMarker(noopTableMarker{}, workingDir, chunkClient, 0.5, true, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	_, _, err = marker.MarkForDelete(context.Background(), tableName, "", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Greater(t, len(index.chunks), 20)
+	require.Less(t, len(index.chunks), 80)
+
+	// the same chunks are sampled in every run
+	for _, c := range index.chunks {
+		require.False(t, marker.sampled([]byte(getChunkID(c.ChunkRef))))
+	}
+}
+
+func TestScrubMarker_FailedUpload(t *testing.T) {
+	workingDir := t.TempDir()
+	chunkClient := client.NewClient(newTestObjectClient(filepath.Join(workingDir, "chunks")), client.FSEncoder, schemaCfg)
+
+	tableName := tablesInInterval(model.Now().Add(-3*24*time.Hour), model.Now().Add(-3*24*time.Hour))[0]
+	from := ExtractIntervalFromTableName(tableName).Start.Add(time.Hour)
+	c := createChunk(t, "1", labels.FromStrings("foo", "bar"), from, from.Add(time.Hour))
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{c}))
+	index := &chunkList{chunks: []chunk.Chunk{c}}
+
+	marker, err := NewScrubMarker(noopTableMarker{}, workingDir, chunkClient, 1, true, 1000, prometheus.NewRegistry())
+	require.NoError(t, err)
+	_, modified, err := marker.MarkForDelete(context.Background(), tableName, "", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.False(t, modified)
+
+	// the table is scrubbed again in the next run
+	require.NoError(t, marker.TableProcessed(tableName, context.DeadlineExceeded))
+	require.True(t, marker.TableMayHaveChunksToScrub(tableName))
+}
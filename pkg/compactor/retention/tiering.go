@@ -0,0 +1,271 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const tieringMoveConcurrency = 10
+
+type TieringLimits interface {
+	StorageTieringAge(userID string) time.Duration
+	AllByUserID() map[string]*validation.Limits
+	DefaultLimits() *validation.Limits
+}
+
+type tieringMetrics struct {
+	chunksMovedTotal *prometheus.CounterVec
+	bytesMovedTotal  prometheus.Counter
+}
+
+func newTieringMetrics(r prometheus.Registerer) *tieringMetrics {
+	return &tieringMetrics{
+		chunksMovedTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_tiering_chunks_moved_total",
+			Help:      "Total number of chunks moved to the tiering object store.",
+		}, []string{"status"}),
+		bytesMovedTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "retention_tiering_bytes_moved_total",
+			Help:      "Total number of bytes of chunks moved to the tiering object store.",
+		}),
+	}
+}
+
+// TieringMarker is a TableMarker moving the chunks of a table that are older
+// than the tiering age of their tenant from the hot to the cold object store.
+// Chunk references in the index do not carry the location of the chunk:
+// readers resolve them in both stores. So instead of rewriting the index, the
+// references of the moved chunks are resolved in the cold object store before
+// the chunks are deleted from the hot object store.
+type TieringMarker struct {
+	TableMarker
+
+	hot, cold client.Client
+	limits    TieringLimits
+	metrics   *tieringMetrics
+
+	mtx sync.Mutex
+	// tables with chunks that are not old enough to be moved yet, or that failed to move
+	pending map[string]struct{}
+	// tables with all their chunks moved in the current retention run
+	done map[string]struct{}
+}
+
+func NewTieringMarker(next TableMarker, hot, cold client.Client, limits TieringLimits, r prometheus.Registerer) *TieringMarker {
+	return &TieringMarker{
+		TableMarker: next,
+		hot:         hot,
+		cold:        cold,
+		limits:      limits,
+		metrics:     newTieringMetrics(r),
+		pending:     map[string]struct{}{},
+		done:        map[string]struct{}{},
+	}
+}
+
+// MarkForDelete applies retention to the table and then moves its old chunks to the cold object store.
+// Failing to move chunks does not fail retention, the table is processed again in the next run.
+func (t *TieringMarker) MarkForDelete(ctx context.Context, tableName, userID string, indexProcessor IndexProcessor, logger log.Logger) (bool, bool, error) {
+	empty, modified, err := t.TableMarker.MarkForDelete(ctx, tableName, userID, indexProcessor, logger)
+	if err != nil || empty {
+		return empty, modified, err
+	}
+
+	if err := t.moveChunks(ctx, tableName, indexProcessor, logger); err != nil {
+		level.Warn(logger).Log("msg", "failed to move chunks to the tiering object store", "err", err)
+		t.markPending(tableName)
+	}
+	return empty, modified, nil
+}
+
+// MarkPhaseStarted must be called when a retention run starts. Tables are checked again in
+// every run, since later compactions can add chunks to them and the tiering ages of the
+// tenants can change.
+func (t *TieringMarker) MarkPhaseStarted() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.pending = map[string]struct{}{}
+	t.done = map[string]struct{}{}
+}
+
+// TableMayHaveChunksToMove returns whether the table may have chunks which are
+// old enough to be moved for any of the tenants.
+func (t *TieringMarker) TableMayHaveChunksToMove(tableName string) bool {
+	minTieringAge := t.minTieringAge()
+	if minTieringAge <= 0 {
+		return false
+	}
+
+	t.mtx.Lock()
+	_, done := t.done[tableName]
+	t.mtx.Unlock()
+	if done {
+		return false
+	}
+
+	interval := ExtractIntervalFromTableName(tableName)
+	return interval.Start.Before(model.Now().Add(-minTieringAge))
+}
+
+// minTieringAge returns the smallest tiering age of all tenants, or 0 if tiering is disabled for all of them.
+func (t *TieringMarker) minTieringAge() time.Duration {
+	minAge := time.Duration(t.limits.DefaultLimits().StorageTieringAge)
+	for _, limits := range t.limits.AllByUserID() {
+		age := time.Duration(limits.StorageTieringAge)
+		if age > 0 && (minAge <= 0 || age < minAge) {
+			minAge = age
+		}
+	}
+	return minAge
+}
+
+// TableProcessed must be called after all the index sets of a table have been
+// processed. Tables without chunks left to move are skipped until the next run starts.
+func (t *TieringMarker) TableProcessed(tableName string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if _, ok := t.pending[tableName]; ok {
+		delete(t.pending, tableName)
+		return
+	}
+	t.done[tableName] = struct{}{}
+}
+
+func (t *TieringMarker) markPending(tableName string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.pending[tableName] = struct{}{}
+}
+
+type chunkToMove struct {
+	userID, chunkID string
+}
+
+func (t *TieringMarker) moveChunks(ctx context.Context, tableName string, indexProcessor IndexProcessor, logger log.Logger) error {
+	tableInterval := ExtractIntervalFromTableName(tableName)
+	now := time.Now()
+
+	var chunks []chunkToMove
+	err := indexProcessor.ForEachChunk(ctx, func(c ChunkEntry) (bool, error) {
+		// Chunks indexed in multiple tables are moved with the first table they are indexed in.
+		if c.From < tableInterval.Start {
+			return false, nil
+		}
+
+		age := t.limits.StorageTieringAge(string(c.UserID))
+		if age <= 0 {
+			return false, nil
+		}
+		if c.Through.Time().After(now.Add(-age)) {
+			t.markPending(tableName)
+			return false, nil
+		}
+
+		chunks = append(chunks, chunkToMove{userID: string(c.UserID), chunkID: string(c.ChunkID)})
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	level.Info(logger).Log("msg", "moving chunks to the tiering object store", "chunks", len(chunks))
+	return concurrency.ForEachJob(ctx, len(chunks), tieringMoveConcurrency, func(ctx context.Context, idx int) error {
+		status := statusSuccess
+		if err := t.moveChunk(ctx, chunks[idx].userID, chunks[idx].chunkID); err != nil {
+			status = statusFailure
+			t.metrics.chunksMovedTotal.WithLabelValues(status).Inc()
+			return fmt.Errorf("failed to move chunk %s: %w", chunks[idx].chunkID, err)
+		}
+		t.metrics.chunksMovedTotal.WithLabelValues(status).Inc()
+		return nil
+	})
+}
+
+// resolveInCold checks that the chunks can be read back from the cold object
+// store, and that they are identical to the chunks in the hot object store.
+func (t *TieringMarker) resolveInCold(ctx context.Context, chunks []chunk.Chunk) error {
+	moved, err := t.cold.GetChunks(ctx, chunks)
+	if err != nil {
+		return fmt.Errorf("failed to read moved chunks back: %w", err)
+	}
+
+	expected := make(map[logproto.ChunkRef][]byte, len(chunks))
+	for _, c := range chunks {
+		buf, err := c.Encoded()
+		if err != nil {
+			return err
+		}
+		expected[c.ChunkRef] = buf
+	}
+	for _, c := range moved {
+		buf, err := c.Encoded()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(buf, expected[c.ChunkRef]) {
+			return errors.New("moved chunk differs from the original")
+		}
+		delete(expected, c.ChunkRef)
+	}
+	if len(expected) > 0 {
+		return errors.New("moved chunk not found")
+	}
+	return nil
+}
+
+// moveChunk copies a chunk from the hot to the cold object store and then deletes it from the hot object store.
+func (t *TieringMarker) moveChunk(ctx context.Context, userID, chunkID string) error {
+	c, err := chunk.ParseExternalKey(userID, chunkID)
+	if err != nil {
+		return err
+	}
+
+	chunks, err := t.hot.GetChunks(ctx, []chunk.Chunk{c})
+	if err != nil {
+		if t.hot.IsChunkNotFoundErr(err) || t.hot.IsChunkNotFoundErr(errors.Cause(err)) {
+			// moved already
+			return nil
+		}
+		return err
+	}
+	if err := t.cold.PutChunks(ctx, chunks); err != nil {
+		return err
+	}
+	if err := t.resolveInCold(ctx, chunks); err != nil {
+		return err
+	}
+	if err := t.hot.DeleteChunk(ctx, userID, chunkID); err != nil && !t.hot.IsChunkNotFoundErr(err) {
+		return err
+	}
+
+	for _, c := range chunks {
+		buf, err := c.Encoded()
+		if err == nil {
+			t.metrics.bytesMovedTotal.Add(float64(len(buf)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package retention
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type fakeTieringLimits struct {
+	perTenant map[string]time.Duration
+}
+
+func (f fakeTieringLimits) StorageTieringAge(userID string) time.Duration {
+	return f.perTenant[userID]
+}
+
+func (f fakeTieringLimits) AllByUserID() map[string]*validation.Limits {
+	res := make(map[string]*validation.Limits, len(f.perTenant))
+	for userID, age := range f.perTenant {
+		res[userID] = &validation.Limits{StorageTieringAge: model.Duration(age)}
+	}
+	return res
+}
+
+func (f fakeTieringLimits) DefaultLimits() *validation.Limits {
+	return &validation.Limits{}
+}
+
+type noopTableMarker struct{}
+
+func (noopTableMarker) MarkForDelete(_ context.Context, _, _ string, _ IndexProcessor, _ log.Logger) (bool, bool, error) {
+	return false, false, nil
+}
+
+func hasChunk(t *testing.T, c client.Client, chk chunk.Chunk) bool {
+	_, err := c.GetChunks(context.Background(), []chunk.Chunk{chk})
+	if err != nil && c.IsChunkNotFoundErr(err) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func TestTieringMarker(t *testing.T) {
+	dir := t.TempDir()
+	hot := client.NewClient(newTestObjectClient(filepath.Join(dir, "hot")), client.FSEncoder, schemaCfg)
+	cold := client.NewClient(newTestObjectClient(filepath.Join(dir, "cold")), client.FSEncoder, schemaCfg)
+
+	now := model.Now()
+	from := now.Add(-7 * 24 * time.Hour).Add(-time.Hour)
+	lbs := labels.FromStrings("foo", "bar")
+	oldChunk := createChunk(t, "1", lbs, from, from.Add(time.Hour))
+	otherTenantChunk := createChunk(t, "2", lbs, from, from.Add(time.Hour))
+	require.NoError(t, hot.PutChunks(context.Background(), []chunk.Chunk{oldChunk, otherTenantChunk}))
+
+	table := newTable(tablesInInterval(from, from)[0])
+	table.Put(oldChunk)
+	table.Put(otherTenantChunk)
+
+	marker := NewTieringMarker(noopTableMarker{}, hot, cold, fakeTieringLimits{perTenant: map[string]time.Duration{"1": 24 * time.Hour}}, prometheus.NewRegistry())
+	require.True(t, marker.TableMayHaveChunksToMove(table.name))
+
+	empty, modified, err := marker.MarkForDelete(context.Background(), table.name, "", table, log.NewNopLogger())
+	require.NoError(t, err)
+	require.False(t, empty)
+	require.False(t, modified)
+
+	// only the chunk of the tenant with tiering enabled is moved
+	require.False(t, hasChunk(t, hot, oldChunk))
+	require.True(t, hasChunk(t, cold, oldChunk))
+	require.True(t, hasChunk(t, hot, otherTenantChunk))
+	require.False(t, hasChunk(t, cold, otherTenantChunk))
+
+	// moving a chunk twice is a no-op
+	_, _, err = marker.MarkForDelete(context.Background(), table.name, "", table, log.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, hasChunk(t, cold, oldChunk))
+
+	marker.TableProcessed(table.name)
+	require.False(t, marker.TableMayHaveChunksToMove(table.name))
+
+	// the table is checked again in the next retention run
+	marker.MarkPhaseStarted()
+	require.True(t, marker.TableMayHaveChunksToMove(table.name))
+}
+
+func TestTieringMarker_PendingTable(t *testing.T) {
+	dir := t.TempDir()
+	hot := client.NewClient(newTestObjectClient(filepath.Join(dir, "hot")), client.FSEncoder, schemaCfg)
+	cold := client.NewClient(newTestObjectClient(filepath.Join(dir, "cold")), client.FSEncoder, schemaCfg)
+
+	now := model.Now()
+	from := now.Add(-2 * time.Hour)
+	recentChunk := createChunk(t, "1", labels.FromStrings("foo", "bar"), from, now)
+	require.NoError(t, hot.PutChunks(context.Background(), []chunk.Chunk{recentChunk}))
+
+	table := newTable(tablesInInterval(from, from)[0])
+	table.Put(recentChunk)
+
+	marker := NewTieringMarker(noopTableMarker{}, hot, cold, fakeTieringLimits{perTenant: map[string]time.Duration{"1": time.Hour}}, prometheus.NewRegistry())
+	_, _, err := marker.MarkForDelete(context.Background(), table.name, "", table, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// the chunk is not old enough yet, so the table must be processed again
+	require.True(t, hasChunk(t, hot, recentChunk))
+	marker.TableProcessed(table.name)
+	require.True(t, marker.TableMayHaveChunksToMove(table.name))
+}
+
+// droppingClient acknowledges writes without storing the chunks.
+type droppingClient struct {
+	client.Client
+}
+
+func (droppingClient) PutChunks(_ context.Context, _ []chunk.Chunk) error {
+	return nil
+}
+
+func TestTieringMarker_ChunkNotInCold(t *testing.T) {
+	dir := t.TempDir()
+	hot := client.NewClient(newTestObjectClient(filepath.Join(dir, "hot")), client.FSEncoder, schemaCfg)
+	cold := client.NewClient(newTestObjectClient(filepath.Join(dir, "cold")), client.FSEncoder, schemaCfg)
+
+	from := model.Now().Add(-7 * 24 * time.Hour)
+	oldChunk := createChunk(t, "1", labels.FromStrings("foo", "bar"), from, from.Add(time.Hour))
+	require.NoError(t, hot.PutChunks(context.Background(), []chunk.Chunk{oldChunk}))
+
+	table := newTable(tablesInInterval(from, from)[0])
+	table.Put(oldChunk)
+
+	marker := NewTieringMarker(noopTableMarker{}, hot, droppingClient{cold}, fakeTieringLimits{perTenant: map[string]time.Duration{"1": 24 * time.Hour}}, prometheus.NewRegistry())
+	_, _, err := marker.MarkForDelete(context.Background(), table.name, "", table, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// the chunk does not resolve in the cold store, so it is kept in the hot store and moved again later
+	require.True(t, hasChunk(t, hot, oldChunk))
+	marker.TableProcessed(table.name)
+	require.True(t, marker.TableMayHaveChunksToMove(table.name))
+}
@@ -1,14 +1,18 @@
 package retention
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 	"unsafe"
 
 	"github.com/prometheus/common/model"
+
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 )
 
 // unsafeGetString is like yolostring but with a meaningful name
@@ -57,3 +61,46 @@ func ExtractIntervalFromTableName(tableName string) model.Interval {
 	interval.End = interval.Start.Add(24*time.Hour) - 1
 	return interval
 }
+
+// readLines returns the set of non-empty lines of the file, which is empty if the file does not exist.
+func readLines(path string) (map[string]struct{}, error) {
+	lines := map[string]struct{}{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lines, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines[line] = struct{}{}
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// appendLine durably appends the line to the file, creating the file and its directory if needed.
+func appendLine(path, line string) error {
+	if err := chunk_util.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,10 +2,14 @@ package compactor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -13,6 +17,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
@@ -20,7 +25,8 @@ import (
 )
 
 const (
-	gzipExtension = ".gz"
+	gzipExtension   = ".gz"
+	leaseFileSuffix = ".compaction-lease"
 )
 
 var errRetentionFileCountNotOne = fmt.Errorf("can't apply retention when index file count is not one")
@@ -83,13 +89,18 @@ type table struct {
 	usersWithPerUserIndex []string
 	logger                log.Logger
 
+	// conditionalWrites makes the compaction fail if another compactor claimed the lease
+	// of the table since the compaction started.
+	conditionalWrites bool
+	leaseVersion      string
+
 	ctx context.Context
 }
 
 func newTable(ctx context.Context, workingDirectory string, indexStorageClient storage.Client,
 	indexCompactor IndexCompactor, periodConfig config.PeriodConfig,
 	tableMarker retention.TableMarker, expirationChecker tableExpirationChecker,
-	uploadConcurrency int,
+	uploadConcurrency int, conditionalWrites bool,
 ) (*table, error) {
 	err := chunk_util.EnsureDirectory(workingDirectory)
 	if err != nil {
@@ -109,6 +120,7 @@ func newTable(ctx context.Context, workingDirectory string, indexStorageClient s
 		baseUserIndexSet:   storage.NewIndexSet(indexStorageClient, true),
 		baseCommonIndexSet: storage.NewIndexSet(indexStorageClient, false),
 		uploadConcurrency:  uploadConcurrency,
+		conditionalWrites:  conditionalWrites,
 	}
 	table.logger = log.With(util_log.Logger, "table-name", table.name)
 
@@ -116,6 +128,16 @@ func newTable(ctx context.Context, workingDirectory string, indexStorageClient s
 }
 
 func (t *table) compact(applyRetention bool) error {
+	if t.conditionalWrites {
+		// the version of the lease is read before listing the files, so that claiming it
+		// fails if another compactor modified the table after the files were listed.
+		version, err := t.indexStorageClient.FileVersion(t.ctx, "", t.leaseFileName())
+		if err != nil && !t.indexStorageClient.IsFileNotFoundErr(err) {
+			return err
+		}
+		t.leaseVersion = version
+	}
+
 	t.indexStorageClient.RefreshIndexTableCache(t.ctx, t.name)
 	indexFiles, usersWithPerUserIndex, err := t.indexStorageClient.ListFiles(t.ctx, t.name, false)
 	if err != nil {
@@ -185,6 +207,12 @@ func (t *table) compact(applyRetention bool) error {
 }
 
 func (t *table) done() error {
+	if t.conditionalWrites && t.modifiesStorage() {
+		if err := t.claimLease(); err != nil {
+			return err
+		}
+	}
+
 	userIDs := make([]string, 0, len(t.indexSets))
 	for userID := range t.indexSets {
 		// indexSet.done() uploads the compacted db and cleans up the source index files.
@@ -211,9 +239,57 @@ func (t *table) done() error {
 		}
 	}
 
+	if t.conditionalWrites && t.removedAllFiles() {
+		// the table was dropped by retention, so its lease would be left behind forever.
+		err := t.indexStorageClient.DeleteFile(t.ctx, "", t.leaseFileName())
+		if err != nil && !t.indexStorageClient.IsFileNotFoundErr(err) {
+			level.Warn(t.logger).Log("msg", "failed to remove the lease of the table", "err", err)
+		}
+	}
+
 	return nil
 }
 
+// modifiesStorage returns true if done uploads or removes files of any of the index sets.
+func (t *table) modifiesStorage() bool {
+	for _, is := range t.indexSets {
+		if is.uploadCompactedDB || is.removeSourceObjects {
+			return true
+		}
+	}
+	return false
+}
+
+// removedAllFiles returns true if done removed all the files of the table without uploading any.
+func (t *table) removedAllFiles() bool {
+	for _, is := range t.indexSets {
+		if is.uploadCompactedDB || (len(is.sourceObjects) > 0 && !is.removeSourceObjects) {
+			return false
+		}
+	}
+	return true
+}
+
+// leaseFileName is the name of the lease object of the table. It is stored next to the
+// table directories, so that it is neither listed as a table nor as an index file.
+func (t *table) leaseFileName() string {
+	return t.name + leaseFileSuffix
+}
+
+// claimLease writes the lease object of the table if it wasn't written since its version was
+// read before listing the files of the table, which fails if another compactor is compacting
+// the table concurrently, before any file is uploaded or removed.
+func (t *table) claimLease() error {
+	// the content changes on every claim, for the object stores deriving versions from it.
+	content := strings.NewReader(strconv.FormatInt(time.Now().UnixNano(), 10))
+	cond := client.WriteCondition{IfMatch: t.leaseVersion, IfNoneMatch: t.leaseVersion == ""}
+	_, err := t.indexStorageClient.PutFileIf(t.ctx, "", t.leaseFileName(), content, cond)
+	if errors.Is(err, client.ErrPreconditionFailed) {
+		return fmt.Errorf("table %s is being compacted by another compactor: %w", t.name, err)
+	}
+	return err
+}
+
 // applyRetention applies retention on the index sets
 func (t *table) applyRetention() error {
 	tableInterval := retention.ExtractIntervalFromTableName(t.name)
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
@@ -199,7 +200,7 @@ func TestTable_Compaction(t *testing.T) {
 					require.NoError(t, err)
 
 					table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-						newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10)
+						newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10, false)
 					require.NoError(t, err)
 
 					require.NoError(t, table.compact(false))
@@ -236,7 +237,7 @@ func TestTable_Compaction(t *testing.T) {
 
 					// running compaction again should not do anything.
 					table, err = newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-						newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10)
+						newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10, false)
 					require.NoError(t, err)
 
 					require.NoError(t, table.compact(false))
@@ -304,6 +305,8 @@ func TestTable_CompactionRetention(t *testing.T) {
 				assert: func(t *testing.T, storagePath, tableName string) {
 					_, err := os.ReadDir(filepath.Join(storagePath, tableName))
 					require.True(t, os.IsNotExist(err))
+					// the lease of the table is removed along with it
+					require.NoFileExists(t, filepath.Join(storagePath, tableName+leaseFileSuffix))
 				},
 				tableMarker: TableMarkerFunc(func(ctx context.Context, tableName, userID string, indexFile retention.IndexProcessor, logger log.Logger) (bool, bool, error) {
 					return true, true, nil
@@ -324,6 +327,7 @@ func TestTable_CompactionRetention(t *testing.T) {
 					validateTable(t, filepath.Join(storagePath, tableName), expectedNumCommonDBs, expectedNumUsers, func(filename string) {
 						require.True(t, strings.HasSuffix(filename, ".gz"))
 					})
+					require.FileExists(t, filepath.Join(storagePath, tableName+leaseFileSuffix))
 				},
 				tableMarker: TableMarkerFunc(func(ctx context.Context, tableName, userID string, indexFile retention.IndexProcessor, logger log.Logger) (bool, bool, error) {
 					return false, true, nil
@@ -379,7 +383,7 @@ func TestTable_CompactionRetention(t *testing.T) {
 					newTestIndexCompactor(), config.PeriodConfig{},
 					tt.tableMarker, IntervalMayHaveExpiredChunksFunc(func(interval model.Interval, userID string) bool {
 						return true
-					}), 10)
+					}), 10, true)
 				require.NoError(t, err)
 
 				require.NoError(t, table.compact(true))
@@ -452,7 +456,7 @@ func TestTable_CompactionFailure(t *testing.T) {
 	require.NoError(t, err)
 
 	table, err := newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-		newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10)
+		newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10, false)
 	require.NoError(t, err)
 
 	// compaction should fail due to a non-boltdb file.
@@ -470,10 +474,77 @@ func TestTable_CompactionFailure(t *testing.T) {
 	require.NoError(t, os.Remove(filepath.Join(tablePathInStorage, "fail.gz")))
 
 	table, err = newTable(context.Background(), tableWorkingDirectory, storage.NewIndexStorageClient(objectClient, ""),
-		newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10)
+		newTestIndexCompactor(), config.PeriodConfig{}, nil, nil, 10, false)
 	require.NoError(t, err)
 	require.NoError(t, table.compact(false))
 
 	// ensure that we have cleanup the local working directory after successful compaction.
 	require.NoFileExists(t, tableWorkingDirectory)
 }
+
+// hookIndexCompactor calls afterCompaction after compacting the table.
+type hookIndexCompactor struct {
+	IndexCompactor
+	afterCompaction func()
+}
+
+func (c hookIndexCompactor) NewTableCompactor(ctx context.Context, commonIndexSet IndexSet, existingUserIndexSet map[string]IndexSet,
+	makeEmptyUserIndexSetFunc MakeEmptyUserIndexSetFunc, periodConfig config.PeriodConfig,
+) TableCompactor {
+	return hookTableCompactor{
+		TableCompactor:  c.IndexCompactor.NewTableCompactor(ctx, commonIndexSet, existingUserIndexSet, makeEmptyUserIndexSetFunc, periodConfig),
+		afterCompaction: c.afterCompaction,
+	}
+}
+
+type hookTableCompactor struct {
+	TableCompactor
+	afterCompaction func()
+}
+
+func (c hookTableCompactor) CompactTable() error {
+	if err := c.TableCompactor.CompactTable(); err != nil {
+		return err
+	}
+	c.afterCompaction()
+	return nil
+}
+
+func TestTable_CompactionConditionalWrites(t *testing.T) {
+	tempDir := t.TempDir()
+	objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+	tablePathInStorage := filepath.Join(objectStoragePath, tableName)
+
+	SetupTable(t, tablePathInStorage, IndexesConfig{NumUnCompactedFiles: 10}, PerUserIndexesConfig{})
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: objectStoragePath})
+	require.NoError(t, err)
+
+	newConditionalTable := func(workingDir string, indexCompactor IndexCompactor) *table {
+		table, err := newTable(context.Background(), filepath.Join(tempDir, workingDir, tableName), storage.NewIndexStorageClient(objectClient, ""),
+			indexCompactor, config.PeriodConfig{}, nil, nil, 10, true)
+		require.NoError(t, err)
+		return table
+	}
+
+	// another compactor compacts the table while the first one is compacting it
+	first := newConditionalTable("first", hookIndexCompactor{
+		IndexCompactor: newTestIndexCompactor(),
+		afterCompaction: func() {
+			require.NoError(t, newConditionalTable("second", newTestIndexCompactor()).compact(false))
+		},
+	})
+
+	// which makes the first compactor fail before uploading or removing any file
+	require.ErrorIs(t, first.compact(false), client.ErrPreconditionFailed)
+
+	files, err := os.ReadDir(tablePathInStorage)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.FileExists(t, filepath.Join(objectStoragePath, tableName+leaseFileSuffix))
+
+	// the table is listed without the lease
+	tables, err := storage.NewIndexStorageClient(objectClient, "").ListTables(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{tableName}, tables)
+}
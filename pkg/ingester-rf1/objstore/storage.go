@@ -93,6 +93,30 @@ func (m *Multi) GetObject(ctx context.Context, objectKey string) (io.ReadCloser,
 	return s.GetObject(ctx, objectKey)
 }
 
+func (m *Multi) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	s, err := m.GetStoreFor(model.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.GetObjectRange(ctx, objectKey, offset, length)
+}
+
+func (m *Multi) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	s, err := m.GetStoreFor(model.Now())
+	if err != nil {
+		return "", err
+	}
+	return s.PutObjectIf(ctx, objectKey, object, cond)
+}
+
+func (m *Multi) ObjectVersion(ctx context.Context, objectKey string) (string, error) {
+	s, err := m.GetStoreFor(model.Now())
+	if err != nil {
+		return "", err
+	}
+	return s.ObjectVersion(ctx, objectKey)
+}
+
 func (m *Multi) List(ctx context.Context, prefix string, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	s, err := m.GetStoreFor(model.Now())
 	if err != nil {
@@ -193,9 +193,10 @@ type streamInstance struct {
 type streamIterator struct {
 	instances []streamInstance
 
-	current Series
-	buffer  []chunkWithBuffer
-	err     error
+	current       Series
+	currentStream *stream
+	buffer        []chunkWithBuffer
+	err           error
 }
 
 // newStreamsIterator returns a new stream iterators that iterates over one instance at a time, then
@@ -264,6 +265,7 @@ func (s *streamIterator) Next() bool {
 		s.current.Chunks = append(s.current.Chunks, c.Chunk)
 	}
 
+	s.currentStream = stream
 	s.current.UserID = currentInstance.id
 	s.current.Fingerprint = uint64(stream.fp)
 	s.current.Labels = logproto.FromLabelsToLabelAdapters(stream.labels)
@@ -348,7 +350,8 @@ func (w *WALCheckpointWriter) Advance() (bool, error) {
 		return false, errors.Wrap(err, "create checkpoint dir")
 	}
 
-	checkpoint, err := wlog.NewSize(log.With(util_log.Logger, "component", "checkpoint_wal"), nil, checkpointDirTemp, walSegmentSize, wlog.CompressionNone)
+	// the checkpoint is compressed like the WAL segments
+	checkpoint, err := wlog.NewSize(log.With(util_log.Logger, "component", "checkpoint_wal"), nil, checkpointDirTemp, walSegmentSize, w.segmentWAL.CompressionType())
 	if err != nil {
 		return false, errors.Wrap(err, "open checkpoint")
 	}
@@ -5,13 +5,18 @@ package ingester
 
 import (
 	bytes "bytes"
+	context "context"
 	fmt "fmt"
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
 	_ "github.com/gogo/protobuf/types"
 	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
+	push "github.com/grafana/loki/pkg/push"
 	_ "github.com/grafana/loki/v3/pkg/logproto"
 	github_com_grafana_loki_v3_pkg_logproto "github.com/grafana/loki/v3/pkg/logproto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	io "io"
 	math "math"
 	math_bits "math/bits"
@@ -242,40 +247,43 @@ func init() {
 func init() { proto.RegisterFile("pkg/ingester/checkpoint.proto", fileDescriptor_00f4b7152db9bdb5) }
 
 var fileDescriptor_00f4b7152db9bdb5 = []byte{
-	// 521 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0x31, 0x8f, 0xd3, 0x30,
-	0x14, 0x8e, 0xdb, 0x5c, 0xae, 0xe7, 0xc2, 0x62, 0x9d, 0x90, 0x29, 0xc2, 0xad, 0x6e, 0xea, 0x94,
-	0x48, 0xbd, 0x1b, 0x18, 0x10, 0xd2, 0xf5, 0x10, 0x12, 0x52, 0x07, 0x14, 0x8e, 0x85, 0x05, 0xb9,
-	0x89, 0xeb, 0x44, 0x4d, 0xe3, 0xc8, 0x76, 0x91, 0xba, 0xf1, 0x13, 0x6e, 0xe3, 0x2f, 0xf0, 0x53,
-	0x6e, 0xec, 0x78, 0x02, 0xe9, 0xa0, 0xe9, 0xc2, 0x78, 0x3f, 0x01, 0xd9, 0x49, 0x4a, 0xd9, 0xe8,
-	0xf6, 0xbe, 0xf7, 0xfc, 0xbd, 0x4f, 0xfe, 0xde, 0x07, 0x9f, 0x17, 0x73, 0x1e, 0xa4, 0x39, 0x67,
-	0x4a, 0x33, 0x19, 0x44, 0x09, 0x8b, 0xe6, 0x85, 0x48, 0x73, 0xed, 0x17, 0x52, 0x68, 0x81, 0x1e,
-	0x67, 0x62, 0x9e, 0x7e, 0x6a, 0xe6, 0xbd, 0x53, 0x2e, 0xb8, 0xb0, 0x93, 0xc0, 0x54, 0xd5, 0xa3,
-	0x5e, 0x9f, 0x0b, 0xc1, 0x33, 0x16, 0x58, 0x34, 0x5d, 0xce, 0x02, 0x9d, 0x2e, 0x98, 0xd2, 0x74,
-	0x51, 0xd4, 0x0f, 0x9e, 0x19, 0x91, 0x4c, 0xf0, 0x8a, 0xd9, 0x14, 0xd5, 0xf0, 0xec, 0x47, 0x0b,
-	0x1e, 0x5d, 0x25, 0xcb, 0x7c, 0x8e, 0x5e, 0x40, 0x77, 0x26, 0xc5, 0x02, 0x83, 0x01, 0x18, 0x76,
-	0x47, 0x3d, 0xbf, 0x5a, 0xeb, 0x37, 0x6b, 0xfd, 0xeb, 0x66, 0xed, 0xb8, 0x73, 0x7b, 0xdf, 0x77,
-	0x6e, 0x7e, 0xf6, 0x41, 0x68, 0x19, 0xe8, 0x02, 0xb6, 0xb4, 0xc0, 0xad, 0x03, 0x78, 0x2d, 0x2d,
-	0xd0, 0x18, 0x9e, 0xcc, 0xb2, 0xa5, 0x4a, 0x58, 0x7c, 0xa9, 0x71, 0xfb, 0x00, 0xf2, 0x5f, 0x1a,
-	0x7a, 0x03, 0xbb, 0x19, 0x55, 0xfa, 0x43, 0x11, 0x53, 0xcd, 0x62, 0xec, 0x1e, 0xb0, 0x65, 0x9f,
-	0x88, 0x9e, 0x40, 0x2f, 0xca, 0x84, 0x62, 0x31, 0x3e, 0x1a, 0x80, 0x61, 0x27, 0xac, 0x91, 0xe9,
-	0xab, 0x55, 0x1e, 0xb1, 0x18, 0x7b, 0x55, 0xbf, 0x42, 0x08, 0x41, 0x37, 0xa6, 0x9a, 0xe2, 0xe3,
-	0x01, 0x18, 0x3e, 0x0a, 0x6d, 0x6d, 0x7a, 0x09, 0xa3, 0x31, 0xee, 0x54, 0x3d, 0x53, 0x9f, 0x7d,
-	0x6d, 0x43, 0xef, 0x3d, 0x93, 0x29, 0x53, 0x66, 0xd5, 0x52, 0x31, 0xf9, 0xf6, 0xb5, 0x35, 0xf8,
-	0x24, 0xac, 0x11, 0x1a, 0xc0, 0xee, 0xcc, 0x5c, 0x58, 0x16, 0x32, 0xcd, 0xb5, 0x75, 0xd1, 0x0d,
-	0xf7, 0x5b, 0x48, 0x40, 0x2f, 0xa3, 0x53, 0x96, 0x29, 0xdc, 0x1e, 0xb4, 0x87, 0xdd, 0xd1, 0x53,
-	0x7f, 0x77, 0xc3, 0x09, 0xe3, 0x34, 0x5a, 0x4d, 0xcc, 0xf4, 0x1d, 0x4d, 0xe5, 0xf8, 0xa5, 0xf9,
-	0xde, 0xf7, 0xfb, 0xfe, 0x05, 0x4f, 0x75, 0xb2, 0x9c, 0xfa, 0x91, 0x58, 0x04, 0x5c, 0xd2, 0x19,
-	0xcd, 0x69, 0x60, 0xb2, 0x14, 0x7c, 0x3e, 0x0f, 0xf6, 0xd3, 0xe0, 0x5b, 0xea, 0x65, 0x4c, 0x0b,
-	0xcd, 0x64, 0x58, 0xcb, 0xa0, 0x11, 0xf4, 0x22, 0x13, 0x09, 0x85, 0x5d, 0x2b, 0x78, 0xea, 0xff,
-	0x93, 0x43, 0xdf, 0xe6, 0x65, 0xec, 0x1a, 0xad, 0xb0, 0x7e, 0x59, 0x67, 0xe0, 0xe8, 0xc0, 0x0c,
-	0xf4, 0x60, 0xc7, 0x9c, 0x61, 0x92, 0xe6, 0xcc, 0x3a, 0x7c, 0x12, 0xee, 0x30, 0xc2, 0xf0, 0x98,
-	0xe5, 0x5a, 0xae, 0xae, 0xb4, 0xb5, 0xb9, 0x1d, 0x36, 0xd0, 0x24, 0x27, 0x49, 0x79, 0xc2, 0x94,
-	0xbe, 0x56, 0xd6, 0xee, 0xff, 0x4e, 0xce, 0x8e, 0x36, 0x7e, 0xb5, 0xde, 0x10, 0xe7, 0x6e, 0x43,
-	0x9c, 0x87, 0x0d, 0x01, 0x5f, 0x4a, 0x02, 0xbe, 0x95, 0x04, 0xdc, 0x96, 0x04, 0xac, 0x4b, 0x02,
-	0x7e, 0x95, 0x04, 0xfc, 0x2e, 0x89, 0xf3, 0x50, 0x12, 0x70, 0xb3, 0x25, 0xce, 0x7a, 0x4b, 0x9c,
-	0xbb, 0x2d, 0x71, 0x3e, 0x76, 0x1a, 0x0f, 0xa6, 0x9e, 0x15, 0x3a, 0xff, 0x13, 0x00, 0x00, 0xff,
-	0xff, 0x4e, 0xf6, 0xfc, 0x95, 0xc2, 0x03, 0x00, 0x00,
+	// 570 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0x3d, 0x6f, 0xd4, 0x40,
+	0x10, 0xf5, 0xde, 0x39, 0x8e, 0xb3, 0x07, 0x42, 0x5a, 0x42, 0xb4, 0x1c, 0x62, 0xef, 0x94, 0xea,
+	0x2a, 0x5b, 0xba, 0xa4, 0xa0, 0x40, 0x48, 0xb9, 0x44, 0x48, 0x48, 0x29, 0x22, 0x27, 0x34, 0x34,
+	0x68, 0xcf, 0x5e, 0x7f, 0xe8, 0x7c, 0x5e, 0x6b, 0x77, 0x8d, 0x94, 0x8e, 0x9f, 0x90, 0x8e, 0xbf,
+	0xc0, 0x4f, 0x49, 0x99, 0x32, 0x02, 0x29, 0x10, 0xa7, 0xa1, 0xcc, 0x4f, 0x40, 0xbb, 0xb6, 0x93,
+	0x83, 0xee, 0x1a, 0x6b, 0xde, 0x9b, 0x7d, 0xf3, 0xe4, 0x99, 0x07, 0x5f, 0x97, 0x8b, 0xc4, 0xcf,
+	0x8a, 0x84, 0x49, 0xc5, 0x84, 0x1f, 0xa6, 0x2c, 0x5c, 0x94, 0x3c, 0x2b, 0x94, 0x57, 0x0a, 0xae,
+	0x38, 0x7a, 0x9a, 0xf3, 0x45, 0xf6, 0xb9, 0xeb, 0x0f, 0xb7, 0x13, 0x9e, 0x70, 0xd3, 0xf1, 0x75,
+	0xd5, 0x3c, 0x1a, 0x8e, 0x12, 0xce, 0x93, 0x9c, 0xf9, 0x06, 0xcd, 0xab, 0xd8, 0x57, 0xd9, 0x92,
+	0x49, 0x45, 0x97, 0x65, 0xfb, 0xe0, 0x95, 0x36, 0xc9, 0x79, 0xd2, 0x28, 0xbb, 0xa2, 0x6d, 0x3e,
+	0xd7, 0xcd, 0xb2, 0x92, 0xa9, 0xf9, 0x34, 0xe4, 0xee, 0xcf, 0x1e, 0xdc, 0x38, 0x4c, 0xab, 0x62,
+	0x81, 0xde, 0x40, 0x3b, 0x16, 0x7c, 0x89, 0xc1, 0x18, 0x4c, 0x06, 0xd3, 0xa1, 0xd7, 0x78, 0x79,
+	0x9d, 0x97, 0x77, 0xd6, 0x79, 0xcd, 0xdc, 0xcb, 0x9b, 0x91, 0x75, 0xf1, 0x6b, 0x04, 0x02, 0xa3,
+	0x40, 0xfb, 0xb0, 0xa7, 0x38, 0xee, 0xad, 0xa1, 0xeb, 0x29, 0x8e, 0x66, 0x70, 0x2b, 0xce, 0x2b,
+	0x99, 0xb2, 0xe8, 0x40, 0xe1, 0xfe, 0x1a, 0xe2, 0x47, 0x19, 0x7a, 0x0f, 0x07, 0x39, 0x95, 0xea,
+	0x63, 0x19, 0x51, 0xc5, 0x22, 0x6c, 0xaf, 0x31, 0x65, 0x55, 0x88, 0x76, 0xa0, 0x13, 0xe6, 0x5c,
+	0xb2, 0x08, 0x6f, 0x8c, 0xc1, 0xc4, 0x0d, 0x5a, 0xa4, 0x79, 0x79, 0x5e, 0x84, 0x2c, 0xc2, 0x4e,
+	0xc3, 0x37, 0x08, 0x21, 0x68, 0x47, 0x54, 0x51, 0xbc, 0x39, 0x06, 0x93, 0x27, 0x81, 0xa9, 0x35,
+	0x97, 0x32, 0x1a, 0x61, 0xb7, 0xe1, 0x74, 0xbd, 0xfb, 0xad, 0x0f, 0x9d, 0x53, 0x26, 0x32, 0x26,
+	0xf5, 0xa8, 0x4a, 0x32, 0xf1, 0xe1, 0xc8, 0x2c, 0x78, 0x2b, 0x68, 0x11, 0x1a, 0xc3, 0x41, 0xac,
+	0xcf, 0x2e, 0x4a, 0x91, 0x15, 0xca, 0x6c, 0xd1, 0x0e, 0x56, 0x29, 0xc4, 0xa1, 0x93, 0xd3, 0x39,
+	0xcb, 0x25, 0xee, 0x8f, 0xfb, 0x93, 0xc1, 0xf4, 0xa5, 0xf7, 0x70, 0xd8, 0x63, 0x96, 0xd0, 0xf0,
+	0xfc, 0x58, 0x77, 0x4f, 0x68, 0x26, 0x66, 0x6f, 0xf5, 0xef, 0xfd, 0xb8, 0x19, 0xed, 0x27, 0x99,
+	0x4a, 0xab, 0xb9, 0x17, 0xf2, 0xa5, 0x9f, 0x08, 0x1a, 0xd3, 0x82, 0xfa, 0x3a, 0x60, 0xfe, 0x97,
+	0x3d, 0x7f, 0x35, 0x22, 0x9e, 0x91, 0x1e, 0x44, 0xb4, 0x54, 0x4c, 0x04, 0xad, 0x0d, 0x9a, 0x42,
+	0x27, 0xd4, 0x91, 0x90, 0xd8, 0x36, 0x86, 0xdb, 0xde, 0x3f, 0xe1, 0xf4, 0x4c, 0x5e, 0x66, 0xb6,
+	0xf6, 0x0a, 0xda, 0x97, 0x6d, 0x06, 0x36, 0xd6, 0xcc, 0xc0, 0x10, 0xba, 0xfa, 0x0c, 0xc7, 0x59,
+	0xc1, 0xcc, 0x86, 0xb7, 0x82, 0x07, 0x8c, 0x30, 0xdc, 0x64, 0x85, 0x12, 0xe7, 0x87, 0xca, 0xac,
+	0xb9, 0x1f, 0x74, 0x50, 0x27, 0x27, 0xcd, 0x92, 0x94, 0x49, 0x75, 0x26, 0xb1, 0xbb, 0x86, 0xe5,
+	0xa3, 0x6c, 0x7a, 0x02, 0xdd, 0x33, 0x41, 0x0b, 0x19, 0x33, 0x81, 0x8e, 0xe0, 0xb3, 0xae, 0x3e,
+	0x55, 0x82, 0xd1, 0xa5, 0x44, 0x2f, 0xfe, 0xfb, 0xe5, 0xe6, 0x88, 0xc3, 0x9d, 0xc7, 0xd5, 0x9f,
+	0x54, 0x32, 0x0d, 0x98, 0x2c, 0x79, 0x21, 0xd9, 0xae, 0x35, 0x01, 0xb3, 0x77, 0x57, 0xb7, 0xc4,
+	0xba, 0xbe, 0x25, 0xd6, 0xfd, 0x2d, 0x01, 0x5f, 0x6b, 0x02, 0xbe, 0xd7, 0x04, 0x5c, 0xd6, 0x04,
+	0x5c, 0xd5, 0x04, 0xfc, 0xae, 0x09, 0xf8, 0x53, 0x13, 0xeb, 0xbe, 0x26, 0xe0, 0xe2, 0x8e, 0x58,
+	0x57, 0x77, 0xc4, 0xba, 0xbe, 0x23, 0xd6, 0x27, 0xb7, 0xb3, 0x98, 0x3b, 0x66, 0xee, 0xde, 0xdf,
+	0x01, 0x00, 0xe6, 0x00, 0x30, 0x81, 0x29, 0x04, 0x00, 0x00,
 }
 
 func (this *Chunk) Equal(that interface{}) bool {
@@ -426,6 +434,127 @@ func valueToGoStringCheckpoint(v interface{}, typ string) string {
 	pv := reflect.Indirect(rv).Interface()
 	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
 }
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TransferClient is the client API for Transfer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TransferClient interface {
+	// TransferStreams receives the streams of a tenant, as a sequence of Series
+	// whose chunks may be split across consecutive Series, and responds once
+	// they are all added.
+	TransferStreams(ctx context.Context, opts ...grpc.CallOption) (Transfer_TransferStreamsClient, error)
+}
+
+type transferClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransferClient(cc *grpc.ClientConn) TransferClient {
+	return &transferClient{cc}
+}
+
+func (c *transferClient) TransferStreams(ctx context.Context, opts ...grpc.CallOption) (Transfer_TransferStreamsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Transfer_serviceDesc.Streams[0], "/loki_ingester.Transfer/TransferStreams", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transferTransferStreamsClient{stream}
+	return x, nil
+}
+
+type Transfer_TransferStreamsClient interface {
+	Send(*Series) error
+	CloseAndRecv() (*push.PushResponse, error)
+	grpc.ClientStream
+}
+
+type transferTransferStreamsClient struct {
+	grpc.ClientStream
+}
+
+func (x *transferTransferStreamsClient) Send(m *Series) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *transferTransferStreamsClient) CloseAndRecv() (*push.PushResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(push.PushResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TransferServer is the server API for Transfer service.
+type TransferServer interface {
+	// TransferStreams receives the streams of a tenant, as a sequence of Series
+	// whose chunks may be split across consecutive Series, and responds once
+	// they are all added.
+	TransferStreams(Transfer_TransferStreamsServer) error
+}
+
+// UnimplementedTransferServer can be embedded to have forward compatible implementations.
+type UnimplementedTransferServer struct {
+}
+
+func (*UnimplementedTransferServer) TransferStreams(srv Transfer_TransferStreamsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransferStreams not implemented")
+}
+
+func RegisterTransferServer(s *grpc.Server, srv TransferServer) {
+	s.RegisterService(&_Transfer_serviceDesc, srv)
+}
+
+func _Transfer_TransferStreams_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TransferServer).TransferStreams(&transferTransferStreamsServer{stream})
+}
+
+type Transfer_TransferStreamsServer interface {
+	SendAndClose(*push.PushResponse) error
+	Recv() (*Series, error)
+	grpc.ServerStream
+}
+
+type transferTransferStreamsServer struct {
+	grpc.ServerStream
+}
+
+func (x *transferTransferStreamsServer) SendAndClose(m *push.PushResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *transferTransferStreamsServer) Recv() (*Series, error) {
+	m := new(Series)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Transfer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "loki_ingester.Transfer",
+	HandlerType: (*TransferServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TransferStreams",
+			Handler:       _Transfer_TransferStreams_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/ingester/checkpoint.proto",
+}
+
 func (m *Chunk) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
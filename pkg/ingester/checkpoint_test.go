@@ -57,9 +57,18 @@ func defaultIngesterTestConfigWithWAL(t *testing.T, walDir string) Config {
 }
 
 func TestIngesterWAL(t *testing.T) {
+	for _, compression := range []string{"none", "snappy", "zstd"} {
+		t.Run(compression, func(t *testing.T) {
+			testIngesterWAL(t, compression)
+		})
+	}
+}
+
+func testIngesterWAL(t *testing.T, compression string) {
 	walDir := t.TempDir()
 
 	ingesterConfig := defaultIngesterTestConfigWithWAL(t, walDir)
+	ingesterConfig.WAL.Compression = compression
 
 	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
@@ -64,17 +64,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 
 // New returns a new ingester client.
 func New(cfg Config, addr string) (HealthAndIngesterClient, error) {
-	opts := []grpc.DialOption{
-		grpc.WithDefaultCallOptions(cfg.GRPCClientConfig.CallOptions()...),
-	}
-
-	dialOpts, err := cfg.GRPCClientConfig.DialOption(instrumentation(&cfg))
-	if err != nil {
-		return nil, err
-	}
-
-	opts = append(opts, dialOpts...)
-	conn, err := grpc.Dial(addr, opts...)
+	conn, err := Dial(cfg, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +77,21 @@ func New(cfg Config, addr string) (HealthAndIngesterClient, error) {
 	}, nil
 }
 
+// Dial returns a connection to the ingester at addr, configured and instrumented like the ingester clients.
+func Dial(cfg Config, addr string) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(cfg.GRPCClientConfig.CallOptions()...),
+	}
+
+	dialOpts, err := cfg.GRPCClientConfig.DialOption(instrumentation(&cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, dialOpts...)
+	return grpc.Dial(addr, opts...)
+}
+
 func instrumentation(cfg *Config) ([]grpc.UnaryClientInterceptor, []grpc.StreamClientInterceptor) {
 	var unaryInterceptors []grpc.UnaryClientInterceptor
 	unaryInterceptors = append(unaryInterceptors, cfg.GRPCUnaryClientInterceptors...)
@@ -34,6 +34,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	server_util "github.com/grafana/loki/v3/pkg/util/server"
@@ -59,7 +60,6 @@ import (
 	index_stats "github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/v3/pkg/util"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
-	"github.com/grafana/loki/v3/pkg/util/wal"
 )
 
 const (
@@ -94,6 +94,8 @@ type Config struct {
 	TargetChunkSize     int               `yaml:"chunk_target_size"`
 	ChunkEncoding       string            `yaml:"chunk_encoding"`
 	parsedEncoding      chunkenc.Encoding `yaml:"-"` // placeholder for validated encoding
+	ColumnarChunks      bool              `yaml:"columnar_chunks"`
+	ChunkBlockHints     bool              `yaml:"chunk_block_hints"`
 	MaxChunkAge         time.Duration     `yaml:"max_chunk_age"`
 	AutoForgetUnhealthy bool              `yaml:"autoforget_unhealthy"`
 
@@ -105,6 +107,7 @@ type Config struct {
 
 	// For testing, you can override the address and ID of this ingester.
 	ingesterClientFactory func(cfg client.Config, addr string) (client.HealthAndIngesterClient, error)
+	transferClientFactory func(cfg client.Config, addr string) (*grpc.ClientConn, error)
 
 	QueryStore                  bool          `yaml:"-"`
 	QueryStoreMaxLookBackPeriod time.Duration `yaml:"query_store_max_look_back_period"`
@@ -124,6 +127,9 @@ type Config struct {
 
 	ShutdownMarkerPath string `yaml:"shutdown_marker_path"`
 
+	TransferOnShutdown bool          `yaml:"transfer_on_shutdown"`
+	TransferTimeout    time.Duration `yaml:"transfer_timeout"`
+
 	OwnedStreamsCheckInterval time.Duration `yaml:"owned_streams_check_interval" doc:"description=Interval at which the ingester ownedStreamService checks for changes in the ring to recalculate owned streams."`
 }
 
@@ -142,7 +148,9 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.MaxChunkIdle, "ingester.chunks-idle-period", 30*time.Minute, "How long chunks should sit in-memory with no updates before being flushed if they don't hit the max block size. This means that half-empty chunks will still be flushed after a certain period as long as they receive no further activity.")
 	f.IntVar(&cfg.BlockSize, "ingester.chunks-block-size", 256*1024, "The targeted _uncompressed_ size in bytes of a chunk block When this threshold is exceeded the head block will be cut and compressed inside the chunk.")
 	f.IntVar(&cfg.TargetChunkSize, "ingester.chunk-target-size", 1572864, "A target _compressed_ size in bytes for chunks. This is a desired size not an exact size, chunks may be slightly bigger or significantly smaller if they get flushed for other reasons (e.g. chunk_idle_period). A value of 0 creates chunks with a fixed 10 blocks, a non zero value will create chunks with a variable number of blocks to meet the target size.") // 1.5 MB
-	f.StringVar(&cfg.ChunkEncoding, "ingester.chunk-encoding", chunkenc.EncGZIP.String(), fmt.Sprintf("The algorithm to use for compressing chunk. (%s)", chunkenc.SupportedEncoding()))
+	f.StringVar(&cfg.ChunkEncoding, "ingester.chunk-encoding", chunkenc.EncGZIP.String(), fmt.Sprintf("The algorithm to use for compressing chunk. (%s). zstd-dict compresses the blocks of a chunk with a dictionary built from its first block, which requires schema v13 or later and cannot be read by older versions of Loki.", chunkenc.SupportedEncoding()))
+	f.BoolVar(&cfg.ColumnarChunks, "ingester.columnar-chunks", false, "Experimental: Store the timestamps, lines and structured metadata of chunk blocks in separately compressed columns, so that metric queries which do not need the content of the lines, like count_over_time, skip decompressing them. Requires schema v13 or later. Chunks written with this option cannot be read by older versions of Loki.")
+	f.BoolVar(&cfg.ChunkBlockHints, "ingester.chunk-block-hints", false, "Experimental: Store in columnar chunks a bloom filter of the lines and the names of the structured metadata of every block, so that queries skip decompressing the blocks which cannot match their line filters and structured metadata filters. Implies -ingester.columnar-chunks. Requires schema v13 or later. Chunks written with this option cannot be read by older versions of Loki.")
 	f.DurationVar(&cfg.SyncPeriod, "ingester.sync-period", 1*time.Hour, "Parameters used to synchronize ingesters to cut chunks at the same moment. Sync period is used to roll over incoming entry to a new chunk. If chunk's utilization isn't high enough (eg. less than 50% when sync_min_utilization is set to 0.5), then this chunk rollover doesn't happen.")
 	f.Float64Var(&cfg.SyncMinUtilization, "ingester.sync-min-utilization", 0.1, "Minimum utilization of chunk when doing synchronization.")
 	f.IntVar(&cfg.MaxReturnedErrors, "ingester.max-ignored-stream-errors", 10, "The maximum number of errors a stream will report to the user when a push fails. 0 to make unlimited.")
@@ -152,6 +160,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.IndexShards, "ingester.index-shards", index.DefaultIndexShards, "Shard factor used in the ingesters for the in process reverse index. This MUST be evenly divisible by ALL schema shard factors or Loki will not start.")
 	f.IntVar(&cfg.MaxDroppedStreams, "ingester.tailer.max-dropped-streams", 10, "Maximum number of dropped streams to keep in memory during tailing.")
 	f.StringVar(&cfg.ShutdownMarkerPath, "ingester.shutdown-marker-path", "", "Path where the shutdown marker file is stored. If not set and common.path_prefix is set then common.path_prefix will be used.")
+	f.BoolVar(&cfg.TransferOnShutdown, "ingester.transfer-on-shutdown", false, "Experimental: When the ingester must flush its chunks on shutdown, transfer its in-memory streams to the ingesters taking over their tokens in the ring instead. The streams which cannot be transferred are flushed.")
+	f.DurationVar(&cfg.TransferTimeout, "ingester.transfer-timeout", time.Minute, "The timeout for the transfer of the in-memory streams on shutdown, after which the streams which are not transferred yet are flushed.")
 	f.DurationVar(&cfg.OwnedStreamsCheckInterval, "ingester.owned-streams-check-interval", 30*time.Second, "Interval at which the ingester ownedStreamService checks for changes in the ring to recalculate owned streams.")
 }
 
@@ -178,6 +188,9 @@ func (cfg *Config) Validate() error {
 	if cfg.IndexShards <= 0 {
 		return fmt.Errorf("invalid ingester index shard factor: %d", cfg.IndexShards)
 	}
+	if cfg.TransferOnShutdown && cfg.TransferTimeout <= 0 {
+		return fmt.Errorf("invalid transfer timeout: %s", cfg.TransferTimeout)
+	}
 
 	return nil
 }
@@ -202,6 +215,7 @@ type Interface interface {
 	logproto.PusherServer
 	logproto.QuerierServer
 	logproto.StreamDataServer
+	TransferServer
 
 	CheckReady(ctx context.Context) error
 	FlushHandler(w http.ResponseWriter, _ *http.Request)
@@ -280,6 +294,9 @@ func New(cfg Config, clientConfig client.Config, store Store, limits Limits, con
 	if cfg.ingesterClientFactory == nil {
 		cfg.ingesterClientFactory = client.New
 	}
+	if cfg.transferClientFactory == nil {
+		cfg.transferClientFactory = client.Dial
+	}
 	compressionStats.Set(cfg.ChunkEncoding)
 	targetSizeStats.Set(int64(cfg.TargetChunkSize))
 	walStats.Set("disabled")
@@ -444,6 +461,15 @@ func (i *Ingester) setupAutoForget() {
 	}()
 }
 
+// onWALCorruption reports the corrupted records skipped while replaying the WAL.
+func (i *Ingester) onWALCorruption(walType string) func(err error, skippedBytes int64) {
+	return func(err error, skippedBytes int64) {
+		i.metrics.walCorruptionsTotal.WithLabelValues(walType).Inc()
+		i.metrics.walCorruptedBytesTotal.WithLabelValues(walType).Add(float64(skippedBytes))
+		level.Warn(i.logger).Log("msg", "skipped corrupted WAL records", "type", walType, "skipped_bytes", skippedBytes, "err", err)
+	}
+}
+
 func (i *Ingester) starting(ctx context.Context) error {
 	if i.cfg.WAL.Enabled {
 		start := time.Now()
@@ -479,7 +505,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 		defer endReplay()
 
 		level.Info(i.logger).Log("msg", "recovering from checkpoint")
-		checkpointReader, checkpointCloser, err := newCheckpointReader(i.cfg.WAL.Dir, i.logger)
+		checkpointReader, checkpointCloser, err := newCheckpointReader(i.cfg.WAL.Dir, i.logger, i.onWALCorruption(walTypeCheckpoint))
 		if err != nil {
 			return err
 		}
@@ -501,11 +527,11 @@ func (i *Ingester) starting(ctx context.Context) error {
 		)
 
 		level.Info(i.logger).Log("msg", "recovering from WAL")
-		segmentReader, segmentCloser, err := wal.NewWalReader(i.cfg.WAL.Dir, -1)
+		segmentReader, err := newSegmentsReader(i.cfg.WAL.Dir, i.onWALCorruption(walTypeSegment))
 		if err != nil {
 			return err
 		}
-		defer segmentCloser.Close()
+		defer segmentReader.Close()
 
 		segmentRecoveryErr := RecoverWAL(ctx, segmentReader, recoverer)
 		if segmentRecoveryErr != nil {
@@ -1420,7 +1446,7 @@ func (i *Ingester) tail(req *logproto.TailRequest, queryServer logproto.Querier_
 		return fmt.Errorf("unsupported query expression: want (LogSelectorExpr), got (%T)", req.Plan.AST)
 	}
 
-	tailer, err := newTailer(instanceID, expr, queryServer, i.cfg.MaxDroppedStreams)
+	tailer, err := newTailer(instanceID, expr, req.FieldMasks, queryServer, i.cfg.MaxDroppedStreams)
 	if err != nil {
 		return err
 	}
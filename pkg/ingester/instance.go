@@ -46,6 +46,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/util/deletion"
 	"github.com/grafana/loki/v3/pkg/util/httpreq"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 	mathutil "github.com/grafana/loki/v3/pkg/util/math"
 	lokiring "github.com/grafana/loki/v3/pkg/util/ring"
 	server_util "github.com/grafana/loki/v3/pkg/util/server"
@@ -256,24 +257,33 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 	}
 
 	if !record.IsEmpty() {
-		if err := i.wal.Log(record); err != nil {
-			if e, ok := err.(*os.PathError); ok && e.Err == syscall.ENOSPC {
-				i.metrics.walDiskFullFailures.Inc()
-				i.flushOnShutdownSwitch.TriggerAnd(func() {
-					level.Error(util_log.Logger).Log(
-						"msg",
-						"Error writing to WAL, disk full, no further messages will be logged for this error",
-					)
-				})
-			} else {
-				return err
-			}
+		if err := i.logRecord(record); err != nil {
+			return err
 		}
 	}
 
 	return appendErr
 }
 
+// logRecord writes the record to the WAL. A full disk doesn't fail the write, but triggers the flush of the chunks on
+// shutdown, as the WAL no longer records them.
+func (i *instance) logRecord(record *wal.Record) error {
+	if err := i.wal.Log(record); err != nil {
+		if e, ok := err.(*os.PathError); ok && e.Err == syscall.ENOSPC {
+			i.metrics.walDiskFullFailures.Inc()
+			i.flushOnShutdownSwitch.TriggerAnd(func() {
+				level.Error(util_log.Logger).Log(
+					"msg",
+					"Error writing to WAL, disk full, no further messages will be logged for this error",
+				)
+			})
+		} else {
+			return err
+		}
+	}
+	return nil
+}
+
 func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stream, record *wal.Record) (*stream, error) {
 	// record is only nil when replaying WAL. We don't want to drop data when replaying a WAL after
 	// reducing the stream limits, for instance.
@@ -398,6 +408,15 @@ func (i *instance) chunkFormatAt(at model.Time) (byte, chunkenc.HeadBlockFmt, er
 		return 0, 0, err
 	}
 
+	if chunkFormat >= chunkenc.ChunkFormatV4 {
+		switch {
+		case i.cfg.ChunkBlockHints:
+			chunkFormat = chunkenc.ChunkFormatV7
+		case i.cfg.ColumnarChunks:
+			chunkFormat = chunkenc.ChunkFormatV6
+		}
+	}
+
 	return chunkFormat, headblock, nil
 }
 
@@ -461,6 +480,11 @@ func (i *instance) query(ctx context.Context, req logql.SelectLogParams) (iter.E
 		return nil, err
 	}
 
+	pipeline, err = masking.SetupPipeline(req, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
 	if i.pipelineWrapper != nil && httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) != "true" {
 		userID, err := tenant.TenantID(ctx)
 		if err != nil {
@@ -521,6 +545,11 @@ func (i *instance) querySample(ctx context.Context, req logql.SelectSampleParams
 		return nil, err
 	}
 
+	extractor, err = masking.SetupExtractor(req, extractor)
+	if err != nil {
+		return nil, err
+	}
+
 	if i.extractorWrapper != nil && httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) != "true" {
 		userID, err := tenant.TenantID(ctx)
 		if err != nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/chunkenc"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
@@ -76,6 +77,49 @@ var defaultPeriodConfigs = []config.PeriodConfig{
 
 var NilMetrics = newIngesterMetrics(nil, constants.Loki)
 
+func TestChunkFormatAt_ColumnarChunks(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	cfg.ColumnarChunks = true
+	periodConfigs := []config.PeriodConfig{
+		{
+			From:      MustParseDayTime("1900-01-01"),
+			IndexType: types.StorageTypeBigTable,
+			Schema:    "v12",
+		},
+		{
+			From:      MustParseDayTime("2000-01-01"),
+			IndexType: types.StorageTypeBigTable,
+			Schema:    "v13",
+		},
+	}
+	i, err := newInstance(cfg, periodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil, nil)
+	require.NoError(t, err)
+
+	// schemas older than v13 do not support columnar chunks
+	chunkfmt, _, err := i.chunkFormatAt(model.TimeFromUnix(MustParseDayTime("1950-01-01").Unix()))
+	require.NoError(t, err)
+	require.Equal(t, chunkenc.ChunkFormatV3, chunkfmt)
+
+	chunkfmt, headfmt, err := i.chunkFormatAt(model.Now())
+	require.NoError(t, err)
+	require.Equal(t, chunkenc.ChunkFormatV6, chunkfmt)
+	require.Equal(t, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, headfmt)
+
+	// block hints are stored in columnar chunks
+	i.cfg.ChunkBlockHints = true
+	chunkfmt, _, err = i.chunkFormatAt(model.TimeFromUnix(MustParseDayTime("1950-01-01").Unix()))
+	require.NoError(t, err)
+	require.Equal(t, chunkenc.ChunkFormatV3, chunkfmt)
+
+	chunkfmt, _, err = i.chunkFormatAt(model.Now())
+	require.NoError(t, err)
+	require.Equal(t, chunkenc.ChunkFormatV7, chunkfmt)
+}
+
 func TestLabelsCollisions(t *testing.T) {
 	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
@@ -557,7 +601,7 @@ func Benchmark_instance_addNewTailer(b *testing.B) {
 	inst, _ := newInstance(&Config{}, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil, nil)
 	expr, err := syntax.ParseLogSelector(`{namespace="foo",pod="bar",instance=~"10.*"}`, true)
 	require.NoError(b, err)
-	t, err := newTailer("foo", expr, nil, 10)
+	t, err := newTailer("foo", expr, nil, nil, 10)
 	require.NoError(b, err)
 	for i := 0; i < 10000; i++ {
 		require.NoError(b, inst.Push(ctx, &logproto.PushRequest{
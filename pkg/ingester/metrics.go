@@ -23,6 +23,7 @@ type ingesterMetrics struct {
 	walReplaySamplesDropped *prometheus.CounterVec
 	walReplayBytesDropped   *prometheus.CounterVec
 	walCorruptionsTotal     *prometheus.CounterVec
+	walCorruptedBytesTotal  *prometheus.CounterVec
 	walLoggedBytesTotal     prometheus.Counter
 	walRecordsLogged        prometheus.Counter
 
@@ -65,6 +66,9 @@ type ingesterMetrics struct {
 	// Shutdown marker for ingester scale down
 	shutdownMarker prometheus.Gauge
 
+	transferredStreamsTotal *prometheus.CounterVec
+	transferredChunksTotal  *prometheus.CounterVec
+
 	flushQueueLength       prometheus.Gauge
 	duplicateLogBytesTotal *prometheus.CounterVec
 	streamsOwnershipCheck  prometheus.Histogram
@@ -112,6 +116,10 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Name: "loki_ingester_wal_corruptions_total",
 			Help: "Total number of WAL corruptions encountered.",
 		}, []string{"type"}),
+		walCorruptedBytesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "loki_ingester_wal_corrupted_bytes_skipped_total",
+			Help: "Total number of bytes of corrupted WAL records skipped during replay.",
+		}, []string{"type"}),
 		checkpointDeleteFail: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "loki_ingester_checkpoint_deletions_failed_total",
 			Help: "Total number of checkpoint deletions that failed.",
@@ -289,6 +297,19 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Help:      "1 if prepare shutdown has been called, 0 otherwise",
 		}),
 
+		transferredStreamsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "transferred_streams_total",
+			Help:      "Total number of in-memory streams transferred to or from other ingesters on shutdown.",
+		}, []string{"direction"}),
+		transferredChunksTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "transferred_chunks_total",
+			Help:      "Total number of in-memory chunks transferred to or from other ingesters on shutdown.",
+		}, []string{"direction"}),
+
 		flushQueueLength: promauto.With(r).NewGauge(prometheus.GaugeOpts{
 			Namespace: metricsNamespace,
 			Subsystem: "ingester",
@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/record"
-	"github.com/prometheus/prometheus/tsdb/wlog"
 	"golang.org/x/net/context"
 
 	"github.com/grafana/loki/v3/pkg/ingester/wal"
@@ -31,7 +30,7 @@ func (NoopWALReader) Err() error     { return nil }
 func (NoopWALReader) Record() []byte { return nil }
 func (NoopWALReader) Close() error   { return nil }
 
-func newCheckpointReader(dir string, logger log.Logger) (WALReader, io.Closer, error) {
+func newCheckpointReader(dir string, logger log.Logger, onCorruption func(err error, skippedBytes int64)) (WALReader, io.Closer, error) {
 	lastCheckpointDir, idx, err := lastCheckpoint(dir)
 	if err != nil {
 		return nil, nil, err
@@ -42,11 +41,11 @@ func newCheckpointReader(dir string, logger log.Logger) (WALReader, io.Closer, e
 		return reader, reader, nil
 	}
 
-	r, err := wlog.NewSegmentsReader(lastCheckpointDir)
+	r, err := newSegmentsReader(lastCheckpointDir, onCorruption)
 	if err != nil {
 		return nil, nil, err
 	}
-	return wlog.NewReader(r), r, nil
+	return r, r, nil
 }
 
 type Recoverer interface {
@@ -394,6 +393,9 @@ func recoverGeneric(
 				continue
 			}
 		}
+		if err := reader.Err(); err != nil {
+			errCh <- err
+		}
 
 		for _, w := range inputs {
 			close(w)
@@ -1,8 +1,11 @@
 package ingester
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math/rand"
+	"os"
 	"runtime"
 	"strings"
 	"sync"
@@ -11,10 +14,12 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/user"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/record"
+	"github.com/prometheus/prometheus/tsdb/wlog"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
@@ -303,3 +308,85 @@ func TestSeriesRecoveryNoDuplicates(t *testing.T) {
 	}
 	require.Equal(t, expected, result.resps[0].Streams)
 }
+
+func TestSegmentsReader_SkipsCorruptedRecords(t *testing.T) {
+	for _, compression := range []wlog.CompressionType{wlog.CompressionNone, wlog.CompressionSnappy, wlog.CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := wlog.NewSize(log.NewNopLogger(), nil, dir, walSegmentSize, compression)
+			require.NoError(t, err)
+
+			// random records of 1KB, which compression does not shrink, and records spanning several pages
+			rnd := rand.New(rand.NewSource(0))
+			var recs [][]byte
+			for i := 0; i < 200; i++ {
+				rec := make([]byte, 1024)
+				if i%20 == 19 {
+					rec = make([]byte, 2*walPageSize)
+				}
+				rnd.Read(rec)
+				recs = append(recs, rec)
+				require.NoError(t, w.Log(rec))
+				if i == 150 {
+					_, err := w.NextSegment()
+					require.NoError(t, err)
+				}
+			}
+			require.NoError(t, w.Close())
+
+			// corrupt a record of the second page of the first segment
+			segment := wlog.SegmentName(dir, 0)
+			b, err := os.ReadFile(segment)
+			require.NoError(t, err)
+			b[walPageSize+walPageSize/2] ^= 0xff
+			require.NoError(t, os.WriteFile(segment, b, 0o666))
+
+			var (
+				corruptions  int
+				skippedBytes int64
+			)
+			r, err := newSegmentsReader(dir, func(_ error, skipped int64) {
+				corruptions++
+				skippedBytes += skipped
+			})
+			require.NoError(t, err)
+			defer r.Close()
+
+			var read [][]byte
+			for r.Next() {
+				read = append(read, bytes.Clone(r.Record()))
+			}
+			require.NoError(t, r.Err())
+
+			// only the records of the corrupted page are lost
+			require.Equal(t, 1, corruptions)
+			require.LessOrEqual(t, skippedBytes, int64(4*walPageSize))
+			require.Less(t, len(read), len(recs))
+			require.GreaterOrEqual(t, len(read), len(recs)-4*walPageSize/1024)
+			require.Equal(t, recs[0], read[0])
+			require.Equal(t, recs[len(recs)-1], read[len(read)-1])
+			for _, rec := range read {
+				require.Contains(t, recs, rec)
+			}
+		})
+	}
+}
+
+func TestSegmentsReader_CloseStopsDecoder(t *testing.T) {
+	dir := t.TempDir()
+	w, err := wlog.NewSize(log.NewNopLogger(), nil, dir, walSegmentSize, wlog.CompressionZstd)
+	require.NoError(t, err)
+	require.NoError(t, w.Log([]byte("record")))
+	require.NoError(t, w.Close())
+
+	r, err := newSegmentsReader(dir, func(error, int64) {})
+	require.NoError(t, err)
+	require.True(t, r.Next())
+	require.Equal(t, []byte("record"), r.Record())
+
+	// the goroutines of the zstd decoder are stopped once the reader is closed
+	require.NoError(t, r.Close())
+	_, err = r.zstdReader.DecodeAll(nil, nil)
+	require.ErrorIs(t, err, zstd.ErrDecoderClosed)
+	require.NoError(t, r.Close())
+}
@@ -0,0 +1,254 @@
+package ingester
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+)
+
+// The layout of the WAL segments written by wlog: segments are made of pages, holding fragments of records. Each
+// fragment has a header with the type of the fragment, its compression and the length and checksum of its data.
+const (
+	walPageSize         = 32 * 1024
+	walFragmentHdrSize  = 7
+	walFragmentTypeMask = 0b111
+	walSnappyMask       = 1 << 3
+	walZstdMask         = 1 << 4
+)
+
+const (
+	walFragmentPageTerm = iota // rest of the page is empty
+	walFragmentFull            // full record
+	walFragmentFirst           // first fragment of a record
+	walFragmentMiddle          // middle fragments of a record
+	walFragmentLast            // last fragment of a record
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// segmentsReader reads the records of the segments of a WAL directory, written by wlog. Unlike wlog.Reader, which
+// stops at the first fragment failing its checksum, it skips the corrupted records up to the next record starting
+// in a valid page and reports the corruption, so that a corruption only loses the records of the pages it spans.
+type segmentsReader struct {
+	dir          string
+	next, last   int
+	onCorruption func(err error, skippedBytes int64)
+
+	segment    *os.File
+	pageOffset int64 // offset of the page in the segment
+	page       [walPageSize]byte
+	pageLen    int
+	pos        int // position of the next fragment in the page
+
+	recStart    int64 // offset of the first fragment of the record being read in the segment
+	reading     bool
+	compression byte
+	buf         []byte
+	rec         []byte
+	zstdReader  *zstd.Decoder
+
+	// the corruption being skipped
+	skipping  bool
+	skipErr   error
+	skipStart int64
+
+	err error
+}
+
+func newSegmentsReader(dir string, onCorruption func(err error, skippedBytes int64)) (*segmentsReader, error) {
+	first, last, err := wlog.Segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if first < 0 {
+		first, last = 0, -1
+	}
+	// Calling zstd.NewReader with a nil io.Reader and no options cannot return an error.
+	zstdReader, _ := zstd.NewReader(nil)
+	return &segmentsReader{
+		dir:          dir,
+		next:         first,
+		last:         last,
+		onCorruption: onCorruption,
+		zstdReader:   zstdReader,
+	}, nil
+}
+
+func (r *segmentsReader) Next() bool {
+	for r.err == nil {
+		if r.segment == nil {
+			if r.next > r.last {
+				return false
+			}
+			r.err = r.openSegment(r.next)
+			r.next++
+			continue
+		}
+
+		if r.pos >= r.pageLen {
+			if r.err = r.readPage(); r.err != nil {
+				if r.err == io.EOF {
+					r.err = r.closeSegment()
+				}
+				continue
+			}
+		}
+
+		if r.nextFragment() {
+			return true
+		}
+	}
+	return false
+}
+
+// nextFragment reads the next fragment of the page, and returns true if it completes a record.
+func (r *segmentsReader) nextFragment() bool {
+	hdr := r.page[r.pos:r.pageLen]
+	offset := r.pageOffset + int64(r.pos)
+
+	typ := hdr[0] & walFragmentTypeMask
+	if typ == walFragmentPageTerm {
+		r.pos = r.pageLen
+		return false
+	}
+	if len(hdr) < walFragmentHdrSize {
+		r.corrupted(errors.Errorf("truncated fragment header at %d", offset))
+		return false
+	}
+	length := int(binary.BigEndian.Uint16(hdr[1:]))
+	if walFragmentHdrSize+length > len(hdr) {
+		r.corrupted(errors.Errorf("invalid fragment size %d at %d", length, offset))
+		return false
+	}
+	data := hdr[walFragmentHdrSize : walFragmentHdrSize+length]
+	if crc := crc32.Checksum(data, castagnoliTable); crc != binary.BigEndian.Uint32(hdr[3:]) {
+		r.corrupted(errors.Errorf("unexpected checksum %x, expected %x at %d", crc, binary.BigEndian.Uint32(hdr[3:]), offset))
+		return false
+	}
+	r.pos += walFragmentHdrSize + length
+
+	switch typ {
+	case walFragmentFull, walFragmentFirst:
+		if r.reading {
+			r.skipFrom(errors.Errorf("record at %d is not terminated", r.recStart), r.recStart)
+		}
+		if r.skipping {
+			r.onCorruption(errors.Wrapf(r.skipErr, "segment %s", wlog.SegmentName(r.dir, r.next-1)), offset-r.skipStart)
+			r.skipping = false
+		}
+		r.reading, r.recStart, r.compression = true, offset, hdr[0]&(walSnappyMask|walZstdMask)
+		r.buf = append(r.buf[:0], data...)
+	case walFragmentMiddle, walFragmentLast:
+		if !r.reading {
+			// the remaining fragments of a record that is already skipped
+			if !r.skipping {
+				r.skipFrom(errors.Errorf("unexpected fragment at %d", offset), offset)
+			}
+			return false
+		}
+		r.buf = append(r.buf, data...)
+	default:
+		r.corrupted(errors.Errorf("unknown fragment type %d at %d", typ, offset))
+		return false
+	}
+
+	if typ == walFragmentFirst || typ == walFragmentMiddle {
+		return false
+	}
+	r.reading = false
+
+	var err error
+	switch r.compression {
+	case walSnappyMask:
+		r.rec, err = snappy.Decode(r.rec[:cap(r.rec)], r.buf)
+	case walZstdMask:
+		r.rec, err = r.zstdReader.DecodeAll(r.buf, r.rec[:0])
+	default:
+		r.rec = append(r.rec[:0], r.buf...)
+	}
+	if err != nil {
+		r.skipFrom(errors.Wrapf(err, "decompress record at %d", r.recStart), r.recStart)
+		return false
+	}
+	return true
+}
+
+// corrupted skips the rest of the page, and the record being read.
+func (r *segmentsReader) corrupted(err error) {
+	start := r.pageOffset + int64(r.pos)
+	if r.reading {
+		start = r.recStart
+		r.reading = false
+	}
+	r.skipFrom(err, start)
+	r.pos = r.pageLen
+}
+
+func (r *segmentsReader) skipFrom(err error, start int64) {
+	r.reading = false
+	if r.skipping {
+		return
+	}
+	r.skipping, r.skipErr, r.skipStart = true, err, start
+}
+
+func (r *segmentsReader) readPage() error {
+	r.pageOffset += int64(r.pageLen)
+	n, err := io.ReadFull(r.segment, r.page[:])
+	if err == io.ErrUnexpectedEOF {
+		// the last page of the segment is not complete
+		err = nil
+	}
+	r.pageLen, r.pos = n, 0
+	if n == 0 && err == nil {
+		err = io.EOF
+	}
+	return err
+}
+
+func (r *segmentsReader) openSegment(i int) error {
+	f, err := os.Open(wlog.SegmentName(r.dir, i))
+	if err != nil {
+		return err
+	}
+	r.segment, r.pageOffset, r.pageLen, r.pos = f, 0, 0, 0
+	return nil
+}
+
+func (r *segmentsReader) closeSegment() error {
+	end := r.pageOffset + int64(r.pageLen)
+	if r.reading {
+		r.skipFrom(errors.Errorf("last record at %d is torn", r.recStart), r.recStart)
+	}
+	if r.skipping {
+		r.onCorruption(errors.Wrapf(r.skipErr, "segment %s", wlog.SegmentName(r.dir, r.next-1)), end-r.skipStart)
+		r.skipping = false
+	}
+
+	err := r.segment.Close()
+	r.segment = nil
+	return err
+}
+
+func (r *segmentsReader) Err() error { return r.err }
+
+// Record should not be used across multiple calls to Next()
+func (r *segmentsReader) Record() []byte { return r.rec }
+
+func (r *segmentsReader) Close() error {
+	// closing the decoder stops its goroutines, it is a no-op once closed
+	r.zstdReader.Close()
+
+	if r.segment == nil {
+		return nil
+	}
+	err := r.segment.Close()
+	r.segment = nil
+	return err
+}
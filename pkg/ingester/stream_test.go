@@ -622,7 +622,7 @@ func Benchmark_PushStream(b *testing.B) {
 	s := newStream(chunkfmt, headfmt, &Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, NewStreamRateCalculator(), NilMetrics, nil, nil)
 	expr, err := syntax.ParseLogSelector(`{namespace="loki-dev"}`, true)
 	require.NoError(b, err)
-	t, err := newTailer("foo", expr, &fakeTailServer{}, 10)
+	t, err := newTailer("foo", expr, nil, &fakeTailServer{}, 10)
 	require.NoError(b, err)
 
 	go t.loop()
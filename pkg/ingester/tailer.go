@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/util"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 )
 
 const (
@@ -57,13 +58,17 @@ type tailer struct {
 	conn TailServer
 }
 
-func newTailer(orgID string, expr syntax.LogSelectorExpr, conn TailServer, maxDroppedStreams int) (*tailer, error) {
+func newTailer(orgID string, expr syntax.LogSelectorExpr, fieldMasks []*logproto.FieldMask, conn TailServer, maxDroppedStreams int) (*tailer, error) {
 	// Make sure we can build a pipeline. The stream processing code doesn't have a place to handle
 	// this error so make sure we handle it here.
 	pipeline, err := expr.Pipeline()
 	if err != nil {
 		return nil, err
 	}
+	pipeline, err = masking.WrapPipeline(fieldMasks, pipeline)
+	if err != nil {
+		return nil, err
+	}
 	matchers := expr.Matchers()
 
 	return &tailer{
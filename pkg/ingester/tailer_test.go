@@ -23,7 +23,7 @@ func TestTailer_RoundTrip(t *testing.T) {
 	lbs := makeRandomLabels()
 	expr, err := syntax.ParseLogSelector(lbs.String(), true)
 	require.NoError(t, err)
-	tail, err := newTailer("org-id", expr, server, 10)
+	tail, err := newTailer("org-id", expr, nil, server, 10)
 	require.NoError(t, err)
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -81,7 +81,7 @@ func TestTailer_sendRaceConditionOnSendWhileClosing(t *testing.T) {
 	for run := 0; run < runs; run++ {
 		expr, err := syntax.ParseLogSelector(stream.Labels, true)
 		require.NoError(t, err)
-		tailer, err := newTailer("org-id", expr, nil, 10)
+		tailer, err := newTailer("org-id", expr, nil, nil, 10)
 		require.NoError(t, err)
 		require.NotNil(t, tailer)
 
@@ -136,7 +136,7 @@ func Test_dropstream(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			expr, err := syntax.ParseLogSelector(`{app="foo"} |= "foo"`, true)
 			require.NoError(t, err)
-			tail, err := newTailer("foo", expr, &fakeTailServer{}, maxDroppedStreams)
+			tail, err := newTailer("foo", expr, nil, &fakeTailServer{}, maxDroppedStreams)
 			require.NoError(t, err)
 
 			for i := 0; i < c.drop; i++ {
@@ -204,7 +204,7 @@ func (f *fakeTailServer) Reset() {
 func Test_TailerSendRace(t *testing.T) {
 	expr, err := syntax.ParseLogSelector(`{app="foo"} |= "foo"`, true)
 	require.NoError(t, err)
-	tail, err := newTailer("foo", expr, &fakeTailServer{}, 10)
+	tail, err := newTailer("foo", expr, nil, &fakeTailServer{}, 10)
 	require.NoError(t, err)
 
 	var wg sync.WaitGroup
@@ -344,7 +344,7 @@ func Test_StructuredMetadata(t *testing.T) {
 			var server fakeTailServer
 			expr, err := syntax.ParseLogSelector(tc.query, true)
 			require.NoError(t, err)
-			tail, err := newTailer("foo", expr, &server, 10)
+			tail, err := newTailer("foo", expr, nil, &server, 10)
 			require.NoError(t, err)
 
 			var wg sync.WaitGroup
@@ -374,7 +374,7 @@ func Benchmark_isClosed(t *testing.B) {
 	var server fakeTailServer
 	expr, err := syntax.ParseLogSelector(`{app="foo"}`, true)
 	require.NoError(t, err)
-	tail, err := newTailer("foo", expr, &server, 0)
+	tail, err := newTailer("foo", expr, nil, &server, 0)
 	require.NoError(t, err)
 
 	require.Equal(t, false, tail.isClosed())
@@ -0,0 +1,467 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	tsdb_record "github.com/prometheus/prometheus/tsdb/record"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/util"
+	lokiring "github.com/grafana/loki/v3/pkg/util/ring"
+)
+
+const (
+	transferDirectionSent     = "sent"
+	transferDirectionReceived = "received"
+
+	// transferMessageSize is the size above which the chunks of a stream are split across several messages, to stay
+	// below the default 4MB limit of the messages received by the gRPC server.
+	transferMessageSize = 2 << 20
+)
+
+// transferStreamsClient is the client side of a transfer.
+type transferStreamsClient struct {
+	Transfer_TransferStreamsClient
+}
+
+func newTransferStreamsClient(ctx context.Context, conn *grpc.ClientConn) (*transferStreamsClient, error) {
+	stream, err := NewTransferClient(conn).TransferStreams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &transferStreamsClient{stream}, nil
+}
+
+func (c *transferStreamsClient) Send(m *Series) error {
+	err := c.Transfer_TransferStreamsClient.Send(m)
+	if err == io.EOF {
+		// the server ended the transfer, its error is returned by CloseAndRecv
+		_, err = c.CloseAndRecv()
+	}
+	return err
+}
+
+// TransferOut implements ring.FlushTransferer. When -ingester.transfer-on-shutdown is enabled and the ingester has to
+// flush its chunks on shutdown, it transfers its in-memory streams to the ingesters taking over their tokens instead.
+// The transferred streams are removed from the ingester, so that if the transfer fails the lifecycler only flushes the
+// streams which are not transferred.
+func (i *Ingester) TransferOut(ctx context.Context) error {
+	if !i.cfg.TransferOnShutdown || !i.lifecycler.FlushOnShutdown() {
+		return ring.ErrTransferDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.cfg.TransferTimeout)
+	defer cancel()
+
+	if err := i.waitLeaving(ctx); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	level.Info(i.logger).Log("msg", "transferring in-memory streams")
+
+	transfer := newStreamsTransfer(ctx, i)
+	defer transfer.close()
+
+	var errs util.MultiError
+	it := newStreamsIterator(i)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			// the remaining streams are flushed
+			errs.Add(err)
+			break
+		}
+		series := it.Stream()
+		if series.UserID != transfer.userID {
+			errs.Add(transfer.finishTenant())
+			transfer.startTenant(series.UserID)
+		}
+		transfer.send(it.currentStream, series)
+	}
+	errs.Add(transfer.finishTenant())
+	errs.Add(it.Error())
+
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	level.Info(i.logger).Log("msg", "transferred in-memory streams", "duration", time.Since(start))
+	return nil
+}
+
+// waitLeaving waits for the ring to show the ingester as leaving, so that its tokens are owned by the ingesters taking
+// over its streams.
+func (i *Ingester) waitLeaving(ctx context.Context) error {
+	b := backoff.New(ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	})
+	for b.Ongoing() {
+		if state, err := i.readRing.GetInstanceState(i.lifecycler.ID); err == nil && state == ring.LEAVING {
+			return nil
+		}
+		b.Wait()
+	}
+	return errors.Wrap(b.Err(), "waiting for the ring to show the ingester as leaving")
+}
+
+// transferTargets returns the addresses of the ingesters taking over the stream: the ingesters which replicate it as
+// the ingester is leaving, and would not without that.
+func (i *Ingester) transferTargets(userID string, s *stream) []string {
+	token := lokiring.TokenFor(userID, s.labelsString)
+	extended, err := i.readRing.Get(token, ring.Write, nil, nil, nil)
+	if err != nil {
+		return nil
+	}
+	// This fails when all the other replicas of the stream are unhealthy, in which case all the ingesters of the
+	// extended replication set take over the stream.
+	owners, _ := i.readRing.Get(token, ring.WriteNoExtend, nil, nil, nil)
+
+	var targets []string
+	for _, instance := range extended.Instances {
+		if instance.Addr == i.lifecycler.Addr || owners.Includes(instance.Addr) {
+			continue
+		}
+		targets = append(targets, instance.Addr)
+	}
+	return targets
+}
+
+// streamsTransfer transfers the streams of the tenants, one tenant after the other, to the ingesters taking over
+// their tokens.
+type streamsTransfer struct {
+	ing   *Ingester
+	ctx   context.Context
+	conns map[string]*grpc.ClientConn
+
+	// the transfer of the current tenant
+	userID    string
+	tenantCtx context.Context
+	cancel    context.CancelFunc
+	clients   map[string]*transferStreamsClient
+	failed    map[string]error
+	streams   []transferredStream
+}
+
+type transferredStream struct {
+	stream  *stream
+	chunks  int
+	targets []string
+}
+
+func newStreamsTransfer(ctx context.Context, ing *Ingester) *streamsTransfer {
+	return &streamsTransfer{
+		ing:   ing,
+		ctx:   ctx,
+		conns: map[string]*grpc.ClientConn{},
+	}
+}
+
+func (t *streamsTransfer) startTenant(userID string) {
+	t.userID = userID
+	t.clients = map[string]*transferStreamsClient{}
+	t.failed = map[string]error{}
+	t.streams = t.streams[:0]
+	t.tenantCtx, t.cancel = context.WithCancel(user.InjectOrgID(t.ctx, userID))
+}
+
+// send sends the stream to the ingesters taking over it. The series holds the chunks of the stream, and is only valid
+// until the next call.
+func (t *streamsTransfer) send(s *stream, series *Series) {
+	targets := t.ing.transferTargets(t.userID, s)
+	for _, addr := range targets {
+		if _, ok := t.failed[addr]; ok {
+			continue
+		}
+		client, err := t.client(addr)
+		if err == nil {
+			err = sendSeries(client, series)
+		}
+		if err != nil {
+			t.failed[addr] = err
+		}
+	}
+	t.streams = append(t.streams, transferredStream{stream: s, chunks: len(series.Chunks), targets: targets})
+}
+
+// sendSeries sends the chunks of the series, split across several messages if they are too large.
+func sendSeries(client *transferStreamsClient, series *Series) error {
+	msg := *series
+	for chunks := series.Chunks; len(chunks) > 0; {
+		n, size := 1, chunks[0].Size()
+		for ; n < len(chunks) && size+chunks[n].Size() <= transferMessageSize; n++ {
+			size += chunks[n].Size()
+		}
+		msg.Chunks, chunks = chunks[:n], chunks[n:]
+		if err := client.Send(&msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *streamsTransfer) client(addr string) (*transferStreamsClient, error) {
+	if client, ok := t.clients[addr]; ok {
+		return client, nil
+	}
+	conn, ok := t.conns[addr]
+	if !ok {
+		var err error
+		conn, err = t.ing.cfg.transferClientFactory(t.ing.clientConfig, addr)
+		if err != nil {
+			return nil, err
+		}
+		t.conns[addr] = conn
+	}
+	client, err := newTransferStreamsClient(t.tenantCtx, conn)
+	if err != nil {
+		return nil, err
+	}
+	t.clients[addr] = client
+	return client, nil
+}
+
+// finishTenant completes the transfer of the current tenant, and removes the streams which are transferred to all the
+// ingesters taking over them. It returns an error if some streams are not transferred.
+func (t *streamsTransfer) finishTenant() error {
+	if t.userID == "" {
+		return nil
+	}
+	defer t.cancel()
+
+	for addr, client := range t.clients {
+		if _, ok := t.failed[addr]; ok {
+			continue
+		}
+		if _, err := client.CloseAndRecv(); err != nil {
+			t.failed[addr] = err
+		}
+	}
+
+	var errs util.MultiError
+	for addr, err := range t.failed {
+		errs.Add(errors.Wrapf(err, "failed to transfer the streams of tenant %s to %s", t.userID, addr))
+	}
+
+	inst, ok := t.ing.getInstanceByID(t.userID)
+	untransferred := 0
+	for _, s := range t.streams {
+		if !ok || !t.transferred(s) {
+			untransferred++
+			continue
+		}
+		inst.removeTransferredStream(s.stream)
+		t.ing.metrics.transferredStreamsTotal.WithLabelValues(transferDirectionSent).Inc()
+		t.ing.metrics.transferredChunksTotal.WithLabelValues(transferDirectionSent).Add(float64(s.chunks))
+	}
+	if untransferred > 0 {
+		errs.Add(fmt.Errorf("%d streams of tenant %s were not transferred", untransferred, t.userID))
+	}
+	return errs.Err()
+}
+
+func (t *streamsTransfer) transferred(s transferredStream) bool {
+	if len(s.targets) == 0 {
+		return false
+	}
+	for _, addr := range s.targets {
+		if _, ok := t.failed[addr]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *streamsTransfer) close() {
+	for _, conn := range t.conns {
+		_ = conn.Close()
+	}
+}
+
+// removeTransferredStream removes a stream transferred to other ingesters, and its chunks.
+func (i *instance) removeTransferredStream(s *stream) {
+	i.streams.WithLock(func() {
+		s.chunkMtx.Lock()
+		defer s.chunkMtx.Unlock()
+
+		i.metrics.memoryChunks.Sub(float64(len(s.chunks)))
+		s.chunks = nil
+		i.removeStream(s)
+	})
+}
+
+// TransferStreams implements TransferServer. It adds the streams transferred by a leaving ingester to the instance of
+// the tenant.
+func (i *Ingester) TransferStreams(stream Transfer_TransferStreamsServer) error {
+	if state := i.lifecycler.GetState(); state != ring.ACTIVE {
+		return fmt.Errorf("ingester is not active: %s", state)
+	}
+
+	userID, err := tenant.TenantID(stream.Context())
+	if err != nil {
+		return err
+	}
+	inst, err := i.GetOrCreateInstance(userID)
+	if err != nil {
+		return err
+	}
+
+	// the chunks of a stream may be split across several consecutive messages
+	var series *Series
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if msg.UserID != userID {
+			return fmt.Errorf("unexpected stream of tenant %s in the transfer of tenant %s", msg.UserID, userID)
+		}
+
+		if series != nil && series.Fingerprint == msg.Fingerprint {
+			series.Chunks = append(series.Chunks, msg.Chunks...)
+			continue
+		}
+		if err := i.addTransferredSeries(inst, series); err != nil {
+			return err
+		}
+		series = msg
+	}
+	if err := i.addTransferredSeries(inst, series); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&logproto.PushResponse{})
+}
+
+func (i *Ingester) addTransferredSeries(inst *instance, series *Series) error {
+	if series == nil {
+		return nil
+	}
+	if err := inst.addTransferredStream(series); err != nil {
+		return errors.Wrapf(err, "failed to add transferred stream %s", logproto.FromLabelAdaptersToLabels(series.Labels))
+	}
+	i.metrics.memoryChunks.Add(float64(len(series.Chunks)))
+	i.metrics.transferredStreamsTotal.WithLabelValues(transferDirectionReceived).Inc()
+	i.metrics.transferredChunksTotal.WithLabelValues(transferDirectionReceived).Add(float64(len(series.Chunks)))
+	return nil
+}
+
+// addTransferredStream adds the chunks of a stream transferred by a leaving ingester. The stream limits are not
+// enforced, as the entries of the stream were already accepted.
+func (i *instance) addTransferredStream(series *Series) error {
+	ls := logproto.FromLabelAdaptersToLabels(series.Labels)
+	s, _, err := i.streams.LoadOrStoreNew(ls.String(),
+		func() (*stream, error) {
+			s, err := i.createStreamByFP(ls, i.getHashForLabels(ls))
+			// Lock before adding to maps
+			if err == nil {
+				s.chunkMtx.Lock()
+			}
+			return s, err
+		},
+		func(s *stream) error {
+			s.chunkMtx.Lock()
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	defer s.chunkMtx.Unlock()
+
+	// Decoding the chunks verifies they are not corrupted.
+	chunks, err := fromWireChunks(s.cfg, s.chunkHeadBlockFormat, series.Chunks)
+	if err != nil {
+		return err
+	}
+
+	// The entries are written to the WAL before the chunks are added, so that the transfer is only acknowledged once
+	// they are recorded.
+	entryCt, err := i.logTransferredChunks(s, chunks)
+	if err != nil {
+		return err
+	}
+	s.entryCt += entryCt
+
+	if len(s.chunks) == 0 {
+		s.chunks = chunks
+		s.lastLine.ts = series.To
+		s.lastLine.content = series.LastLine
+		s.highestTs = series.HighestTs
+		return nil
+	}
+
+	// The stream already received pushes since the ingester took it over: the transferred chunks are older, and closed
+	// so that the entries are only appended to the chunks of the stream.
+	for j := range chunks {
+		chunks[j].closed = true
+	}
+	s.chunks = append(chunks, s.chunks...)
+	if series.HighestTs.After(s.highestTs) {
+		s.highestTs = series.HighestTs
+	}
+	return nil
+}
+
+// logTransferredChunks writes the entries of the chunks transferred to a stream to the WAL, with a record per chunk,
+// and returns their number. The counters of the records follow the entry counter of the stream, so that replaying the
+// WAL doesn't drop the entries as duplicates of the entries pushed to the stream before the transfer.
+func (i *instance) logTransferredChunks(s *stream, descs []chunkDesc) (int64, error) {
+	var entryCt int64
+	for _, desc := range descs {
+		entries, err := chunkEntries(desc.chunk, s.labels)
+		if err != nil {
+			return 0, err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		entryCt += int64(len(entries))
+
+		record := recordPool.GetRecord()
+		record.UserID = i.instanceID
+		// replaying the series of a stream which already exists is a no-op
+		record.Series = append(record.Series, tsdb_record.RefSeries{
+			Ref:    chunks.HeadSeriesRef(s.fp),
+			Labels: s.labels,
+		})
+		record.AddEntries(uint64(s.fp), s.entryCt+entryCt, entries...)
+		err = i.logRecord(record)
+		recordPool.PutRecord(record)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return entryCt, nil
+}
+
+// chunkEntries returns all the entries of a chunk.
+func chunkEntries(c chunkenc.Chunk, ls labels.Labels) ([]logproto.Entry, error) {
+	it, err := c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, log.NewNoopPipeline().ForStream(ls))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var entries []logproto.Entry
+	for it.Next() {
+		entries = append(entries, it.At())
+	}
+	return entries, it.Err()
+}
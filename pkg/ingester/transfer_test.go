@@ -0,0 +1,239 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+// transferRingMock shows the ingester as leaving, and the target as the ingester taking over all the streams.
+type transferRingMock struct {
+	*readRingMock
+	leaving string
+	target  string
+}
+
+func (r *transferRingMock) GetInstanceState(instanceID string) (ring.InstanceState, error) {
+	if instanceID == r.leaving {
+		return ring.LEAVING, nil
+	}
+	return ring.ACTIVE, nil
+}
+
+func (r *transferRingMock) Get(_ uint32, op ring.Operation, _ []ring.InstanceDesc, _ []string, _ []string) (ring.ReplicationSet, error) {
+	if op == ring.WriteNoExtend {
+		return ring.ReplicationSet{}, errors.New("at least 1 healthy replica required, could only find 0")
+	}
+	return ring.ReplicationSet{Instances: []ring.InstanceDesc{{Addr: r.target, State: ring.ACTIVE}}}, nil
+}
+
+func TestTransferOut(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	newIngester := func(cfg Config, readRing ring.ReadRing) *Ingester {
+		i, err := New(cfg, client.Config{}, &mockStore{chunks: map[string][]chunk.Chunk{}}, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, log.NewNopLogger(), nil, readRing)
+		require.NoError(t, err)
+		return i
+	}
+	listeners := map[string]*bufconn.Listener{}
+	serve := func(addr string, i *Ingester) {
+		listener := bufconn.Listen(1024 * 1024)
+		server := grpc.NewServer(grpc.ChainStreamInterceptor(middleware.StreamServerUserHeaderInterceptor))
+		RegisterTransferServer(server, i)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		t.Cleanup(server.Stop)
+		listeners[addr] = listener
+	}
+
+	// the receiver is active, and already received pushes for a stream of the sender
+	receiverCfg := defaultIngesterTestConfigWithWAL(t, t.TempDir())
+	// the transferred streams must be replayed from the WAL segments, without a checkpoint
+	receiverCfg.WAL.CheckpointDuration = time.Hour
+	receiverCfg.LifecyclerConfig.ID = "receiver"
+	receiver := newIngester(receiverCfg, mockReadRingWithOneActiveIngester())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), receiver))
+	defer services.StopAndAwaitTerminated(context.Background(), receiver) //nolint:errcheck
+	require.Eventually(t, func() bool {
+		return receiver.lifecycler.GetState() == ring.ACTIVE
+	}, 5*time.Second, 10*time.Millisecond)
+	serve("receiver", receiver)
+
+	// the other ingester is not started, and rejects transfers
+	pendingCfg := defaultIngesterTestConfig(t)
+	pendingCfg.LifecyclerConfig.ID = "pending"
+	serve("pending", newIngester(pendingCfg, mockReadRingWithOneActiveIngester()))
+
+	senderCfg := defaultIngesterTestConfig(t)
+	senderCfg.TransferOnShutdown = true
+	senderCfg.transferClientFactory = func(_ client.Config, addr string) (*grpc.ClientConn, error) {
+		listener, ok := listeners[addr]
+		if !ok {
+			return nil, fmt.Errorf("unknown ingester %s", addr)
+		}
+		return grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStreamInterceptor(middleware.StreamClientUserHeaderInterceptor), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+	}
+	ringMock := &transferRingMock{readRingMock: mockReadRingWithOneActiveIngester(), leaving: "localhost", target: "pending"}
+	sender := newIngester(senderCfg, ringMock)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), sender))
+	defer services.StopAndAwaitTerminated(context.Background(), sender) //nolint:errcheck
+
+	start := time.Unix(0, 0)
+	push := func(i *Ingester, userID, labels string, from, to int) {
+		req := &logproto.PushRequest{Streams: []logproto.Stream{{Labels: labels}}}
+		for j := from; j < to; j++ {
+			req.Streams[0].Entries = append(req.Streams[0].Entries, logproto.Entry{
+				Timestamp: start.Add(time.Duration(j) * time.Second),
+				Line:      fmt.Sprintf("line %d", j),
+			})
+		}
+		_, err := i.Push(user.InjectOrgID(context.Background(), userID), req)
+		require.NoError(t, err)
+	}
+	push(sender, "tenant-1", `{app="foo"}`, 0, 100)
+	push(sender, "tenant-1", `{app="bar"}`, 0, 10)
+	push(sender, "tenant-2", `{app="foo"}`, 0, 10)
+	push(receiver, "tenant-1", `{app="foo"}`, 100, 110)
+
+	// transfers are disabled unless the ingester flushes on shutdown
+	sender.lifecycler.SetFlushOnShutdown(false)
+	require.Equal(t, ring.ErrTransferDisabled, sender.TransferOut(context.Background()))
+
+	// the streams which fail to be transferred are kept, to be flushed
+	sender.lifecycler.SetFlushOnShutdown(true)
+	require.ErrorContains(t, sender.TransferOut(context.Background()), "ingester is not active")
+	require.Equal(t, 3, newIngesterSeriesIter(sender).Count())
+
+	ringMock.target = "receiver"
+	require.NoError(t, sender.TransferOut(context.Background()))
+	require.Equal(t, 0, newIngesterSeriesIter(sender).Count())
+
+	query := func(i *Ingester, userID, selector string) []string {
+		inst, ok := i.getInstanceByID(userID)
+		require.True(t, ok)
+		it, err := inst.Query(context.Background(), logql.SelectLogParams{
+			QueryRequest: &logproto.QueryRequest{
+				Selector:  selector,
+				Limit:     1000,
+				Start:     start,
+				End:       start.Add(time.Hour),
+				Direction: logproto.FORWARD,
+				Plan: &plan.QueryPlan{
+					AST: syntax.MustParseExpr(selector),
+				},
+			},
+		})
+		require.NoError(t, err)
+		defer it.Close()
+
+		var lines []string
+		for it.Next() {
+			lines = append(lines, it.At().Line)
+		}
+		require.NoError(t, it.Err())
+		return lines
+	}
+	lines := query(receiver, "tenant-1", `{app="foo"}`)
+	require.Len(t, lines, 110)
+	for j, line := range lines {
+		require.Equal(t, fmt.Sprintf("line %d", j), line)
+	}
+	require.Len(t, query(receiver, "tenant-1", `{app="bar"}`), 10)
+	require.Len(t, query(receiver, "tenant-2", `{app="foo"}`), 10)
+
+	// the transferred streams are recorded in the WAL of the receiver, along with the entries pushed after the transfer
+	push(receiver, "tenant-1", `{app="foo"}`, 110, 120)
+	push(receiver, "tenant-1", `{app="baz"}`, 0, 10)
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), receiver))
+
+	restarted := newIngester(receiverCfg, mockReadRingWithOneActiveIngester())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), restarted))
+	defer services.StopAndAwaitTerminated(context.Background(), restarted) //nolint:errcheck
+
+	lines = query(restarted, "tenant-1", `{app="foo"}`)
+	require.Len(t, lines, 120)
+	for j, line := range lines {
+		require.Equal(t, fmt.Sprintf("line %d", j), line)
+	}
+	require.Len(t, query(restarted, "tenant-1", `{app="bar"}`), 10)
+	require.Len(t, query(restarted, "tenant-1", `{app="baz"}`), 10)
+	require.Len(t, query(restarted, "tenant-2", `{app="foo"}`), 10)
+}
+
+func TestSendSeries_SplitsLargeStreams(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	received := make(chan []*Series, 1)
+	server := grpc.NewServer()
+	RegisterTransferServer(server, transferServerFunc(func(stream Transfer_TransferStreamsServer) error {
+		var msgs []*Series
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				received <- msgs
+				return stream.SendAndClose(&logproto.PushResponse{})
+			}
+			msgs = append(msgs, msg)
+		}
+	}))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial("", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := newTransferStreamsClient(context.Background(), conn)
+	require.NoError(t, err)
+
+	series := &Series{UserID: "tenant", Fingerprint: 1}
+	for j := 0; j < 5; j++ {
+		series.Chunks = append(series.Chunks, Chunk{Data: make([]byte, transferMessageSize/2)})
+	}
+	require.NoError(t, sendSeries(stream, series))
+	_, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	msgs := <-received
+	require.Len(t, msgs, 5)
+	for _, msg := range msgs {
+		require.Equal(t, uint64(1), msg.Fingerprint)
+		require.Len(t, msg.Chunks, 1)
+	}
+}
+
+type transferServerFunc func(stream Transfer_TransferStreamsServer) error
+
+func (f transferServerFunc) TransferStreams(stream Transfer_TransferStreamsServer) error {
+	return f(stream)
+}
@@ -29,12 +29,18 @@ type WALConfig struct {
 	CheckpointDuration  time.Duration    `yaml:"checkpoint_duration"`
 	FlushOnShutdown     bool             `yaml:"flush_on_shutdown"`
 	ReplayMemoryCeiling flagext.ByteSize `yaml:"replay_memory_ceiling"`
+	Compression         string           `yaml:"compression"`
 }
 
 func (cfg *WALConfig) Validate() error {
 	if cfg.Enabled && cfg.CheckpointDuration < 1 {
 		return errors.Errorf("invalid checkpoint duration: %v", cfg.CheckpointDuration)
 	}
+	switch wlog.CompressionType(cfg.Compression) {
+	case "", wlog.CompressionNone, wlog.CompressionSnappy, wlog.CompressionZstd:
+	default:
+		return errors.Errorf("invalid WAL compression: %s, supported values are: none, snappy, zstd", cfg.Compression)
+	}
 	return nil
 }
 
@@ -48,6 +54,7 @@ func (cfg *WALConfig) RegisterFlags(f *flag.FlagSet) {
 	// Need to set default here
 	cfg.ReplayMemoryCeiling = flagext.ByteSize(defaultCeiling)
 	f.Var(&cfg.ReplayMemoryCeiling, "ingester.wal-replay-memory-ceiling", "Maximum memory size the WAL may use during replay. After hitting this, it will flush data to storage before continuing. A unit suffix (KB, MB, GB) may be applied.")
+	f.StringVar(&cfg.Compression, "ingester.wal-compression", string(wlog.CompressionNone), "Compression of the records of the WAL segments and checkpoints. Supported values are: none, snappy, zstd. The compression is marked in the header of every record, so that the WAL is replayed regardless of the compression it was written with.")
 }
 
 // WAL interface allows us to have a no-op WAL when the WAL is disabled.
@@ -81,7 +88,7 @@ func newWAL(cfg WALConfig, registerer prometheus.Registerer, metrics *ingesterMe
 		return noopWAL{}, nil
 	}
 
-	tsdbWAL, err := wlog.NewSize(util_log.Logger, registerer, cfg.Dir, walSegmentSize, wlog.CompressionNone)
+	tsdbWAL, err := wlog.NewSize(util_log.Logger, registerer, cfg.Dir, walSegmentSize, wlog.CompressionType(cfg.Compression))
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +315,7 @@ type QueryRequest struct {
 	// If populated, these represent the chunk references that the querier should
 	// use to fetch the data, plus any other chunks reported by ingesters.
 	StoreChunks *ChunkRefGroup `protobuf:"bytes,10,opt,name=storeChunks,proto3" json:"storeChunks"`
+	FieldMasks  []*FieldMask   `protobuf:"bytes,11,rep,name=fieldMasks,proto3" json:"fieldMasks,omitempty"`
 }
 
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
@@ -406,6 +407,13 @@ func (m *QueryRequest) GetStoreChunks() *ChunkRefGroup {
 	return nil
 }
 
+func (m *QueryRequest) GetFieldMasks() []*FieldMask {
+	if m != nil {
+		return m.FieldMasks
+	}
+	return nil
+}
+
 type SampleQueryRequest struct {
 	Selector string                                                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"` // Deprecated: Do not use.
 	Start    time.Time                                              `protobuf:"bytes,2,opt,name=start,proto3,stdtime" json:"start"`
@@ -416,6 +424,7 @@ type SampleQueryRequest struct {
 	// If populated, these represent the chunk references that the querier should
 	// use to fetch the data, plus any other chunks reported by ingesters.
 	StoreChunks *ChunkRefGroup `protobuf:"bytes,10,opt,name=storeChunks,proto3" json:"storeChunks"`
+	FieldMasks  []*FieldMask   `protobuf:"bytes,11,rep,name=fieldMasks,proto3" json:"fieldMasks,omitempty"`
 }
 
 func (m *SampleQueryRequest) Reset()      { *m = SampleQueryRequest{} }
@@ -493,6 +502,13 @@ func (m *SampleQueryRequest) GetStoreChunks() *ChunkRefGroup {
 	return nil
 }
 
+func (m *SampleQueryRequest) GetFieldMasks() []*FieldMask {
+	if m != nil {
+		return m.FieldMasks
+	}
+	return nil
+}
+
 // TODO(owen-d): fix. This will break rollouts as soon as the internal repr is changed.
 type Plan struct {
 	Raw []byte `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
@@ -596,6 +612,67 @@ func (m *Delete) GetEnd() int64 {
 	return 0
 }
 
+// FieldMask masks the structured metadata and parsed labels whose name is field
+// or matches fieldRegex, by applying action (hash, redact or drop) to them.
+type FieldMask struct {
+	Field      string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	FieldRegex string `protobuf:"bytes,2,opt,name=fieldRegex,proto3" json:"fieldRegex,omitempty"`
+	Action     string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+}
+
+func (m *FieldMask) Reset()      { *m = FieldMask{} }
+func (*FieldMask) ProtoMessage() {}
+func (*FieldMask) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{9}
+}
+func (m *FieldMask) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FieldMask) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FieldMask.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FieldMask) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldMask.Merge(m, src)
+}
+func (m *FieldMask) XXX_Size() int {
+	return m.Size()
+}
+func (m *FieldMask) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldMask.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldMask proto.InternalMessageInfo
+
+func (m *FieldMask) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldMask) GetFieldRegex() string {
+	if m != nil {
+		return m.FieldRegex
+	}
+	return ""
+}
+
+func (m *FieldMask) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
 type QueryResponse struct {
 	Streams  []github_com_grafana_loki_pkg_push.Stream `protobuf:"bytes,1,rep,name=streams,proto3,customtype=github.com/grafana/loki/pkg/push.Stream" json:"streams,omitempty"`
 	Stats    stats.Ingester                            `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats"`
@@ -605,7 +682,7 @@ type QueryResponse struct {
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{9}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{10}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -657,7 +734,7 @@ type SampleQueryResponse struct {
 func (m *SampleQueryResponse) Reset()      { *m = SampleQueryResponse{} }
 func (*SampleQueryResponse) ProtoMessage() {}
 func (*SampleQueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{10}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{11}
 }
 func (m *SampleQueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -711,7 +788,7 @@ type LabelRequest struct {
 func (m *LabelRequest) Reset()      { *m = LabelRequest{} }
 func (*LabelRequest) ProtoMessage() {}
 func (*LabelRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{11}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{12}
 }
 func (m *LabelRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -782,7 +859,7 @@ type LabelResponse struct {
 func (m *LabelResponse) Reset()      { *m = LabelResponse{} }
 func (*LabelResponse) ProtoMessage() {}
 func (*LabelResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{12}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{13}
 }
 func (m *LabelResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -827,7 +904,7 @@ type Sample struct {
 func (m *Sample) Reset()      { *m = Sample{} }
 func (*Sample) ProtoMessage() {}
 func (*Sample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{13}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{14}
 }
 func (m *Sample) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -886,7 +963,7 @@ type LegacySample struct {
 func (m *LegacySample) Reset()      { *m = LegacySample{} }
 func (*LegacySample) ProtoMessage() {}
 func (*LegacySample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{14}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{15}
 }
 func (m *LegacySample) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -938,7 +1015,7 @@ type Series struct {
 func (m *Series) Reset()      { *m = Series{} }
 func (*Series) ProtoMessage() {}
 func (*Series) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{15}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{16}
 }
 func (m *Series) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -989,17 +1066,18 @@ func (m *Series) GetStreamHash() uint64 {
 }
 
 type TailRequest struct {
-	Query    string                                                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"` // Deprecated: Do not use.
-	DelayFor uint32                                                 `protobuf:"varint,3,opt,name=delayFor,proto3" json:"delayFor,omitempty"`
-	Limit    uint32                                                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	Start    time.Time                                              `protobuf:"bytes,5,opt,name=start,proto3,stdtime" json:"start"`
-	Plan     *github_com_grafana_loki_v3_pkg_querier_plan.QueryPlan `protobuf:"bytes,6,opt,name=plan,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/plan.QueryPlan" json:"plan,omitempty"`
+	Query      string                                                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"` // Deprecated: Do not use.
+	DelayFor   uint32                                                 `protobuf:"varint,3,opt,name=delayFor,proto3" json:"delayFor,omitempty"`
+	Limit      uint32                                                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Start      time.Time                                              `protobuf:"bytes,5,opt,name=start,proto3,stdtime" json:"start"`
+	Plan       *github_com_grafana_loki_v3_pkg_querier_plan.QueryPlan `protobuf:"bytes,6,opt,name=plan,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/plan.QueryPlan" json:"plan,omitempty"`
+	FieldMasks []*FieldMask                                           `protobuf:"bytes,7,rep,name=fieldMasks,proto3" json:"fieldMasks,omitempty"`
 }
 
 func (m *TailRequest) Reset()      { *m = TailRequest{} }
 func (*TailRequest) ProtoMessage() {}
 func (*TailRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{16}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{17}
 }
 func (m *TailRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1057,6 +1135,13 @@ func (m *TailRequest) GetStart() time.Time {
 	return time.Time{}
 }
 
+func (m *TailRequest) GetFieldMasks() []*FieldMask {
+	if m != nil {
+		return m.FieldMasks
+	}
+	return nil
+}
+
 type TailResponse struct {
 	Stream         *github_com_grafana_loki_pkg_push.Stream `protobuf:"bytes,1,opt,name=stream,proto3,customtype=github.com/grafana/loki/pkg/push.Stream" json:"stream,omitempty"`
 	DroppedStreams []*DroppedStream                         `protobuf:"bytes,2,rep,name=droppedStreams,proto3" json:"droppedStreams,omitempty"`
@@ -1065,7 +1150,7 @@ type TailResponse struct {
 func (m *TailResponse) Reset()      { *m = TailResponse{} }
 func (*TailResponse) ProtoMessage() {}
 func (*TailResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{17}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{18}
 }
 func (m *TailResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1111,7 +1196,7 @@ type SeriesRequest struct {
 func (m *SeriesRequest) Reset()      { *m = SeriesRequest{} }
 func (*SeriesRequest) ProtoMessage() {}
 func (*SeriesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{18}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{19}
 }
 func (m *SeriesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1175,7 +1260,7 @@ type SeriesResponse struct {
 func (m *SeriesResponse) Reset()      { *m = SeriesResponse{} }
 func (*SeriesResponse) ProtoMessage() {}
 func (*SeriesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{19}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{20}
 }
 func (m *SeriesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1218,7 +1303,7 @@ type SeriesIdentifier struct {
 func (m *SeriesIdentifier) Reset()      { *m = SeriesIdentifier{} }
 func (*SeriesIdentifier) ProtoMessage() {}
 func (*SeriesIdentifier) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{20}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{21}
 }
 func (m *SeriesIdentifier) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1262,7 +1347,7 @@ type SeriesIdentifier_LabelsEntry struct {
 func (m *SeriesIdentifier_LabelsEntry) Reset()      { *m = SeriesIdentifier_LabelsEntry{} }
 func (*SeriesIdentifier_LabelsEntry) ProtoMessage() {}
 func (*SeriesIdentifier_LabelsEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{20, 0}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{21, 0}
 }
 func (m *SeriesIdentifier_LabelsEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1314,7 +1399,7 @@ type DroppedStream struct {
 func (m *DroppedStream) Reset()      { *m = DroppedStream{} }
 func (*DroppedStream) ProtoMessage() {}
 func (*DroppedStream) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{21}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{22}
 }
 func (m *DroppedStream) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1372,7 +1457,7 @@ type LabelPair struct {
 func (m *LabelPair) Reset()      { *m = LabelPair{} }
 func (*LabelPair) ProtoMessage() {}
 func (*LabelPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{22}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{23}
 }
 func (m *LabelPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1425,7 +1510,7 @@ type LegacyLabelPair struct {
 func (m *LegacyLabelPair) Reset()      { *m = LegacyLabelPair{} }
 func (*LegacyLabelPair) ProtoMessage() {}
 func (*LegacyLabelPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{23}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{24}
 }
 func (m *LegacyLabelPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1475,7 +1560,7 @@ type Chunk struct {
 func (m *Chunk) Reset()      { *m = Chunk{} }
 func (*Chunk) ProtoMessage() {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{24}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{25}
 }
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1517,7 +1602,7 @@ type TailersCountRequest struct {
 func (m *TailersCountRequest) Reset()      { *m = TailersCountRequest{} }
 func (*TailersCountRequest) ProtoMessage() {}
 func (*TailersCountRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{25}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{26}
 }
 func (m *TailersCountRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1553,7 +1638,7 @@ type TailersCountResponse struct {
 func (m *TailersCountResponse) Reset()      { *m = TailersCountResponse{} }
 func (*TailersCountResponse) ProtoMessage() {}
 func (*TailersCountResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{26}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{27}
 }
 func (m *TailersCountResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1598,7 +1683,7 @@ type GetChunkIDsRequest struct {
 func (m *GetChunkIDsRequest) Reset()      { *m = GetChunkIDsRequest{} }
 func (*GetChunkIDsRequest) ProtoMessage() {}
 func (*GetChunkIDsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{27}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{28}
 }
 func (m *GetChunkIDsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1655,7 +1740,7 @@ type GetChunkIDsResponse struct {
 func (m *GetChunkIDsResponse) Reset()      { *m = GetChunkIDsResponse{} }
 func (*GetChunkIDsResponse) ProtoMessage() {}
 func (*GetChunkIDsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{28}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{29}
 }
 func (m *GetChunkIDsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1708,7 +1793,7 @@ type ChunkRef struct {
 func (m *ChunkRef) Reset()      { *m = ChunkRef{} }
 func (*ChunkRef) ProtoMessage() {}
 func (*ChunkRef) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{29}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{30}
 }
 func (m *ChunkRef) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1765,7 +1850,7 @@ type ChunkRefGroup struct {
 func (m *ChunkRefGroup) Reset()      { *m = ChunkRefGroup{} }
 func (*ChunkRefGroup) ProtoMessage() {}
 func (*ChunkRefGroup) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{30}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{31}
 }
 func (m *ChunkRefGroup) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1812,7 +1897,7 @@ type LabelValuesForMetricNameRequest struct {
 func (m *LabelValuesForMetricNameRequest) Reset()      { *m = LabelValuesForMetricNameRequest{} }
 func (*LabelValuesForMetricNameRequest) ProtoMessage() {}
 func (*LabelValuesForMetricNameRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{31}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{32}
 }
 func (m *LabelValuesForMetricNameRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1872,7 +1957,7 @@ type LabelNamesForMetricNameRequest struct {
 func (m *LabelNamesForMetricNameRequest) Reset()      { *m = LabelNamesForMetricNameRequest{} }
 func (*LabelNamesForMetricNameRequest) ProtoMessage() {}
 func (*LabelNamesForMetricNameRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{32}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{33}
 }
 func (m *LabelNamesForMetricNameRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1923,7 +2008,7 @@ type LineFilter struct {
 func (m *LineFilter) Reset()      { *m = LineFilter{} }
 func (*LineFilter) ProtoMessage() {}
 func (*LineFilter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{33}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{34}
 }
 func (m *LineFilter) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1971,7 +2056,7 @@ type GetChunkRefRequest struct {
 func (m *GetChunkRefRequest) Reset()      { *m = GetChunkRefRequest{} }
 func (*GetChunkRefRequest) ProtoMessage() {}
 func (*GetChunkRefRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{34}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{35}
 }
 func (m *GetChunkRefRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2014,7 +2099,7 @@ type GetChunkRefResponse struct {
 func (m *GetChunkRefResponse) Reset()      { *m = GetChunkRefResponse{} }
 func (*GetChunkRefResponse) ProtoMessage() {}
 func (*GetChunkRefResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{35}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{36}
 }
 func (m *GetChunkRefResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2059,7 +2144,7 @@ type GetSeriesRequest struct {
 func (m *GetSeriesRequest) Reset()      { *m = GetSeriesRequest{} }
 func (*GetSeriesRequest) ProtoMessage() {}
 func (*GetSeriesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{36}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{37}
 }
 func (m *GetSeriesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2102,7 +2187,7 @@ type GetSeriesResponse struct {
 func (m *GetSeriesResponse) Reset()      { *m = GetSeriesResponse{} }
 func (*GetSeriesResponse) ProtoMessage() {}
 func (*GetSeriesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{37}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{38}
 }
 func (m *GetSeriesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2146,7 +2231,7 @@ type IndexSeries struct {
 func (m *IndexSeries) Reset()      { *m = IndexSeries{} }
 func (*IndexSeries) ProtoMessage() {}
 func (*IndexSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{38}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{39}
 }
 func (m *IndexSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2183,7 +2268,7 @@ type QueryIndexResponse struct {
 func (m *QueryIndexResponse) Reset()      { *m = QueryIndexResponse{} }
 func (*QueryIndexResponse) ProtoMessage() {}
 func (*QueryIndexResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{39}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{40}
 }
 func (m *QueryIndexResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2234,7 +2319,7 @@ type Row struct {
 func (m *Row) Reset()      { *m = Row{} }
 func (*Row) ProtoMessage() {}
 func (*Row) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{40}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{41}
 }
 func (m *Row) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2284,7 +2369,7 @@ type QueryIndexRequest struct {
 func (m *QueryIndexRequest) Reset()      { *m = QueryIndexRequest{} }
 func (*QueryIndexRequest) ProtoMessage() {}
 func (*QueryIndexRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{41}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{42}
 }
 func (m *QueryIndexRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2331,7 +2416,7 @@ type IndexQuery struct {
 func (m *IndexQuery) Reset()      { *m = IndexQuery{} }
 func (*IndexQuery) ProtoMessage() {}
 func (*IndexQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{42}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{43}
 }
 func (m *IndexQuery) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2404,7 +2489,7 @@ type IndexStatsRequest struct {
 func (m *IndexStatsRequest) Reset()      { *m = IndexStatsRequest{} }
 func (*IndexStatsRequest) ProtoMessage() {}
 func (*IndexStatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{43}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{44}
 }
 func (m *IndexStatsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2450,7 +2535,7 @@ type IndexStatsResponse struct {
 func (m *IndexStatsResponse) Reset()      { *m = IndexStatsResponse{} }
 func (*IndexStatsResponse) ProtoMessage() {}
 func (*IndexStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{44}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{45}
 }
 func (m *IndexStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2520,7 +2605,7 @@ type VolumeRequest struct {
 func (m *VolumeRequest) Reset()      { *m = VolumeRequest{} }
 func (*VolumeRequest) ProtoMessage() {}
 func (*VolumeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{45}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{46}
 }
 func (m *VolumeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2592,7 +2677,7 @@ type VolumeResponse struct {
 func (m *VolumeResponse) Reset()      { *m = VolumeResponse{} }
 func (*VolumeResponse) ProtoMessage() {}
 func (*VolumeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{46}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{47}
 }
 func (m *VolumeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2643,7 +2728,7 @@ type Volume struct {
 func (m *Volume) Reset()      { *m = Volume{} }
 func (*Volume) ProtoMessage() {}
 func (*Volume) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{47}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{48}
 }
 func (m *Volume) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2698,7 +2783,7 @@ type DetectedFieldsRequest struct {
 func (m *DetectedFieldsRequest) Reset()      { *m = DetectedFieldsRequest{} }
 func (*DetectedFieldsRequest) ProtoMessage() {}
 func (*DetectedFieldsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{48}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{49}
 }
 func (m *DetectedFieldsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2777,7 +2862,7 @@ type DetectedFieldsResponse struct {
 func (m *DetectedFieldsResponse) Reset()      { *m = DetectedFieldsResponse{} }
 func (*DetectedFieldsResponse) ProtoMessage() {}
 func (*DetectedFieldsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{49}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{50}
 }
 func (m *DetectedFieldsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2833,7 +2918,7 @@ type DetectedField struct {
 func (m *DetectedField) Reset()      { *m = DetectedField{} }
 func (*DetectedField) ProtoMessage() {}
 func (*DetectedField) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{50}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{51}
 }
 func (m *DetectedField) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2906,7 +2991,7 @@ type DetectedLabelsRequest struct {
 func (m *DetectedLabelsRequest) Reset()      { *m = DetectedLabelsRequest{} }
 func (*DetectedLabelsRequest) ProtoMessage() {}
 func (*DetectedLabelsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{51}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{52}
 }
 func (m *DetectedLabelsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2963,7 +3048,7 @@ type DetectedLabelsResponse struct {
 func (m *DetectedLabelsResponse) Reset()      { *m = DetectedLabelsResponse{} }
 func (*DetectedLabelsResponse) ProtoMessage() {}
 func (*DetectedLabelsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{52}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{53}
 }
 func (m *DetectedLabelsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3008,7 +3093,7 @@ type DetectedLabel struct {
 func (m *DetectedLabel) Reset()      { *m = DetectedLabel{} }
 func (*DetectedLabel) ProtoMessage() {}
 func (*DetectedLabel) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{53}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{54}
 }
 func (m *DetectedLabel) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3070,6 +3155,7 @@ func init() {
 	proto.RegisterType((*SampleQueryRequest)(nil), "logproto.SampleQueryRequest")
 	proto.RegisterType((*Plan)(nil), "logproto.Plan")
 	proto.RegisterType((*Delete)(nil), "logproto.Delete")
+	proto.RegisterType((*FieldMask)(nil), "logproto.FieldMask")
 	proto.RegisterType((*QueryResponse)(nil), "logproto.QueryResponse")
 	proto.RegisterType((*SampleQueryResponse)(nil), "logproto.SampleQueryResponse")
 	proto.RegisterType((*LabelRequest)(nil), "logproto.LabelRequest")
@@ -3121,175 +3207,178 @@ func init() {
 func init() { proto.RegisterFile("pkg/logproto/logproto.proto", fileDescriptor_c28a5f14f1f4c79a) }
 
 var fileDescriptor_c28a5f14f1f4c79a = []byte{
-	// 2673 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x1a, 0x4d, 0x8c, 0x5b, 0x47,
-	0xd9, 0xcf, 0x7e, 0xfe, 0xfb, 0xec, 0xdd, 0x6c, 0x66, 0x9d, 0xc4, 0xda, 0xa4, 0x7e, 0xdb, 0x11,
-	0xb4, 0xa1, 0x49, 0xd7, 0x4d, 0x4a, 0x4b, 0x9a, 0x52, 0x4a, 0xbc, 0xdb, 0xa4, 0x49, 0xb7, 0x69,
-	0x3a, 0x9b, 0xa6, 0x05, 0x51, 0x55, 0x2f, 0xf6, 0xac, 0xf7, 0x29, 0xf6, 0x7b, 0xce, 0x7b, 0xe3,
-	0xa6, 0x7b, 0x43, 0xe2, 0x8c, 0xa8, 0xc4, 0x01, 0xb8, 0x20, 0x21, 0x21, 0x81, 0x40, 0xbd, 0x20,
-	0x4e, 0x08, 0xc1, 0x85, 0x43, 0xb9, 0xf5, 0x58, 0xf5, 0x60, 0xe8, 0xf6, 0x82, 0x56, 0x42, 0xaa,
-	0x84, 0xc4, 0xa1, 0x27, 0x34, 0x7f, 0xef, 0xcd, 0x7b, 0x6b, 0x93, 0x3a, 0x04, 0xb5, 0xb9, 0xd8,
-	0xf3, 0x7d, 0xf3, 0xcd, 0x37, 0xf3, 0xfd, 0xcc, 0xf7, 0x7d, 0xf3, 0xd9, 0x70, 0x7c, 0x74, 0xab,
-	0xdf, 0x1e, 0x04, 0xfd, 0x51, 0x18, 0xb0, 0x20, 0x1e, 0xac, 0x89, 0x4f, 0x54, 0xd1, 0xf0, 0x4a,
-	0xa3, 0x1f, 0xf4, 0x03, 0x49, 0xc3, 0x47, 0x72, 0x7e, 0xc5, 0xe9, 0x07, 0x41, 0x7f, 0x40, 0xdb,
-	0x02, 0xba, 0x39, 0xde, 0x6e, 0x33, 0x6f, 0x48, 0x23, 0xe6, 0x0e, 0x47, 0x8a, 0x60, 0x55, 0x71,
-	0xbf, 0x3d, 0x18, 0x06, 0x3d, 0x3a, 0x68, 0x47, 0xcc, 0x65, 0x91, 0xfc, 0x54, 0x14, 0xcb, 0x9c,
-	0x62, 0x34, 0x8e, 0x76, 0xc4, 0x87, 0x44, 0xe2, 0xdf, 0x5b, 0x70, 0x64, 0xd3, 0xbd, 0x49, 0x07,
-	0xd7, 0x83, 0x1b, 0xee, 0x60, 0x4c, 0x23, 0x42, 0xa3, 0x51, 0xe0, 0x47, 0x14, 0xad, 0x43, 0x69,
-	0xc0, 0x27, 0xa2, 0xa6, 0xb5, 0x5a, 0x38, 0x59, 0x3b, 0x7b, 0x6a, 0x2d, 0x3e, 0xf2, 0xd4, 0x05,
-	0x12, 0x1b, 0xbd, 0xe0, 0xb3, 0x70, 0x97, 0xa8, 0xa5, 0x2b, 0x37, 0xa0, 0x66, 0xa0, 0xd1, 0x12,
-	0x14, 0x6e, 0xd1, 0xdd, 0xa6, 0xb5, 0x6a, 0x9d, 0xac, 0x12, 0x3e, 0x44, 0x67, 0xa0, 0xf8, 0x36,
-	0x67, 0xd3, 0xcc, 0xaf, 0x5a, 0x27, 0x6b, 0x67, 0x8f, 0x27, 0x9b, 0xbc, 0xe6, 0x7b, 0xb7, 0xc7,
-	0x54, 0xac, 0x56, 0x1b, 0x49, 0xca, 0xf3, 0xf9, 0x73, 0x16, 0x3e, 0x05, 0x87, 0x0f, 0xcc, 0xa3,
-	0xa3, 0x50, 0x12, 0x14, 0xf2, 0xc4, 0x55, 0xa2, 0x20, 0xdc, 0x00, 0xb4, 0xc5, 0x42, 0xea, 0x0e,
-	0x89, 0xcb, 0xf8, 0x79, 0x6f, 0x8f, 0x69, 0xc4, 0xf0, 0xcb, 0xb0, 0x9c, 0xc2, 0x2a, 0xb1, 0x9f,
-	0x86, 0x5a, 0x94, 0xa0, 0x95, 0xec, 0x8d, 0xe4, 0x58, 0xc9, 0x1a, 0x62, 0x12, 0xe2, 0x9f, 0x5b,
-	0x00, 0xc9, 0x1c, 0x6a, 0x01, 0xc8, 0xd9, 0x17, 0xdd, 0x68, 0x47, 0x08, 0x6c, 0x13, 0x03, 0x83,
-	0x4e, 0xc3, 0xe1, 0x04, 0xba, 0x1a, 0x6c, 0xed, 0xb8, 0x61, 0x4f, 0xe8, 0xc0, 0x26, 0x07, 0x27,
-	0x10, 0x02, 0x3b, 0x74, 0x19, 0x6d, 0x16, 0x56, 0xad, 0x93, 0x05, 0x22, 0xc6, 0x5c, 0x5a, 0x46,
-	0x7d, 0xd7, 0x67, 0x4d, 0x5b, 0xa8, 0x53, 0x41, 0x1c, 0xcf, 0xed, 0x4b, 0xa3, 0x66, 0x71, 0xd5,
-	0x3a, 0xb9, 0x40, 0x14, 0x84, 0xff, 0x5d, 0x80, 0xfa, 0xab, 0x63, 0x1a, 0xee, 0x2a, 0x05, 0xa0,
-	0x16, 0x54, 0x22, 0x3a, 0xa0, 0x5d, 0x16, 0x84, 0xd2, 0x22, 0x9d, 0x7c, 0xd3, 0x22, 0x31, 0x0e,
-	0x35, 0xa0, 0x38, 0xf0, 0x86, 0x1e, 0x13, 0xc7, 0x5a, 0x20, 0x12, 0x40, 0xe7, 0xa1, 0x18, 0x31,
-	0x37, 0x64, 0xe2, 0x2c, 0xb5, 0xb3, 0x2b, 0x6b, 0xd2, 0x31, 0xd7, 0xb4, 0x63, 0xae, 0x5d, 0xd7,
-	0x8e, 0xd9, 0xa9, 0xbc, 0x3f, 0x71, 0x72, 0xef, 0xfe, 0xcd, 0xb1, 0x88, 0x5c, 0x82, 0x9e, 0x86,
-	0x02, 0xf5, 0x7b, 0xe2, 0xbc, 0x9f, 0x77, 0x25, 0x5f, 0x80, 0xce, 0x40, 0xb5, 0xe7, 0x85, 0xb4,
-	0xcb, 0xbc, 0xc0, 0x17, 0x52, 0x2d, 0x9e, 0x5d, 0x4e, 0x2c, 0xb2, 0xa1, 0xa7, 0x48, 0x42, 0x85,
-	0x4e, 0x43, 0x29, 0xe2, 0xaa, 0x8b, 0x9a, 0x65, 0xee, 0x0b, 0x9d, 0xc6, 0xfe, 0xc4, 0x59, 0x92,
-	0x98, 0xd3, 0xc1, 0xd0, 0x63, 0x74, 0x38, 0x62, 0xbb, 0x44, 0xd1, 0xa0, 0xc7, 0xa0, 0xdc, 0xa3,
-	0x03, 0xca, 0x0d, 0x5e, 0x11, 0x06, 0x5f, 0x32, 0xd8, 0x8b, 0x09, 0xa2, 0x09, 0xd0, 0x9b, 0x60,
-	0x8f, 0x06, 0xae, 0xdf, 0xac, 0x0a, 0x29, 0x16, 0x13, 0xc2, 0x6b, 0x03, 0xd7, 0xef, 0x3c, 0xf3,
-	0xd1, 0xc4, 0x79, 0xaa, 0xef, 0xb1, 0x9d, 0xf1, 0xcd, 0xb5, 0x6e, 0x30, 0x6c, 0xf7, 0x43, 0x77,
-	0xdb, 0xf5, 0xdd, 0xf6, 0x20, 0xb8, 0xe5, 0xb5, 0xdf, 0x7e, 0xb2, 0xcd, 0xef, 0xe0, 0xed, 0x31,
-	0x0d, 0x3d, 0x1a, 0xb6, 0x39, 0x9b, 0x35, 0x61, 0x12, 0xbe, 0x94, 0x08, 0xb6, 0xe8, 0x0a, 0xf7,
-	0xbf, 0x20, 0xa4, 0xeb, 0x3b, 0x63, 0xff, 0x56, 0xd4, 0x04, 0xb1, 0xcb, 0xb1, 0x64, 0x17, 0x81,
-	0x27, 0x74, 0xfb, 0x52, 0x18, 0x8c, 0x47, 0x9d, 0x43, 0xfb, 0x13, 0xc7, 0xa4, 0x27, 0x26, 0x70,
-	0xc5, 0xae, 0x94, 0x96, 0xca, 0xf8, 0xbd, 0x02, 0xa0, 0x2d, 0x77, 0x38, 0x1a, 0xd0, 0xb9, 0xcc,
-	0x1f, 0x1b, 0x3a, 0x7f, 0xcf, 0x86, 0x2e, 0xcc, 0x6b, 0xe8, 0xc4, 0x6a, 0xf6, 0x7c, 0x56, 0x2b,
-	0x7e, 0x5e, 0xab, 0x95, 0xbe, 0xf4, 0x56, 0xc3, 0x4d, 0xb0, 0x39, 0x67, 0x1e, 0x2c, 0x43, 0xf7,
-	0x8e, 0xb0, 0x4d, 0x9d, 0xf0, 0x21, 0xde, 0x84, 0x92, 0x94, 0x0b, 0xad, 0x64, 0x8d, 0x97, 0xbe,
-	0xb7, 0x89, 0xe1, 0x0a, 0xda, 0x24, 0x4b, 0x89, 0x49, 0x0a, 0x42, 0xd9, 0xf8, 0x8f, 0x16, 0x2c,
-	0x28, 0x8f, 0x50, 0xb1, 0xef, 0x26, 0x94, 0x65, 0xec, 0xd1, 0x71, 0xef, 0x58, 0x36, 0xee, 0x5d,
-	0xe8, 0xb9, 0x23, 0x46, 0xc3, 0x4e, 0xfb, 0xfd, 0x89, 0x63, 0x7d, 0x34, 0x71, 0x1e, 0x9d, 0xa5,
-	0x34, 0x9d, 0x6b, 0x74, 0xbc, 0xd4, 0x8c, 0xd1, 0x29, 0x71, 0x3a, 0x16, 0x29, 0xb7, 0x3a, 0xb4,
-	0x26, 0x53, 0xd4, 0x65, 0xbf, 0x4f, 0x23, 0xce, 0xd9, 0xe6, 0x1e, 0x41, 0x24, 0x0d, 0x17, 0xf3,
-	0x8e, 0x1b, 0xfa, 0x9e, 0xdf, 0x8f, 0x9a, 0x05, 0x11, 0xd3, 0x63, 0x18, 0xff, 0xd4, 0x82, 0xe5,
-	0x94, 0x5b, 0x2b, 0x21, 0xce, 0x41, 0x29, 0xe2, 0x96, 0xd2, 0x32, 0x18, 0x4e, 0xb1, 0x25, 0xf0,
-	0x9d, 0x45, 0x75, 0xf8, 0x92, 0x84, 0x89, 0xa2, 0xbf, 0x7f, 0x47, 0xfb, 0x8b, 0x05, 0x75, 0x91,
-	0x98, 0xf4, 0x5d, 0x43, 0x60, 0xfb, 0xee, 0x90, 0x2a, 0x53, 0x89, 0xb1, 0x91, 0xad, 0xf8, 0x76,
-	0x15, 0x9d, 0xad, 0xe6, 0x0d, 0xb0, 0xd6, 0x3d, 0x07, 0x58, 0x2b, 0xb9, 0x77, 0x0d, 0x28, 0x72,
-	0xf7, 0xde, 0x15, 0xc1, 0xb5, 0x4a, 0x24, 0x80, 0x1f, 0x85, 0x05, 0x25, 0x85, 0x52, 0xed, 0xac,
-	0x04, 0x3b, 0x84, 0x92, 0xb4, 0x04, 0xfa, 0x0a, 0x54, 0xe3, 0xc2, 0x44, 0x48, 0x5b, 0xe8, 0x94,
-	0xf6, 0x27, 0x4e, 0x9e, 0x45, 0x24, 0x99, 0x40, 0x8e, 0x99, 0xf4, 0xad, 0x4e, 0x75, 0x7f, 0xe2,
-	0x48, 0x84, 0x4a, 0xf1, 0xe8, 0x04, 0xd8, 0x3b, 0x3c, 0x6f, 0x72, 0x15, 0xd8, 0x9d, 0xca, 0xfe,
-	0xc4, 0x11, 0x30, 0x11, 0x9f, 0xf8, 0x12, 0xd4, 0x37, 0x69, 0xdf, 0xed, 0xee, 0xaa, 0x4d, 0x1b,
-	0x9a, 0x1d, 0xdf, 0xd0, 0xd2, 0x3c, 0x1e, 0x86, 0x7a, 0xbc, 0xe3, 0x5b, 0xc3, 0x48, 0xdd, 0x86,
-	0x5a, 0x8c, 0x7b, 0x39, 0xc2, 0x3f, 0xb3, 0x40, 0xf9, 0x00, 0xc2, 0x46, 0xb5, 0xc3, 0x63, 0x21,
-	0xec, 0x4f, 0x1c, 0x85, 0xd1, 0xc5, 0x0c, 0x7a, 0x16, 0xca, 0x91, 0xd8, 0x91, 0x33, 0xcb, 0xba,
-	0x96, 0x98, 0xe8, 0x1c, 0xe2, 0x2e, 0xb2, 0x3f, 0x71, 0x34, 0x21, 0xd1, 0x03, 0xb4, 0x96, 0x2a,
-	0x08, 0xa4, 0x60, 0x8b, 0xfb, 0x13, 0xc7, 0xc0, 0x9a, 0x05, 0x02, 0xfe, 0xcc, 0x82, 0xda, 0x75,
-	0xd7, 0x8b, 0x5d, 0xa8, 0xa9, 0x4d, 0x94, 0xc4, 0x6a, 0x89, 0xe0, 0x9e, 0xd8, 0xa3, 0x03, 0x77,
-	0xf7, 0x62, 0x10, 0x0a, 0xbe, 0x0b, 0x24, 0x86, 0x93, 0x1c, 0x6e, 0x4f, 0xcd, 0xe1, 0xc5, 0xf9,
-	0x43, 0xfb, 0xff, 0x37, 0x90, 0x5e, 0xb1, 0x2b, 0xf9, 0xa5, 0x02, 0x7e, 0xcf, 0x82, 0xba, 0x14,
-	0x5e, 0x79, 0xde, 0xf7, 0xa0, 0x24, 0x75, 0x23, 0xc4, 0xff, 0x2f, 0x81, 0xe9, 0xd4, 0x3c, 0x41,
-	0x49, 0xf1, 0x44, 0xcf, 0xc3, 0x62, 0x2f, 0x0c, 0x46, 0x23, 0xda, 0xdb, 0x52, 0xe1, 0x2f, 0x9f,
-	0x0d, 0x7f, 0x1b, 0xe6, 0x3c, 0xc9, 0x90, 0xe3, 0xbf, 0x5a, 0xb0, 0xa0, 0x82, 0x89, 0x32, 0x57,
-	0xac, 0x62, 0xeb, 0x9e, 0xb3, 0x67, 0x7e, 0xde, 0xec, 0x79, 0x14, 0x4a, 0x7d, 0x9e, 0x5f, 0x74,
-	0x40, 0x52, 0xd0, 0x7c, 0x59, 0x15, 0x5f, 0x81, 0x45, 0x2d, 0xca, 0x8c, 0x88, 0xba, 0x92, 0x8d,
-	0xa8, 0x97, 0x7b, 0xd4, 0x67, 0xde, 0xb6, 0x17, 0xc7, 0x48, 0x45, 0x8f, 0x7f, 0x64, 0xc1, 0x52,
-	0x96, 0x04, 0x6d, 0x64, 0x1e, 0x16, 0x8f, 0xcc, 0x66, 0x67, 0xbe, 0x29, 0x34, 0x6b, 0xf5, 0xb2,
-	0x78, 0xea, 0x6e, 0x2f, 0x8b, 0x86, 0x19, 0x64, 0xaa, 0x2a, 0x2a, 0xe0, 0x9f, 0x58, 0xb0, 0x90,
-	0xb2, 0x25, 0x3a, 0x07, 0xf6, 0x76, 0x18, 0x0c, 0xe7, 0x32, 0x94, 0x58, 0x81, 0xbe, 0x0e, 0x79,
-	0x16, 0xcc, 0x65, 0xa6, 0x3c, 0x0b, 0xb8, 0x95, 0x94, 0xf8, 0x05, 0x59, 0xb7, 0x4b, 0x08, 0x3f,
-	0x05, 0x55, 0x21, 0xd0, 0x35, 0xd7, 0x0b, 0xa7, 0x26, 0x8c, 0xe9, 0x02, 0x3d, 0x0b, 0x87, 0x64,
-	0x30, 0x9c, 0xbe, 0xb8, 0x3e, 0x6d, 0x71, 0x5d, 0x2f, 0x3e, 0x0e, 0x45, 0x51, 0x74, 0xf0, 0x25,
-	0x3d, 0x97, 0xb9, 0x7a, 0x09, 0x1f, 0xe3, 0x23, 0xb0, 0xcc, 0xef, 0x20, 0x0d, 0xa3, 0xf5, 0x60,
-	0xec, 0x33, 0xfd, 0x6e, 0x3a, 0x0d, 0x8d, 0x34, 0x5a, 0x79, 0x49, 0x03, 0x8a, 0x5d, 0x8e, 0x10,
-	0x3c, 0x16, 0x88, 0x04, 0xf0, 0x2f, 0x2d, 0x40, 0x97, 0x28, 0x13, 0xbb, 0x5c, 0xde, 0x88, 0xaf,
-	0xc7, 0x0a, 0x54, 0x86, 0x2e, 0xeb, 0xee, 0xd0, 0x30, 0xd2, 0xf5, 0x8b, 0x86, 0xbf, 0x88, 0xc2,
-	0x13, 0x9f, 0x81, 0xe5, 0xd4, 0x29, 0x95, 0x4c, 0x2b, 0x50, 0xe9, 0x2a, 0x9c, 0x4a, 0x79, 0x31,
-	0x8c, 0x7f, 0x97, 0x87, 0x8a, 0x2e, 0xeb, 0xd0, 0x19, 0xa8, 0x6d, 0x7b, 0x7e, 0x9f, 0x86, 0xa3,
-	0xd0, 0x53, 0x2a, 0xb0, 0x65, 0x99, 0x67, 0xa0, 0x89, 0x09, 0xa0, 0xc7, 0xa1, 0x3c, 0x8e, 0x68,
-	0xf8, 0x96, 0x27, 0x6f, 0x7a, 0xb5, 0xd3, 0xd8, 0x9b, 0x38, 0xa5, 0xd7, 0x22, 0x1a, 0x5e, 0xde,
-	0xe0, 0xc9, 0x67, 0x2c, 0x46, 0x44, 0x7e, 0xf7, 0xd0, 0x4b, 0xca, 0x4d, 0x45, 0x01, 0xd7, 0xf9,
-	0x06, 0x3f, 0x7e, 0x26, 0xd4, 0x8d, 0xc2, 0x60, 0x48, 0xd9, 0x0e, 0x1d, 0x47, 0xed, 0x6e, 0x30,
-	0x1c, 0x06, 0x7e, 0x5b, 0x74, 0x02, 0x84, 0xd0, 0x3c, 0x83, 0xf2, 0xe5, 0xca, 0x73, 0xaf, 0x43,
-	0x99, 0xed, 0x84, 0xc1, 0xb8, 0xbf, 0x23, 0x12, 0x43, 0xa1, 0x73, 0x7e, 0x7e, 0x7e, 0x9a, 0x03,
-	0xd1, 0x03, 0xf4, 0x30, 0xd7, 0x16, 0xed, 0xde, 0x8a, 0xc6, 0x43, 0xf9, 0xf6, 0xec, 0x14, 0xf7,
-	0x27, 0x8e, 0xf5, 0x38, 0x89, 0xd1, 0xf8, 0x02, 0x2c, 0xa4, 0x4a, 0x61, 0xf4, 0x04, 0xd8, 0x21,
-	0xdd, 0xd6, 0xa1, 0x00, 0x1d, 0xac, 0x98, 0x65, 0xf6, 0xe7, 0x34, 0x44, 0x7c, 0xe2, 0x1f, 0xe6,
-	0xc1, 0x31, 0x5e, 0xfd, 0x17, 0x83, 0xf0, 0x65, 0xca, 0x42, 0xaf, 0x7b, 0xd5, 0x1d, 0x52, 0xed,
-	0x5e, 0x0e, 0xd4, 0x86, 0x02, 0xf9, 0x96, 0x71, 0x8b, 0x60, 0x18, 0xd3, 0xa1, 0x87, 0x00, 0xc4,
-	0xb5, 0x93, 0xf3, 0xf2, 0x42, 0x55, 0x05, 0x46, 0x4c, 0xaf, 0xa7, 0x94, 0xdd, 0x9e, 0x53, 0x39,
-	0x4a, 0xc9, 0x97, 0xb3, 0x4a, 0x9e, 0x9b, 0x4f, 0xac, 0x59, 0xf3, 0xba, 0x14, 0xd3, 0xd7, 0x05,
-	0xff, 0xd3, 0x82, 0xd6, 0xa6, 0x3e, 0xf9, 0x3d, 0xaa, 0x43, 0xcb, 0x9b, 0xbf, 0x4f, 0xf2, 0x16,
-	0xee, 0xa3, 0xbc, 0x76, 0x46, 0xde, 0x16, 0xc0, 0xa6, 0xe7, 0xd3, 0x8b, 0xde, 0x80, 0xd1, 0x70,
-	0xca, 0x23, 0xe9, 0xc7, 0x85, 0x24, 0xe2, 0x10, 0xba, 0xad, 0x75, 0xb0, 0x6e, 0x84, 0xf9, 0xfb,
-	0x21, 0x62, 0xfe, 0x3e, 0x8a, 0x58, 0xc8, 0x44, 0x40, 0x1f, 0xca, 0xdb, 0x42, 0x3c, 0x99, 0xb1,
-	0x53, 0xfd, 0xa7, 0x44, 0xf6, 0xce, 0xb7, 0xd4, 0xe6, 0x4f, 0xdf, 0xa5, 0xe0, 0x12, 0x5d, 0xc1,
-	0x76, 0xb4, 0xeb, 0x33, 0xf7, 0x1d, 0x63, 0x3d, 0xd1, 0x9b, 0x20, 0x57, 0xd5, 0x74, 0xc5, 0xa9,
-	0x35, 0xdd, 0x73, 0x6a, 0x9b, 0xff, 0xa5, 0xae, 0xc3, 0xcf, 0x25, 0x01, 0x56, 0x18, 0x45, 0x05,
-	0xd8, 0x47, 0xee, 0x76, 0xfd, 0xd5, 0xa5, 0xff, 0x93, 0x05, 0x4b, 0x97, 0x28, 0x4b, 0xd7, 0x58,
-	0x0f, 0x90, 0x49, 0xf1, 0x8b, 0x70, 0xd8, 0x38, 0xbf, 0x92, 0xfe, 0xc9, 0x4c, 0x61, 0x75, 0x24,
-	0x91, 0xff, 0xb2, 0xdf, 0xa3, 0xef, 0xa8, 0xf7, 0x6a, 0xba, 0xa6, 0xba, 0x06, 0x35, 0x63, 0x12,
-	0x5d, 0xc8, 0x54, 0x53, 0xcb, 0x99, 0x36, 0x2d, 0xaf, 0x08, 0x3a, 0x0d, 0x25, 0x93, 0x7c, 0x95,
-	0xaa, 0x5a, 0x39, 0xae, 0x3c, 0xb6, 0x00, 0x09, 0x73, 0x09, 0xb6, 0x66, 0xee, 0x13, 0xd8, 0x97,
-	0xe2, 0xb2, 0x2a, 0x86, 0xd1, 0xc3, 0x60, 0x87, 0xc1, 0x1d, 0x5d, 0x26, 0x2f, 0x24, 0x5b, 0x92,
-	0xe0, 0x0e, 0x11, 0x53, 0xf8, 0x59, 0x28, 0x90, 0xe0, 0x0e, 0x6a, 0x01, 0x84, 0xae, 0xdf, 0xa7,
-	0x37, 0xe2, 0x07, 0x5a, 0x9d, 0x18, 0x98, 0x19, 0x75, 0xc9, 0x3a, 0x1c, 0x36, 0x4f, 0x24, 0xcd,
-	0xbd, 0x06, 0xe5, 0x57, 0xc7, 0xa6, 0xba, 0x1a, 0x19, 0x75, 0xc9, 0x3e, 0x80, 0x26, 0xe2, 0x3e,
-	0x03, 0x09, 0x1e, 0x9d, 0x80, 0x2a, 0x73, 0x6f, 0x0e, 0xe8, 0xd5, 0x24, 0x04, 0x26, 0x08, 0x3e,
-	0xcb, 0xdf, 0x96, 0x37, 0x8c, 0x02, 0x2b, 0x41, 0xa0, 0xc7, 0x60, 0x29, 0x39, 0xf3, 0xb5, 0x90,
-	0x6e, 0x7b, 0xef, 0x08, 0x0b, 0xd7, 0xc9, 0x01, 0x3c, 0x3a, 0x09, 0x87, 0x12, 0xdc, 0x96, 0x28,
-	0x64, 0x6c, 0x41, 0x9a, 0x45, 0x73, 0xdd, 0x08, 0x71, 0x5f, 0xb8, 0x3d, 0x76, 0x07, 0xe2, 0xf2,
-	0xd5, 0x89, 0x81, 0xc1, 0x7f, 0xb6, 0xe0, 0xb0, 0x34, 0x35, 0x73, 0xd9, 0x03, 0xe9, 0xf5, 0xbf,
-	0xb2, 0x00, 0x99, 0x12, 0x28, 0xd7, 0xfa, 0xaa, 0xd9, 0x67, 0xe2, 0x95, 0x52, 0x4d, 0x3c, 0x99,
-	0x25, 0x2a, 0x69, 0x15, 0x61, 0x28, 0x75, 0x65, 0x3f, 0x4d, 0x34, 0xc6, 0xe5, 0x9b, 0x5c, 0x62,
-	0x88, 0xfa, 0x46, 0x0e, 0x14, 0x6f, 0xee, 0x32, 0x1a, 0xa9, 0x17, 0xb5, 0x68, 0x25, 0x08, 0x04,
-	0x91, 0x5f, 0x7c, 0x2f, 0xea, 0x33, 0xe1, 0x35, 0x76, 0xb2, 0x97, 0x42, 0x11, 0x3d, 0xc0, 0xbf,
-	0xcd, 0xc3, 0xc2, 0x8d, 0x60, 0x30, 0x4e, 0x92, 0xe6, 0x83, 0x94, 0x30, 0x52, 0xcf, 0xfc, 0xa2,
-	0x7e, 0xe6, 0x23, 0xb0, 0x23, 0x46, 0x47, 0xc2, 0xb3, 0x0a, 0x44, 0x8c, 0x11, 0x86, 0x3a, 0x73,
-	0xc3, 0x3e, 0x65, 0xf2, 0xf1, 0xd4, 0x2c, 0x89, 0xaa, 0x36, 0x85, 0x43, 0xab, 0x50, 0x73, 0xfb,
-	0xfd, 0x90, 0xf6, 0x5d, 0x46, 0x3b, 0xbb, 0xcd, 0xb2, 0xd8, 0xcc, 0x44, 0xe1, 0x37, 0x60, 0x51,
-	0x2b, 0x4b, 0x99, 0xf4, 0x09, 0x28, 0xbf, 0x2d, 0x30, 0x53, 0xda, 0x6e, 0x92, 0x54, 0x85, 0x31,
-	0x4d, 0x96, 0xfe, 0x79, 0x41, 0x9f, 0x19, 0x5f, 0x81, 0x92, 0x24, 0x47, 0x27, 0xcc, 0x27, 0x90,
-	0xac, 0x02, 0x39, 0xac, 0xde, 0x33, 0x18, 0x4a, 0x92, 0x91, 0x32, 0xbc, 0xf0, 0x0d, 0x89, 0x21,
-	0xea, 0x1b, 0xff, 0xcb, 0x82, 0x23, 0x1b, 0x94, 0xd1, 0x2e, 0xa3, 0xbd, 0x8b, 0x1e, 0x1d, 0xf4,
-	0xbe, 0xd0, 0xd7, 0x79, 0xdc, 0x63, 0x2b, 0x18, 0x3d, 0x36, 0x1e, 0x77, 0x06, 0x9e, 0x4f, 0x37,
-	0x8d, 0x26, 0x4d, 0x82, 0xe0, 0x11, 0x62, 0x9b, 0x1f, 0x5c, 0x4e, 0xcb, 0xdf, 0x73, 0x0c, 0x4c,
-	0x6c, 0xe1, 0x52, 0x62, 0x61, 0xfc, 0x03, 0x0b, 0x8e, 0x66, 0xa5, 0x56, 0x46, 0x6a, 0x43, 0x49,
-	0x2c, 0x9e, 0xd2, 0xde, 0x4d, 0xad, 0x20, 0x8a, 0x0c, 0x9d, 0x4b, 0xed, 0x2f, 0x7e, 0x07, 0xea,
-	0x34, 0xf7, 0x27, 0x4e, 0x23, 0xc1, 0x1a, 0x1d, 0x04, 0x83, 0x16, 0xff, 0x81, 0xbf, 0xb3, 0x4d,
-	0x9e, 0xc2, 0xde, 0xdc, 0xbf, 0x54, 0xec, 0x95, 0x00, 0xfa, 0x1a, 0xd8, 0x6c, 0x77, 0xa4, 0x42,
-	0x6e, 0xe7, 0xc8, 0x67, 0x13, 0xe7, 0x70, 0x6a, 0xd9, 0xf5, 0xdd, 0x11, 0x25, 0x82, 0x84, 0xbb,
-	0x65, 0xd7, 0x0d, 0x7b, 0x9e, 0xef, 0x0e, 0x3c, 0x26, 0xd5, 0x68, 0x13, 0x13, 0x85, 0x9a, 0x50,
-	0x1e, 0xb9, 0x61, 0xa4, 0xeb, 0xa6, 0x2a, 0xd1, 0xa0, 0x68, 0x81, 0xdc, 0xa2, 0xac, 0xbb, 0x23,
-	0xc3, 0xac, 0x6a, 0x81, 0x08, 0x4c, 0xaa, 0x05, 0x22, 0x30, 0xf8, 0x17, 0x86, 0xe3, 0xc8, 0x3b,
-	0xf1, 0xa5, 0x73, 0x1c, 0xfc, 0x9d, 0xc4, 0xca, 0xfa, 0x88, 0xca, 0xca, 0xcf, 0xc3, 0x62, 0x2f,
-	0x35, 0x33, 0xdb, 0xda, 0xb2, 0xbd, 0x9b, 0x21, 0xc7, 0xe3, 0xc4, 0x74, 0x02, 0x33, 0xc3, 0x74,
-	0x19, 0x7b, 0xe4, 0x0f, 0xda, 0x23, 0xd1, 0x7a, 0xe1, 0xee, 0x5a, 0x7f, 0xec, 0x11, 0xa8, 0xc6,
-	0x3f, 0xe5, 0xa1, 0x1a, 0x94, 0x2f, 0xbe, 0x42, 0x5e, 0xbf, 0x40, 0x36, 0x96, 0x72, 0xa8, 0x0e,
-	0x95, 0xce, 0x85, 0xf5, 0x97, 0x04, 0x64, 0x9d, 0xfd, 0x4d, 0x49, 0x17, 0x02, 0x21, 0xfa, 0x26,
-	0x14, 0x65, 0x76, 0x3f, 0x9a, 0x08, 0x67, 0xfe, 0xca, 0xb5, 0x72, 0xec, 0x00, 0x5e, 0x6a, 0x09,
-	0xe7, 0x9e, 0xb0, 0xd0, 0x55, 0xa8, 0x09, 0xa4, 0xea, 0x23, 0x9f, 0xc8, 0xb6, 0x73, 0x53, 0x9c,
-	0x1e, 0x9a, 0x31, 0x6b, 0xf0, 0x3b, 0x0f, 0x45, 0xa9, 0xb0, 0xa3, 0x99, 0x22, 0x6c, 0xca, 0x69,
-	0x52, 0x9d, 0x75, 0x9c, 0x43, 0xcf, 0x80, 0x7d, 0xdd, 0xf5, 0x06, 0xc8, 0xa8, 0x01, 0x8d, 0xf6,
-	0xef, 0xca, 0xd1, 0x2c, 0xda, 0xd8, 0xf6, 0xb9, 0xb8, 0x8b, 0x7d, 0x2c, 0xdb, 0x4a, 0xd3, 0xcb,
-	0x9b, 0x07, 0x27, 0xe2, 0x9d, 0x5f, 0x91, 0xbd, 0x56, 0xdd, 0xd0, 0x41, 0x0f, 0xa5, 0xb7, 0xca,
-	0xf4, 0x7f, 0x56, 0x5a, 0xb3, 0xa6, 0x63, 0x86, 0x9b, 0x50, 0x33, 0x9a, 0x29, 0xa6, 0x5a, 0x0f,
-	0x76, 0x82, 0x4c, 0xb5, 0x4e, 0xe9, 0xc0, 0xe0, 0x1c, 0xba, 0x04, 0x15, 0x5e, 0x39, 0x8b, 0x1f,
-	0x5d, 0x8e, 0x67, 0x0b, 0x64, 0xa3, 0x30, 0x5a, 0x39, 0x31, 0x7d, 0x32, 0x66, 0xf4, 0x6d, 0xa8,
-	0x5e, 0xa2, 0x4c, 0x65, 0x97, 0x63, 0xd9, 0xf4, 0x34, 0x45, 0x53, 0xe9, 0x14, 0x87, 0x73, 0xe8,
-	0x0d, 0x51, 0xc4, 0xa7, 0x83, 0x2b, 0x72, 0x66, 0x04, 0xd1, 0xf8, 0x5c, 0xab, 0xb3, 0x09, 0x62,
-	0xce, 0xaf, 0xa7, 0x38, 0xab, 0x3c, 0xec, 0xcc, 0xb8, 0xb0, 0x31, 0x67, 0xe7, 0x2e, 0x7f, 0xc9,
-	0xc0, 0xb9, 0xb3, 0x6f, 0xea, 0x7f, 0x25, 0x6c, 0xb8, 0xcc, 0x45, 0xaf, 0xc0, 0xa2, 0xd0, 0x65,
-	0xfc, 0xb7, 0x85, 0x94, 0xcf, 0x1f, 0xf8, 0x8f, 0x44, 0xca, 0xe7, 0x0f, 0xfe, 0x57, 0x02, 0xe7,
-	0x3a, 0x6f, 0x7e, 0xf0, 0x71, 0x2b, 0xf7, 0xe1, 0xc7, 0xad, 0xdc, 0xa7, 0x1f, 0xb7, 0xac, 0xef,
-	0xef, 0xb5, 0xac, 0x5f, 0xef, 0xb5, 0xac, 0xf7, 0xf7, 0x5a, 0xd6, 0x07, 0x7b, 0x2d, 0xeb, 0xef,
-	0x7b, 0x2d, 0xeb, 0x1f, 0x7b, 0xad, 0xdc, 0xa7, 0x7b, 0x2d, 0xeb, 0xdd, 0x4f, 0x5a, 0xb9, 0x0f,
-	0x3e, 0x69, 0xe5, 0x3e, 0xfc, 0xa4, 0x95, 0xfb, 0xee, 0xa3, 0x77, 0x7f, 0xb0, 0xca, 0xb0, 0x58,
-	0x12, 0x5f, 0x4f, 0xfe, 0x27, 0x00, 0x00, 0xff, 0xff, 0xb0, 0x55, 0xf6, 0x50, 0x3b, 0x23, 0x00,
-	0x00,
+	// 2722 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x3a, 0x4d, 0x6c, 0x1b, 0xc7,
+	0xd5, 0x5a, 0x72, 0x49, 0x8a, 0x8f, 0x94, 0x2c, 0x8f, 0x68, 0x99, 0xa0, 0x6d, 0x52, 0x19, 0x7c,
+	0x5f, 0xe2, 0xc6, 0x8e, 0x18, 0xdb, 0x4d, 0xea, 0x38, 0x4d, 0x5b, 0x53, 0x8a, 0x1d, 0x3b, 0xb2,
+	0xe3, 0x8c, 0x1c, 0x27, 0x29, 0x1a, 0x04, 0x6b, 0x72, 0x44, 0x2d, 0x44, 0xee, 0xd2, 0xbb, 0xc3,
+	0xd8, 0xbc, 0x15, 0xe8, 0xb9, 0x68, 0x80, 0x1e, 0xda, 0x5e, 0x0a, 0x14, 0x28, 0xd0, 0xa2, 0x41,
+	0x51, 0xa0, 0xe8, 0xa9, 0x28, 0xda, 0x4b, 0x0f, 0xe9, 0x2d, 0xc7, 0x20, 0x07, 0xb6, 0x51, 0x2e,
+	0x85, 0x80, 0x02, 0x01, 0x7a, 0xeb, 0xa9, 0x98, 0xbf, 0xdd, 0xd9, 0x15, 0x59, 0x85, 0xae, 0x8b,
+	0x34, 0xbd, 0x90, 0xf3, 0xde, 0xbc, 0x79, 0x33, 0xef, 0x67, 0xde, 0x7b, 0xf3, 0x48, 0x38, 0x31,
+	0xd8, 0xed, 0x36, 0x7b, 0x7e, 0x77, 0x10, 0xf8, 0xcc, 0x8f, 0x06, 0x6b, 0xe2, 0x13, 0xcd, 0x6b,
+	0xb8, 0x56, 0xe9, 0xfa, 0x5d, 0x5f, 0xd2, 0xf0, 0x91, 0x9c, 0xaf, 0x35, 0xba, 0xbe, 0xdf, 0xed,
+	0xd1, 0xa6, 0x80, 0xee, 0x0e, 0xb7, 0x9b, 0xcc, 0xed, 0xd3, 0x90, 0x39, 0xfd, 0x81, 0x22, 0x58,
+	0x55, 0xdc, 0xef, 0xf5, 0xfa, 0x7e, 0x87, 0xf6, 0x9a, 0x21, 0x73, 0x58, 0x28, 0x3f, 0x15, 0xc5,
+	0x32, 0xa7, 0x18, 0x0c, 0xc3, 0x1d, 0xf1, 0x21, 0x91, 0xf8, 0x37, 0x16, 0x1c, 0xdb, 0x74, 0xee,
+	0xd2, 0xde, 0x6d, 0xff, 0x8e, 0xd3, 0x1b, 0xd2, 0x90, 0xd0, 0x70, 0xe0, 0x7b, 0x21, 0x45, 0xeb,
+	0x90, 0xef, 0xf1, 0x89, 0xb0, 0x6a, 0xad, 0x66, 0x4f, 0x97, 0xce, 0x9f, 0x59, 0x8b, 0x8e, 0x3c,
+	0x71, 0x81, 0xc4, 0x86, 0x2f, 0x7a, 0x2c, 0x18, 0x11, 0xb5, 0xb4, 0x76, 0x07, 0x4a, 0x06, 0x1a,
+	0x2d, 0x41, 0x76, 0x97, 0x8e, 0xaa, 0xd6, 0xaa, 0x75, 0xba, 0x48, 0xf8, 0x10, 0x9d, 0x83, 0xdc,
+	0x3b, 0x9c, 0x4d, 0x35, 0xb3, 0x6a, 0x9d, 0x2e, 0x9d, 0x3f, 0x11, 0x6f, 0xf2, 0x9a, 0xe7, 0xde,
+	0x1b, 0x52, 0xb1, 0x5a, 0x6d, 0x24, 0x29, 0x2f, 0x65, 0x2e, 0x5a, 0xf8, 0x0c, 0x1c, 0x3d, 0x30,
+	0x8f, 0x56, 0x20, 0x2f, 0x28, 0xe4, 0x89, 0x8b, 0x44, 0x41, 0xb8, 0x02, 0x68, 0x8b, 0x05, 0xd4,
+	0xe9, 0x13, 0x87, 0xf1, 0xf3, 0xde, 0x1b, 0xd2, 0x90, 0xe1, 0x1b, 0xb0, 0x9c, 0xc0, 0x2a, 0xb1,
+	0x9f, 0x85, 0x52, 0x18, 0xa3, 0x95, 0xec, 0x95, 0xf8, 0x58, 0xf1, 0x1a, 0x62, 0x12, 0xe2, 0x1f,
+	0x5b, 0x00, 0xf1, 0x1c, 0xaa, 0x03, 0xc8, 0xd9, 0x97, 0x9c, 0x70, 0x47, 0x08, 0x6c, 0x13, 0x03,
+	0x83, 0xce, 0xc2, 0xd1, 0x18, 0xba, 0xe9, 0x6f, 0xed, 0x38, 0x41, 0x47, 0xe8, 0xc0, 0x26, 0x07,
+	0x27, 0x10, 0x02, 0x3b, 0x70, 0x18, 0xad, 0x66, 0x57, 0xad, 0xd3, 0x59, 0x22, 0xc6, 0x5c, 0x5a,
+	0x46, 0x3d, 0xc7, 0x63, 0x55, 0x5b, 0xa8, 0x53, 0x41, 0x1c, 0xcf, 0xed, 0x4b, 0xc3, 0x6a, 0x6e,
+	0xd5, 0x3a, 0xbd, 0x40, 0x14, 0x84, 0xdf, 0xb3, 0xa1, 0xfc, 0xea, 0x90, 0x06, 0x23, 0xa5, 0x00,
+	0x54, 0x87, 0xf9, 0x90, 0xf6, 0x68, 0x9b, 0xf9, 0x81, 0xb4, 0x48, 0x2b, 0x53, 0xb5, 0x48, 0x84,
+	0x43, 0x15, 0xc8, 0xf5, 0xdc, 0xbe, 0xcb, 0xc4, 0xb1, 0x16, 0x88, 0x04, 0xd0, 0x25, 0xc8, 0x85,
+	0xcc, 0x09, 0x98, 0x38, 0x4b, 0xe9, 0x7c, 0x6d, 0x4d, 0x3a, 0xe6, 0x9a, 0x76, 0xcc, 0xb5, 0xdb,
+	0xda, 0x31, 0x5b, 0xf3, 0xef, 0x8f, 0x1b, 0x73, 0xef, 0xfe, 0xb9, 0x61, 0x11, 0xb9, 0x04, 0x3d,
+	0x0b, 0x59, 0xea, 0x75, 0xaa, 0xf6, 0x0c, 0x2b, 0xf9, 0x02, 0x74, 0x0e, 0x8a, 0x1d, 0x37, 0xa0,
+	0x6d, 0xe6, 0xfa, 0x9e, 0x90, 0x6a, 0xf1, 0xfc, 0x72, 0x6c, 0x91, 0x0d, 0x3d, 0x45, 0x62, 0x2a,
+	0x74, 0x16, 0xf2, 0x21, 0x57, 0x5d, 0x58, 0x2d, 0x70, 0x5f, 0x68, 0x55, 0xf6, 0xc7, 0x8d, 0x25,
+	0x89, 0x39, 0xeb, 0xf7, 0x5d, 0x46, 0xfb, 0x03, 0x36, 0x22, 0x8a, 0x06, 0x3d, 0x09, 0x85, 0x0e,
+	0xed, 0x51, 0x6e, 0xf0, 0x79, 0x61, 0xf0, 0x25, 0x83, 0xbd, 0x98, 0x20, 0x9a, 0x00, 0xbd, 0x05,
+	0xf6, 0xa0, 0xe7, 0x78, 0xd5, 0xa2, 0x90, 0x62, 0x31, 0x26, 0xbc, 0xd5, 0x73, 0xbc, 0xd6, 0x73,
+	0x1f, 0x8d, 0x1b, 0xcf, 0x74, 0x5d, 0xb6, 0x33, 0xbc, 0xbb, 0xd6, 0xf6, 0xfb, 0xcd, 0x6e, 0xe0,
+	0x6c, 0x3b, 0x9e, 0xd3, 0xec, 0xf9, 0xbb, 0x6e, 0xf3, 0x9d, 0x0b, 0x4d, 0x7e, 0x07, 0xef, 0x0d,
+	0x69, 0xe0, 0xd2, 0xa0, 0xc9, 0xd9, 0xac, 0x09, 0x93, 0xf0, 0xa5, 0x44, 0xb0, 0x45, 0xd7, 0xb9,
+	0xff, 0xf9, 0x01, 0x5d, 0xdf, 0x19, 0x7a, 0xbb, 0x61, 0x15, 0xc4, 0x2e, 0xc7, 0xe3, 0x5d, 0x04,
+	0x9e, 0xd0, 0xed, 0xab, 0x81, 0x3f, 0x1c, 0xb4, 0x8e, 0xec, 0x8f, 0x1b, 0x26, 0x3d, 0x31, 0x01,
+	0x74, 0x01, 0x60, 0xdb, 0xa5, 0xbd, 0xce, 0x0d, 0x27, 0xdc, 0x0d, 0xab, 0x25, 0x21, 0x99, 0xa1,
+	0xb8, 0x2b, 0x7a, 0x8e, 0x18, 0x64, 0xd7, 0xed, 0xf9, 0xfc, 0x52, 0x01, 0xef, 0x65, 0x01, 0x6d,
+	0x39, 0xfd, 0x41, 0x8f, 0xce, 0xe4, 0x33, 0x91, 0x77, 0x64, 0x1e, 0xda, 0x3b, 0xb2, 0xb3, 0x7a,
+	0x47, 0x6c, 0x6a, 0x7b, 0x36, 0x53, 0xe7, 0x3e, 0xab, 0xa9, 0xf3, 0xff, 0x9b, 0xa6, 0xc6, 0x55,
+	0xb0, 0xf9, 0x71, 0x78, 0x58, 0x0e, 0x9c, 0xfb, 0xc2, 0xa0, 0x65, 0xc2, 0x87, 0x78, 0x13, 0xf2,
+	0x52, 0x19, 0xa8, 0x96, 0xb6, 0x78, 0x32, 0x42, 0xc4, 0xd6, 0xce, 0x6a, 0x3b, 0x2e, 0xc5, 0x76,
+	0xcc, 0x0a, 0x0b, 0xe1, 0x37, 0xa1, 0x18, 0x1d, 0x80, 0x2f, 0x12, 0x47, 0x50, 0xdc, 0x24, 0xc0,
+	0xe3, 0xa5, 0x18, 0x10, 0xda, 0xa5, 0x0f, 0x04, 0xbf, 0x22, 0x31, 0x30, 0x3c, 0xaa, 0x39, 0xf2,
+	0xfe, 0x67, 0x65, 0xb4, 0x93, 0x10, 0xfe, 0x9d, 0x05, 0x0b, 0xca, 0x43, 0x55, 0x00, 0xbf, 0x0b,
+	0x05, 0x19, 0x40, 0x75, 0xf0, 0x3e, 0x9e, 0x0e, 0xde, 0x97, 0x3b, 0xce, 0x80, 0xd1, 0xa0, 0xd5,
+	0x7c, 0x7f, 0xdc, 0xb0, 0x3e, 0x1a, 0x37, 0x9e, 0x98, 0x66, 0x44, 0x9d, 0x30, 0xd5, 0x3a, 0xa2,
+	0x19, 0xa3, 0x33, 0x42, 0x70, 0x16, 0x2a, 0x37, 0x3f, 0xb2, 0x26, 0xa0, 0xb5, 0x6b, 0x5e, 0x97,
+	0x86, 0x9c, 0xb3, 0xcd, 0x3d, 0x94, 0x48, 0x1a, 0xae, 0xc1, 0xfb, 0x4e, 0xe0, 0xb9, 0x5e, 0x37,
+	0xac, 0x66, 0x45, 0x62, 0x8a, 0x60, 0xfc, 0x43, 0x0b, 0x96, 0x13, 0xd7, 0x4c, 0x09, 0x71, 0x11,
+	0xf2, 0x21, 0xf7, 0x1c, 0x2d, 0x83, 0xe1, 0xa4, 0x5b, 0x02, 0xdf, 0x5a, 0x54, 0x87, 0xcf, 0x4b,
+	0x98, 0x28, 0xfa, 0x47, 0x77, 0xb4, 0x3f, 0x5a, 0x50, 0x16, 0xd9, 0x55, 0xdf, 0x7d, 0x04, 0xb6,
+	0xe7, 0xf4, 0xa9, 0xb2, 0x9b, 0x18, 0x1b, 0x29, 0x97, 0x6f, 0x37, 0xaf, 0x53, 0xee, 0xac, 0x59,
+	0xc2, 0x7a, 0xe8, 0x2c, 0x61, 0xc5, 0x71, 0xa0, 0x02, 0x39, 0x7e, 0xdd, 0x46, 0x22, 0x43, 0x14,
+	0x89, 0x04, 0xf0, 0x13, 0xb0, 0xa0, 0xa4, 0x50, 0xaa, 0x9d, 0x56, 0x25, 0xf4, 0x21, 0x2f, 0x2d,
+	0x81, 0xfe, 0x0f, 0x8a, 0x51, 0x75, 0x25, 0xa4, 0xcd, 0xb6, 0xf2, 0xfb, 0xe3, 0x46, 0x86, 0x85,
+	0x24, 0x9e, 0x40, 0x0d, 0xb3, 0x72, 0xb1, 0x5a, 0xc5, 0xfd, 0x71, 0x43, 0x22, 0x54, 0x9d, 0x82,
+	0x4e, 0x82, 0xbd, 0xc3, 0x93, 0x3f, 0x57, 0x81, 0xdd, 0x9a, 0xdf, 0x1f, 0x37, 0x04, 0x4c, 0xc4,
+	0x27, 0xbe, 0x0a, 0xe5, 0x4d, 0xda, 0x75, 0xda, 0x23, 0xb5, 0x69, 0x45, 0xb3, 0xe3, 0x1b, 0x5a,
+	0x9a, 0xc7, 0x63, 0x50, 0x8e, 0x76, 0x7c, 0xbb, 0x1f, 0xaa, 0x8b, 0x56, 0x8a, 0x70, 0x37, 0x42,
+	0xfc, 0x23, 0x0b, 0x94, 0x0f, 0x20, 0x6c, 0x94, 0x6c, 0x3c, 0x36, 0xc3, 0xfe, 0xb8, 0xa1, 0x30,
+	0xba, 0x22, 0x43, 0xcf, 0x43, 0x21, 0x14, 0x3b, 0x72, 0x66, 0x69, 0xd7, 0x12, 0x13, 0xad, 0x23,
+	0xdc, 0x45, 0xf6, 0xc7, 0x0d, 0x4d, 0x48, 0xf4, 0x00, 0xad, 0x25, 0xaa, 0x1a, 0x29, 0xd8, 0xe2,
+	0xfe, 0xb8, 0x61, 0x60, 0xcd, 0x2a, 0x07, 0xff, 0x2a, 0x03, 0xa5, 0xdb, 0x8e, 0x1b, 0xb9, 0x50,
+	0x55, 0x9b, 0x28, 0xce, 0x1d, 0x12, 0xc1, 0x3d, 0xb1, 0x43, 0x7b, 0xce, 0xe8, 0x8a, 0x1f, 0x08,
+	0xbe, 0x0b, 0x24, 0x82, 0xe3, 0x42, 0xc4, 0x9e, 0x58, 0x88, 0xe4, 0x66, 0x4f, 0x35, 0xff, 0xe1,
+	0xc0, 0x9e, 0x0c, 0xc6, 0x85, 0xcf, 0x9a, 0x77, 0x33, 0x4b, 0x59, 0xfc, 0x4b, 0x0b, 0xca, 0x52,
+	0x63, 0xca, 0x5d, 0xbf, 0x05, 0x79, 0xa9, 0x50, 0xa1, 0xb3, 0x7f, 0x11, 0xcd, 0xce, 0xcc, 0x12,
+	0xc9, 0x14, 0x4f, 0xf4, 0x75, 0x58, 0xec, 0x04, 0xfe, 0x60, 0x40, 0x3b, 0x5b, 0x2a, 0x66, 0x66,
+	0xd2, 0x31, 0x73, 0xc3, 0x9c, 0x27, 0x29, 0x72, 0xfc, 0x27, 0x0b, 0x16, 0x54, 0x04, 0x52, 0x36,
+	0x8e, 0xec, 0x62, 0x3d, 0x74, 0x09, 0x90, 0x99, 0xb5, 0x04, 0x58, 0x81, 0x7c, 0x97, 0x27, 0x49,
+	0x1d, 0xc5, 0x14, 0x34, 0x5b, 0x69, 0x80, 0xaf, 0xc3, 0xa2, 0x16, 0x65, 0x4a, 0x18, 0xae, 0xa5,
+	0xc3, 0xf0, 0xb5, 0x0e, 0xf5, 0x98, 0xbb, 0xed, 0x46, 0x81, 0x55, 0xd1, 0xe3, 0xef, 0x59, 0xb0,
+	0x94, 0x26, 0x41, 0x1b, 0xa9, 0x27, 0xd5, 0xe3, 0xd3, 0xd9, 0x99, 0xaf, 0x29, 0xcd, 0x5a, 0xbd,
+	0xa9, 0x9e, 0x39, 0xec, 0x4d, 0x55, 0x31, 0x23, 0x53, 0x51, 0x85, 0x12, 0xfc, 0x03, 0x0b, 0x16,
+	0x12, 0xb6, 0x44, 0x17, 0xc1, 0xde, 0x0e, 0xfc, 0xfe, 0x4c, 0x86, 0x12, 0x2b, 0xd0, 0x97, 0x21,
+	0xc3, 0xfc, 0x99, 0xcc, 0x94, 0x61, 0x3e, 0xb7, 0x92, 0x12, 0x5f, 0xe5, 0x70, 0x09, 0xe1, 0x67,
+	0xa0, 0x28, 0x04, 0xba, 0xe5, 0xb8, 0xc1, 0xc4, 0x2c, 0x33, 0x59, 0xa0, 0xe7, 0xe1, 0x88, 0x8c,
+	0xa0, 0x93, 0x17, 0x97, 0x27, 0x2d, 0x2e, 0xeb, 0xc5, 0x27, 0x20, 0x27, 0x2a, 0x27, 0xbe, 0xa4,
+	0xe3, 0x30, 0x47, 0x2f, 0xe1, 0x63, 0x7c, 0x0c, 0x96, 0xf9, 0x1d, 0xa4, 0x41, 0xb8, 0xee, 0x0f,
+	0x3d, 0xa6, 0x5f, 0x8c, 0x67, 0xa1, 0x92, 0x44, 0x2b, 0x2f, 0xa9, 0x40, 0xae, 0xcd, 0x11, 0x82,
+	0xc7, 0x02, 0x91, 0x00, 0xfe, 0xa9, 0x05, 0xe8, 0x2a, 0x65, 0x62, 0x97, 0x6b, 0x1b, 0xd1, 0xf5,
+	0xa8, 0xc1, 0x7c, 0xdf, 0x61, 0xed, 0x1d, 0x1a, 0x84, 0xba, 0x9e, 0xd2, 0xf0, 0xe7, 0x51, 0x3d,
+	0xe3, 0x73, 0xb0, 0x9c, 0x38, 0xa5, 0x92, 0xa9, 0x06, 0xf3, 0x6d, 0x85, 0x53, 0x79, 0x32, 0x82,
+	0xf1, 0xaf, 0x33, 0x30, 0xaf, 0x6b, 0x53, 0x74, 0x0e, 0x4a, 0xdb, 0xae, 0xd7, 0xa5, 0xc1, 0x20,
+	0x70, 0x95, 0x0a, 0x6c, 0x59, 0xab, 0x1a, 0x68, 0x62, 0x02, 0xe8, 0x29, 0x28, 0x0c, 0x43, 0x1a,
+	0xbc, 0xed, 0xca, 0x9b, 0x5e, 0x6c, 0x55, 0xf6, 0xc6, 0x8d, 0xfc, 0x6b, 0x21, 0x0d, 0xae, 0x6d,
+	0xf0, 0x8c, 0x35, 0x14, 0x23, 0x22, 0xbf, 0x3b, 0xe8, 0x65, 0xe5, 0xa6, 0xa2, 0xa0, 0x6c, 0x7d,
+	0x85, 0x1f, 0x3f, 0x15, 0xea, 0x06, 0x81, 0xdf, 0xa7, 0x6c, 0x87, 0x0e, 0xc3, 0x66, 0xdb, 0xef,
+	0xf7, 0x7d, 0xaf, 0x29, 0x7a, 0x20, 0x42, 0x68, 0x9e, 0x76, 0xf9, 0x72, 0xe5, 0xb9, 0xb7, 0xa1,
+	0xc0, 0x76, 0x02, 0x7f, 0xd8, 0xdd, 0x11, 0xd9, 0x24, 0xdb, 0xba, 0x34, 0x3b, 0x3f, 0xcd, 0x81,
+	0xe8, 0x01, 0x7a, 0x8c, 0x6b, 0x8b, 0xb6, 0x77, 0xc3, 0x61, 0x5f, 0xbe, 0xba, 0x5b, 0xb9, 0xfd,
+	0x71, 0xc3, 0x7a, 0x8a, 0x44, 0x68, 0x7c, 0x19, 0x16, 0x12, 0xf5, 0x3c, 0x7a, 0x1a, 0xec, 0x80,
+	0x6e, 0xeb, 0x50, 0x80, 0x0e, 0x96, 0xfd, 0xb2, 0x64, 0xe0, 0x34, 0x44, 0x7c, 0xe2, 0xef, 0x66,
+	0xa0, 0x61, 0xf4, 0x3b, 0xae, 0xf8, 0xc1, 0x0d, 0xca, 0x02, 0xb7, 0x7d, 0xd3, 0xe9, 0x53, 0xed,
+	0x5e, 0x0d, 0x28, 0xf5, 0x05, 0xf2, 0x6d, 0xe3, 0x16, 0x41, 0x3f, 0xa2, 0x43, 0xa7, 0x00, 0xc4,
+	0xb5, 0x93, 0xf3, 0xf2, 0x42, 0x15, 0x05, 0x46, 0x4c, 0xaf, 0x27, 0x94, 0xdd, 0x9c, 0x51, 0x39,
+	0x4a, 0xc9, 0xd7, 0xd2, 0x4a, 0x9e, 0x99, 0x4f, 0xa4, 0x59, 0xf3, 0xba, 0xe4, 0x92, 0xd7, 0x05,
+	0xff, 0xcd, 0x82, 0xfa, 0xa6, 0x3e, 0xf9, 0x43, 0xaa, 0x43, 0xcb, 0x9b, 0x79, 0x44, 0xf2, 0x66,
+	0x1f, 0xa1, 0xbc, 0x76, 0x4a, 0xde, 0x3a, 0xc0, 0xa6, 0xeb, 0xd1, 0x2b, 0x6e, 0x8f, 0xd1, 0x60,
+	0xc2, 0xa3, 0xed, 0xfb, 0xd9, 0x38, 0xe2, 0x10, 0xba, 0xad, 0x75, 0xb0, 0x6e, 0x84, 0xf9, 0x47,
+	0x21, 0x62, 0xe6, 0x11, 0x8a, 0x98, 0x4d, 0x45, 0x40, 0x0f, 0x0a, 0xdb, 0x42, 0x3c, 0x99, 0xb1,
+	0x13, 0x9d, 0xb7, 0x58, 0xf6, 0xd6, 0xd7, 0xd4, 0xe6, 0xcf, 0x1e, 0x52, 0xa5, 0x89, 0x7e, 0x68,
+	0x33, 0x1c, 0x79, 0xcc, 0x79, 0x60, 0xac, 0x27, 0x7a, 0x13, 0xe4, 0xa8, 0x42, 0x30, 0x37, 0xb1,
+	0x10, 0x7c, 0x41, 0x6d, 0xf3, 0xef, 0x14, 0x83, 0xf8, 0x05, 0x58, 0x4e, 0x18, 0x45, 0x05, 0xd8,
+	0xc7, 0x0f, 0xbb, 0xfe, 0xea, 0xd2, 0xff, 0xde, 0x82, 0xa5, 0xab, 0x94, 0x25, 0x6b, 0xac, 0x2f,
+	0x90, 0x49, 0xf1, 0x4b, 0x70, 0xd4, 0x38, 0xbf, 0x92, 0xfe, 0x42, 0xaa, 0xb0, 0x3a, 0x16, 0xcb,
+	0x7f, 0xcd, 0xeb, 0xd0, 0x07, 0xea, 0x91, 0x9b, 0xac, 0xa9, 0x6e, 0x41, 0xc9, 0x98, 0x44, 0x97,
+	0x53, 0xd5, 0xd4, 0x72, 0xaa, 0x41, 0xcd, 0x2b, 0x82, 0x56, 0x45, 0xc9, 0x24, 0x9f, 0xb2, 0xaa,
+	0x56, 0x8e, 0x2a, 0x8f, 0x2d, 0x40, 0xc2, 0x5c, 0x82, 0xad, 0x99, 0xfb, 0x04, 0xf6, 0xe5, 0xa8,
+	0xac, 0x8a, 0x60, 0xf4, 0x18, 0xd8, 0x81, 0x7f, 0x5f, 0x97, 0xc9, 0x0b, 0xf1, 0x96, 0xc4, 0xbf,
+	0x4f, 0xc4, 0x14, 0x7e, 0x1e, 0xb2, 0xc4, 0xbf, 0xcf, 0x3b, 0x1a, 0x81, 0xe3, 0x75, 0xe9, 0x9d,
+	0xe8, 0x55, 0x57, 0x26, 0x06, 0x66, 0x4a, 0x5d, 0xb2, 0x0e, 0x47, 0xcd, 0x13, 0x49, 0x73, 0xaf,
+	0x41, 0xe1, 0xd5, 0xa1, 0xa9, 0xae, 0x4a, 0x4a, 0x5d, 0x62, 0x09, 0xd1, 0x44, 0xdc, 0x67, 0x20,
+	0xc6, 0xa3, 0x93, 0x50, 0x64, 0xce, 0xdd, 0x1e, 0xbd, 0x19, 0x87, 0xc0, 0x18, 0xc1, 0x67, 0xf9,
+	0x83, 0xf4, 0x8e, 0x51, 0x60, 0xc5, 0x08, 0xf4, 0x24, 0x2c, 0xc5, 0x67, 0xbe, 0x15, 0xd0, 0x6d,
+	0xf7, 0x81, 0xb0, 0x70, 0x99, 0x1c, 0xc0, 0xa3, 0xd3, 0x70, 0x24, 0xc6, 0x6d, 0x89, 0x42, 0xc6,
+	0x16, 0xa4, 0x69, 0x34, 0xd7, 0x8d, 0x10, 0xf7, 0xc5, 0x7b, 0x43, 0xa7, 0x27, 0x2e, 0x5f, 0x99,
+	0x18, 0x18, 0xfc, 0x07, 0x0b, 0x8e, 0x4a, 0x53, 0x33, 0x87, 0x7d, 0x21, 0xbd, 0xfe, 0x67, 0x16,
+	0x20, 0x53, 0x02, 0xe5, 0x5a, 0xff, 0x6f, 0x36, 0xa7, 0x78, 0xa5, 0x54, 0x12, 0xef, 0x6c, 0x89,
+	0x8a, 0xfb, 0x4b, 0x18, 0xf2, 0x6d, 0xd9, 0x14, 0x14, 0x3f, 0x09, 0xc8, 0x87, 0xbc, 0xc4, 0x10,
+	0xf5, 0xcd, 0xfb, 0x0f, 0x77, 0x47, 0x8c, 0x86, 0xea, 0x19, 0x2e, 0xfa, 0x0f, 0x02, 0x41, 0xe4,
+	0x17, 0xdf, 0x8b, 0x7a, 0x4c, 0x78, 0x8d, 0x1d, 0xef, 0xa5, 0x50, 0x44, 0x0f, 0xf0, 0x7b, 0x19,
+	0x58, 0xb8, 0xe3, 0xf7, 0x86, 0x7d, 0xfa, 0x05, 0xd4, 0x73, 0xb2, 0x37, 0x90, 0xd3, 0xbd, 0x01,
+	0x04, 0x76, 0xc8, 0xe8, 0x40, 0x78, 0x56, 0x96, 0x88, 0x31, 0xc2, 0x50, 0x66, 0x4e, 0xd0, 0xa5,
+	0x4c, 0x3e, 0x9e, 0xaa, 0x79, 0x51, 0xd5, 0x26, 0x70, 0x68, 0x15, 0x4a, 0x4e, 0xb7, 0x1b, 0xd0,
+	0xae, 0xc3, 0x68, 0x6b, 0x54, 0x2d, 0x88, 0xcd, 0x4c, 0x14, 0x7e, 0x03, 0x16, 0xb5, 0xb2, 0x94,
+	0x49, 0x9f, 0x86, 0xc2, 0x3b, 0x02, 0x33, 0xa1, 0x57, 0x27, 0x49, 0x55, 0x18, 0xd3, 0x64, 0xc9,
+	0x1f, 0x56, 0xf4, 0x99, 0xf1, 0x75, 0xc8, 0x4b, 0x72, 0xde, 0x38, 0x8a, 0xab, 0x15, 0x59, 0x05,
+	0x72, 0x58, 0xbd, 0x67, 0x30, 0xe4, 0x25, 0xa3, 0x6a, 0x36, 0xf6, 0x0d, 0x89, 0x21, 0xea, 0x1b,
+	0xff, 0xdd, 0x82, 0x63, 0x1b, 0x94, 0xd1, 0x36, 0xa3, 0x1d, 0xd1, 0x6d, 0xf8, 0x5c, 0x5f, 0xe7,
+	0x51, 0x63, 0x2e, 0x6b, 0x34, 0xe6, 0x78, 0xdc, 0xe9, 0xb9, 0x1e, 0xdd, 0x34, 0x3a, 0x3b, 0x31,
+	0x22, 0xea, 0x07, 0xcb, 0x69, 0xf9, 0x4b, 0x96, 0x81, 0x89, 0x2c, 0x9c, 0x8f, 0x2d, 0x8c, 0xbf,
+	0x63, 0xc1, 0x4a, 0x5a, 0x6a, 0x65, 0xa4, 0x26, 0xe4, 0xc5, 0xe2, 0x09, 0x3d, 0xe1, 0xc4, 0x0a,
+	0xa2, 0xc8, 0xd0, 0xc5, 0xc4, 0xfe, 0xe2, 0x17, 0xb0, 0x56, 0x75, 0x7f, 0xdc, 0xa8, 0xc4, 0x58,
+	0xa3, 0x83, 0x60, 0xd0, 0xe2, 0xdf, 0xf2, 0x77, 0xb6, 0xc9, 0x53, 0xd8, 0x9b, 0xfb, 0x97, 0xee,
+	0x78, 0x0b, 0x00, 0x7d, 0x09, 0x6c, 0x36, 0x1a, 0xa8, 0x90, 0xdb, 0x3a, 0xf6, 0x8f, 0x71, 0xe3,
+	0x68, 0x62, 0xd9, 0xed, 0xd1, 0x80, 0x12, 0x41, 0xc2, 0xdd, 0xb2, 0xed, 0x04, 0x1d, 0xd7, 0x73,
+	0x7a, 0x2e, 0x93, 0x6a, 0xb4, 0x89, 0x89, 0x42, 0x55, 0x28, 0x0c, 0x9c, 0x20, 0xd4, 0x75, 0x53,
+	0x91, 0x68, 0x50, 0xb4, 0x40, 0x76, 0x29, 0x6b, 0xef, 0xc8, 0x30, 0xab, 0x5a, 0x20, 0x02, 0x93,
+	0x68, 0x81, 0x08, 0x0c, 0xfe, 0x89, 0xe1, 0x38, 0xf2, 0x4e, 0xfc, 0xd7, 0x39, 0x0e, 0x7e, 0x13,
+	0x56, 0xd2, 0x47, 0x54, 0x56, 0xe6, 0xdd, 0xac, 0xc4, 0xcc, 0x74, 0x6b, 0x8b, 0x79, 0x92, 0x22,
+	0xc7, 0xc3, 0xd8, 0x74, 0x02, 0x33, 0xc5, 0x74, 0x29, 0x7b, 0x64, 0x0e, 0xda, 0x23, 0xd6, 0x7a,
+	0xf6, 0x70, 0xad, 0x3f, 0xf9, 0x38, 0x14, 0xa3, 0x1f, 0x31, 0x51, 0x09, 0x0a, 0x57, 0x5e, 0x21,
+	0xaf, 0x5f, 0x26, 0x1b, 0x4b, 0x73, 0xa8, 0x0c, 0xf3, 0xad, 0xcb, 0xeb, 0x2f, 0x0b, 0xc8, 0x3a,
+	0xff, 0x8b, 0xbc, 0x2e, 0x04, 0x02, 0xf4, 0x55, 0xc8, 0xc9, 0xec, 0xbe, 0x12, 0x0b, 0x67, 0xfe,
+	0x54, 0x57, 0x3b, 0x7e, 0x00, 0x2f, 0xb5, 0x84, 0xe7, 0x9e, 0xb6, 0xd0, 0x4d, 0x28, 0x09, 0xa4,
+	0x6a, 0x3e, 0x9f, 0x4c, 0xf7, 0x80, 0x13, 0x9c, 0x4e, 0x4d, 0x99, 0x35, 0xf8, 0x5d, 0x82, 0x9c,
+	0x54, 0xd8, 0x4a, 0xaa, 0x08, 0x9b, 0x70, 0x9a, 0x44, 0x3b, 0x1e, 0xcf, 0xa1, 0xe7, 0xc0, 0xe6,
+	0x6d, 0x15, 0x64, 0xd4, 0x80, 0x46, 0xcf, 0xb8, 0xb6, 0x92, 0x46, 0x1b, 0xdb, 0xbe, 0x10, 0xb5,
+	0xbe, 0x8f, 0xa7, 0x5b, 0x69, 0x7a, 0x79, 0xf5, 0xe0, 0x44, 0xb4, 0xf3, 0x2b, 0x50, 0x36, 0x1b,
+	0x3a, 0xe8, 0x54, 0x72, 0xab, 0x54, 0xff, 0xa7, 0x56, 0x9f, 0x36, 0x1d, 0x31, 0xdc, 0x84, 0x92,
+	0xd1, 0x4c, 0x31, 0xd5, 0x7a, 0xb0, 0x13, 0x54, 0x3b, 0x35, 0x65, 0x36, 0xe2, 0x76, 0x15, 0xe6,
+	0x79, 0xe5, 0x2c, 0x7e, 0xa9, 0x39, 0x91, 0x2e, 0x90, 0x8d, 0xc2, 0xa8, 0x76, 0x72, 0xf2, 0x64,
+	0xc4, 0xe8, 0x1b, 0x50, 0xbc, 0x4a, 0x99, 0xca, 0x2e, 0xc7, 0xd3, 0xe9, 0x69, 0x82, 0xa6, 0x92,
+	0x29, 0x0e, 0xcf, 0xa1, 0x37, 0x44, 0x11, 0x9f, 0x0c, 0xae, 0xa8, 0x31, 0x25, 0x88, 0x46, 0xe7,
+	0x5a, 0x9d, 0x4e, 0x10, 0x71, 0x7e, 0x3d, 0xc1, 0x59, 0xe5, 0xe1, 0xc6, 0x94, 0x0b, 0x1b, 0x71,
+	0x6e, 0x1c, 0xf2, 0x67, 0x14, 0x3c, 0x77, 0xfe, 0x2d, 0xfd, 0x7f, 0x8c, 0x0d, 0x87, 0x39, 0xe8,
+	0x15, 0x58, 0x14, 0xba, 0x8c, 0xfe, 0xb0, 0x91, 0xf0, 0xf9, 0x03, 0xff, 0x0e, 0xa9, 0x9d, 0x9a,
+	0x32, 0xab, 0xd9, 0xb7, 0xde, 0xfa, 0xe0, 0xe3, 0xfa, 0xdc, 0x87, 0x1f, 0xd7, 0xe7, 0x3e, 0xfd,
+	0xb8, 0x6e, 0x7d, 0x7b, 0xaf, 0x6e, 0xfd, 0x7c, 0xaf, 0x6e, 0xbd, 0xbf, 0x57, 0xb7, 0x3e, 0xd8,
+	0xab, 0x5b, 0x7f, 0xd9, 0xab, 0x5b, 0x7f, 0xdd, 0xab, 0xcf, 0x7d, 0xba, 0x57, 0xb7, 0xde, 0xfd,
+	0xa4, 0x3e, 0xf7, 0xc1, 0x27, 0xf5, 0xb9, 0x0f, 0x3f, 0xa9, 0xcf, 0x7d, 0xf3, 0x89, 0xc3, 0x1f,
+	0xac, 0x32, 0x2c, 0xe6, 0xc5, 0xd7, 0x85, 0x7f, 0x0e, 0x00, 0xf9, 0xd6, 0x6a, 0xab, 0x35, 0x24,
+	0x00, 0x00,
 }
 
 func (x Direction) String() string {
@@ -3503,6 +3592,14 @@ func (this *QueryRequest) Equal(that interface{}) bool {
 	if !this.StoreChunks.Equal(that1.StoreChunks) {
 		return false
 	}
+	if len(this.FieldMasks) != len(that1.FieldMasks) {
+		return false
+	}
+	for i := range this.FieldMasks {
+		if !this.FieldMasks[i].Equal(that1.FieldMasks[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *SampleQueryRequest) Equal(that interface{}) bool {
@@ -3559,6 +3656,14 @@ func (this *SampleQueryRequest) Equal(that interface{}) bool {
 	if !this.StoreChunks.Equal(that1.StoreChunks) {
 		return false
 	}
+	if len(this.FieldMasks) != len(that1.FieldMasks) {
+		return false
+	}
+	for i := range this.FieldMasks {
+		if !this.FieldMasks[i].Equal(that1.FieldMasks[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *Plan) Equal(that interface{}) bool {
@@ -3615,6 +3720,36 @@ func (this *Delete) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *FieldMask) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*FieldMask)
+	if !ok {
+		that2, ok := that.(FieldMask)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Field != that1.Field {
+		return false
+	}
+	if this.FieldRegex != that1.FieldRegex {
+		return false
+	}
+	if this.Action != that1.Action {
+		return false
+	}
+	return true
+}
 func (this *QueryResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -3898,6 +4033,14 @@ func (this *TailRequest) Equal(that interface{}) bool {
 	} else if !this.Plan.Equal(*that1.Plan) {
 		return false
 	}
+	if len(this.FieldMasks) != len(that1.FieldMasks) {
+		return false
+	}
+	for i := range this.FieldMasks {
+		if !this.FieldMasks[i].Equal(that1.FieldMasks[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *TailResponse) Equal(that interface{}) bool {
@@ -5157,7 +5300,7 @@ func (this *QueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 13)
+	s := make([]string, 0, 14)
 	s = append(s, "&logproto.QueryRequest{")
 	s = append(s, "Selector: "+fmt.Sprintf("%#v", this.Selector)+",\n")
 	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
@@ -5172,6 +5315,9 @@ func (this *QueryRequest) GoString() string {
 	if this.StoreChunks != nil {
 		s = append(s, "StoreChunks: "+fmt.Sprintf("%#v", this.StoreChunks)+",\n")
 	}
+	if this.FieldMasks != nil {
+		s = append(s, "FieldMasks: "+fmt.Sprintf("%#v", this.FieldMasks)+",\n")
+	}
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5179,7 +5325,7 @@ func (this *SampleQueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 11)
+	s := make([]string, 0, 12)
 	s = append(s, "&logproto.SampleQueryRequest{")
 	s = append(s, "Selector: "+fmt.Sprintf("%#v", this.Selector)+",\n")
 	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
@@ -5192,6 +5338,9 @@ func (this *SampleQueryRequest) GoString() string {
 	if this.StoreChunks != nil {
 		s = append(s, "StoreChunks: "+fmt.Sprintf("%#v", this.StoreChunks)+",\n")
 	}
+	if this.FieldMasks != nil {
+		s = append(s, "FieldMasks: "+fmt.Sprintf("%#v", this.FieldMasks)+",\n")
+	}
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5217,6 +5366,18 @@ func (this *Delete) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *FieldMask) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&logproto.FieldMask{")
+	s = append(s, "Field: "+fmt.Sprintf("%#v", this.Field)+",\n")
+	s = append(s, "FieldRegex: "+fmt.Sprintf("%#v", this.FieldRegex)+",\n")
+	s = append(s, "Action: "+fmt.Sprintf("%#v", this.Action)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *QueryResponse) GoString() string {
 	if this == nil {
 		return "nil"
@@ -5310,13 +5471,16 @@ func (this *TailRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&logproto.TailRequest{")
 	s = append(s, "Query: "+fmt.Sprintf("%#v", this.Query)+",\n")
 	s = append(s, "DelayFor: "+fmt.Sprintf("%#v", this.DelayFor)+",\n")
 	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
 	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
 	s = append(s, "Plan: "+fmt.Sprintf("%#v", this.Plan)+",\n")
+	if this.FieldMasks != nil {
+		s = append(s, "FieldMasks: "+fmt.Sprintf("%#v", this.FieldMasks)+",\n")
+	}
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -6620,6 +6784,20 @@ func (m *QueryRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.FieldMasks) > 0 {
+		for iNdEx := len(m.FieldMasks) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.FieldMasks[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x5a
+		}
+	}
 	if m.StoreChunks != nil {
 		{
 			size, err := m.StoreChunks.MarshalToSizedBuffer(dAtA[:i])
@@ -6723,6 +6901,20 @@ func (m *SampleQueryRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.FieldMasks) > 0 {
+		for iNdEx := len(m.FieldMasks) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.FieldMasks[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x5a
+		}
+	}
 	if m.StoreChunks != nil {
 		{
 			size, err := m.StoreChunks.MarshalToSizedBuffer(dAtA[:i])
@@ -6866,6 +7058,50 @@ func (m *Delete) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *FieldMask) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FieldMask) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FieldMask) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Action) > 0 {
+		i -= len(m.Action)
+		copy(dAtA[i:], m.Action)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Action)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.FieldRegex) > 0 {
+		i -= len(m.FieldRegex)
+		copy(dAtA[i:], m.FieldRegex)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.FieldRegex)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *QueryResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -7219,6 +7455,20 @@ func (m *TailRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.FieldMasks) > 0 {
+		for iNdEx := len(m.FieldMasks) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.FieldMasks[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if m.Plan != nil {
 		{
 			size := m.Plan.Size()
@@ -9013,8 +9263,14 @@ func (m *QueryRequest) Size() (n int) {
 		l = m.StoreChunks.Size()
 		n += 1 + l + sovLogproto(uint64(l))
 	}
-	return n
-}
+	if len(m.FieldMasks) > 0 {
+		for _, e := range m.FieldMasks {
+			l = e.Size()
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
+	return n
+}
 
 func (m *SampleQueryRequest) Size() (n int) {
 	if m == nil {
@@ -9050,6 +9306,12 @@ func (m *SampleQueryRequest) Size() (n int) {
 		l = m.StoreChunks.Size()
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if len(m.FieldMasks) > 0 {
+		for _, e := range m.FieldMasks {
+			l = e.Size()
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -9085,6 +9347,27 @@ func (m *Delete) Size() (n int) {
 	return n
 }
 
+func (m *FieldMask) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	l = len(m.FieldRegex)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	l = len(m.Action)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	return n
+}
+
 func (m *QueryResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -9251,6 +9534,12 @@ func (m *TailRequest) Size() (n int) {
 		l = m.Plan.Size()
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if len(m.FieldMasks) > 0 {
+		for _, e := range m.FieldMasks {
+			l = e.Size()
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -10054,6 +10343,11 @@ func (this *QueryRequest) String() string {
 		repeatedStringForDeletes += strings.Replace(f.String(), "Delete", "Delete", 1) + ","
 	}
 	repeatedStringForDeletes += "}"
+	repeatedStringForFieldMasks := "[]*FieldMask{"
+	for _, f := range this.FieldMasks {
+		repeatedStringForFieldMasks += strings.Replace(f.String(), "FieldMask", "FieldMask", 1) + ","
+	}
+	repeatedStringForFieldMasks += "}"
 	s := strings.Join([]string{`&QueryRequest{`,
 		`Selector:` + fmt.Sprintf("%v", this.Selector) + `,`,
 		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
@@ -10064,6 +10358,7 @@ func (this *QueryRequest) String() string {
 		`Deletes:` + repeatedStringForDeletes + `,`,
 		`Plan:` + fmt.Sprintf("%v", this.Plan) + `,`,
 		`StoreChunks:` + strings.Replace(this.StoreChunks.String(), "ChunkRefGroup", "ChunkRefGroup", 1) + `,`,
+		`FieldMasks:` + repeatedStringForFieldMasks + `,`,
 		`}`,
 	}, "")
 	return s
@@ -10077,6 +10372,11 @@ func (this *SampleQueryRequest) String() string {
 		repeatedStringForDeletes += strings.Replace(f.String(), "Delete", "Delete", 1) + ","
 	}
 	repeatedStringForDeletes += "}"
+	repeatedStringForFieldMasks := "[]*FieldMask{"
+	for _, f := range this.FieldMasks {
+		repeatedStringForFieldMasks += strings.Replace(f.String(), "FieldMask", "FieldMask", 1) + ","
+	}
+	repeatedStringForFieldMasks += "}"
 	s := strings.Join([]string{`&SampleQueryRequest{`,
 		`Selector:` + fmt.Sprintf("%v", this.Selector) + `,`,
 		`Start:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Start), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
@@ -10085,6 +10385,7 @@ func (this *SampleQueryRequest) String() string {
 		`Deletes:` + repeatedStringForDeletes + `,`,
 		`Plan:` + fmt.Sprintf("%v", this.Plan) + `,`,
 		`StoreChunks:` + strings.Replace(this.StoreChunks.String(), "ChunkRefGroup", "ChunkRefGroup", 1) + `,`,
+		`FieldMasks:` + repeatedStringForFieldMasks + `,`,
 		`}`,
 	}, "")
 	return s
@@ -10111,6 +10412,18 @@ func (this *Delete) String() string {
 	}, "")
 	return s
 }
+func (this *FieldMask) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FieldMask{`,
+		`Field:` + fmt.Sprintf("%v", this.Field) + `,`,
+		`FieldRegex:` + fmt.Sprintf("%v", this.FieldRegex) + `,`,
+		`Action:` + fmt.Sprintf("%v", this.Action) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryResponse) String() string {
 	if this == nil {
 		return "nil"
@@ -10203,12 +10516,18 @@ func (this *TailRequest) String() string {
 	if this == nil {
 		return "nil"
 	}
+	repeatedStringForFieldMasks := "[]*FieldMask{"
+	for _, f := range this.FieldMasks {
+		repeatedStringForFieldMasks += strings.Replace(f.String(), "FieldMask", "FieldMask", 1) + ","
+	}
+	repeatedStringForFieldMasks += "}"
 	s := strings.Join([]string{`&TailRequest{`,
 		`Query:` + fmt.Sprintf("%v", this.Query) + `,`,
 		`DelayFor:` + fmt.Sprintf("%v", this.DelayFor) + `,`,
 		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
 		`Start:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Start), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Plan:` + fmt.Sprintf("%v", this.Plan) + `,`,
+		`FieldMasks:` + repeatedStringForFieldMasks + `,`,
 		`}`,
 	}, "")
 	return s
@@ -11587,6 +11906,40 @@ func (m *QueryRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FieldMasks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FieldMasks = append(m.FieldMasks, &FieldMask{})
+			if err := m.FieldMasks[len(m.FieldMasks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -11876,6 +12229,40 @@ func (m *SampleQueryRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FieldMasks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FieldMasks = append(m.FieldMasks, &FieldMask{})
+			if err := m.FieldMasks[len(m.FieldMasks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -12110,6 +12497,155 @@ func (m *Delete) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *FieldMask) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FieldMask: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FieldMask: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FieldRegex", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FieldRegex = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Action", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Action = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -13199,6 +13735,40 @@ func (m *TailRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FieldMasks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FieldMasks = append(m.FieldMasks, &FieldMask{})
+			if err := m.FieldMasks[len(m.FieldMasks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -357,6 +357,41 @@ func (e *MergeLastOverTimeExpr) Walk(f syntax.WalkFn) {
 	}
 }
 
+// IsFullySharded returns true if all the logs read by a mapped expression are read by downstream
+// expressions targeting a shard, i.e. if none of its subexpressions must be evaluated over all the
+// data at once.
+func IsFullySharded(expr syntax.Expr) bool {
+	sharded := true
+	check := func(e syntax.Expr) {
+		switch e := e.(type) {
+		case DownstreamSampleExpr:
+			sharded = sharded && e.shard != nil
+		case *ConcatSampleExpr:
+			for cur := e; cur != nil; cur = cur.next {
+				sharded = sharded && cur.shard != nil
+			}
+		case *ConcatLogSelectorExpr:
+			for cur := e; cur != nil; cur = cur.next {
+				sharded = sharded && cur.shard != nil
+			}
+		case DownstreamLogSelectorExpr:
+			sharded = sharded && e.shard != nil
+		case *syntax.LogRange, syntax.LogSelectorExpr:
+			// logs read by the evaluator itself.
+			sharded = false
+		}
+	}
+	// The downstream expressions of log queries are only found at the root, and walking them
+	// would visit the selectors they embed.
+	switch expr.(type) {
+	case *ConcatLogSelectorExpr, DownstreamLogSelectorExpr:
+		check(expr)
+	default:
+		expr.Walk(check)
+	}
+	return sharded
+}
+
 type Downstreamable interface {
 	Downstreamer(context.Context) Downstreamer
 }
@@ -752,3 +752,23 @@ and
     10`
 	assert.Equal(t, expected, got)
 }
+
+func TestIsFullySharded(t *testing.T) {
+	for _, tc := range []struct {
+		query   string
+		sharded bool
+	}{
+		{query: `{foo="bar"} |= "error"`, sharded: true},
+		{query: `sum by (cluster) (rate({foo="bar"}[1m]))`, sharded: true},
+		{query: `sum(rate({foo="bar"}[1m])) / sum(rate({foo="baz"}[1m]))`, sharded: true},
+		{query: `quantile_over_time(0.5, {foo="bar"} | json | unwrap bytes [1d]) by (cluster)`, sharded: false},
+		{query: `quantile_over_time(0.5, {foo="bar"} | json | unwrap bytes [1d]) by (cluster) > sum(rate({foo="baz"}[1m]))`, sharded: false},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			mapper := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(2)), nilShardMetrics, nil)
+			_, _, mapped, err := mapper.Parse(syntax.MustParseExpr(tc.query))
+			require.NoError(t, err)
+			require.Equal(t, tc.sharded, IsFullySharded(mapped))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/regexp"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// Field masking actions.
+const (
+	// MaskHash replaces the value of the field with a hash of the value.
+	MaskHash = "hash"
+	// MaskRedact replaces the value of the field with RedactedValue.
+	MaskRedact = "redact"
+	// MaskDrop removes the field.
+	MaskDrop = "drop"
+)
+
+// RedactedValue is the value of the redacted fields.
+const RedactedValue = "<redacted>"
+
+// FieldMaskRule masks the fields whose name is Field or matches FieldRegex.
+type FieldMaskRule struct {
+	Field      string
+	FieldRegex string
+	Action     string
+}
+
+type fieldMask struct {
+	name   string
+	regex  *regexp.Regexp
+	prefix string // literal prefix of the names matching regex
+	action string
+}
+
+// FieldMasker masks the structured metadata and parsed labels of log lines.
+// Stream labels are never masked.
+type FieldMasker struct {
+	masks []fieldMask
+}
+
+// NewFieldMasker creates a FieldMasker from rules. The first rule matching a field is applied.
+func NewFieldMasker(rules []FieldMaskRule) (*FieldMasker, error) {
+	masks := make([]fieldMask, 0, len(rules))
+	for _, r := range rules {
+		switch r.Action {
+		case MaskHash, MaskRedact, MaskDrop:
+		default:
+			return nil, fmt.Errorf("invalid field mask action %q, must be one of %s, %s or %s", r.Action, MaskHash, MaskRedact, MaskDrop)
+		}
+
+		m := fieldMask{name: r.Field, action: r.Action}
+		switch {
+		case r.Field != "" && r.FieldRegex != "":
+			return nil, errors.New("field mask must have either a field or a field regex, not both")
+		case r.FieldRegex != "":
+			re, err := regexp.Compile("^(?:" + r.FieldRegex + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid field mask regex %q: %w", r.FieldRegex, err)
+			}
+			m.regex = re
+			m.prefix, _ = re.LiteralPrefix()
+		case r.Field == "":
+			return nil, errors.New("field mask must have a field or a field regex")
+		}
+		masks = append(masks, m)
+	}
+	return &FieldMasker{masks: masks}, nil
+}
+
+// Masked returns true if the field is masked.
+func (m *FieldMasker) Masked(name string) bool {
+	_, ok := m.Action(name)
+	return ok
+}
+
+// Mask returns the masked value of a field, or false if the field must be dropped.
+// The value of fields which are not masked is returned unchanged.
+func (m *FieldMasker) Mask(name, value string) (string, bool) {
+	action, ok := m.Action(name)
+	if !ok {
+		return value, true
+	}
+	switch action {
+	case MaskHash:
+		h := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(h[:8]), true
+	case MaskRedact:
+		return RedactedValue, true
+	default:
+		return "", false
+	}
+}
+
+// Action returns the action of the first rule masking the field, or false if the field is not masked.
+func (m *FieldMasker) Action(name string) (string, bool) {
+	if isSpecialLabel(name) {
+		return "", false
+	}
+	for _, mask := range m.masks {
+		if mask.regex != nil && mask.regex.MatchString(name) || mask.regex == nil && mask.name == name {
+			return mask.action, true
+		}
+	}
+	return "", false
+}
+
+// MaskedWithPrefix returns true if a masked field can have a name starting with prefix.
+// Rules with a regex are only compared on the literal prefix of the regex, so it can return
+// true for fields which are not masked.
+func (m *FieldMasker) MaskedWithPrefix(prefix string) bool {
+	for _, mask := range m.masks {
+		if mask.regex == nil && strings.HasPrefix(mask.name, prefix) ||
+			mask.regex != nil && (strings.HasPrefix(mask.prefix, prefix) || strings.HasPrefix(prefix, mask.prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskResult masks the structured metadata and parsed labels of the result.
+// Grouped results are not categorized, so their labels which are not labels of the stream are masked instead.
+func (m *FieldMasker) maskResult(lr LabelsResult, stream labels.Labels, h *hasher) LabelsResult {
+	if len(lr.StructuredMetadata()) == 0 && len(lr.Parsed()) == 0 {
+		lbls, changed := m.maskLabels(lr.Stream(), func(name string) bool { return stream.Has(name) })
+		if !changed {
+			return lr
+		}
+		return NewLabelsResult(lbls.String(), h.Hash(lbls), lbls, nil, nil)
+	}
+
+	structuredMetadata, structuredMetadataChanged := m.maskLabels(lr.StructuredMetadata(), nil)
+	parsed, parsedChanged := m.maskLabels(lr.Parsed(), nil)
+	if !structuredMetadataChanged && !parsedChanged {
+		return lr
+	}
+	all := flattenLabels(nil, lr.Stream(), structuredMetadata, parsed)
+	return NewLabelsResult(all.String(), h.Hash(all), lr.Stream(), structuredMetadata, parsed)
+}
+
+// maskLabels returns the masked labels, or the labels unchanged if none is masked.
+func (m *FieldMasker) maskLabels(lbls labels.Labels, skip func(string) bool) (labels.Labels, bool) {
+	var res labels.Labels
+	changed := false
+	for i, l := range lbls {
+		value, keep := l.Value, true
+		if skip == nil || !skip(l.Name) {
+			value, keep = m.Mask(l.Name, l.Value)
+		}
+		if keep && value == l.Value {
+			if changed {
+				res = append(res, l)
+			}
+			continue
+		}
+		if !changed {
+			res = make(labels.Labels, i, len(lbls))
+			copy(res, lbls[:i])
+			changed = true
+		}
+		if keep {
+			res = append(res, labels.Label{Name: l.Name, Value: value})
+		}
+	}
+	if !changed {
+		return lbls, false
+	}
+	return res, true
+}
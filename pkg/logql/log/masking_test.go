@@ -0,0 +1,106 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFieldMasker(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rules []FieldMaskRule
+		err   bool
+	}{
+		{name: "field", rules: []FieldMaskRule{{Field: "email", Action: MaskHash}}},
+		{name: "regex", rules: []FieldMaskRule{{FieldRegex: "user_.+", Action: MaskDrop}}},
+		{name: "invalid action", rules: []FieldMaskRule{{Field: "email", Action: "encrypt"}}, err: true},
+		{name: "invalid regex", rules: []FieldMaskRule{{FieldRegex: "user_(", Action: MaskDrop}}, err: true},
+		{name: "field and regex", rules: []FieldMaskRule{{Field: "email", FieldRegex: "user_.+", Action: MaskDrop}}, err: true},
+		{name: "no field", rules: []FieldMaskRule{{Action: MaskRedact}}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewFieldMasker(tc.rules)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFieldMasker_Mask(t *testing.T) {
+	m, err := NewFieldMasker([]FieldMaskRule{
+		{Field: "email", Action: MaskHash},
+		{FieldRegex: "user_.+", Action: MaskRedact},
+		{Field: "user_token", Action: MaskDrop}, // shadowed by the regex rule.
+		{FieldRegex: ".*", Action: MaskDrop},
+	})
+	require.NoError(t, err)
+
+	v, keep := m.Mask("email", "bob@example.com")
+	require.True(t, keep)
+	require.Len(t, v, 16)
+	v2, _ := m.Mask("email", "bob@example.com")
+	require.Equal(t, v, v2)
+
+	v, keep = m.Mask("user_token", "secret")
+	require.True(t, keep)
+	require.Equal(t, RedactedValue, v)
+
+	_, keep = m.Mask("anything", "value")
+	require.False(t, keep)
+
+	v, keep = m.Mask("__error__", "JSONParserErr")
+	require.True(t, keep)
+	require.Equal(t, "JSONParserErr", v)
+	require.False(t, m.Masked("__error__"))
+}
+
+func TestMaskingPipeline(t *testing.T) {
+	m, err := NewFieldMasker([]FieldMaskRule{
+		{Field: "email", Action: MaskRedact},
+		{Field: "token", Action: MaskDrop},
+		{Field: "app", Action: MaskDrop}, // stream labels are never masked.
+	})
+	require.NoError(t, err)
+
+	lbs := labels.FromStrings("app", "foo")
+	p := NewMaskingPipeline(m, NewPipeline([]Stage{
+		NewJSONParser(),
+		// label filters see the values before masking.
+		NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "email", "bob@example.com")),
+	}))
+
+	line := `{"email":"bob@example.com","level":"info"}`
+	for i := 0; i < 2; i++ { // the second run uses the cache.
+		l, lbr, matches := p.ForStream(lbs).ProcessString(0, line, labels.Label{Name: "token", Value: "secret"})
+		require.True(t, matches)
+		require.Equal(t, line, l)
+		require.Equal(t, labels.FromStrings("app", "foo"), lbr.Stream())
+		require.Nil(t, lbr.StructuredMetadata())
+		require.Equal(t, labels.FromStrings("email", RedactedValue, "level", "info"), lbr.Parsed())
+		require.Equal(t, labels.FromStrings("app", "foo", "email", RedactedValue, "level", "info").String(), lbr.String())
+	}
+
+	_, _, matches := p.ForStream(lbs).Process(0, []byte(`{"email":"alice@example.com"}`))
+	require.False(t, matches)
+}
+
+func TestMaskingSampleExtractor(t *testing.T) {
+	m, err := NewFieldMasker([]FieldMaskRule{{Field: "email", Action: MaskRedact}})
+	require.NoError(t, err)
+
+	lbs := labels.FromStrings("app", "foo")
+	for _, groups := range [][]string{nil, {"app", "email"}} {
+		ex, err := NewLineSampleExtractor(CountExtractor, []Stage{NewJSONParser()}, groups, false, false)
+		require.NoError(t, err)
+
+		v, lbr, ok := NewMaskingSampleExtractor(m, ex).ForStream(lbs).Process(0, []byte(`{"email":"bob@example.com"}`))
+		require.True(t, ok)
+		require.Equal(t, 1., v)
+		require.Equal(t, labels.FromStrings("app", "foo", "email", RedactedValue).String(), lbr.String())
+	}
+}
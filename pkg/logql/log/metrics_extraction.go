@@ -2,6 +2,7 @@ package log
 
 import (
 	"context"
+	"reflect"
 	"sort"
 	"strconv"
 	"time"
@@ -26,6 +27,21 @@ var (
 	BytesExtractor LineExtractor = func(line []byte) float64 { return float64(len(line)) }
 )
 
+// lineSizeExtractor extracts a float64 from the size of a log line.
+type lineSizeExtractor func(size int) float64
+
+// lineSizeExtractorFor returns the lineSizeExtractor equivalent to ex, if any.
+func lineSizeExtractorFor(ex LineExtractor) lineSizeExtractor {
+	switch reflect.ValueOf(ex).Pointer() {
+	case reflect.ValueOf(CountExtractor).Pointer():
+		return func(int) float64 { return 1. }
+	case reflect.ValueOf(BytesExtractor).Pointer():
+		return func(size int) float64 { return float64(size) }
+	default:
+		return nil
+	}
+}
+
 // SampleExtractor creates StreamSampleExtractor that can extract samples for a given log stream.
 type SampleExtractor interface {
 	ForStream(labels labels.Labels) StreamSampleExtractor
@@ -40,6 +56,18 @@ type StreamSampleExtractor interface {
 	ReferencedStructuredMetadata() bool
 }
 
+// LineSizeSampleExtractor is implemented by StreamSampleExtractors which may extract
+// samples without the content of the log lines, like count_over_time and bytes_over_time
+// whose stages only filter on stream labels and structured metadata.
+// Chunks storing log lines apart from their timestamps use it to skip decoding them.
+type LineSizeSampleExtractor interface {
+	// RequiresLine returns whether the content of the line is needed to extract samples.
+	RequiresLine() bool
+	// ProcessLineSize extracts a sample given the size of the line instead of its content.
+	// It must only be called if RequiresLine returns false.
+	ProcessLineSize(ts int64, size int, structuredMetadata ...labels.Label) (float64, LabelsResult, bool)
+}
+
 // SampleExtractorWrapper takes an extractor, wraps it is some desired functionality
 // and returns a new pipeline
 type SampleExtractorWrapper interface {
@@ -50,6 +78,11 @@ type lineSampleExtractor struct {
 	Stage
 	LineExtractor
 
+	// sizeExtractor is set when the samples can be extracted from the size of the lines only.
+	sizeExtractor lineSizeExtractor
+	// stages are the stages reduced into Stage, kept to match sketches.
+	stages []Stage
+
 	baseBuilder      *BaseLabelsBuilder
 	streamExtractors map[uint64]StreamSampleExtractor
 }
@@ -59,9 +92,15 @@ type lineSampleExtractor struct {
 func NewLineSampleExtractor(ex LineExtractor, stages []Stage, groups []string, without, noLabels bool) (SampleExtractor, error) {
 	s := ReduceStages(stages)
 	hints := NewParserHint(s.RequiredLabelNames(), groups, without, noLabels, "", stages)
+	var sizeExtractor lineSizeExtractor
+	if onlyLabelFilters(stages) {
+		sizeExtractor = lineSizeExtractorFor(ex)
+	}
 	return &lineSampleExtractor{
 		Stage:            s,
 		LineExtractor:    ex,
+		sizeExtractor:    sizeExtractor,
+		stages:           stages,
 		baseBuilder:      NewBaseLabelsBuilderWithGrouping(groups, hints, without, noLabels),
 		streamExtractors: make(map[uint64]StreamSampleExtractor),
 	}, nil
@@ -76,16 +115,31 @@ func (l *lineSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtrac
 	res := &streamLineSampleExtractor{
 		Stage:         l.Stage,
 		LineExtractor: l.LineExtractor,
+		sizeExtractor: l.sizeExtractor,
+		stages:        l.stages,
 		builder:       l.baseBuilder.ForLabels(labels, hash),
 	}
 	l.streamExtractors[hash] = res
 	return res
 }
 
+// onlyLabelFilters returns whether all stages are label filters, which do not read the log line.
+// Without parser stages, label filters only see the stream labels and the structured metadata.
+func onlyLabelFilters(stages []Stage) bool {
+	for _, s := range stages {
+		if _, ok := s.(LabelFilterer); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 type streamLineSampleExtractor struct {
 	Stage
 	LineExtractor
-	builder *LabelsBuilder
+	sizeExtractor lineSizeExtractor
+	stages        []Stage
+	builder       *LabelsBuilder
 }
 
 func (l *streamLineSampleExtractor) ReferencedStructuredMetadata() bool {
@@ -113,12 +167,30 @@ func (l *streamLineSampleExtractor) ProcessString(ts int64, line string, structu
 	return l.Process(ts, unsafeGetBytes(line), structuredMetadata...)
 }
 
+func (l *streamLineSampleExtractor) RequiresLine() bool {
+	return l.sizeExtractor == nil
+}
+
+func (l *streamLineSampleExtractor) ProcessLineSize(ts int64, size int, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
+	l.builder.Reset()
+	l.builder.Add(StructuredMetadataLabel, structuredMetadata...)
+
+	if l.Stage != NoopStage {
+		// label filters return the line as is, there is no need for its content.
+		if _, ok := l.Stage.Process(ts, nil, l.builder); !ok {
+			return 0, nil, false
+		}
+	}
+	return l.sizeExtractor(size), l.builder.GroupedLabels(), true
+}
+
 func (l *streamLineSampleExtractor) BaseLabels() LabelsResult { return l.builder.currentResult }
 
 type convertionFn func(value string) (float64, error)
 
 type labelSampleExtractor struct {
 	preStage     Stage
+	preStages    []Stage
 	postFilter   Stage
 	labelName    string
 	conversionFn convertionFn
@@ -156,6 +228,7 @@ func LabelExtractorWithStages(
 	hints := NewParserHint(append(preStage.RequiredLabelNames(), postFilter.RequiredLabelNames()...), groups, without, noLabels, labelName, append(preStages, postFilter))
 	return &labelSampleExtractor{
 		preStage:         preStage,
+		preStages:        preStages,
 		conversionFn:     convFn,
 		labelName:        labelName,
 		postFilter:       postFilter,
@@ -301,6 +374,85 @@ func (sp *filteringStreamExtractor) ProcessString(ts int64, line string, structu
 	return sp.extractor.ProcessString(ts, line)
 }
 
+// NewMaskingSampleExtractor creates a sample extractor masking the structured
+// metadata and parsed labels of the samples, once they are extracted.
+func NewMaskingSampleExtractor(m *FieldMasker, e SampleExtractor) SampleExtractor {
+	return &maskingSampleExtractor{
+		masker:    m,
+		extractor: e,
+	}
+}
+
+type maskingSampleExtractor struct {
+	masker    *FieldMasker
+	extractor SampleExtractor
+}
+
+func (p *maskingSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtractor {
+	return &maskingStreamExtractor{
+		masker:    p.masker,
+		stream:    labels,
+		extractor: p.extractor.ForStream(labels),
+		hasher:    newHasher(),
+		cache:     make(map[uint64]LabelsResult),
+	}
+}
+
+type maskingStreamExtractor struct {
+	masker    *FieldMasker
+	stream    labels.Labels
+	extractor StreamSampleExtractor
+	hasher    *hasher
+	// cache of the masked results by hash of the results of the extractor.
+	cache map[uint64]LabelsResult
+}
+
+func (sp *maskingStreamExtractor) ReferencedStructuredMetadata() bool {
+	return sp.extractor.ReferencedStructuredMetadata()
+}
+
+func (sp *maskingStreamExtractor) BaseLabels() LabelsResult {
+	return sp.extractor.BaseLabels()
+}
+
+func (sp *maskingStreamExtractor) Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
+	v, lr, ok := sp.extractor.Process(ts, line, structuredMetadata...)
+	if !ok {
+		return 0, nil, false
+	}
+	return v, sp.mask(lr), true
+}
+
+func (sp *maskingStreamExtractor) RequiresLine() bool {
+	ls, ok := sp.extractor.(LineSizeSampleExtractor)
+	return !ok || ls.RequiresLine()
+}
+
+func (sp *maskingStreamExtractor) ProcessLineSize(ts int64, size int, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
+	v, lr, ok := sp.extractor.(LineSizeSampleExtractor).ProcessLineSize(ts, size, structuredMetadata...)
+	if !ok {
+		return 0, nil, false
+	}
+	return v, sp.mask(lr), true
+}
+
+func (sp *maskingStreamExtractor) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
+	v, lr, ok := sp.extractor.ProcessString(ts, line, structuredMetadata...)
+	if !ok {
+		return 0, nil, false
+	}
+	return v, sp.mask(lr), true
+}
+
+func (sp *maskingStreamExtractor) mask(lr LabelsResult) LabelsResult {
+	if masked, ok := sp.cache[lr.Hash()]; ok {
+		return masked
+	}
+	masked := sp.masker.maskResult(lr, sp.stream, sp.hasher)
+	sp.cache[lr.Hash()] = masked
+	return masked
+}
+
 func convertFloat(v string) (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
@@ -419,6 +419,48 @@ func TestNewLineSampleExtractorWithStructuredMetadata(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestNewLineSampleExtractor_LineSize(t *testing.T) {
+	lbs := labels.FromStrings("foo", "bar")
+	structuredMetadata := labels.FromStrings("user", "bob")
+	labelFilter := NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "user", "bob"))
+
+	for _, tc := range []struct {
+		name         string
+		ex           LineExtractor
+		stages       []Stage
+		requiresLine bool
+		expected     float64
+	}{
+		{"count", CountExtractor, nil, false, 1},
+		{"bytes", BytesExtractor, nil, false, 3},
+		{"structured metadata filter", BytesExtractor, []Stage{labelFilter}, false, 3},
+		{"line filter", CountExtractor, []Stage{labelFilter, mustFilter(NewFilter("foo", LineMatchEqual)).ToStage()}, true, 0},
+		{"parser", CountExtractor, []Stage{NewLogfmtParser(false, false)}, true, 0},
+		{"custom extractor", func([]byte) float64 { return 2 }, nil, true, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			se, err := NewLineSampleExtractor(tc.ex, tc.stages, nil, false, false)
+			require.NoError(t, err)
+
+			sse := se.ForStream(lbs).(LineSizeSampleExtractor)
+			require.Equal(t, tc.requiresLine, sse.RequiresLine())
+			if tc.requiresLine {
+				return
+			}
+
+			f, l, ok := sse.ProcessLineSize(0, 3, structuredMetadata...)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, f)
+			assertLabelResult(t, append(lbs, structuredMetadata...), l)
+		})
+	}
+
+	se, err := NewLineSampleExtractor(CountExtractor, []Stage{labelFilter}, nil, false, false)
+	require.NoError(t, err)
+	_, _, ok := se.ForStream(lbs).(LineSizeSampleExtractor).ProcessLineSize(0, 3, labels.FromStrings("user", "alice")...)
+	require.False(t, ok)
+}
+
 func TestFilteringSampleExtractor(t *testing.T) {
 	se := NewFilteringSampleExtractor([]PipelineFilter{
 		newPipelineFilter(2, 4, labels.FromStrings("foo", "bar", "bar", "baz"), nil, "e"),
@@ -346,6 +346,77 @@ func (sp *filteringStreamPipeline) ProcessString(ts int64, line string, structur
 	return sp.pipeline.ProcessString(ts, line, structuredMetadata...)
 }
 
+// NewMaskingPipeline creates a pipeline masking the structured metadata and
+// parsed labels of the entries, once they went through the whole pipeline.
+// Label filters of the pipeline therefore see the values before masking.
+func NewMaskingPipeline(m *FieldMasker, p Pipeline) Pipeline {
+	return &maskingPipeline{
+		masker:   m,
+		pipeline: p,
+	}
+}
+
+type maskingPipeline struct {
+	masker   *FieldMasker
+	pipeline Pipeline
+}
+
+func (p *maskingPipeline) ForStream(labels labels.Labels) StreamPipeline {
+	return &maskingStreamPipeline{
+		masker:   p.masker,
+		stream:   labels,
+		pipeline: p.pipeline.ForStream(labels),
+		hasher:   newHasher(),
+		cache:    make(map[uint64]LabelsResult),
+	}
+}
+
+func (p *maskingPipeline) Reset() {
+	p.pipeline.Reset()
+}
+
+type maskingStreamPipeline struct {
+	masker   *FieldMasker
+	stream   labels.Labels
+	pipeline StreamPipeline
+	hasher   *hasher
+	// cache of the masked results by hash of the results of the pipeline.
+	cache map[uint64]LabelsResult
+}
+
+func (sp *maskingStreamPipeline) ReferencedStructuredMetadata() bool {
+	return sp.pipeline.ReferencedStructuredMetadata()
+}
+
+func (sp *maskingStreamPipeline) BaseLabels() LabelsResult {
+	return sp.pipeline.BaseLabels()
+}
+
+func (sp *maskingStreamPipeline) Process(ts int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
+	line, lr, ok := sp.pipeline.Process(ts, line, structuredMetadata...)
+	if !ok {
+		return nil, nil, false
+	}
+	return line, sp.mask(lr), true
+}
+
+func (sp *maskingStreamPipeline) ProcessString(ts int64, line string, structuredMetadata ...labels.Label) (string, LabelsResult, bool) {
+	line, lr, ok := sp.pipeline.ProcessString(ts, line, structuredMetadata...)
+	if !ok {
+		return "", nil, false
+	}
+	return line, sp.mask(lr), true
+}
+
+func (sp *maskingStreamPipeline) mask(lr LabelsResult) LabelsResult {
+	if masked, ok := sp.cache[lr.Hash()]; ok {
+		return masked
+	}
+	masked := sp.masker.maskResult(lr, sp.stream, sp.hasher)
+	sp.cache[lr.Hash()] = masked
+	return masked
+}
+
 // ReduceStages reduces multiple stages into one.
 func ReduceStages(stages []Stage) Stage {
 	if len(stages) == 0 {
@@ -0,0 +1,153 @@
+package log
+
+import (
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// Sketch summarises a set of log lines, like a block of a chunk, to tell
+// without reading them whether some of the lines may pass a query.
+// Its Checker methods return false only if none of the lines can satisfy the test.
+type Sketch interface {
+	Checker
+	// MayHaveStructuredMetadata returns false only if none of the lines has the structured metadata name.
+	MayHaveStructuredMetadata(name string) bool
+}
+
+// SketchMatcher is implemented by StreamPipelines and StreamSampleExtractors
+// which can tell from a Sketch that none of the lines it summarises can pass them.
+type SketchMatcher interface {
+	// MatchesSketch returns false if none of the lines summarised by s can pass.
+	MatchesSketch(s Sketch) bool
+}
+
+// lineFilterStage is the stage of a line filter, which keeps the filter
+// around to match it against sketches.
+type lineFilterStage struct {
+	Stage
+	filter Filterer
+}
+
+// NewLineFilterStage returns the stage filtering lines with f.
+func NewLineFilterStage(f Filterer) Stage {
+	stage := f.ToStage()
+	if stage == NoopStage {
+		return stage
+	}
+	return &lineFilterStage{Stage: stage, filter: f}
+}
+
+// stagesMatchSketch returns false if none of the lines summarised by s can pass the stages.
+// Only the leading line and label filters are matched, the stages after the first
+// one which may modify the line or the labels are not.
+func stagesMatchSketch(stages []Stage, lbs *LabelsBuilder, s Sketch) bool {
+	for _, stage := range stages {
+		switch st := stage.(type) {
+		case *lineFilterStage:
+			if !filterMayMatch(st.filter, s) {
+				return false
+			}
+		case LabelFilterer:
+			if !labelFilterMayMatch(st, lbs, s) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// filterMayMatch returns false if f can't match any of the lines summarised by test.
+// Unlike Matcher.Matches, it never negates the result of a test, which would
+// turn a line that may match into one that can't.
+func filterMayMatch(f Filterer, test Checker) bool {
+	switch f := f.(type) {
+	case *containsFilter:
+		return test.Test(f.match, f.caseInsensitive, false)
+	case equalFilter:
+		return test.Test(f.match, f.caseInsensitive, true)
+	case containsAllFilter:
+		return f.Matches(test)
+	case *containsAllFilter:
+		return f.Matches(test)
+	case andFilter:
+		return filterMayMatch(f.left, test) && filterMayMatch(f.right, test)
+	case andFilters:
+		for _, filter := range f.filters {
+			if !filterMayMatch(filter, test) {
+				return false
+			}
+		}
+		return true
+	case orFilter:
+		return filterMayMatch(f.left, test) || filterMayMatch(f.right, test)
+	default:
+		return true
+	}
+}
+
+// labelFilterMayMatch returns false if f can't match any of the lines summarised by s,
+// because it requires a structured metadata none of them has.
+// As it runs before any parser, the labels it sees are the stream labels and the structured metadata.
+func labelFilterMayMatch(f LabelFilterer, lbs *LabelsBuilder, s Sketch) bool {
+	var m *labels.Matcher
+	switch f := f.(type) {
+	case *BinaryLabelFilter:
+		if f.And {
+			return labelFilterMayMatch(f.Left, lbs, s) && labelFilterMayMatch(f.Right, lbs, s)
+		}
+		return labelFilterMayMatch(f.Left, lbs, s) || labelFilterMayMatch(f.Right, lbs, s)
+	case *StringLabelFilter:
+		m = f.Matcher
+	case *LineFilterLabelFilter:
+		m = f.Matcher
+	default:
+		return true
+	}
+	// internal labels, like __error__, are not stored as structured metadata.
+	if strings.HasPrefix(m.Name, "__") || m.Matches("") || lbs.BaseHas(m.Name) {
+		return true
+	}
+	return s.MayHaveStructuredMetadata(m.Name)
+}
+
+func (p *streamPipeline) MatchesSketch(s Sketch) bool {
+	return stagesMatchSketch(p.stages, p.builder, s)
+}
+
+// MatchesSketch implements SketchMatcher. Lines can only be dropped by the
+// filters of deletes, so the sketch is matched against the queried pipeline only.
+func (sp *filteringStreamPipeline) MatchesSketch(s Sketch) bool {
+	return matchesSketch(sp.pipeline, s)
+}
+
+func (sp *maskingStreamPipeline) MatchesSketch(s Sketch) bool {
+	return matchesSketch(sp.pipeline, s)
+}
+
+func (sp *filteringStreamExtractor) MatchesSketch(s Sketch) bool {
+	return matchesSketch(sp.extractor, s)
+}
+
+func (l *streamLineSampleExtractor) MatchesSketch(s Sketch) bool {
+	return stagesMatchSketch(l.stages, l.builder, s)
+}
+
+func (l *streamLabelSampleExtractor) MatchesSketch(s Sketch) bool {
+	return stagesMatchSketch(l.preStages, l.builder, s)
+}
+
+func (l *maskingStreamExtractor) MatchesSketch(s Sketch) bool {
+	return matchesSketch(l.extractor, s)
+}
+
+// matchesSketch returns false if the lines summarised by s can't pass v,
+// a StreamPipeline or a StreamSampleExtractor.
+func matchesSketch(v interface{}, s Sketch) bool {
+	if m, ok := v.(SketchMatcher); ok {
+		return m.MatchesSketch(s)
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package rewrite
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// SelectorRuleConfig configures a per-tenant rule replacing stream matchers.
+type SelectorRuleConfig struct {
+	Name        string `yaml:"name" json:"name" doc:"description=Name of the rule, reported in the query statistics."`
+	Selector    string `yaml:"selector" json:"selector" doc:"description=Stream selector whose matchers are replaced when they are all present in a query stream selector."`
+	Replacement string `yaml:"replacement" json:"replacement" doc:"description=Stream selector whose matchers replace the matchers of the selector."`
+
+	SelectorMatchers    []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+	ReplacementMatchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Validate validates the rule and parses its selectors.
+func (c *SelectorRuleConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("query rewrite rule name must not be empty")
+	}
+	if _, ok := builtinRules[c.Name]; ok {
+		return fmt.Errorf("query rewrite rule name %q is reserved for a built-in rule", c.Name)
+	}
+
+	var err error
+	if c.SelectorMatchers, err = syntax.ParseMatchers(c.Selector, true); err != nil {
+		return fmt.Errorf("invalid selector of query rewrite rule %s: %w", c.Name, err)
+	}
+	if c.ReplacementMatchers, err = syntax.ParseMatchers(c.Replacement, true); err != nil {
+		return fmt.Errorf("invalid replacement of query rewrite rule %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// Rule returns the rule configured. The config must have been validated.
+func (c SelectorRuleConfig) Rule() Rule {
+	return NewSelectorRule(c.Name, c.SelectorMatchers, c.ReplacementMatchers)
+}
@@ -0,0 +1,86 @@
+// Package rewrite implements rules rewriting LogQL expressions into equivalent
+// expressions which are cheaper to execute. The rules are applied by the query
+// frontend before the query is split and sharded.
+package rewrite
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// Rule rewrites a LogQL expression.
+type Rule interface {
+	// Name identifies the rule in the query statistics and metrics.
+	Name() string
+	// Apply rewrites the given expression, which may be modified in place, and returns
+	// the resulting expression and whether it was changed.
+	Apply(expr syntax.Expr) (syntax.Expr, bool, error)
+}
+
+const (
+	RuleReorderLineFilters = "reorder_line_filters"
+	RuleRegexToContains    = "regex_to_contains"
+	RulePushLabelFilters   = "push_label_filters"
+)
+
+var builtinRules = map[string]Rule{
+	RuleReorderLineFilters: reorderLineFilters{},
+	RuleRegexToContains:    regexToContains{},
+	RulePushLabelFilters:   pushLabelFilters{},
+}
+
+// BuiltinRuleNames returns the names of the built-in rules in alphabetical order.
+func BuiltinRuleNames() []string {
+	names := make([]string, 0, len(builtinRules))
+	for name := range builtinRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinRules returns the built-in rules with the given names, in the given order.
+func BuiltinRules(names []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(names))
+	for _, name := range names {
+		r, ok := builtinRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown query rewrite rule %q, expected one of %v", name, BuiltinRuleNames())
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Rewrite applies the rules in order to a copy of the expression.
+// It returns the rewritten expression and the names of the rules that changed it.
+// If no rule applies, the original expression is returned.
+func Rewrite(expr syntax.Expr, rules []Rule) (syntax.Expr, []string, error) {
+	if len(rules) == 0 {
+		return expr, nil, nil
+	}
+
+	rewritten, err := syntax.Clone[syntax.Expr](expr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var applied []string
+	for _, r := range rules {
+		res, changed, err := r.Apply(rewritten)
+		if err != nil {
+			return nil, nil, fmt.Errorf("query rewrite rule %s: %w", r.Name(), err)
+		}
+		if changed {
+			rewritten = res
+			applied = append(applied, r.Name())
+		}
+	}
+
+	if len(applied) == 0 {
+		return expr, nil, nil
+	}
+	return rewritten, applied, nil
+}
@@ -0,0 +1,120 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	for _, tc := range []struct {
+		rule     string
+		in       string
+		expected string
+	}{
+		// reorder_line_filters
+		{RuleReorderLineFilters, `{app="foo"} | json | level="error" |= "timeout"`, `{app="foo"} |= "timeout" | json | level="error"`},
+		{RuleReorderLineFilters, `{app="foo"} | logfmt |= "a" | json != "b"`, `{app="foo"} |= "a" != "b" | logfmt | json`},
+		{RuleReorderLineFilters, `{app="foo"} | json | line_format "{{.msg}}" | logfmt |= "a"`, `{app="foo"} | json | line_format "{{.msg}}" |= "a" | logfmt`},
+		{RuleReorderLineFilters, `{app="foo"} | unpack |= "a"`, ``},
+		{RuleReorderLineFilters, `{app="foo"} | json | decolorize |= "a"`, ``},
+		{RuleReorderLineFilters, `{app="foo"} |= "a" | json`, ``},
+		{RuleReorderLineFilters, `sum(rate({app="foo"} | json |= "a" [5m]))`, `sum(rate({app="foo"} |= "a" | json[5m]))`},
+		// regex_to_contains
+		{RuleRegexToContains, `{app="foo"} |~ "error"`, `{app="foo"} |= "error"`},
+		{RuleRegexToContains, `{app="foo"} !~ "foo\\.bar"`, `{app="foo"} != "foo.bar"`},
+		{RuleRegexToContains, `{app="foo"} |~ "error|warn"`, `{app="foo"} |= "error" or "warn"`},
+		{RuleRegexToContains, `{app="foo"} |~ "error" or "warn"`, `{app="foo"} |= "error" or "warn"`},
+		{RuleRegexToContains, `{app="foo"} |= "a" |~ "b"`, `{app="foo"} |= "a" |= "b"`},
+		{RuleRegexToContains, `{app="foo"} !~ "error|warn"`, ``},
+		{RuleRegexToContains, `{app="foo"} |~ "(?i)error"`, ``},
+		{RuleRegexToContains, `{app="foo"} |~ "err.r"`, ``},
+		{RuleRegexToContains, `{app=~"foo"}`, `{app="foo"}`},
+		{RuleRegexToContains, `{app=~"foo|bar"}`, ``},
+		{RuleRegexToContains, `{app="foo"} | json | level=~"error" or level!~"debug"`, `{app="foo"} | json | ( level="error" or level!="debug" )`},
+		// push_label_filters
+		{RulePushLabelFilters, `{app=~"foo|bar"} | app="foo"`, `{app=~"foo|bar", app="foo"}`},
+		{RulePushLabelFilters, `{app=~".+"} |= "a" | app!="foo" | json`, `{app=~".+", app!="foo"} |= "a" | json`},
+		{RulePushLabelFilters, `sum(count_over_time({app=~"foo|bar"} | app="foo" [5m]))`, `sum(count_over_time({app=~"foo|bar", app="foo"}[5m]))`},
+		{RulePushLabelFilters, `{app=~"foo|bar"} | json | app="foo"`, ``},
+		{RulePushLabelFilters, `{app="foo", env=~".*"} | env="prod"`, ``},
+		{RulePushLabelFilters, `{app="foo"} | env="prod"`, ``},
+	} {
+		t.Run(tc.rule+" "+tc.in, func(t *testing.T) {
+			rules, err := BuiltinRules([]string{tc.rule})
+			require.NoError(t, err)
+
+			in := syntax.MustParseExpr(tc.in)
+			out, applied, err := Rewrite(in, rules)
+			require.NoError(t, err)
+			// the input must never be modified.
+			require.Equal(t, syntax.MustParseExpr(tc.in).String(), in.String())
+
+			if tc.expected == "" {
+				require.Empty(t, applied)
+				require.Equal(t, in, out)
+				return
+			}
+			require.Equal(t, []string{tc.rule}, applied)
+			require.Equal(t, syntax.MustParseExpr(tc.expected).String(), out.String())
+			// the rewritten expression must be valid.
+			_, err = syntax.ParseExpr(out.String())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuiltinRules_Unknown(t *testing.T) {
+	_, err := BuiltinRules([]string{RuleRegexToContains, "foo"})
+	require.Error(t, err)
+}
+
+func TestRewrite(t *testing.T) {
+	rules, err := BuiltinRules(BuiltinRuleNames())
+	require.NoError(t, err)
+
+	out, applied, err := Rewrite(syntax.MustParseExpr(`{app=~"foo|bar"} | json |~ "error" | app="foo"`), rules)
+	require.NoError(t, err)
+	// the label filter follows a parser so it can't be pushed into the selector.
+	require.Equal(t, []string{RuleRegexToContains, RuleReorderLineFilters}, applied)
+	require.Equal(t, `{app=~"foo|bar"} |= "error" | json | app="foo"`, out.String())
+
+	out, applied, err = Rewrite(syntax.MustParseExpr(`{app=~"foo|bar"} |~ "error" | app="foo" | json`), rules)
+	require.NoError(t, err)
+	require.Equal(t, []string{RulePushLabelFilters, RuleRegexToContains}, applied)
+	require.Equal(t, `{app=~"foo|bar", app="foo"} |= "error" | json`, out.String())
+}
+
+func TestSelectorRule(t *testing.T) {
+	rule := NewSelectorRule("legacy_app",
+		[]*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "legacy")},
+		[]*labels.Matcher{
+			labels.MustNewMatcher(labels.MatchEqual, "app", "modern"),
+			labels.MustNewMatcher(labels.MatchEqual, "env", "prod"),
+		},
+	)
+
+	for _, tc := range []struct {
+		in       string
+		expected string
+	}{
+		{`{app="legacy"}`, `{app="modern", env="prod"}`},
+		{`{app="legacy", env="prod"} |= "a"`, `{env="prod", app="modern"} |= "a"`},
+		{`sum(rate({app="legacy"}[5m])) / sum(rate({app="other"}[5m]))`, `(sum(rate({app="modern", env="prod"}[5m])) / sum(rate({app="other"}[5m])))`},
+		{`{app=~"legacy"}`, ``},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			out, applied, err := Rewrite(syntax.MustParseExpr(tc.in), []Rule{rule})
+			require.NoError(t, err)
+			if tc.expected == "" {
+				require.Empty(t, applied)
+				return
+			}
+			require.Equal(t, []string{"legacy_app"}, applied)
+			require.Equal(t, tc.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package rewrite
+
+import (
+	"regexp/syntax"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	logqlsyntax "github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// reorderLineFilters moves line filters in front of the parsers and label stages
+// preceding them, so that lines are dropped before being parsed.
+// Line filters are never moved across stages modifying the log line.
+type reorderLineFilters struct{}
+
+func (reorderLineFilters) Name() string { return RuleReorderLineFilters }
+
+func (reorderLineFilters) Apply(expr logqlsyntax.Expr) (logqlsyntax.Expr, bool, error) {
+	var changed bool
+	forEachPipeline(expr, func(p *logqlsyntax.PipelineExpr) {
+		stages := p.MultiStages.ReorderLineFilters()
+		for i := range stages {
+			if stages[i] != p.MultiStages[i] {
+				changed = true
+				break
+			}
+		}
+		p.MultiStages = stages
+	})
+	return expr, changed, nil
+}
+
+// regexToContains replaces regular expressions matching a literal string by
+// the equivalent and cheaper contains or equality matchers.
+type regexToContains struct{}
+
+func (regexToContains) Name() string { return RuleRegexToContains }
+
+func (regexToContains) Apply(expr logqlsyntax.Expr) (logqlsyntax.Expr, bool, error) {
+	var changed bool
+	expr.Walk(func(e logqlsyntax.Expr) {
+		switch e := e.(type) {
+		case *logqlsyntax.MatchersExpr:
+			for i, m := range e.Mts {
+				if nm, ok := literalMatcher(m); ok {
+					e.Mts[i] = nm
+					changed = true
+				}
+			}
+		case *logqlsyntax.LineFilterExpr:
+			// Walk visits the filters chained with Left, but not the ones chained with Or.
+			if literalLineFilter(e) {
+				changed = true
+			}
+		case *logqlsyntax.LabelFilterExpr:
+			if f, ok := literalLabelFilter(e.LabelFilterer); ok {
+				e.LabelFilterer = f
+				changed = true
+			}
+		}
+	})
+	return expr, changed, nil
+}
+
+// literalLineFilter rewrites the line filter, including its or-chain, if all its
+// regular expressions match literal strings.
+func literalLineFilter(f *logqlsyntax.LineFilterExpr) bool {
+	if f.Op != "" || f.IsOrChild {
+		return false
+	}
+
+	var ty log.LineMatchType
+	switch f.Ty {
+	case log.LineMatchRegexp:
+		ty = log.LineMatchEqual
+	case log.LineMatchNotRegexp:
+		ty = log.LineMatchNotEqual
+	default:
+		return false
+	}
+
+	var literals []string
+	for cur := f; cur != nil; cur = cur.Or {
+		lits, ok := regexpLiterals(cur.Match, f.Ty == log.LineMatchRegexp)
+		if !ok {
+			return false
+		}
+		literals = append(literals, lits...)
+	}
+	// a negative filter can only hold a single literal: `!= "a" or "b"` doesn't exist.
+	if ty == log.LineMatchNotEqual && len(literals) != 1 {
+		return false
+	}
+
+	f.Ty, f.Match, f.Or = ty, literals[0], nil
+	prev := f
+	for _, lit := range literals[1:] {
+		or := &logqlsyntax.LineFilterExpr{
+			LineFilter: logqlsyntax.LineFilter{Ty: ty, Match: lit},
+			IsOrChild:  true,
+		}
+		prev.Or = or
+		prev = or
+	}
+	return true
+}
+
+func literalLabelFilter(f log.LabelFilterer) (log.LabelFilterer, bool) {
+	switch f := f.(type) {
+	case *log.BinaryLabelFilter:
+		left, lok := literalLabelFilter(f.Left)
+		right, rok := literalLabelFilter(f.Right)
+		if !lok && !rok {
+			return f, false
+		}
+		return &log.BinaryLabelFilter{Left: left, Right: right, And: f.And}, true
+	case *log.StringLabelFilter:
+		if m, ok := literalMatcher(f.Matcher); ok {
+			return log.NewStringLabelFilter(m), true
+		}
+	case *log.LineFilterLabelFilter:
+		if m, ok := literalMatcher(f.Matcher); ok {
+			return log.NewStringLabelFilter(m), true
+		}
+	}
+	return f, false
+}
+
+// literalMatcher returns the equality matcher equivalent to m, if m is a regular
+// expression matcher matching a single literal value. Label matchers are anchored
+// so such matchers are equivalent to `=` or `!=`.
+func literalMatcher(m *labels.Matcher) (*labels.Matcher, bool) {
+	var ty labels.MatchType
+	switch m.Type {
+	case labels.MatchRegexp:
+		ty = labels.MatchEqual
+	case labels.MatchNotRegexp:
+		ty = labels.MatchNotEqual
+	default:
+		return nil, false
+	}
+	lits, ok := regexpLiterals(m.Value, false)
+	if !ok || len(lits) != 1 {
+		return nil, false
+	}
+	nm, err := labels.NewMatcher(ty, m.Name, lits[0])
+	if err != nil {
+		return nil, false
+	}
+	return nm, true
+}
+
+// regexpLiterals returns the literal strings matched by the regular expression.
+// Alternations of literals are only allowed if allowAlternate is set.
+func regexpLiterals(expr string, allowAlternate bool) ([]string, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	re = re.Simplify()
+
+	var nodes []*syntax.Regexp
+	switch {
+	case re.Op == syntax.OpLiteral:
+		nodes = []*syntax.Regexp{re}
+	case re.Op == syntax.OpAlternate && allowAlternate:
+		nodes = re.Sub
+	default:
+		return nil, false
+	}
+
+	lits := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Op != syntax.OpLiteral || n.Flags&syntax.FoldCase != 0 {
+			return nil, false
+		}
+		lits = append(lits, string(n.Rune))
+	}
+	return lits, true
+}
+
+// pushLabelFilters moves label filters on stream labels into the stream selector,
+// so that they are used to select the streams instead of filtering each line.
+// A label filter is only moved when it precedes every stage which can add, modify or
+// remove labels, and when the selector guarantees that the label exists in the stream.
+type pushLabelFilters struct{}
+
+func (pushLabelFilters) Name() string { return RulePushLabelFilters }
+
+func (pushLabelFilters) Apply(expr logqlsyntax.Expr) (logqlsyntax.Expr, bool, error) {
+	var changed bool
+	forEachPipeline(expr, func(p *logqlsyntax.PipelineExpr) {
+		stages := p.MultiStages[:0]
+		pushable := true
+		for _, s := range p.MultiStages {
+			switch st := s.(type) {
+			case *logqlsyntax.LabelFilterExpr:
+				if pushable {
+					if m, ok := streamLabelMatcher(p.Left, st.LabelFilterer); ok {
+						p.Left.Mts = append(p.Left.Mts, m)
+						changed = true
+						continue
+					}
+				}
+			case *logqlsyntax.LineFilterExpr, *logqlsyntax.LineFmtExpr, *logqlsyntax.DecolorizeExpr:
+			default:
+				pushable = false
+			}
+			stages = append(stages, s)
+		}
+		p.MultiStages = stages
+	})
+	if !changed {
+		return expr, false, nil
+	}
+	return removeEmptyPipelines(expr), true, nil
+}
+
+// streamLabelMatcher returns the matcher of the label filter if it can be added
+// to the stream selector.
+func streamLabelMatcher(selector *logqlsyntax.MatchersExpr, f log.LabelFilterer) (*labels.Matcher, bool) {
+	var m *labels.Matcher
+	switch f := f.(type) {
+	case *log.StringLabelFilter:
+		m = f.Matcher
+	case *log.LineFilterLabelFilter:
+		m = f.Matcher
+	default:
+		return nil, false
+	}
+	if strings.HasPrefix(m.Name, "__") {
+		return nil, false
+	}
+	for _, sm := range selector.Mts {
+		// the label exists in every selected stream, so parsed labels and structured
+		// metadata with the same name can't shadow it.
+		if sm.Name == m.Name && !sm.Matches("") {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// removeEmptyPipelines replaces the pipelines without stages by their stream selector.
+func removeEmptyPipelines(expr logqlsyntax.Expr) logqlsyntax.Expr {
+	if p, ok := expr.(*logqlsyntax.PipelineExpr); ok && len(p.MultiStages) == 0 {
+		return p.Left
+	}
+	expr.Walk(func(e logqlsyntax.Expr) {
+		if r, ok := e.(*logqlsyntax.LogRange); ok {
+			if p, ok := r.Left.(*logqlsyntax.PipelineExpr); ok && len(p.MultiStages) == 0 {
+				r.Left = p.Left
+			}
+		}
+	})
+	return expr
+}
+
+func forEachPipeline(expr logqlsyntax.Expr, f func(*logqlsyntax.PipelineExpr)) {
+	expr.Walk(func(e logqlsyntax.Expr) {
+		if p, ok := e.(*logqlsyntax.PipelineExpr); ok {
+			f(p)
+		}
+	})
+}
+
+type selectorRule struct {
+	name    string
+	match   []*labels.Matcher
+	replace []*labels.Matcher
+}
+
+// NewSelectorRule returns a rule replacing the match matchers by the replace matchers
+// in every stream selector containing all of the match matchers.
+func NewSelectorRule(name string, match, replace []*labels.Matcher) Rule {
+	return &selectorRule{name: name, match: match, replace: replace}
+}
+
+func (r *selectorRule) Name() string { return r.name }
+
+func (r *selectorRule) Apply(expr logqlsyntax.Expr) (logqlsyntax.Expr, bool, error) {
+	var changed bool
+	expr.Walk(func(e logqlsyntax.Expr) {
+		sel, ok := e.(*logqlsyntax.MatchersExpr)
+		if !ok {
+			return
+		}
+		for _, m := range r.match {
+			if !slices.ContainsFunc(sel.Mts, sameMatcher(m)) {
+				return
+			}
+		}
+
+		mts := make([]*labels.Matcher, 0, len(sel.Mts)+len(r.replace))
+		for _, m := range sel.Mts {
+			if !slices.ContainsFunc(r.match, sameMatcher(m)) {
+				mts = append(mts, m)
+			}
+		}
+		for _, m := range r.replace {
+			if !slices.ContainsFunc(mts, sameMatcher(m)) {
+				mts = append(mts, m)
+			}
+		}
+		sel.Mts = mts
+		changed = true
+	})
+	return expr, changed, nil
+}
+
+func sameMatcher(m *labels.Matcher) func(*labels.Matcher) bool {
+	return func(o *labels.Matcher) bool {
+		return o.Name == m.Name && o.Type == m.Type && o.Value == m.Value
+	}
+}
@@ -132,6 +132,28 @@ func (m MultiStageExpr) stages() ([]log.Stage, error) {
 // reorderStages reorders m such that LineFilters
 // are as close to the front of the filter as possible.
 func (m MultiStageExpr) reorderStages() []StageExpr {
+	return m.reorderLineFilters(func(filters []*LineFilterExpr) []StageExpr {
+		return []StageExpr{combineFilters(filters)}
+	})
+}
+
+// ReorderLineFilters returns the stages of m with the LineFilters moved
+// as close to the front as possible, like when building the pipeline of m,
+// but without combining them.
+func (m MultiStageExpr) ReorderLineFilters() MultiStageExpr {
+	return m.reorderLineFilters(func(filters []*LineFilterExpr) []StageExpr {
+		stages := make([]StageExpr, 0, len(filters))
+		for _, f := range filters {
+			stages = append(stages, f)
+		}
+		return stages
+	})
+}
+
+// reorderLineFilters moves the LineFilters of m in front of the stages
+// preceding them, up to the first stage modifying the log line. The moved
+// filters are replaced by the stages returned by group.
+func (m MultiStageExpr) reorderLineFilters(group func([]*LineFilterExpr) []StageExpr) []StageExpr {
 	var (
 		result  = make([]StageExpr, 0, len(m))
 		filters = make([]*LineFilterExpr, 0, len(m))
@@ -142,15 +164,15 @@ func (m MultiStageExpr) reorderStages() []StageExpr {
 		switch f := s.(type) {
 		case *LineFilterExpr:
 			filters = append(filters, f)
-		case *LineFmtExpr:
-			// line_format modifies the contents of the line so any line filter
-			// originally after a line_format must still be after the same
-			// line_format.
+		case *LineFmtExpr, *DecolorizeExpr:
+			// line_format and decolorize modify the contents of the line so
+			// any line filter originally after them must still be after the
+			// same stage.
 
 			rest = append(rest, f)
 
 			if len(filters) > 0 {
-				result = append(result, combineFilters(filters))
+				result = append(result, group(filters)...)
 			}
 			result = append(result, rest...)
 
@@ -164,7 +186,7 @@ func (m MultiStageExpr) reorderStages() []StageExpr {
 			// unpack.
 			if f.Op == OpParserTypeUnpack {
 				if len(filters) > 0 {
-					result = append(result, combineFilters(filters))
+					result = append(result, group(filters)...)
 				}
 				result = append(result, rest...)
 
@@ -177,7 +199,7 @@ func (m MultiStageExpr) reorderStages() []StageExpr {
 	}
 
 	if len(filters) > 0 {
-		result = append(result, combineFilters(filters))
+		result = append(result, group(filters)...)
 	}
 	return append(result, rest...)
 }
@@ -561,7 +583,7 @@ func (e *LineFilterExpr) Stage() (log.Stage, error) {
 	if err != nil {
 		return nil, err
 	}
-	return f.ToStage(), nil
+	return log.NewLineFilterStage(f), nil
 }
 
 type LogfmtParserExpr struct {
@@ -1007,6 +1007,16 @@ func TestFilterReodering(t *testing.T) {
 		require.Len(t, stages, 5)
 		require.Equal(t, `|= "06497595" | unpack != "message" | json | line_format "new log: {{.foo}}"`, MultiStageExpr(stages).String())
 	})
+
+	t.Run("decolorize test", func(t *testing.T) {
+		logExpr := `{container_name="app"} | logfmt | decolorize |= "foo" | json |= "bar"`
+		l, err := ParseExpr(logExpr)
+		require.NoError(t, err)
+
+		stages := l.(*PipelineExpr).MultiStages.reorderStages()
+		require.Len(t, stages, 4)
+		require.Equal(t, `| logfmt | decolorize |= "foo" |= "bar" | json`, MultiStageExpr(stages).String())
+	})
 }
 
 var result bool
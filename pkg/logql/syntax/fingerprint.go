@@ -0,0 +1,119 @@
+package syntax
+
+import (
+	"sort"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// literalPlaceholder replaces string literals in a normalized expression.
+const literalPlaceholder = "?"
+
+// Normalize returns a copy of the expression with all literals stripped:
+// matcher and filter values, numbers, durations and aggregation parameters
+// are replaced with placeholders and stream matchers are sorted by name.
+// Two queries that only differ in those values (e.g. the same dashboard panel
+// rendered for another namespace or time range) share the same normalized form.
+func Normalize(e Expr) (Expr, error) {
+	normalized, err := Clone[Expr](e)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized.Walk(func(e Expr) {
+		switch concrete := e.(type) {
+		case *MatchersExpr:
+			concrete.Mts = normalizeMatchers(concrete.Mts)
+		case *LineFilterExpr:
+			// Walk only descends into Left, so the chained "or" filters are handled here.
+			for f := concrete; f != nil; f = f.Or {
+				f.Match = literalPlaceholder
+			}
+		case *LabelFilterExpr:
+			concrete.LabelFilterer = normalizeLabelFilterer(concrete.LabelFilterer)
+		case *LogRange:
+			concrete.Interval = 0
+			concrete.Offset = 0
+		case *RangeAggregationExpr:
+			if concrete.Params != nil {
+				concrete.Params = new(float64)
+			}
+		case *VectorAggregationExpr:
+			concrete.Params = 0
+		case *LiteralExpr:
+			concrete.Val = 0
+		case *VectorExpr:
+			concrete.Val = 0
+		}
+	})
+
+	return normalized, nil
+}
+
+// Fingerprint returns a stable hash of the normalized form of the expression.
+// See Normalize for the details of which parts of the expression are ignored.
+func Fingerprint(e Expr) (uint64, error) {
+	normalized, err := Normalize(e)
+	if err != nil {
+		return 0, err
+	}
+	return xxhash.Sum64String(normalized.String()), nil
+}
+
+func normalizeMatchers(mts []*labels.Matcher) []*labels.Matcher {
+	res := make([]*labels.Matcher, 0, len(mts))
+	for _, m := range mts {
+		res = append(res, normalizeMatcher(m))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].Type < res[j].Type
+	})
+	return res
+}
+
+// normalizeMatcher builds the matcher directly instead of using labels.NewMatcher,
+// since the placeholder is not a valid regular expression. The result must only be
+// used for its string representation.
+func normalizeMatcher(m *labels.Matcher) *labels.Matcher {
+	if m == nil {
+		return nil
+	}
+	return &labels.Matcher{Type: m.Type, Name: m.Name, Value: literalPlaceholder}
+}
+
+func normalizeLabelFilterer(filter log.LabelFilterer) log.LabelFilterer {
+	switch concrete := filter.(type) {
+	case *log.BinaryLabelFilter:
+		return &log.BinaryLabelFilter{
+			Left:  normalizeLabelFilterer(concrete.Left),
+			Right: normalizeLabelFilterer(concrete.Right),
+			And:   concrete.And,
+		}
+	case *log.NoopLabelFilter:
+		return &log.NoopLabelFilter{Matcher: normalizeMatcher(concrete.Matcher)}
+	case *log.BytesLabelFilter:
+		return &log.BytesLabelFilter{Name: concrete.Name, Type: concrete.Type}
+	case *log.DurationLabelFilter:
+		return &log.DurationLabelFilter{Name: concrete.Name, Type: concrete.Type}
+	case *log.NumericLabelFilter:
+		return &log.NumericLabelFilter{Name: concrete.Name, Type: concrete.Type}
+	case *log.StringLabelFilter:
+		return &log.StringLabelFilter{Matcher: normalizeMatcher(concrete.Matcher)}
+	case *log.LineFilterLabelFilter:
+		// The string representation of a LineFilterLabelFilter may use the
+		// underlying filter instead of the matcher value, so it is replaced
+		// by a plain string filter.
+		return &log.StringLabelFilter{Matcher: normalizeMatcher(concrete.Matcher)}
+	case *log.IPLabelFilter:
+		copied := *concrete
+		copied.Pattern = literalPlaceholder
+		return &copied
+	}
+	return filter
+}
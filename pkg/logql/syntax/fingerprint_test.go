@@ -0,0 +1,98 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"matchers": {
+			query:    `{env="prod", app=~"loki.*"}`,
+			expected: `{app=~"?", env="?"}`,
+		},
+		"line filters": {
+			query:    `{app="foo"} |= "bar" != "baz" |~ "a|b" or "c"`,
+			expected: `{app="?"} |= "?" != "?" |~ "?" or "?"`,
+		},
+		"label filters": {
+			query:    `{app="foo"} | json | ( latency>=250ms or ( status_code<500 , status_code>200 ) ) | method=~"GET|POST" | size > 1KB | addr = ip("10.0.0.0/8")`,
+			expected: `{app="?"} | json | ( latency>=0s or ( status_code<0 , status_code>0 ) ) | method=~"?" | size>0B | addr=ip("?")`,
+		},
+		"range aggregation": {
+			query:    `sum by (app) (count_over_time({app="foo"} |= "err" [5m] offset 1h))`,
+			expected: `sum by (app)(count_over_time({app="?"} |= "?"[0s]))`,
+		},
+		"quantile and topk": {
+			query:    `topk(10, quantile_over_time(0.99, {app="foo"} | logfmt | unwrap latency [1m]) by (app))`,
+			expected: `topk(0,quantile_over_time(0,{app="?"} | logfmt | unwrap latency[0s]) by (app))`,
+		},
+		"binary operation with literal": {
+			query:    `count_over_time({app="foo"}[1m]) > 100`,
+			expected: `(count_over_time({app="?"}[0s]) > 0)`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, err := ParseExpr(test.query)
+			require.NoError(t, err)
+
+			normalized, err := Normalize(expr)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, normalized.String())
+
+			// the original expression must not be modified
+			require.Equal(t, MustParseExpr(test.query).String(), expr.String())
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		same bool
+	}{
+		{
+			a:    `sum(rate({namespace="a", app="foo"} |= "err" [5m]))`,
+			b:    `sum(rate({app="bar", namespace="b"} |= "panic" [1h]))`,
+			same: true,
+		},
+		{
+			a:    `{app="foo"} | json | status >= 500`,
+			b:    `{app="foo"} | json | status >= 400`,
+			same: true,
+		},
+		{
+			a:    `sum(rate({app="foo"}[5m]))`,
+			b:    `sum by (app) (rate({app="foo"}[5m]))`,
+			same: false,
+		},
+		{
+			a:    `{app="foo"} |= "err"`,
+			b:    `{app="foo"} |~ "err"`,
+			same: false,
+		},
+		{
+			a:    `{app="foo"} | json`,
+			b:    `{app="foo"} | logfmt`,
+			same: false,
+		},
+	} {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			a, err := Fingerprint(MustParseExpr(tc.a))
+			require.NoError(t, err)
+			b, err := Fingerprint(MustParseExpr(tc.b))
+			require.NoError(t, err)
+			if tc.same {
+				require.Equal(t, a, b)
+			} else {
+				require.NotEqual(t, a, b)
+			}
+		})
+	}
+}
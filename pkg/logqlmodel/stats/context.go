@@ -21,6 +21,7 @@ package stats
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"sync/atomic" //lint:ignore faillint we can't use go.uber.org/atomic with a protobuf struct without wrapping it.
 	"time"
@@ -217,6 +218,16 @@ func (s *Store) ChunksDownloadDuration() time.Duration {
 func (s *Summary) Merge(m Summary) {
 	s.Splits += m.Splits
 	s.Shards += m.Shards
+	for _, name := range m.AppliedRewrites {
+		if !slices.Contains(s.AppliedRewrites, name) {
+			s.AppliedRewrites = append(s.AppliedRewrites, name)
+		}
+	}
+	for _, name := range m.FailedRemotes {
+		if !slices.Contains(s.FailedRemotes, name) {
+			s.FailedRemotes = append(s.FailedRemotes, name)
+		}
+	}
 }
 
 func (q *Querier) Merge(m Querier) {
@@ -490,6 +501,18 @@ func (c *Context) AddSplitQueries(num int64) {
 	atomic.AddInt64(&c.result.Summary.Splits, num)
 }
 
+// AddAppliedRewrites records the names of the query rewrite rules applied to the query.
+func (c *Context) AddAppliedRewrites(names ...string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, name := range names {
+		if !slices.Contains(c.result.Summary.AppliedRewrites, name) {
+			c.result.Summary.AppliedRewrites = append(c.result.Summary.AppliedRewrites, name)
+		}
+	}
+}
+
 func (c *Context) SetQueryReferencedStructuredMetadata() {
 	c.store.QueryReferencedStructured = true
 }
@@ -232,6 +232,10 @@ type Summary struct {
 	TotalPostFilterLines int64 `protobuf:"varint,11,opt,name=totalPostFilterLines,proto3" json:"totalPostFilterLines"`
 	// Total bytes processed of metadata.
 	TotalStructuredMetadataBytesProcessed int64 `protobuf:"varint,12,opt,name=totalStructuredMetadataBytesProcessed,proto3" json:"totalStructuredMetadataBytesProcessed"`
+	// Names of the query rewrite rules applied by the query frontend.
+	AppliedRewrites []string `protobuf:"bytes,13,rep,name=appliedRewrites,proto3" json:"appliedRewrites,omitempty"`
+	// Names of the remote clusters of a federated query whose results are missing.
+	FailedRemotes []string `protobuf:"bytes,14,rep,name=failedRemotes,proto3" json:"failedRemotes,omitempty"`
 }
 
 func (m *Summary) Reset()      { *m = Summary{} }
@@ -350,6 +354,20 @@ func (m *Summary) GetTotalStructuredMetadataBytesProcessed() int64 {
 	return 0
 }
 
+func (m *Summary) GetAppliedRewrites() []string {
+	if m != nil {
+		return m.AppliedRewrites
+	}
+	return nil
+}
+
+func (m *Summary) GetFailedRemotes() []string {
+	if m != nil {
+		return m.FailedRemotes
+	}
+	return nil
+}
+
 // Statistics from Index queries
 // TODO(owen-d): include bytes.
 // Needs some index methods added to return _sized_ chunk refs to know
@@ -873,94 +891,97 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 1378 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x58, 0xcf, 0x6f, 0xdc, 0x44,
-	0x14, 0xde, 0xcd, 0xd6, 0x9b, 0x74, 0xf2, 0xab, 0x9d, 0xa4, 0x74, 0x4b, 0x2b, 0x3b, 0x2c, 0x54,
-	0x14, 0x21, 0x65, 0x55, 0x8a, 0x84, 0x40, 0x54, 0x42, 0x4e, 0x89, 0x54, 0x29, 0x15, 0xe5, 0x2d,
-	0x08, 0x04, 0x27, 0xc7, 0x9e, 0xec, 0x5a, 0xf5, 0xda, 0x8e, 0x3d, 0x0e, 0xcd, 0x09, 0xfe, 0x04,
-	0xee, 0xdc, 0x11, 0x17, 0x4e, 0x5c, 0xe0, 0xcc, 0xa5, 0xc7, 0x1e, 0x7b, 0xb2, 0xe8, 0xe6, 0x82,
-	0x7c, 0xea, 0x1f, 0xc0, 0x01, 0xcd, 0x9b, 0x59, 0xff, 0x5a, 0x6f, 0x9a, 0xcb, 0x7a, 0xde, 0xf7,
-	0xbe, 0xef, 0xcd, 0xf8, 0x79, 0xe6, 0xbd, 0xd1, 0x92, 0x9d, 0xf0, 0xc9, 0x68, 0xe0, 0x05, 0xa3,
-	0x63, 0x6f, 0x12, 0x38, 0xcc, 0x1b, 0xc4, 0xdc, 0xe2, 0xb1, 0xfc, 0xdd, 0x0d, 0xa3, 0x80, 0x07,
-	0x54, 0x43, 0xe3, 0xcd, 0xed, 0x51, 0x30, 0x0a, 0x10, 0x19, 0x88, 0x91, 0x74, 0xf6, 0x7f, 0x5d,
-	0x22, 0x5d, 0x60, 0x71, 0xe2, 0x71, 0xfa, 0x31, 0x59, 0x8e, 0x93, 0xc9, 0xc4, 0x8a, 0x4e, 0x7b,
-	0xed, 0x9d, 0xf6, 0x9d, 0xd5, 0x0f, 0x36, 0x76, 0x65, 0x98, 0xa1, 0x44, 0xcd, 0xcd, 0x67, 0xa9,
-	0xd1, 0xca, 0x52, 0x63, 0x46, 0x83, 0xd9, 0x40, 0x48, 0x8f, 0x13, 0x16, 0xb9, 0x2c, 0xea, 0x2d,
-	0x55, 0xa4, 0x5f, 0x4a, 0xb4, 0x90, 0x2a, 0x1a, 0xcc, 0x06, 0xf4, 0x3e, 0x59, 0x71, 0xfd, 0x11,
-	0x8b, 0x39, 0x8b, 0x7a, 0x1d, 0xd4, 0x6e, 0x2a, 0xed, 0x43, 0x05, 0x9b, 0x57, 0x94, 0x38, 0x27,
-	0x42, 0x3e, 0xa2, 0x1f, 0x92, 0xae, 0x6d, 0xd9, 0x63, 0x16, 0xf7, 0x2e, 0xa1, 0x78, 0x5d, 0x89,
-	0xf7, 0x10, 0x34, 0xd7, 0x95, 0x54, 0x43, 0x12, 0x28, 0x2e, 0xbd, 0x4b, 0x34, 0xd7, 0x77, 0xd8,
-	0xd3, 0x9e, 0x86, 0xa2, 0xb5, 0x7c, 0x46, 0x87, 0x3d, 0x2d, 0x34, 0x48, 0x01, 0xf9, 0xe8, 0xff,
-	0x72, 0x89, 0x74, 0xf7, 0x72, 0xb5, 0x3d, 0x4e, 0xfc, 0x27, 0x2a, 0x4d, 0x6b, 0xe5, 0x29, 0x4b,
-	0x33, 0x0a, 0x0a, 0xc8, 0x47, 0x31, 0xe1, 0xd2, 0x79, 0x92, 0xf2, 0x84, 0xe2, 0xcd, 0x22, 0xfc,
-	0x30, 0x2a, 0x2d, 0x55, 0xcd, 0x86, 0xd2, 0x28, 0x0e, 0xa8, 0x27, 0xdd, 0x23, 0xab, 0x48, 0x93,
-	0xdf, 0x54, 0x25, 0xa5, 0x2a, 0xdd, 0x52, 0xd2, 0x32, 0x11, 0xca, 0x06, 0xdd, 0x27, 0x6b, 0x27,
-	0x81, 0x97, 0x4c, 0x98, 0x8a, 0xa2, 0x35, 0x44, 0xd9, 0x56, 0x51, 0x2a, 0x4c, 0xa8, 0x58, 0x22,
-	0x4e, 0x2c, 0xbe, 0xf2, 0x6c, 0x35, 0xdd, 0xf3, 0xe2, 0x94, 0x99, 0x50, 0xb1, 0xc4, 0x4b, 0x79,
-	0xd6, 0x21, 0xf3, 0x54, 0x98, 0xe5, 0xf3, 0x5e, 0xaa, 0x44, 0x84, 0xb2, 0x41, 0xbf, 0x27, 0x5b,
-	0xae, 0x1f, 0x73, 0xcb, 0xe7, 0x8f, 0x18, 0x8f, 0x5c, 0x5b, 0x05, 0x5b, 0x69, 0x08, 0x76, 0x53,
-	0x05, 0x6b, 0x12, 0x40, 0x13, 0xd8, 0xff, 0xab, 0x4b, 0x96, 0xd5, 0x31, 0xa1, 0x5f, 0x93, 0xeb,
-	0x87, 0xa7, 0x9c, 0xc5, 0x8f, 0xa3, 0xc0, 0x66, 0x71, 0xcc, 0x9c, 0xc7, 0x2c, 0x1a, 0x32, 0x3b,
-	0xf0, 0x1d, 0xdc, 0x30, 0x1d, 0xf3, 0x66, 0x96, 0x1a, 0x8b, 0x28, 0xb0, 0xc8, 0x21, 0xc2, 0x7a,
-	0xae, 0xdf, 0x18, 0x76, 0xa9, 0x08, 0xbb, 0x80, 0x02, 0x8b, 0x1c, 0xf4, 0x21, 0xd9, 0xe2, 0x01,
-	0xb7, 0x3c, 0xb3, 0x32, 0x2d, 0xee, 0xb9, 0x8e, 0x79, 0x5d, 0x24, 0xa1, 0xc1, 0x0d, 0x4d, 0x60,
-	0x1e, 0xea, 0xa0, 0x32, 0x15, 0xee, 0xc1, 0x72, 0xa8, 0xaa, 0x1b, 0x9a, 0x40, 0x7a, 0x87, 0xac,
-	0xb0, 0xa7, 0xcc, 0xfe, 0xca, 0x9d, 0x30, 0xdc, 0x7d, 0x6d, 0x73, 0x4d, 0x14, 0x80, 0x19, 0x06,
-	0xf9, 0x88, 0xbe, 0x4f, 0x2e, 0x1f, 0x27, 0x2c, 0x61, 0x48, 0xed, 0x22, 0x75, 0x3d, 0x4b, 0x8d,
-	0x02, 0x84, 0x62, 0x48, 0x77, 0x09, 0x89, 0x93, 0x43, 0x59, 0x7a, 0x62, 0xdc, 0x47, 0x1d, 0x73,
-	0x23, 0x4b, 0x8d, 0x12, 0x0a, 0xa5, 0x31, 0x3d, 0x20, 0xdb, 0xb8, 0xba, 0xcf, 0x7d, 0x2e, 0xb7,
-	0x23, 0x4f, 0x22, 0x9f, 0x39, 0xb8, 0x69, 0x3a, 0x66, 0x2f, 0x4b, 0x8d, 0x46, 0x3f, 0x34, 0xa2,
-	0xb4, 0x4f, 0xba, 0x71, 0xe8, 0xb9, 0x3c, 0xee, 0x5d, 0x46, 0x3d, 0x11, 0xe7, 0x57, 0x22, 0xa0,
-	0x9e, 0xc8, 0x19, 0x5b, 0x91, 0x13, 0xf7, 0x48, 0x89, 0x83, 0x08, 0xa8, 0x67, 0xbe, 0xaa, 0xc7,
-	0x41, 0xcc, 0xf7, 0x5d, 0x8f, 0xb3, 0x08, 0xb3, 0xd7, 0x5b, 0xad, 0xad, 0xaa, 0xe6, 0x87, 0x46,
-	0x94, 0xfe, 0x48, 0x6e, 0x23, 0x3e, 0xe4, 0x51, 0x62, 0xf3, 0x24, 0x62, 0xce, 0x23, 0xc6, 0x2d,
-	0xc7, 0xe2, 0x56, 0x6d, 0x4b, 0xac, 0x61, 0xf8, 0xf7, 0xb2, 0xd4, 0xb8, 0x98, 0x00, 0x2e, 0x46,
-	0xeb, 0xff, 0xd9, 0x26, 0x1a, 0x56, 0x5e, 0x7a, 0x97, 0xac, 0xa2, 0x64, 0x4f, 0xd4, 0xcc, 0x58,
-	0x9d, 0x96, 0x4d, 0x71, 0xaa, 0x4b, 0x30, 0x94, 0x0d, 0xfa, 0x19, 0xb9, 0x12, 0xe6, 0x2f, 0xa4,
-	0x74, 0xf2, 0x38, 0x6c, 0x67, 0xa9, 0x31, 0xe7, 0x83, 0x39, 0x84, 0x7e, 0x42, 0x36, 0x64, 0x5e,
-	0x1f, 0x24, 0x91, 0xc5, 0xdd, 0xc0, 0x57, 0x7b, 0x9f, 0x66, 0xa9, 0x51, 0xf3, 0x40, 0xcd, 0xee,
-	0x7f, 0x4a, 0x96, 0x55, 0x87, 0x13, 0x15, 0x3e, 0xe6, 0x41, 0xc4, 0x6a, 0x4d, 0x61, 0x28, 0xb0,
-	0xa2, 0xc2, 0x23, 0x05, 0xe4, 0xa3, 0xff, 0xfb, 0x12, 0x59, 0x79, 0x58, 0x34, 0xb2, 0x35, 0x7c,
-	0x2f, 0x60, 0xa2, 0x04, 0xc9, 0x52, 0xa1, 0x99, 0x57, 0x44, 0x65, 0x2c, 0xe3, 0x50, 0xb1, 0xe8,
-	0x3e, 0xa1, 0xa5, 0x6c, 0x3c, 0xb2, 0x38, 0x6a, 0x65, 0x02, 0xde, 0xc8, 0x52, 0xa3, 0xc1, 0x0b,
-	0x0d, 0x58, 0x3e, 0xbb, 0x89, 0x76, 0xac, 0x52, 0x50, 0xcc, 0xae, 0x70, 0xa8, 0x58, 0x22, 0x75,
-	0xc5, 0xe1, 0x1d, 0x32, 0x9f, 0xab, 0xb3, 0x8e, 0xa9, 0xab, 0x7a, 0xa0, 0x66, 0x17, 0xf9, 0xd2,
-	0x2e, 0x9c, 0xaf, 0xff, 0x2e, 0x11, 0x0d, 0xfd, 0xf9, 0xc4, 0xea, 0xa3, 0xb2, 0x23, 0xb5, 0x57,
-	0x8a, 0x89, 0x73, 0x0f, 0xd4, 0x6c, 0xfa, 0x05, 0xb9, 0x56, 0x42, 0x1e, 0x04, 0x3f, 0xf8, 0x5e,
-	0x60, 0x39, 0x79, 0xd6, 0x6e, 0x64, 0xa9, 0xd1, 0x4c, 0x80, 0x66, 0x58, 0x7c, 0x03, 0xbb, 0x82,
-	0x61, 0x29, 0xea, 0x14, 0xdf, 0x60, 0xde, 0x0b, 0x0d, 0x18, 0xb5, 0xc9, 0x0d, 0x51, 0x77, 0x4e,
-	0x81, 0x1d, 0xb1, 0x88, 0xf9, 0x36, 0x73, 0x8a, 0xa3, 0xd3, 0x5b, 0xdf, 0x69, 0xdf, 0x59, 0x31,
-	0x6f, 0x67, 0xa9, 0xf1, 0xd6, 0x42, 0xd2, 0xec, 0x7c, 0xc1, 0xe2, 0x38, 0xc5, 0xdd, 0xa5, 0x76,
-	0x33, 0x10, 0xd8, 0x82, 0xbb, 0xcb, 0xec, 0xfd, 0x80, 0x1d, 0xc5, 0xfb, 0x8c, 0xdb, 0xe3, 0xbc,
-	0x2a, 0x97, 0xdf, 0xaf, 0xe2, 0x85, 0x06, 0x8c, 0x7e, 0x4b, 0x7a, 0x76, 0x80, 0xdb, 0xdd, 0x0d,
-	0xfc, 0xbd, 0xc0, 0xe7, 0x51, 0xe0, 0x1d, 0x58, 0x9c, 0xf9, 0xf6, 0x29, 0x16, 0xee, 0x8e, 0x79,
-	0x2b, 0x4b, 0x8d, 0x85, 0x1c, 0x58, 0xe8, 0xa1, 0x0e, 0xb9, 0x15, 0xba, 0x21, 0x13, 0x2d, 0xee,
-	0x9b, 0xc8, 0x0a, 0x43, 0x16, 0xc9, 0x13, 0xce, 0x1c, 0x59, 0x18, 0x65, 0xa1, 0xdf, 0xc9, 0x52,
-	0xe3, 0x5c, 0x1e, 0x9c, 0xeb, 0xed, 0xff, 0xa1, 0x11, 0x0d, 0xf3, 0x24, 0xb6, 0xdf, 0x98, 0x59,
-	0x8e, 0x4c, 0x9a, 0x28, 0x66, 0xe5, 0x7d, 0x5f, 0xf5, 0x40, 0xcd, 0xae, 0x68, 0xe5, 0xea, 0xb4,
-	0x06, 0xad, 0x5c, 0x4f, 0xcd, 0xa6, 0x7b, 0xe4, 0xaa, 0xc3, 0xec, 0x60, 0x12, 0x46, 0x58, 0x39,
-	0xe5, 0xd4, 0x32, 0x75, 0xd7, 0xb2, 0xd4, 0x98, 0x77, 0xc2, 0x3c, 0x54, 0x0f, 0x52, 0xce, 0xd0,
-	0x5c, 0x10, 0xb9, 0x8c, 0x79, 0x88, 0xde, 0x27, 0x9b, 0xf5, 0x75, 0xc8, 0x9e, 0xb8, 0x95, 0xa5,
-	0x46, 0xdd, 0x05, 0x75, 0x40, 0xc8, 0xf1, 0x2c, 0x3d, 0x48, 0x42, 0xcf, 0xb5, 0x2d, 0x21, 0xbf,
-	0x5c, 0xc8, 0x6b, 0x2e, 0xa8, 0x03, 0x42, 0x1e, 0xd6, 0x7a, 0x1f, 0x29, 0xe4, 0x35, 0x17, 0xd4,
-	0x01, 0x1a, 0x92, 0x9d, 0x3c, 0xb1, 0x0b, 0xba, 0x93, 0xea, 0xa5, 0xef, 0x64, 0xa9, 0xf1, 0x5a,
-	0x2e, 0xbc, 0x96, 0x41, 0x4f, 0xc9, 0xdb, 0xe5, 0x1c, 0x2e, 0x9a, 0x54, 0x76, 0xd8, 0x77, 0xb3,
-	0xd4, 0xb8, 0x08, 0x1d, 0x2e, 0x42, 0xea, 0xff, 0xdd, 0x21, 0x1a, 0xde, 0x6a, 0x45, 0x8d, 0x67,
-	0xf2, 0x46, 0xb2, 0x1f, 0x24, 0x7e, 0xa5, 0xc3, 0x94, 0x71, 0xa8, 0x58, 0xa2, 0xc1, 0xb2, 0xd9,
-	0x3d, 0xe6, 0x38, 0x11, 0xbd, 0x4a, 0x56, 0x4a, 0x4d, 0x36, 0xd8, 0xba, 0x0f, 0xe6, 0x10, 0xfa,
-	0x11, 0x59, 0x57, 0x18, 0x16, 0x6f, 0x79, 0xb7, 0xd4, 0xcc, 0xab, 0x59, 0x6a, 0x54, 0x1d, 0x50,
-	0x35, 0x85, 0x10, 0x2f, 0xc3, 0xc0, 0x6c, 0xe6, 0x9e, 0xe4, 0x37, 0x49, 0x14, 0x56, 0x1c, 0x50,
-	0x35, 0xc5, 0x9d, 0x10, 0x01, 0x6c, 0x49, 0xf2, 0x78, 0xe1, 0x9d, 0x30, 0x07, 0xa1, 0x18, 0x8a,
-	0xab, 0x66, 0x24, 0xd7, 0x2a, 0xcf, 0x92, 0x26, 0xaf, 0x9a, 0x33, 0x0c, 0xf2, 0x91, 0x48, 0xa0,
-	0x53, 0x2e, 0xf1, 0xcb, 0x45, 0x93, 0x2c, 0xe3, 0x50, 0xb1, 0xc4, 0x79, 0xc3, 0x72, 0x7c, 0xc0,
-	0xfc, 0x11, 0x1f, 0x0f, 0x59, 0x74, 0x92, 0x5f, 0x20, 0xf1, 0xbc, 0xcd, 0x39, 0x61, 0x1e, 0x32,
-	0xd9, 0xf3, 0x97, 0x7a, 0xeb, 0xc5, 0x4b, 0xbd, 0xf5, 0xea, 0xa5, 0xde, 0xfe, 0x69, 0xaa, 0xb7,
-	0x7f, 0x9b, 0xea, 0xed, 0x67, 0x53, 0xbd, 0xfd, 0x7c, 0xaa, 0xb7, 0xff, 0x99, 0xea, 0xed, 0x7f,
-	0xa7, 0x7a, 0xeb, 0xd5, 0x54, 0x6f, 0xff, 0x7c, 0xa6, 0xb7, 0x9e, 0x9f, 0xe9, 0xad, 0x17, 0x67,
-	0x7a, 0xeb, 0xbb, 0xc1, 0xc8, 0xe5, 0xe3, 0xe4, 0x70, 0xd7, 0x0e, 0x26, 0x83, 0x51, 0x64, 0x1d,
-	0x59, 0xbe, 0x35, 0xf0, 0x82, 0x27, 0xee, 0xe0, 0xe4, 0xde, 0xa0, 0xe9, 0x6f, 0x83, 0xc3, 0x2e,
-	0xfe, 0x29, 0x70, 0xef, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0x64, 0x90, 0x88, 0xaf, 0x55, 0x10,
-	0x00, 0x00,
+	// 1436 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x58, 0xcd, 0x6f, 0xdc, 0xd4,
+	0x16, 0x8f, 0x33, 0x71, 0x92, 0xde, 0x7c, 0xb5, 0x37, 0xe9, 0xab, 0xfb, 0xda, 0x37, 0xce, 0x9b,
+	0xf7, 0x2a, 0x82, 0x40, 0x19, 0x95, 0x22, 0x21, 0x10, 0x95, 0xc0, 0x29, 0x41, 0x95, 0x52, 0x51,
+	0x4e, 0x40, 0x20, 0x58, 0x39, 0xf6, 0xc9, 0x8c, 0x55, 0x8f, 0xed, 0xd8, 0xd7, 0x69, 0xb3, 0x82,
+	0x3f, 0x81, 0x3d, 0x7b, 0xc4, 0x86, 0x15, 0x1b, 0xf6, 0x08, 0xa9, 0xcb, 0x2e, 0xbb, 0xb2, 0xe8,
+	0x74, 0x83, 0xbc, 0xea, 0x1f, 0xc0, 0x02, 0xdd, 0x8f, 0xf1, 0xd7, 0x78, 0xd2, 0x6c, 0xc6, 0xf7,
+	0xfc, 0x7e, 0xe7, 0x77, 0xee, 0xf5, 0xf1, 0xbd, 0xe7, 0x5c, 0x0d, 0xd9, 0x8e, 0x1e, 0x0d, 0xfa,
+	0x7e, 0x38, 0x38, 0xf1, 0x47, 0xa1, 0x8b, 0x7e, 0x3f, 0x61, 0x36, 0x4b, 0xe4, 0xef, 0x6e, 0x14,
+	0x87, 0x2c, 0xa4, 0xba, 0x30, 0xfe, 0xbd, 0x35, 0x08, 0x07, 0xa1, 0x40, 0xfa, 0x7c, 0x24, 0xc9,
+	0xde, 0x4f, 0xf3, 0x64, 0x11, 0x30, 0x49, 0x7d, 0x46, 0xdf, 0x27, 0x4b, 0x49, 0x3a, 0x1a, 0xd9,
+	0xf1, 0x99, 0xa1, 0x6d, 0x6b, 0x3b, 0x2b, 0xef, 0xac, 0xef, 0xca, 0x30, 0x87, 0x12, 0xb5, 0x36,
+	0x9e, 0x66, 0xe6, 0x5c, 0x9e, 0x99, 0x13, 0x37, 0x98, 0x0c, 0xb8, 0xf4, 0x24, 0xc5, 0xd8, 0xc3,
+	0xd8, 0x98, 0xaf, 0x49, 0x3f, 0x97, 0x68, 0x29, 0x55, 0x6e, 0x30, 0x19, 0xd0, 0xbb, 0x64, 0xd9,
+	0x0b, 0x06, 0x98, 0x30, 0x8c, 0x8d, 0x8e, 0xd0, 0x6e, 0x28, 0xed, 0x7d, 0x05, 0x5b, 0x97, 0x95,
+	0xb8, 0x70, 0x84, 0x62, 0x44, 0xdf, 0x25, 0x8b, 0x8e, 0xed, 0x0c, 0x31, 0x31, 0x16, 0x84, 0x78,
+	0x4d, 0x89, 0xf7, 0x04, 0x68, 0xad, 0x29, 0xa9, 0x2e, 0x9c, 0x40, 0xf9, 0xd2, 0xdb, 0x44, 0xf7,
+	0x02, 0x17, 0x9f, 0x18, 0xba, 0x10, 0xad, 0x16, 0x33, 0xba, 0xf8, 0xa4, 0xd4, 0x08, 0x17, 0x90,
+	0x8f, 0xde, 0x8f, 0x0b, 0x64, 0x71, 0xaf, 0x50, 0x3b, 0xc3, 0x34, 0x78, 0x64, 0x68, 0x35, 0xb5,
+	0x60, 0x2b, 0x33, 0x72, 0x17, 0x90, 0x8f, 0x72, 0xc2, 0xf9, 0xf3, 0x24, 0xd5, 0x09, 0xf9, 0x9b,
+	0xc5, 0xe2, 0xc3, 0x18, 0x9d, 0x16, 0xcd, 0xba, 0xd2, 0x28, 0x1f, 0x50, 0x4f, 0xba, 0x47, 0x56,
+	0x84, 0x9b, 0xfc, 0xa6, 0xc6, 0x42, 0x8b, 0x74, 0x53, 0x49, 0xab, 0x8e, 0x50, 0x35, 0xe8, 0x3e,
+	0x59, 0x3d, 0x0d, 0xfd, 0x74, 0x84, 0x2a, 0x8a, 0xde, 0x12, 0x65, 0x4b, 0x45, 0xa9, 0x79, 0x42,
+	0xcd, 0xe2, 0x71, 0x12, 0xfe, 0x95, 0x27, 0xab, 0x59, 0x3c, 0x2f, 0x4e, 0xd5, 0x13, 0x6a, 0x16,
+	0x7f, 0x29, 0xdf, 0x3e, 0x42, 0x5f, 0x85, 0x59, 0x3a, 0xef, 0xa5, 0x2a, 0x8e, 0x50, 0x35, 0xe8,
+	0xb7, 0x64, 0xd3, 0x0b, 0x12, 0x66, 0x07, 0xec, 0x01, 0xb2, 0xd8, 0x73, 0x54, 0xb0, 0xe5, 0x96,
+	0x60, 0x37, 0x54, 0xb0, 0x36, 0x01, 0xb4, 0x81, 0xbd, 0x3f, 0x96, 0xc8, 0x92, 0x3a, 0x26, 0xf4,
+	0x4b, 0x72, 0xed, 0xe8, 0x8c, 0x61, 0xf2, 0x30, 0x0e, 0x1d, 0x4c, 0x12, 0x74, 0x1f, 0x62, 0x7c,
+	0x88, 0x4e, 0x18, 0xb8, 0x62, 0xc3, 0x74, 0xac, 0x1b, 0x79, 0x66, 0xce, 0x72, 0x81, 0x59, 0x04,
+	0x0f, 0xeb, 0x7b, 0x41, 0x6b, 0xd8, 0xf9, 0x32, 0xec, 0x0c, 0x17, 0x98, 0x45, 0xd0, 0xfb, 0x64,
+	0x93, 0x85, 0xcc, 0xf6, 0xad, 0xda, 0xb4, 0x62, 0xcf, 0x75, 0xac, 0x6b, 0x3c, 0x09, 0x2d, 0x34,
+	0xb4, 0x81, 0x45, 0xa8, 0x83, 0xda, 0x54, 0xc6, 0x42, 0x23, 0x54, 0x9d, 0x86, 0x36, 0x90, 0xee,
+	0x90, 0x65, 0x7c, 0x82, 0xce, 0x17, 0xde, 0x08, 0xc5, 0xee, 0xd3, 0xac, 0x55, 0x5e, 0x00, 0x26,
+	0x18, 0x14, 0x23, 0xfa, 0x16, 0xb9, 0x74, 0x92, 0x62, 0x8a, 0xc2, 0x75, 0x51, 0xb8, 0xae, 0xe5,
+	0x99, 0x59, 0x82, 0x50, 0x0e, 0xe9, 0x2e, 0x21, 0x49, 0x7a, 0x24, 0x4b, 0x4f, 0x22, 0xf6, 0x51,
+	0xc7, 0x5a, 0xcf, 0x33, 0xb3, 0x82, 0x42, 0x65, 0x4c, 0x0f, 0xc8, 0x96, 0x58, 0xdd, 0x27, 0x01,
+	0x13, 0x1c, 0xb2, 0x34, 0x0e, 0xd0, 0x15, 0x9b, 0xa6, 0x63, 0x19, 0x79, 0x66, 0xb6, 0xf2, 0xd0,
+	0x8a, 0xd2, 0x1e, 0x59, 0x4c, 0x22, 0xdf, 0x63, 0x89, 0x71, 0x49, 0xe8, 0x09, 0x3f, 0xbf, 0x12,
+	0x01, 0xf5, 0x14, 0x3e, 0x43, 0x3b, 0x76, 0x13, 0x83, 0x54, 0x7c, 0x04, 0x02, 0xea, 0x59, 0xac,
+	0xea, 0x61, 0x98, 0xb0, 0x7d, 0xcf, 0x67, 0x18, 0x8b, 0xec, 0x19, 0x2b, 0x8d, 0x55, 0x35, 0x78,
+	0x68, 0x45, 0xe9, 0x77, 0xe4, 0x96, 0xc0, 0x0f, 0x59, 0x9c, 0x3a, 0x2c, 0x8d, 0xd1, 0x7d, 0x80,
+	0xcc, 0x76, 0x6d, 0x66, 0x37, 0xb6, 0xc4, 0xaa, 0x08, 0xff, 0x66, 0x9e, 0x99, 0x17, 0x13, 0xc0,
+	0xc5, 0xdc, 0xe8, 0xa7, 0x64, 0xc3, 0x8e, 0x22, 0xdf, 0x43, 0x17, 0xf0, 0x71, 0xec, 0x31, 0x4c,
+	0x8c, 0xb5, 0xed, 0xce, 0xce, 0x25, 0xeb, 0x3f, 0x79, 0x66, 0x5e, 0x6f, 0x50, 0x6f, 0x87, 0x23,
+	0x8f, 0xe1, 0x28, 0x62, 0x67, 0xd0, 0x54, 0xd1, 0x8f, 0xc9, 0xda, 0xb1, 0xed, 0xf9, 0x1c, 0x19,
+	0x85, 0x3c, 0xcc, 0xba, 0x08, 0x23, 0xce, 0x45, 0x8d, 0xa8, 0x04, 0xa9, 0x2b, 0x7a, 0xbf, 0x69,
+	0x44, 0x17, 0x5d, 0x80, 0xde, 0x26, 0x2b, 0x62, 0xf9, 0x7b, 0xbc, 0x7e, 0x27, 0xea, 0xe4, 0x6e,
+	0xf0, 0x0a, 0x53, 0x81, 0xa1, 0x6a, 0xd0, 0x8f, 0xc8, 0xe5, 0xa8, 0x48, 0xae, 0xd2, 0xc9, 0xa3,
+	0xb9, 0x95, 0x67, 0xe6, 0x14, 0x07, 0x53, 0x08, 0xfd, 0x80, 0xac, 0xcb, 0x6f, 0x7c, 0x2f, 0x8d,
+	0x6d, 0xe6, 0x85, 0x81, 0x3a, 0x87, 0x34, 0xcf, 0xcc, 0x06, 0x03, 0x0d, 0xbb, 0xf7, 0x21, 0x59,
+	0x52, 0xdd, 0x96, 0x77, 0x9b, 0x84, 0x85, 0x31, 0x36, 0x1a, 0xd4, 0x21, 0xc7, 0xca, 0x6e, 0x23,
+	0x5c, 0x40, 0x3e, 0x7a, 0xbf, 0xcc, 0x93, 0xe5, 0xfb, 0x65, 0x53, 0x5d, 0x15, 0xef, 0x05, 0xc8,
+	0xcb, 0xa1, 0x2c, 0x5b, 0xba, 0x75, 0x99, 0x57, 0xe9, 0x2a, 0x0e, 0x35, 0x8b, 0xee, 0x13, 0x5a,
+	0xc9, 0xc6, 0x03, 0x9b, 0x09, 0xad, 0x4c, 0xc0, 0xbf, 0xf2, 0xcc, 0x6c, 0x61, 0xa1, 0x05, 0x2b,
+	0x66, 0xb7, 0x84, 0x9d, 0xa8, 0x14, 0x94, 0xb3, 0x2b, 0x1c, 0x6a, 0x16, 0x4f, 0x5d, 0x59, 0x48,
+	0x0e, 0x31, 0x60, 0xc6, 0x42, 0x99, 0xba, 0x3a, 0x03, 0x0d, 0xbb, 0xcc, 0x97, 0x7e, 0xe1, 0x7c,
+	0xfd, 0xbd, 0x40, 0x74, 0xc1, 0x17, 0x13, 0xab, 0x8f, 0x8a, 0xc7, 0x86, 0xd6, 0x98, 0xb8, 0x60,
+	0xa0, 0x61, 0xd3, 0xcf, 0xc8, 0xd5, 0x0a, 0x72, 0x2f, 0x7c, 0x1c, 0xf8, 0xa1, 0xed, 0x16, 0x59,
+	0xbb, 0x9e, 0x67, 0x66, 0xbb, 0x03, 0xb4, 0xc3, 0xfc, 0x1b, 0x38, 0x35, 0x4c, 0x94, 0xc5, 0x4e,
+	0xf9, 0x0d, 0xa6, 0x59, 0x68, 0xc1, 0xa8, 0x43, 0xae, 0xf3, 0x1a, 0x78, 0x06, 0x78, 0x8c, 0x31,
+	0x06, 0x0e, 0xba, 0xe5, 0x31, 0x36, 0xd6, 0xb6, 0xb5, 0x9d, 0x65, 0xeb, 0x56, 0x9e, 0x99, 0xff,
+	0x9d, 0xe9, 0x34, 0x39, 0xeb, 0x30, 0x3b, 0x4e, 0x79, 0x8f, 0x6a, 0xdc, 0x52, 0x38, 0x36, 0xe3,
+	0x1e, 0x35, 0x79, 0x3f, 0xc0, 0xe3, 0x64, 0x1f, 0x99, 0x33, 0x2c, 0x3a, 0x44, 0xf5, 0xfd, 0x6a,
+	0x2c, 0xb4, 0x60, 0xf4, 0x6b, 0x62, 0x38, 0xa1, 0xd8, 0xee, 0x5e, 0x18, 0xec, 0x85, 0x01, 0x8b,
+	0x43, 0xff, 0xc0, 0x66, 0x18, 0x38, 0x67, 0xa2, 0x89, 0x74, 0xac, 0x9b, 0x79, 0x66, 0xce, 0xf4,
+	0x81, 0x99, 0x0c, 0x75, 0xc9, 0xcd, 0xc8, 0x8b, 0x90, 0xb7, 0xdb, 0xaf, 0x62, 0x3b, 0x8a, 0x30,
+	0x96, 0x27, 0x1c, 0x5d, 0x59, 0xa4, 0x65, 0xd3, 0xd9, 0xce, 0x33, 0xf3, 0x5c, 0x3f, 0x38, 0x97,
+	0xed, 0xfd, 0xaa, 0x13, 0x5d, 0xe4, 0x89, 0x6f, 0xbf, 0x21, 0xda, 0xae, 0x4c, 0x1a, 0x2f, 0xac,
+	0xd5, 0x7d, 0x5f, 0x67, 0xa0, 0x61, 0xd7, 0xb4, 0x72, 0x75, 0x7a, 0x8b, 0x56, 0xae, 0xa7, 0x61,
+	0xd3, 0x3d, 0x72, 0xc5, 0x45, 0x27, 0x1c, 0x45, 0xb1, 0xa8, 0xe2, 0x72, 0x6a, 0x99, 0xba, 0xab,
+	0x79, 0x66, 0x4e, 0x93, 0x30, 0x0d, 0x35, 0x83, 0x54, 0x33, 0x34, 0x15, 0x44, 0x2e, 0x63, 0x1a,
+	0xa2, 0x77, 0xc9, 0x46, 0x73, 0x1d, 0xb2, 0x3f, 0x6f, 0xe6, 0x99, 0xd9, 0xa4, 0xa0, 0x09, 0x70,
+	0xb9, 0x38, 0x4b, 0xf7, 0xd2, 0xc8, 0xf7, 0x1c, 0x9b, 0xe1, 0xa4, 0x3d, 0x0b, 0x79, 0x83, 0x82,
+	0x26, 0xc0, 0xe5, 0x51, 0xa3, 0x0f, 0x93, 0x52, 0xde, 0xa0, 0xa0, 0x09, 0xd0, 0x88, 0x6c, 0x17,
+	0x89, 0x9d, 0xd1, 0x29, 0x55, 0x5f, 0xff, 0x7f, 0x9e, 0x99, 0xaf, 0xf5, 0x85, 0xd7, 0x7a, 0xd0,
+	0x33, 0xf2, 0xbf, 0x6a, 0x0e, 0x67, 0x4d, 0x2a, 0xbb, 0xfd, 0x1b, 0x79, 0x66, 0x5e, 0xc4, 0x1d,
+	0x2e, 0xe2, 0xd4, 0xfb, 0xbd, 0x43, 0x74, 0x71, 0xc3, 0xe6, 0x35, 0x1e, 0xe5, 0xed, 0x68, 0x3f,
+	0x4c, 0x83, 0x5a, 0x87, 0xa9, 0xe2, 0x50, 0xb3, 0x78, 0x83, 0xc5, 0xc9, 0x9d, 0xea, 0x24, 0xc5,
+	0x84, 0xa9, 0x4a, 0xa9, 0xcb, 0x06, 0xdb, 0xe4, 0x60, 0x0a, 0xa1, 0xef, 0x91, 0x35, 0x85, 0x89,
+	0xe2, 0x2d, 0xef, 0xb9, 0xba, 0x75, 0x25, 0xcf, 0xcc, 0x3a, 0x01, 0x75, 0x93, 0x0b, 0xc5, 0xc5,
+	0x1c, 0xd0, 0x41, 0xef, 0xb4, 0xb8, 0xd5, 0x0a, 0x61, 0x8d, 0x80, 0xba, 0xc9, 0xef, 0xa7, 0x02,
+	0x10, 0x2d, 0x49, 0x1e, 0x2f, 0x71, 0x3f, 0x2d, 0x40, 0x28, 0x87, 0xfc, 0xda, 0x1b, 0xcb, 0xb5,
+	0xca, 0xb3, 0xa4, 0xcb, 0x6b, 0xef, 0x04, 0x83, 0x62, 0xc4, 0x13, 0xe8, 0x56, 0x4b, 0xfc, 0x52,
+	0xd9, 0x24, 0xab, 0x38, 0xd4, 0x2c, 0x7e, 0xde, 0x44, 0x39, 0x3e, 0xc0, 0x60, 0xc0, 0x86, 0x87,
+	0x18, 0x9f, 0x16, 0x97, 0x59, 0x71, 0xde, 0xa6, 0x48, 0x98, 0x86, 0x2c, 0x7c, 0xf6, 0xa2, 0x3b,
+	0xf7, 0xfc, 0x45, 0x77, 0xee, 0xd5, 0x8b, 0xae, 0xf6, 0xfd, 0xb8, 0xab, 0xfd, 0x3c, 0xee, 0x6a,
+	0x4f, 0xc7, 0x5d, 0xed, 0xd9, 0xb8, 0xab, 0xfd, 0x39, 0xee, 0x6a, 0x7f, 0x8d, 0xbb, 0x73, 0xaf,
+	0xc6, 0x5d, 0xed, 0x87, 0x97, 0xdd, 0xb9, 0x67, 0x2f, 0xbb, 0x73, 0xcf, 0x5f, 0x76, 0xe7, 0xbe,
+	0xe9, 0x0f, 0x3c, 0x36, 0x4c, 0x8f, 0x76, 0x9d, 0x70, 0xd4, 0x1f, 0xc4, 0xf6, 0xb1, 0x1d, 0xd8,
+	0x7d, 0x3f, 0x7c, 0xe4, 0xf5, 0x4f, 0xef, 0xf4, 0xdb, 0xfe, 0xc2, 0x38, 0x5a, 0x14, 0x7f, 0x50,
+	0xdc, 0xf9, 0x67, 0x00, 0x47, 0x90, 0xf2, 0xf3, 0xe1, 0x10, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -1099,6 +1120,22 @@ func (this *Summary) Equal(that interface{}) bool {
 	if this.TotalStructuredMetadataBytesProcessed != that1.TotalStructuredMetadataBytesProcessed {
 		return false
 	}
+	if len(this.AppliedRewrites) != len(that1.AppliedRewrites) {
+		return false
+	}
+	for i := range this.AppliedRewrites {
+		if this.AppliedRewrites[i] != that1.AppliedRewrites[i] {
+			return false
+		}
+	}
+	if len(this.FailedRemotes) != len(that1.FailedRemotes) {
+		return false
+	}
+	for i := range this.FailedRemotes {
+		if this.FailedRemotes[i] != that1.FailedRemotes[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *Index) Equal(that interface{}) bool {
@@ -1364,7 +1401,7 @@ func (this *Summary) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 16)
+	s := make([]string, 0, 18)
 	s = append(s, "&stats.Summary{")
 	s = append(s, "BytesProcessedPerSecond: "+fmt.Sprintf("%#v", this.BytesProcessedPerSecond)+",\n")
 	s = append(s, "LinesProcessedPerSecond: "+fmt.Sprintf("%#v", this.LinesProcessedPerSecond)+",\n")
@@ -1378,6 +1415,8 @@ func (this *Summary) GoString() string {
 	s = append(s, "Shards: "+fmt.Sprintf("%#v", this.Shards)+",\n")
 	s = append(s, "TotalPostFilterLines: "+fmt.Sprintf("%#v", this.TotalPostFilterLines)+",\n")
 	s = append(s, "TotalStructuredMetadataBytesProcessed: "+fmt.Sprintf("%#v", this.TotalStructuredMetadataBytesProcessed)+",\n")
+	s = append(s, "AppliedRewrites: "+fmt.Sprintf("%#v", this.AppliedRewrites)+",\n")
+	s = append(s, "FailedRemotes: "+fmt.Sprintf("%#v", this.FailedRemotes)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1673,6 +1712,24 @@ func (m *Summary) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.FailedRemotes) > 0 {
+		for iNdEx := len(m.FailedRemotes) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.FailedRemotes[iNdEx])
+			copy(dAtA[i:], m.FailedRemotes[iNdEx])
+			i = encodeVarintStats(dAtA, i, uint64(len(m.FailedRemotes[iNdEx])))
+			i--
+			dAtA[i] = 0x72
+		}
+	}
+	if len(m.AppliedRewrites) > 0 {
+		for iNdEx := len(m.AppliedRewrites) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.AppliedRewrites[iNdEx])
+			copy(dAtA[i:], m.AppliedRewrites[iNdEx])
+			i = encodeVarintStats(dAtA, i, uint64(len(m.AppliedRewrites[iNdEx])))
+			i--
+			dAtA[i] = 0x6a
+		}
+	}
 	if m.TotalStructuredMetadataBytesProcessed != 0 {
 		i = encodeVarintStats(dAtA, i, uint64(m.TotalStructuredMetadataBytesProcessed))
 		i--
@@ -2163,6 +2220,18 @@ func (m *Summary) Size() (n int) {
 	if m.TotalStructuredMetadataBytesProcessed != 0 {
 		n += 1 + sovStats(uint64(m.TotalStructuredMetadataBytesProcessed))
 	}
+	if len(m.AppliedRewrites) > 0 {
+		for _, s := range m.AppliedRewrites {
+			l = len(s)
+			n += 1 + l + sovStats(uint64(l))
+		}
+	}
+	if len(m.FailedRemotes) > 0 {
+		for _, s := range m.FailedRemotes {
+			l = len(s)
+			n += 1 + l + sovStats(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2373,6 +2442,8 @@ func (this *Summary) String() string {
 		`Shards:` + fmt.Sprintf("%v", this.Shards) + `,`,
 		`TotalPostFilterLines:` + fmt.Sprintf("%v", this.TotalPostFilterLines) + `,`,
 		`TotalStructuredMetadataBytesProcessed:` + fmt.Sprintf("%v", this.TotalStructuredMetadataBytesProcessed) + `,`,
+		`AppliedRewrites:` + fmt.Sprintf("%v", this.AppliedRewrites) + `,`,
+		`FailedRemotes:` + fmt.Sprintf("%v", this.FailedRemotes) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3249,6 +3320,70 @@ func (m *Summary) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedRewrites", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppliedRewrites = append(m.AppliedRewrites, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FailedRemotes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FailedRemotes = append(m.FailedRemotes, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
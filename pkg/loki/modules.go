@@ -411,7 +411,7 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		return nil, err
 	}
 	if t.Cfg.Pattern.Enabled {
-		patternQuerier, err := pattern.NewIngesterQuerier(t.Cfg.Pattern, t.PatternRingClient, t.Cfg.MetricsNamespace, prometheus.DefaultRegisterer, util_log.Logger)
+		patternQuerier, err := t.patternQuerier()
 		if err != nil {
 			return nil, err
 		}
@@ -613,6 +613,7 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	logproto.RegisterPusherServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterQuerierServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterStreamDataServer(t.Server.GRPC, t.Ingester)
+	ingester.RegisterTransferServer(t.Server.GRPC, t.Ingester)
 
 	httpMiddleware := middleware.Merge(
 		serverutil.RecoveryHTTPMiddleware,
@@ -709,6 +710,18 @@ func (t *Loki) initPatternIngester() (_ services.Service, err error) {
 	return t.PatternIngester, nil
 }
 
+// patternQuerier returns the querier of the pattern ingesters, shared by the querier and the ruler.
+func (t *Loki) patternQuerier() (*pattern.IngesterQuerier, error) {
+	if t.patternIngesterQuerier == nil {
+		patternQuerier, err := pattern.NewIngesterQuerier(t.Cfg.Pattern, t.PatternRingClient, t.Cfg.MetricsNamespace, prometheus.DefaultRegisterer, util_log.Logger)
+		if err != nil {
+			return nil, err
+		}
+		t.patternIngesterQuerier = patternQuerier
+	}
+	return t.patternIngesterQuerier, nil
+}
+
 func (t *Loki) initPatternRingClient() (_ services.Service, err error) {
 	if !t.Cfg.Pattern.Enabled {
 		return nil, nil
@@ -1140,12 +1153,18 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		frontendHandler = gziphandler.GzipHandler(frontendHandler)
 	}
 
+	var queryStatsTracker *queryrange.QueryStatsTracker
+	if t.Cfg.QueryRange.QueryStats.Enabled {
+		logger := log.With(util_log.Logger, "component", "query-stats")
+		queryStatsTracker = queryrange.NewQueryStatsTracker(t.Cfg.QueryRange.QueryStats, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace, logger)
+	}
+
 	toMerge := []middleware.Interface{
 		httpreq.ExtractQueryTagsMiddleware(),
 		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader, httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
-		queryrange.StatsHTTPMiddleware,
+		queryrange.NewStatsHTTPMiddleware(queryStatsTracker),
 		serverutil.NewPrepopulateMiddleware(),
 		serverutil.ResponseJSONMiddleware(),
 	}
@@ -1209,6 +1228,10 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/series").Methods("GET", "POST").Handler(frontendHandler)
 
+	if queryStatsTracker != nil {
+		t.Server.HTTP.Path("/loki/api/v1/query_stats").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(queryStatsTracker))
+	}
+
 	// Only register tailing requests if this process does not act as a Querier
 	// If this process is also a Querier the Querier will register the tail endpoints.
 	if !t.isModuleActive(Querier) {
@@ -1280,12 +1303,23 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 
 	t.Cfg.Ruler.Ring.ListenPort = t.Cfg.Server.GRPCListenPort
 
+	var alertStateClient client.ObjectClient
+	if alertStateStore := t.Cfg.Ruler.AlertState.ObjectStore; alertStateStore != "" {
+		if alertStateClient, err = storage.NewObjectClient(alertStateStore, t.Cfg.StorageConfig, t.ClientMetrics); err != nil {
+			return nil, fmt.Errorf("failed to create ruler alert state object client: %w", err)
+		}
+	}
+
+	evaluationBudgets := ruler.NewEvaluationBudgets(t.Overrides, prometheus.DefaultRegisterer, util_log.Logger)
+
 	t.ruler, err = ruler.NewRuler(
 		t.Cfg.Ruler,
 		t.ruleEvaluator,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 		t.RulerStorage,
+		alertStateClient,
+		evaluationBudgets,
 		t.Overrides,
 		t.Cfg.MetricsNamespace,
 	)
@@ -1324,6 +1358,9 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteNamespace)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.GetRuleGroup)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteRuleGroup)))
+
+		// Evaluation stats of the rules evaluated by this ruler
+		t.Server.HTTP.Path("/loki/api/v1/rule_stats").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(evaluationBudgets))
 	}
 
 	deleteStore, err := t.deleteRequestsClient("ruler", t.Overrides)
@@ -1373,6 +1410,14 @@ func (t *Loki) initRuleEvaluator() (services.Service, error) {
 		return nil, fmt.Errorf("failed to create %s rule evaluator: %w", mode, err)
 	}
 
+	if t.Cfg.Pattern.Enabled {
+		patternQuerier, err := t.patternQuerier()
+		if err != nil {
+			return nil, err
+		}
+		evaluator = ruler.NewPatternEvaluator(evaluator, patternQuerier, logger)
+	}
+
 	t.ruleEvaluator = ruler.NewEvaluatorWithJitter(evaluator, t.Cfg.Ruler.Evaluation.MaxJitter, fnv.New32a(), logger)
 
 	return nil, nil
@@ -1455,7 +1500,18 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		}
 	}
 
-	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, objectClients, deleteRequestStoreClient, t.Cfg.SchemaConfig, t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
+	var tieringStoreClient client.ObjectClient
+	if tieringStore := t.Cfg.StorageConfig.TieringObjectStore; tieringStore != "" {
+		// chunks are moved to the tiering object store while applying retention
+		if !t.Cfg.CompactorConfig.RetentionEnabled {
+			return nil, fmt.Errorf("compactor.retention-enabled should be true when store.tiering-object-store is configured")
+		}
+		if tieringStoreClient, err = storage.NewObjectClient(tieringStore, t.Cfg.StorageConfig, t.ClientMetrics); err != nil {
+			return nil, fmt.Errorf("failed to create tiering object store client: %w", err)
+		}
+	}
+
+	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, objectClients, deleteRequestStoreClient, t.Cfg.StorageConfig.TieringObjectStore, tieringStoreClient, t.Cfg.SchemaConfig, t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
 	if err != nil {
 		return nil, err
 	}
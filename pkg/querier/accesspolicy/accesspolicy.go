@@ -0,0 +1,91 @@
+// Package accesspolicy restricts the streams a query can read within a tenant, by adding
+// mandatory label matchers to every stream selector of the query.
+package accesspolicy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+type Limits interface {
+	// QueryEnforcedMatchers returns the stream selectors whose matchers are added to every
+	// stream selector of the queries of the tenant.
+	QueryEnforcedMatchers(context.Context, string) []string
+}
+
+// Matchers returns the matchers enforced for the tenants of the context.
+// Queries spanning multiple tenants are only allowed if all tenants enforce the same matchers.
+func Matchers(ctx context.Context, limits Limits) ([]*labels.Matcher, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectors []string
+	for i, id := range tenantIDs {
+		s := limits.QueryEnforcedMatchers(ctx, id)
+		if i > 0 && !slices.Equal(selectors, s) {
+			return nil, fmt.Errorf("tenants %s and %s enforce different label matchers and cannot be queried together", tenantIDs[0], id)
+		}
+		selectors = s
+	}
+
+	var matchers []*labels.Matcher
+	for _, s := range selectors {
+		m, err := syntax.ParseMatchers(s, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enforced label matchers %q: %w", s, err)
+		}
+		matchers = append(matchers, m...)
+	}
+	return matchers, nil
+}
+
+// InjectExpr returns a copy of the expression with the matchers added to all its stream selectors.
+func InjectExpr(expr syntax.Expr, matchers []*labels.Matcher) (syntax.Expr, error) {
+	if len(matchers) == 0 {
+		return expr, nil
+	}
+	expr, err := syntax.Clone[syntax.Expr](expr)
+	if err != nil {
+		return nil, err
+	}
+	expr.Walk(func(e syntax.Expr) {
+		if sel, ok := e.(*syntax.MatchersExpr); ok {
+			sel.Mts = append(slices.Clip(sel.Mts), matchers...)
+		}
+	})
+	return expr, nil
+}
+
+// InjectQuery adds the matchers to all the stream selectors of the query.
+// An empty query is replaced by a stream selector made of the matchers.
+func InjectQuery(query string, matchers []*labels.Matcher) (string, error) {
+	if len(matchers) == 0 {
+		return query, nil
+	}
+	if query == "" {
+		return Selector(matchers), nil
+	}
+	// The query is validated by its handler, which might allow empty selectors.
+	expr, err := syntax.ParseExprWithoutValidation(query)
+	if err != nil {
+		return "", err
+	}
+	expr, err = InjectExpr(expr, matchers)
+	if err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+// Selector returns the stream selector made of the matchers.
+func Selector(matchers []*labels.Matcher) string {
+	return (&syntax.MatchersExpr{Mts: matchers}).String()
+}
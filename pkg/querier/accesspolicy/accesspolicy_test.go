@@ -0,0 +1,94 @@
+package accesspolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+type fakeLimits map[string][]string
+
+func (f fakeLimits) QueryEnforcedMatchers(_ context.Context, userID string) []string {
+	return f[userID]
+}
+
+func TestMatchers(t *testing.T) {
+	limits := fakeLimits{
+		"a": {`{namespace=~"team-a.*"}`, `{cluster="eu"}`},
+		"b": {`{namespace=~"team-a.*"}`, `{cluster="eu"}`},
+		"c": {`{namespace="team-c"}`},
+		"d": {`{namespace=~".*"}`},
+	}
+
+	matchers, err := Matchers(user.InjectOrgID(context.Background(), "a"), limits)
+	require.NoError(t, err)
+	require.Equal(t, `{namespace=~"team-a.*", cluster="eu"}`, Selector(matchers))
+
+	matchers, err = Matchers(user.InjectOrgID(context.Background(), "a|b"), limits)
+	require.NoError(t, err)
+	require.Len(t, matchers, 2)
+
+	matchers, err = Matchers(user.InjectOrgID(context.Background(), "e"), limits)
+	require.NoError(t, err)
+	require.Empty(t, matchers)
+
+	_, err = Matchers(user.InjectOrgID(context.Background(), "a|c"), limits)
+	require.Error(t, err)
+
+	_, err = Matchers(user.InjectOrgID(context.Background(), "d"), limits)
+	require.Error(t, err)
+
+	_, err = Matchers(context.Background(), limits)
+	require.Error(t, err)
+}
+
+func TestInjectQuery(t *testing.T) {
+	matchers, err := syntax.ParseMatchers(`{namespace=~"team-a.*"}`, true)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		query    string
+		expected string
+	}{
+		{``, `{namespace=~"team-a.*"}`},
+		{`{}`, `{namespace=~"team-a.*"}`},
+		{`{app="foo"} |= "err" | json`, `{app="foo", namespace=~"team-a.*"} |= "err" | json`},
+		{`{namespace="team-b"}`, `{namespace="team-b", namespace=~"team-a.*"}`},
+		{
+			`sum(rate({app="foo"}[5m])) / sum(rate({app="bar"} | logfmt | unwrap latency [5m]))`,
+			`(sum(rate({app="foo", namespace=~"team-a.*"}[5m])) / sum(rate({app="bar", namespace=~"team-a.*"} | logfmt | unwrap latency[5m])))`,
+		},
+		{
+			`label_replace(rate({app="foo"}[1m]), "dst", "$1", "src", "(.*)")`,
+			`label_replace(rate({app="foo", namespace=~"team-a.*"}[1m]),"dst","$1","src","(.*)")`,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			actual, err := InjectQuery(tc.query, matchers)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	actual, err := InjectQuery(`{app="foo"}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{app="foo"}`, actual)
+
+	_, err = InjectQuery(`{app="foo"`, matchers)
+	require.Error(t, err)
+}
+
+func TestInjectExpr_DoesNotModifyInput(t *testing.T) {
+	matchers, err := syntax.ParseMatchers(`{namespace="team-a"}`, true)
+	require.NoError(t, err)
+
+	expr := syntax.MustParseExpr(`{app="foo"} |= "err"`)
+	injected, err := InjectExpr(expr, matchers)
+	require.NoError(t, err)
+	require.Equal(t, `{app="foo"} |= "err"`, expr.String())
+	require.Equal(t, `{app="foo", namespace="team-a"} |= "err"`, injected.String())
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/grafana/dskit/tenant"
+
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
@@ -22,6 +24,14 @@ func NewQuerierHandler(api *QuerierAPI) *Handler {
 }
 
 func (h *Handler) Do(ctx context.Context, req queryrangebase.Request) (queryrangebase.Response, error) {
+	// The frontend already enforces the access policy, but the querier can also be queried directly.
+	// Requests without a tenant are left to the handlers, which reject them if they need one.
+	if _, err := tenant.TenantIDs(ctx); err == nil {
+		req, err = queryrange.EnforceAccessPolicy(ctx, h.api.limits, req)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	switch concrete := req.(type) {
 	case *queryrange.LokiRequest:
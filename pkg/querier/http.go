@@ -25,6 +25,8 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/accesspolicy"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
 	index_stats "github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/v3/pkg/util/httpreq"
@@ -152,6 +154,11 @@ func (q *QuerierAPI) TailHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := q.enforceTailAccessPolicy(r.Context(), req); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+
 	encodingFlags := httpreq.ExtractEncodingFlags(r)
 	version := loghttp.GetVersion(r.RequestURI)
 
@@ -430,6 +437,25 @@ func (q *QuerierAPI) SamplesHandler(ctx context.Context, req *logproto.QuerySamp
 	return resp, nil
 }
 
+// enforceTailAccessPolicy adds the label matchers enforced for the tenant to the tail request.
+func (q *QuerierAPI) enforceTailAccessPolicy(ctx context.Context, req *logproto.TailRequest) error {
+	matchers, err := accesspolicy.Matchers(ctx, q.limits)
+	if err != nil || len(matchers) == 0 {
+		return err
+	}
+	if req.Plan == nil || req.Plan.AST == nil {
+		req.Query, err = accesspolicy.InjectQuery(req.Query, matchers)
+		return err
+	}
+	expr, err := accesspolicy.InjectExpr(req.Plan.AST, matchers)
+	if err != nil {
+		return err
+	}
+	req.Query = expr.String()
+	req.Plan = &plan.QueryPlan{AST: expr}
+	return nil
+}
+
 func (q *QuerierAPI) validateMaxEntriesLimits(ctx context.Context, expr syntax.Expr, limit uint32) error {
 	tenantIDs, err := tenant.TenantIDs(ctx)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 )
 
 type TimeRangeLimits interface {
@@ -19,4 +20,6 @@ type Limits interface {
 	MaxStreamsMatchersPerQuery(context.Context, string) int
 	MaxConcurrentTailRequests(context.Context, string) int
 	MaxEntriesLimitPerQuery(context.Context, string) int
+	QueryEnforcedMatchers(context.Context, string) []string
+	QueryFieldMaskingRules(context.Context, string) []masking.RuleConfig
 }
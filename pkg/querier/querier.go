@@ -37,10 +37,12 @@ import (
 	querier_limits "github.com/grafana/loki/v3/pkg/querier/limits"
 	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/detected"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	listutil "github.com/grafana/loki/v3/pkg/util"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 	"github.com/grafana/loki/v3/pkg/util/spanlogger"
 	util_validation "github.com/grafana/loki/v3/pkg/util/validation"
 )
@@ -159,6 +161,15 @@ func (q *SingleTenantQuerier) SelectLogs(ctx context.Context, params logql.Selec
 		level.Error(spanlogger.FromContext(ctx)).Log("msg", "failed loading deletes for user", "err", err)
 	}
 
+	expr, err := params.LogSelector()
+	if err != nil {
+		return nil, err
+	}
+	params.QueryRequest.FieldMasks, _, err = q.fieldMasksForUser(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+
 	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(params.Start, params.End)
 
 	sp := opentracing.SpanFromContext(ctx)
@@ -218,6 +229,15 @@ func (q *SingleTenantQuerier) SelectSamples(ctx context.Context, params logql.Se
 		level.Error(spanlogger.FromContext(ctx)).Log("msg", "failed loading deletes for user", "err", err)
 	}
 
+	expr, err := params.Expr()
+	if err != nil {
+		return nil, err
+	}
+	params.SampleQueryRequest.FieldMasks, _, err = q.fieldMasksForUser(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+
 	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(params.Start, params.End)
 
 	iters := []iter.SampleIterator{}
@@ -281,6 +301,25 @@ func (q *SingleTenantQuerier) deletesForUser(ctx context.Context, startT, endT t
 	return deletes, nil
 }
 
+// fieldMasksForUser returns the field masks of the tenant and their masker, after checking
+// that the query doesn't read the masked fields.
+func (q *SingleTenantQuerier) fieldMasksForUser(ctx context.Context, expr syntax.Expr) ([]*logproto.FieldMask, *logql_log.FieldMasker, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	masks := masking.FieldMasks(q.limits.QueryFieldMaskingRules(ctx, userID))
+	masker, err := masking.Masker(masks)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := masking.ValidateExpr(expr, masker); err != nil {
+		return nil, nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	return masks, masker, nil
+}
+
 func (q *SingleTenantQuerier) isWithinIngesterMaxLookbackPeriod(maxLookback time.Duration, queryEnd time.Time) bool {
 	// if no lookback limits are configured, always consider this within the range of the lookback period
 	if maxLookback <= 0 {
@@ -478,6 +517,11 @@ func (q *SingleTenantQuerier) Tail(ctx context.Context, req *logproto.TailReques
 		level.Error(spanlogger.FromContext(ctx)).Log("msg", "failed loading deletes for user", "err", err)
 	}
 
+	req.FieldMasks, _, err = q.fieldMasksForUser(ctx, req.Plan.AST)
+	if err != nil {
+		return nil, err
+	}
+
 	histReq := logql.SelectLogParams{
 		QueryRequest: &logproto.QueryRequest{
 			Selector:  req.Query,
@@ -1104,6 +1148,11 @@ func (q *SingleTenantQuerier) DetectedFields(ctx context.Context, req *logproto.
 		},
 	}
 
+	_, masker, err := q.fieldMasksForUser(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+
 	iters, err := q.SelectLogs(ctx, params)
 	if err != nil {
 		return nil, err
@@ -1141,6 +1190,11 @@ func (q *SingleTenantQuerier) DetectedFields(ctx context.Context, req *logproto.
 		fieldCount++
 	}
 
+	fields, err = detected.MaskFields(fields[:fieldCount], masker)
+	if err != nil {
+		return nil, err
+	}
+
 	//TODO: detected fields response needs to include the sketch
 	return &logproto.DetectedFieldsResponse{
 		Fields:     fields,
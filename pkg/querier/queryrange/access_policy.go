@@ -0,0 +1,182 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/accesspolicy"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+// NewAccessPolicyMiddleware creates a middleware adding the label matchers enforced for the
+// tenant to the requests, before they are cached, split and sharded.
+func NewAccessPolicyMiddleware(limits accesspolicy.Limits) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			r, err := EnforceAccessPolicy(ctx, limits, r)
+			if err != nil {
+				return nil, err
+			}
+			return next.Do(ctx, r)
+		})
+	})
+}
+
+// EnforceAccessPolicy returns a copy of the request restricted to the streams matching the
+// label matchers enforced for the tenant. The request is returned unchanged if no matchers are enforced.
+func EnforceAccessPolicy(ctx context.Context, limits accesspolicy.Limits, r queryrangebase.Request) (queryrangebase.Request, error) {
+	matchers, err := accesspolicy.Matchers(ctx, limits)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	if len(matchers) == 0 {
+		return r, nil
+	}
+
+	r, err = enforceAccessPolicy(r, matchers)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	return r, nil
+}
+
+func enforceAccessPolicy(r queryrangebase.Request, matchers []*labels.Matcher) (queryrangebase.Request, error) {
+	switch req := r.(type) {
+	case *LokiRequest:
+		clone := *req
+		query, p, err := injectPlan(req.Query, req.Plan, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query, clone.Plan = query, p
+		return &clone, nil
+	case *LokiInstantRequest:
+		clone := *req
+		query, p, err := injectPlan(req.Query, req.Plan, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query, clone.Plan = query, p
+		return &clone, nil
+	case *LokiSeriesRequest:
+		clone := *req
+		if len(req.Match) == 0 {
+			clone.Match = []string{accesspolicy.Selector(matchers)}
+			return &clone, nil
+		}
+		clone.Match = make([]string, 0, len(req.Match))
+		for _, m := range req.Match {
+			match, err := accesspolicy.InjectQuery(m, matchers)
+			if err != nil {
+				return nil, err
+			}
+			clone.Match = append(clone.Match, match)
+		}
+		return &clone, nil
+	case *LabelRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	case *logproto.IndexStatsRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Matchers, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Matchers = query
+		return &clone, nil
+	case *logproto.VolumeRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Matchers, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Matchers = query
+		// Without target labels, volumes are grouped by the labels of the matchers, which
+		// must not change with the enforced matchers.
+		if len(req.TargetLabels) == 0 {
+			clone.TargetLabels = matcherNames(req.Matchers)
+		}
+		return &clone, nil
+	case *logproto.ShardsRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	case *logproto.QueryPatternsRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	case *logproto.QuerySamplesRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	case *DetectedFieldsRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	case *DetectedLabelsRequest:
+		clone := *req
+		query, err := accesspolicy.InjectQuery(req.Query, matchers)
+		if err != nil {
+			return nil, err
+		}
+		clone.Query = query
+		return &clone, nil
+	default:
+		return r, nil
+	}
+}
+
+// injectPlan adds the matchers to the query and to its plan, if any.
+func injectPlan(query string, p *plan.QueryPlan, matchers []*labels.Matcher) (string, *plan.QueryPlan, error) {
+	if p == nil || p.AST == nil {
+		query, err := accesspolicy.InjectQuery(query, matchers)
+		return query, p, err
+	}
+	expr, err := accesspolicy.InjectExpr(p.AST, matchers)
+	if err != nil {
+		return "", nil, err
+	}
+	return expr.String(), &plan.QueryPlan{AST: expr}, nil
+}
+
+// matcherNames returns the names of the labels of a stream selector.
+func matcherNames(selector string) []string {
+	matchers, err := syntax.ParseMatchers(selector, false)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, m := range matchers {
+		if m.Name != "" {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
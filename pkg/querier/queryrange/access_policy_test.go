@@ -0,0 +1,128 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+func TestAccessPolicyMiddleware(t *testing.T) {
+	limits := fakeLimits{
+		queryEnforcedMatchers: []string{`{namespace=~"team-a.*"}`},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		req      queryrangebase.Request
+		expected queryrangebase.Request
+	}{
+		{
+			name:     "range query",
+			req:      newRewriteTestRequest(`sum(rate({app="foo"} |= "error" [5m]))`, false),
+			expected: newRewriteTestRequest(`sum(rate({app="foo", namespace=~"team-a.*"} |= "error"[5m]))`, false),
+		},
+		{
+			name:     "instant query",
+			req:      newRewriteTestRequest(`{app="foo"} | json`, true),
+			expected: newRewriteTestRequest(`{app="foo", namespace=~"team-a.*"} | json`, true),
+		},
+		{
+			name:     "series",
+			req:      &LokiSeriesRequest{Match: []string{`{app="foo"}`, `{app="bar"}`}},
+			expected: &LokiSeriesRequest{Match: []string{`{app="foo", namespace=~"team-a.*"}`, `{app="bar", namespace=~"team-a.*"}`}},
+		},
+		{
+			name:     "series without matchers",
+			req:      &LokiSeriesRequest{},
+			expected: &LokiSeriesRequest{Match: []string{`{namespace=~"team-a.*"}`}},
+		},
+		{
+			name:     "labels without query",
+			req:      &LabelRequest{LabelRequest: logproto.LabelRequest{Name: "app", Values: true}},
+			expected: &LabelRequest{LabelRequest: logproto.LabelRequest{Name: "app", Values: true, Query: `{namespace=~"team-a.*"}`}},
+		},
+		{
+			name:     "index stats",
+			req:      &logproto.IndexStatsRequest{Matchers: `{app="foo"}`},
+			expected: &logproto.IndexStatsRequest{Matchers: `{app="foo", namespace=~"team-a.*"}`},
+		},
+		{
+			name:     "volume keeps its grouping",
+			req:      &logproto.VolumeRequest{Matchers: `{app="foo"}`},
+			expected: &logproto.VolumeRequest{Matchers: `{app="foo", namespace=~"team-a.*"}`, TargetLabels: []string{"app"}},
+		},
+		{
+			name:     "volume with target labels",
+			req:      &logproto.VolumeRequest{Matchers: `{app="foo"}`, TargetLabels: []string{"pod"}},
+			expected: &logproto.VolumeRequest{Matchers: `{app="foo", namespace=~"team-a.*"}`, TargetLabels: []string{"pod"}},
+		},
+		{
+			name:     "detected fields",
+			req:      &DetectedFieldsRequest{DetectedFieldsRequest: logproto.DetectedFieldsRequest{Query: `{app="foo"}`}},
+			expected: &DetectedFieldsRequest{DetectedFieldsRequest: logproto.DetectedFieldsRequest{Query: `{app="foo", namespace=~"team-a.*"}`}},
+		},
+		{
+			name:     "patterns",
+			req:      &logproto.QueryPatternsRequest{Query: `{app="foo"}`},
+			expected: &logproto.QueryPatternsRequest{Query: `{app="foo", namespace=~"team-a.*"}`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var received queryrangebase.Request
+			handler := NewAccessPolicyMiddleware(limits).Wrap(
+				queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+					received = r
+					return &LokiResponse{}, nil
+				}),
+			)
+
+			original := tc.req.GetQuery()
+			_, err := handler.Do(user.InjectOrgID(context.Background(), "1"), tc.req)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.GetQuery(), received.GetQuery())
+			require.Equal(t, original, tc.req.GetQuery())
+
+			switch expected := tc.expected.(type) {
+			case *LokiRequest:
+				require.Equal(t, expected.Plan.String(), received.(*LokiRequest).Plan.String())
+			case *LokiInstantRequest:
+				require.Equal(t, expected.Plan.String(), received.(*LokiInstantRequest).Plan.String())
+			case *LokiSeriesRequest:
+				require.Equal(t, expected.Match, received.(*LokiSeriesRequest).Match)
+			case *logproto.VolumeRequest:
+				require.Equal(t, expected.TargetLabels, received.(*logproto.VolumeRequest).TargetLabels)
+			}
+		})
+	}
+}
+
+func TestAccessPolicyMiddleware_Errors(t *testing.T) {
+	handler := queryrangebase.HandlerFunc(func(_ context.Context, _ queryrangebase.Request) (queryrangebase.Response, error) {
+		return &LokiResponse{}, nil
+	})
+	req := &LokiRequest{Query: `{app="foo"}`, StartTs: time.Unix(0, 0), EndTs: time.Unix(3600, 0)}
+
+	_, err := NewAccessPolicyMiddleware(fakeLimits{}).Wrap(handler).Do(user.InjectOrgID(context.Background(), "1"), req)
+	require.NoError(t, err)
+
+	_, err = NewAccessPolicyMiddleware(fakeLimits{queryEnforcedMatchers: []string{`{namespace="a"}`}}).Wrap(handler).Do(user.InjectOrgID(context.Background(), "1"), &LokiRequest{Query: `{app="foo"`})
+	requireStatusCode(t, http.StatusBadRequest, err)
+
+	_, err = NewAccessPolicyMiddleware(fakeLimits{queryEnforcedMatchers: []string{`{namespace=~".*"}`}}).Wrap(handler).Do(user.InjectOrgID(context.Background(), "1"), req)
+	requireStatusCode(t, http.StatusBadRequest, err)
+}
+
+func requireStatusCode(t *testing.T, code int, err error) {
+	t.Helper()
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok, "expected an httpgrpc error, got %v", err)
+	require.Equal(t, int32(code), resp.Code)
+}
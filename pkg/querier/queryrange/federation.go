@@ -0,0 +1,334 @@
+package queryrange
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/crypto/tls"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const notFederatedWarning = "query cannot be federated, only the results of the local cluster are returned"
+
+// FederationConfig configures the remote Loki clusters queried along with the local one.
+type FederationConfig struct {
+	Remotes              []RemoteClusterConfig `yaml:"remotes" doc:"description=Remote Loki clusters queried along with the local cluster. Each remote cluster is queried as an additional shard of the log and metric queries, and the results of all the clusters are merged by the query frontend. Queries which cannot be sharded are only executed on the local cluster. Series with the same labels in several clusters are only merged by aggregations."`
+	DefaultRemoteTimeout time.Duration         `yaml:"default_remote_timeout"`
+	AllowPartialResults  bool                  `yaml:"allow_partial_results"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *FederationConfig) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.DefaultRemoteTimeout, "frontend.federation.default-remote-timeout", time.Minute, "Timeout of the queries sent to the remote clusters which don't configure their own timeout. 0 to disable.")
+	f.BoolVar(&cfg.AllowPartialResults, "frontend.federation.allow-partial-results", false, "Return the results of the other clusters when a remote cluster fails or times out, instead of failing the query. The names of the failed remote clusters are reported in the statistics of the response.")
+}
+
+// Validate validates the config.
+func (cfg *FederationConfig) Validate() error {
+	names := make(map[string]struct{}, len(cfg.Remotes))
+	for i, r := range cfg.Remotes {
+		if r.Name == "" {
+			return fmt.Errorf("remote %d: name is required", i)
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("remote %s: duplicate name", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return errors.Wrapf(err, "remote %s: invalid url", r.Name)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("remote %s: url must be an absolute http or https url", r.Name)
+		}
+		if r.Timeout < 0 {
+			return fmt.Errorf("remote %s: timeout must not be negative", r.Name)
+		}
+	}
+	return nil
+}
+
+// RemoteClusterConfig configures a remote Loki cluster.
+type RemoteClusterConfig struct {
+	Name      string           `yaml:"name" doc:"description=Name of the remote cluster, reported in the statistics of the queries when it fails."`
+	URL       string           `yaml:"url" doc:"description=URL of the query frontend of the remote cluster, e.g. https://loki.eu-west.example.com. The query API paths are appended to it."`
+	Timeout   time.Duration    `yaml:"timeout" doc:"description=Timeout of the queries sent to the remote cluster. Defaults to default_remote_timeout."`
+	TLS       tls.ClientConfig `yaml:",inline"`
+	BasicAuth util.BasicAuth   `yaml:",inline"`
+}
+
+type FederationMetrics struct {
+	remoteRequests *prometheus.CounterVec
+}
+
+func NewFederationMetrics(registerer prometheus.Registerer, metricsNamespace string) *FederationMetrics {
+	return &FederationMetrics{
+		remoteRequests: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_federated_remote_requests_total",
+			Help:      "Total number of queries sent to each remote cluster, by status.",
+		}, []string{"remote", "status"}),
+	}
+}
+
+// remoteCluster sends queries to the query frontend of a remote cluster.
+type remoteCluster struct {
+	name      string
+	url       *url.URL
+	timeout   time.Duration
+	basicAuth util.BasicAuth
+	client    *http.Client
+}
+
+func newRemoteCluster(cfg RemoteClusterConfig, defaultTimeout time.Duration) (*remoteCluster, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote %s: invalid url", cfg.Name)
+	}
+	tlsConfig, err := cfg.TLS.GetTLSConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote %s: invalid tls config", cfg.Name)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &remoteCluster{
+		name:      cfg.Name,
+		url:       u,
+		timeout:   timeout,
+		basicAuth: cfg.BasicAuth,
+		client:    &http.Client{Transport: transport},
+	}, nil
+}
+
+func (r *remoteCluster) Do(ctx context.Context, req queryrangebase.Request) (queryrangebase.Response, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	httpReq, err := DefaultCodec.EncodeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	u := *r.url
+	u.Path = path.Join(r.url.Path, httpReq.URL.Path)
+	u.RawQuery = httpReq.URL.RawQuery
+	httpReq.URL = &u
+	httpReq.Host = u.Host
+	// RequestURI is only set for the requests handled over httpgrpc, and must be empty for client requests.
+	httpReq.RequestURI = ""
+	if r.basicAuth.IsEnabled() {
+		httpReq.SetBasicAuth(r.basicAuth.Username, r.basicAuth.Password)
+	}
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return DefaultCodec.DecodeResponse(ctx, resp, req)
+}
+
+// NewFederationMiddleware creates a middleware executing the log and metric queries on the remote clusters
+// as well as on the local one. The remote clusters are additional shards of the queries: the queries are
+// mapped by the shard mapper with one shard per cluster, and the results of the clusters are merged by the
+// downstream engine, like the results of the shards of a single cluster.
+func NewFederationMiddleware(
+	cfg FederationConfig,
+	engineOpts logql.EngineOpts,
+	logger log.Logger,
+	limits Limits,
+	mapperMetrics *logql.MapperMetrics,
+	metrics *FederationMetrics,
+) (queryrangebase.Middleware, error) {
+	if len(cfg.Remotes) == 0 {
+		return queryrangebase.PassthroughMiddleware, nil
+	}
+
+	remotes := make([]*remoteCluster, 0, len(cfg.Remotes))
+	for _, r := range cfg.Remotes {
+		remote, err := newRemoteCluster(r, cfg.DefaultRemoteTimeout)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, remote)
+	}
+
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		logger := log.With(logger, "middleware", "Federation")
+		return &federation{
+			next:    next,
+			logger:  logger,
+			metrics: mapperMetrics,
+			shards:  len(remotes) + 1,
+			ng: logql.NewDownstreamEngine(engineOpts, federatedDownstreamer{
+				local:               next,
+				remotes:             remotes,
+				allowPartialResults: cfg.AllowPartialResults,
+				logger:              logger,
+				metrics:             metrics,
+			}, limits, logger),
+		}
+	}), nil
+}
+
+type federation struct {
+	next    queryrangebase.Handler
+	logger  log.Logger
+	metrics *logql.MapperMetrics
+	shards  int
+	ng      *logql.DownstreamEngine
+}
+
+func (f *federation) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	var path string
+	switch req := r.(type) {
+	case *LokiRequest:
+		path = req.GetPath()
+	case *LokiInstantRequest:
+		path = req.GetPath()
+	default:
+		return f.next.Do(ctx, r)
+	}
+
+	params, err := ParamsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quantile sketches and first/last over time merges can't be sent over the query API,
+	// so these aggregations are not mapped.
+	mapper := logql.NewShardMapper(logql.NewPowerOfTwoStrategy(logql.ConstantShards(f.shards)), f.metrics, nil)
+	noop, _, parsed, err := mapper.Parse(params.GetExpression())
+	if err != nil {
+		return nil, err
+	}
+	if noop || !logql.IsFullySharded(parsed) {
+		level.Debug(util_log.WithContext(ctx, f.logger)).Log("msg", notFederatedWarning, "query", r.GetQuery())
+		resp, err := f.next.Do(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return withWarning(resp, notFederatedWarning), nil
+	}
+
+	query := f.ng.Query(ctx, logql.ParamsWithExpressionOverride{Params: params, ExpressionOverride: parsed})
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resultToResponse(res, params, path)
+}
+
+func withWarning(resp queryrangebase.Response, warning string) queryrangebase.Response {
+	switch r := resp.(type) {
+	case *LokiResponse:
+		r.Warnings = append(r.Warnings, warning)
+	case *LokiPromResponse:
+		if r.Response != nil {
+			r.Response.Warnings = append(r.Response.Warnings, warning)
+		}
+	}
+	return resp
+}
+
+// federatedDownstreamer sends the first shard of the queries to the local cluster, and the others
+// to the remote clusters.
+type federatedDownstreamer struct {
+	local               queryrangebase.Handler
+	remotes             []*remoteCluster
+	allowPartialResults bool
+	logger              log.Logger
+	metrics             *FederationMetrics
+}
+
+func (d federatedDownstreamer) Downstreamer(_ context.Context) logql.Downstreamer {
+	return d
+}
+
+func (d federatedDownstreamer) Downstream(ctx context.Context, queries []logql.DownstreamQuery, acc logql.Accumulator) ([]logqlmodel.Result, error) {
+	in := instance{parallelism: len(queries)}
+	return in.For(ctx, queries, acc, func(qry logql.DownstreamQuery) (logqlmodel.Result, error) {
+		shards, _, err := logql.ParseShards(qry.Params.Shards())
+		if err != nil {
+			return logqlmodel.Result{}, err
+		}
+		if len(shards) != 1 || shards[0].PowerOfTwo == nil || int(shards[0].PowerOfTwo.Shard) > len(d.remotes) {
+			return logqlmodel.Result{}, fmt.Errorf("unexpected federated query shards %v", qry.Params.Shards())
+		}
+
+		// The clusters are queried without shards, they shard the queries themselves.
+		req := ParamsToLokiRequest(logql.ParamsWithShardsOverride{Params: qry.Params}).WithQuery(qry.Params.GetExpression().String())
+		i := int(shards[0].PowerOfTwo.Shard)
+		if i == 0 {
+			res, err := d.local.Do(ctx, req)
+			if err != nil {
+				return logqlmodel.Result{}, err
+			}
+			return ResponseToResult(res)
+		}
+
+		remote := d.remotes[i-1]
+		sp, ctx := opentracing.StartSpanFromContext(ctx, "federatedDownstreamer.remote")
+		defer sp.Finish()
+		sp.LogKV("remote", remote.name, "query", req.GetQuery())
+
+		res, err := remote.Do(ctx, req)
+		if err == nil {
+			var result logqlmodel.Result
+			if result, err = ResponseToResult(res); err == nil {
+				d.metrics.remoteRequests.WithLabelValues(remote.name, "success").Inc()
+				return result, nil
+			}
+		}
+
+		d.metrics.remoteRequests.WithLabelValues(remote.name, "failure").Inc()
+		level.Warn(util_log.WithContext(ctx, d.logger)).Log("msg", "remote cluster query failed", "remote", remote.name, "query", req.GetQuery(), "err", err)
+		if !d.allowPartialResults {
+			return logqlmodel.Result{}, errors.Wrapf(err, "remote cluster %s", remote.name)
+		}
+		return missingResult(qry.Params, remote.name, err), nil
+	})
+}
+
+// missingResult returns the empty result standing for the results of a failed remote cluster.
+func missingResult(params logql.Params, remote string, err error) logqlmodel.Result {
+	res := logqlmodel.Result{
+		Statistics: stats.Result{Summary: stats.Summary{FailedRemotes: []string{remote}}},
+		Warnings:   []string{fmt.Sprintf("results of remote cluster %s are missing: %s", remote, err)},
+	}
+	if _, ok := params.GetExpression().(syntax.LogSelectorExpr); ok {
+		res.Data = logqlmodel.Streams{}
+	} else if logql.GetRangeType(params) == logql.InstantType {
+		res.Data = promql.Vector{}
+	} else {
+		res.Data = promql.Matrix{}
+	}
+	return res
+}
@@ -0,0 +1,179 @@
+package queryrange
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func TestFederationConfig_Validate(t *testing.T) {
+	require.NoError(t, (&FederationConfig{}).Validate())
+	require.NoError(t, (&FederationConfig{Remotes: []RemoteClusterConfig{{Name: "eu", URL: "https://loki.eu.example.com/prefix"}}}).Validate())
+	require.Error(t, (&FederationConfig{Remotes: []RemoteClusterConfig{{URL: "https://loki.eu.example.com"}}}).Validate())
+	require.Error(t, (&FederationConfig{Remotes: []RemoteClusterConfig{{Name: "eu", URL: "loki.eu.example.com"}}}).Validate())
+	require.Error(t, (&FederationConfig{Remotes: []RemoteClusterConfig{
+		{Name: "eu", URL: "https://loki.eu.example.com"},
+		{Name: "eu", URL: "https://loki.eu2.example.com"},
+	}}).Validate())
+}
+
+func federationVector(v float64) *LokiPromResponse {
+	return &LokiPromResponse{
+		Response: &queryrangebase.PrometheusResponse{
+			Status: loghttp.QueryStatusSuccess,
+			Data: queryrangebase.PrometheusData{
+				ResultType: loghttp.ResultTypeVector,
+				Result: []queryrangebase.SampleStream{{
+					Labels:  []logproto.LabelAdapter{{Name: "app", Value: "foo"}},
+					Samples: []logproto.LegacySample{{Value: v, TimestampMs: 0}},
+				}},
+			},
+		},
+	}
+}
+
+func federationStreams(entries ...logproto.Entry) *LokiResponse {
+	return &LokiResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+			Result:     []logproto.Stream{{Labels: `{app="foo"}`, Entries: entries}},
+		},
+	}
+}
+
+// newRemoteServer returns a remote cluster answering the queries of the tenant with resp.
+func newRemoteServer(t *testing.T, delay time.Duration, resp queryrangebase.Response) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(user.OrgIDHeaderName) != "1" {
+			http.Error(w, "no org id", http.StatusUnauthorized)
+			return
+		}
+		if resp == nil {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(delay)
+
+		res, err := DefaultCodec.EncodeResponse(r.Context(), r, resp)
+		require.NoError(t, err)
+		w.WriteHeader(res.StatusCode)
+		_, err = io.Copy(w, res.Body)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newFederationHandler(t *testing.T, cfg FederationConfig, local queryrangebase.Response) queryrangebase.Handler {
+	mw, err := NewFederationMiddleware(cfg, testEngineOpts, util_log.Logger, fakeLimits{maxSeries: math.MaxInt32, maxQueryParallelism: 1, queryTimeout: time.Minute}, nilShardingMetrics, NewFederationMetrics(prometheus.NewRegistry(), "loki"))
+	require.NoError(t, err)
+	return mw.Wrap(queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		require.Empty(t, r.(interface{ GetShards() []string }).GetShards())
+		return local, nil
+	}))
+}
+
+func TestFederationMiddleware_Metrics(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	eu := newRemoteServer(t, 0, federationVector(4))
+	failing := newRemoteServer(t, 0, nil)
+	slow := newRemoteServer(t, 200*time.Millisecond, federationVector(10))
+
+	for _, tc := range []struct {
+		name          string
+		remotes       []RemoteClusterConfig
+		partial       bool
+		expected      float64
+		failedRemotes []string
+		err           bool
+	}{
+		{
+			name:     "all clusters",
+			remotes:  []RemoteClusterConfig{{Name: "eu", URL: eu.URL}},
+			expected: 7,
+		},
+		{
+			name:          "failed remote with partial results",
+			remotes:       []RemoteClusterConfig{{Name: "eu", URL: eu.URL}, {Name: "us", URL: failing.URL}},
+			partial:       true,
+			expected:      7,
+			failedRemotes: []string{"us"},
+		},
+		{
+			name:    "failed remote",
+			remotes: []RemoteClusterConfig{{Name: "eu", URL: eu.URL}, {Name: "us", URL: failing.URL}},
+			err:     true,
+		},
+		{
+			name:          "timed out remote",
+			remotes:       []RemoteClusterConfig{{Name: "eu", URL: eu.URL}, {Name: "ap", URL: slow.URL, Timeout: 20 * time.Millisecond}},
+			partial:       true,
+			expected:      7,
+			failedRemotes: []string{"ap"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := newFederationHandler(t, FederationConfig{Remotes: tc.remotes, AllowPartialResults: tc.partial}, federationVector(3))
+
+			resp, err := handler.Do(ctx, newRewriteTestRequest(`sum by (app) (count_over_time({app="foo"}[1m]))`, true))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			res := resp.(*LokiPromResponse)
+			require.Len(t, res.Response.Data.Result, 1)
+			require.Equal(t, tc.expected, res.Response.Data.Result[0].Samples[0].Value)
+			require.Equal(t, tc.failedRemotes, res.Statistics.Summary.FailedRemotes)
+			require.Len(t, res.Response.Warnings, len(tc.failedRemotes))
+		})
+	}
+}
+
+func TestFederationMiddleware_Logs(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	eu := newRemoteServer(t, 0, federationStreams(logproto.Entry{Timestamp: time.Unix(2, 0), Line: "eu"}))
+	handler := newFederationHandler(t, FederationConfig{Remotes: []RemoteClusterConfig{{Name: "eu", URL: eu.URL}}},
+		federationStreams(logproto.Entry{Timestamp: time.Unix(1, 0), Line: "local"}, logproto.Entry{Timestamp: time.Unix(3, 0), Line: "local"}))
+
+	req := newRewriteTestRequest(`{app="foo"} |= "a"`, false).(*LokiRequest)
+	req.Limit = 100
+	req.Direction = logproto.FORWARD
+	resp, err := handler.Do(ctx, req)
+	require.NoError(t, err)
+
+	res := resp.(*LokiResponse)
+	require.Len(t, res.Data.Result, 1)
+	var lines []string
+	for _, e := range res.Data.Result[0].Entries {
+		lines = append(lines, e.Line)
+	}
+	require.Equal(t, []string{"local", "eu", "local"}, lines)
+}
+
+func TestFederationMiddleware_NotFederated(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	eu := newRemoteServer(t, 0, federationVector(4))
+	handler := newFederationHandler(t, FederationConfig{Remotes: []RemoteClusterConfig{{Name: "eu", URL: eu.URL}}}, federationVector(3))
+
+	resp, err := handler.Do(ctx, newRewriteTestRequest(`quantile_over_time(0.99, {app="foo"} | unwrap latency [1m]) by (app)`, true))
+	require.NoError(t, err)
+	res := resp.(*LokiPromResponse)
+	require.Equal(t, float64(3), res.Response.Data.Result[0].Samples[0].Value)
+	require.Equal(t, []string{notFederatedWarning}, res.Response.Warnings)
+}
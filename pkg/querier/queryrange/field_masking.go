@@ -0,0 +1,72 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/masking"
+	"github.com/grafana/loki/v3/pkg/util/querylimits"
+)
+
+// NewFieldMaskingMiddleware creates a middleware rejecting the queries reading fields masked
+// for the tenant, before they are split and sharded. The fields are masked by the queriers.
+// Requests allowed to read the masked fields are not cached, as the cache is shared by all
+// the requests of the tenant.
+func NewFieldMaskingMiddleware(limits Limits) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			if ql := querylimits.ExtractQueryLimitsContext(ctx); ql != nil && ql.UnmaskFields {
+				r = disableCaching(r)
+			}
+
+			var expr syntax.Expr
+			switch req := r.(type) {
+			case *LokiRequest:
+				if req.Plan != nil {
+					expr = req.Plan.AST
+				}
+			case *LokiInstantRequest:
+				if req.Plan != nil {
+					expr = req.Plan.AST
+				}
+			}
+			if expr == nil {
+				return next.Do(ctx, r)
+			}
+
+			tenantIDs, err := tenant.TenantIDs(ctx)
+			if err != nil {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+			}
+			for _, id := range tenantIDs {
+				masker, err := masking.Masker(masking.FieldMasks(limits.QueryFieldMaskingRules(ctx, id)))
+				if err != nil {
+					return nil, err
+				}
+				if err := masking.ValidateExpr(expr, masker); err != nil {
+					return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+				}
+			}
+			return next.Do(ctx, r)
+		})
+	})
+}
+
+func disableCaching(r queryrangebase.Request) queryrangebase.Request {
+	switch req := r.(type) {
+	case *LokiRequest:
+		clone := *req
+		clone.CachingOptions.Disabled = true
+		return &clone
+	case *LokiInstantRequest:
+		clone := *req
+		clone.CachingOptions.Disabled = true
+		return &clone
+	}
+	return r
+}
@@ -0,0 +1,45 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/masking"
+	"github.com/grafana/loki/v3/pkg/util/querylimits"
+)
+
+func TestFieldMaskingMiddleware(t *testing.T) {
+	limits := fakeLimits{
+		queryFieldMaskingRules: []masking.RuleConfig{{Field: "email", Action: log.MaskHash}},
+	}
+
+	var received queryrangebase.Request
+	handler := NewFieldMaskingMiddleware(limits).Wrap(
+		queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			received = r
+			return &LokiResponse{}, nil
+		}),
+	)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	req := newRewriteTestRequest(`sum by (email) (count_over_time({app="foo"} | json [5m]))`, false)
+	_, err := handler.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, req, received)
+	require.False(t, received.GetCachingOptions().Disabled)
+
+	_, err = handler.Do(ctx, newRewriteTestRequest(`{app="foo"} | json | email="bob@example.com"`, true))
+	requireStatusCode(t, http.StatusBadRequest, err)
+
+	unmaskedCtx := querylimits.InjectQueryLimitsContext(ctx, querylimits.QueryLimits{UnmaskFields: true})
+	_, err = handler.Do(unmaskedCtx, req)
+	require.NoError(t, err)
+	require.True(t, received.GetCachingOptions().Disabled)
+	require.False(t, req.GetCachingOptions().Disabled)
+}
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/rewrite"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 )
 
 // Limits extends the cortex limits interface with support for per tenant splitby parameters
@@ -36,4 +38,8 @@ type Limits interface {
 	MaxStatsCacheFreshness(context.Context, string) time.Duration
 	MaxMetadataCacheFreshness(context.Context, string) time.Duration
 	VolumeEnabled(string) bool
+	QueryEnforcedMatchers(context.Context, string) []string
+	QueryFieldMaskingRules(context.Context, string) []masking.RuleConfig
+	QueryRewriteBuiltinRules(context.Context, string) []string
+	QueryRewriteRules(context.Context, string) []rewrite.SelectorRuleConfig
 }
@@ -18,18 +18,22 @@ type Metrics struct {
 	*SplitByMetrics
 	*LogResultCacheMetrics
 	*QueryMetrics
+	*QueryRewriteMetrics
+	*FederationMetrics
 	*queryrangebase.ResultsCacheMetrics
 }
 
 type MiddlewareMapperMetrics struct {
-	shardMapper *logql.MapperMetrics
-	rangeMapper *logql.MapperMetrics
+	shardMapper      *logql.MapperMetrics
+	rangeMapper      *logql.MapperMetrics
+	federationMapper *logql.MapperMetrics
 }
 
 func NewMiddlewareMapperMetrics(registerer prometheus.Registerer) *MiddlewareMapperMetrics {
 	return &MiddlewareMapperMetrics{
-		shardMapper: logql.NewShardMapperMetrics(registerer),
-		rangeMapper: logql.NewRangeMapperMetrics(registerer),
+		shardMapper:      logql.NewShardMapperMetrics(registerer),
+		rangeMapper:      logql.NewRangeMapperMetrics(registerer),
+		federationMapper: logql.NewFederationMapperMetrics(registerer),
 	}
 }
 
@@ -41,6 +45,8 @@ func NewMetrics(registerer prometheus.Registerer, metricsNamespace string) *Metr
 		SplitByMetrics:              NewSplitByMetrics(registerer),
 		LogResultCacheMetrics:       NewLogResultCacheMetrics(registerer),
 		QueryMetrics:                NewMiddlewareQueryMetrics(registerer, metricsNamespace),
+		QueryRewriteMetrics:         NewQueryRewriteMetrics(registerer, metricsNamespace),
+		FederationMetrics:           NewFederationMetrics(registerer, metricsNamespace),
 		ResultsCacheMetrics:         queryrangebase.NewResultsCacheMetrics(registerer),
 	}
 }
@@ -0,0 +1,141 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logql/rewrite"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+type QueryRewriteMetrics struct {
+	rewrites *prometheus.CounterVec
+	failures prometheus.Counter
+}
+
+func NewQueryRewriteMetrics(registerer prometheus.Registerer, metricsNamespace string) *QueryRewriteMetrics {
+	return &QueryRewriteMetrics{
+		rewrites: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_query_rewrites_total",
+			Help:      "Total number of queries rewritten by each query rewrite rule.",
+		}, []string{"rule"}),
+		failures: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_query_rewrite_failures_total",
+			Help:      "Total number of queries which couldn't be rewritten and were executed unchanged.",
+		}),
+	}
+}
+
+type queryRewrite struct {
+	next    queryrangebase.Handler
+	limits  Limits
+	logger  log.Logger
+	metrics *QueryRewriteMetrics
+}
+
+// NewQueryRewriteMiddleware creates a middleware rewriting log and metric queries with
+// the query rewrite rules of the tenant, before they are split and sharded.
+// The names of the rules applied are reported in the statistics of the response.
+func NewQueryRewriteMiddleware(logger log.Logger, limits Limits, metrics *QueryRewriteMetrics) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return &queryRewrite{
+			next:    next,
+			limits:  limits,
+			logger:  logger,
+			metrics: metrics,
+		}
+	})
+}
+
+func (q *queryRewrite) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+	// Rules are defined per tenant, so queries spanning multiple tenants are not rewritten.
+	if len(tenantIDs) != 1 {
+		return q.next.Do(ctx, r)
+	}
+
+	rules := q.rules(ctx, tenantIDs[0])
+	if len(rules) == 0 {
+		return q.next.Do(ctx, r)
+	}
+
+	var expr syntax.Expr
+	switch req := r.(type) {
+	case *LokiRequest:
+		if req.Plan != nil {
+			expr = req.Plan.AST
+		}
+	case *LokiInstantRequest:
+		if req.Plan != nil {
+			expr = req.Plan.AST
+		}
+	}
+	if expr == nil {
+		return q.next.Do(ctx, r)
+	}
+
+	logger := util_log.WithContext(ctx, q.logger)
+	rewritten, applied, err := rewrite.Rewrite(expr, rules)
+	if err != nil {
+		// rewriting is an optimisation, so the original query is executed instead of failing.
+		level.Warn(logger).Log("msg", "failed to rewrite query", "query", r.GetQuery(), "err", err)
+		q.metrics.failures.Inc()
+		return q.next.Do(ctx, r)
+	}
+	if len(applied) == 0 {
+		return q.next.Do(ctx, r)
+	}
+
+	level.Debug(logger).Log("msg", "rewrote query", "original", r.GetQuery(), "rewritten", rewritten.String(), "rules", strings.Join(applied, ","))
+	for _, name := range applied {
+		q.metrics.rewrites.WithLabelValues(name).Inc()
+	}
+	stats.FromContext(ctx).AddAppliedRewrites(applied...)
+
+	switch req := r.(type) {
+	case *LokiRequest:
+		clone := *req
+		clone.Query = rewritten.String()
+		clone.Plan = &plan.QueryPlan{AST: rewritten}
+		r = &clone
+	case *LokiInstantRequest:
+		clone := *req
+		clone.Query = rewritten.String()
+		clone.Plan = &plan.QueryPlan{AST: rewritten}
+		r = &clone
+	}
+	return q.next.Do(ctx, r)
+}
+
+// rules returns the tenant stream selector rules followed by its enabled built-in rules.
+func (q *queryRewrite) rules(ctx context.Context, tenantID string) []rewrite.Rule {
+	var rules []rewrite.Rule
+	for _, cfg := range q.limits.QueryRewriteRules(ctx, tenantID) {
+		rules = append(rules, cfg.Rule())
+	}
+
+	builtin, err := rewrite.BuiltinRules(q.limits.QueryRewriteBuiltinRules(ctx, tenantID))
+	if err != nil {
+		// the rule names are validated when loading the limits, so this should never happen.
+		level.Warn(q.logger).Log("msg", "ignoring invalid built-in query rewrite rules", "tenant", tenantID, "err", err)
+		return rules
+	}
+	return append(rules, builtin...)
+}
@@ -0,0 +1,95 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/rewrite"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+func TestQueryRewriteMiddleware(t *testing.T) {
+	legacy := rewrite.SelectorRuleConfig{Name: "legacy_app", Selector: `{app="legacy"}`, Replacement: `{app="modern"}`}
+	require.NoError(t, legacy.Validate())
+
+	limits := fakeLimits{
+		queryRewriteBuiltinRules: []string{rewrite.RuleRegexToContains},
+		queryRewriteRules:        []rewrite.SelectorRuleConfig{legacy},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		tenantID string
+		req      queryrangebase.Request
+		expected string
+		applied  []string
+	}{
+		{
+			name:     "range query",
+			tenantID: "1",
+			req:      newRewriteTestRequest(`sum(rate({app="legacy"} |~ "error" [5m]))`, false),
+			expected: `sum(rate({app="modern"} |= "error"[5m]))`,
+			applied:  []string{"legacy_app", rewrite.RuleRegexToContains},
+		},
+		{
+			name:     "instant query",
+			tenantID: "1",
+			req:      newRewriteTestRequest(`{app="foo"} |~ "error"`, true),
+			expected: `{app="foo"} |= "error"`,
+			applied:  []string{rewrite.RuleRegexToContains},
+		},
+		{
+			name:     "no rule applied",
+			tenantID: "1",
+			req:      newRewriteTestRequest(`{app="foo"} |= "error"`, false),
+			expected: `{app="foo"} |= "error"`,
+		},
+		{
+			name:     "multiple tenants",
+			tenantID: "1|2",
+			req:      newRewriteTestRequest(`{app="foo"} |~ "error"`, false),
+			expected: `{app="foo"} |~ "error"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := NewQueryRewriteMetrics(nil, "loki")
+			var received queryrangebase.Request
+			handler := NewQueryRewriteMiddleware(log.NewNopLogger(), limits, metrics).Wrap(
+				queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+					received = r
+					return &LokiResponse{}, nil
+				}),
+			)
+
+			statsCtx, ctx := stats.NewContext(user.InjectOrgID(context.Background(), tc.tenantID))
+			_, err := handler.Do(ctx, tc.req)
+			require.NoError(t, err)
+
+			params, err := ParamsFromRequest(received)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, received.GetQuery())
+			require.Equal(t, tc.expected, params.GetExpression().String())
+			require.Equal(t, tc.applied, statsCtx.Result(0, 0, 0).Summary.AppliedRewrites)
+			for _, name := range tc.applied {
+				require.Equal(t, float64(1), testutil.ToFloat64(metrics.rewrites.WithLabelValues(name)))
+			}
+		})
+	}
+}
+
+func newRewriteTestRequest(query string, instant bool) queryrangebase.Request {
+	p := &plan.QueryPlan{AST: syntax.MustParseExpr(query)}
+	if instant {
+		return &LokiInstantRequest{Query: query, TimeTs: time.Unix(0, 0), Plan: p}
+	}
+	return &LokiRequest{Query: query, StartTs: time.Unix(0, 0), EndTs: time.Unix(3600, 0), Plan: p}
+}
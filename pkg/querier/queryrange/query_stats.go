@@ -0,0 +1,372 @@
+package queryrange
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+const (
+	QueryStatsSortByBytes     = "bytes"
+	QueryStatsSortByCount     = "count"
+	QueryStatsSortByExecTime  = "exec_time"
+	QueryStatsSortByQueueTime = "queue_time"
+	QueryStatsSortByChunks    = "chunks"
+
+	defaultQueryStatsLimit = 20
+)
+
+// QueryStatsConfig configures the aggregation of query statistics per tenant and query shape.
+type QueryStatsConfig struct {
+	Enabled                  bool `yaml:"enabled"`
+	MaxFingerprintsPerTenant int  `yaml:"max_fingerprints_per_tenant"`
+	MetricsTopN              int  `yaml:"metrics_top_n"`
+}
+
+// RegisterFlags adds the flags required to configure this flag set.
+func (cfg *QueryStatsConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "frontend.query-stats.enabled", false, "Aggregate the statistics of log and metric queries per tenant and query fingerprint. The fingerprint is computed from the query with all literals stripped, so that queries of the same shape, e.g. the same dashboard panel, are grouped together. The aggregated statistics are exposed on /loki/api/v1/query_stats.")
+	f.IntVar(&cfg.MaxFingerprintsPerTenant, "frontend.query-stats.max-fingerprints-per-tenant", 1000, "Maximum number of query fingerprints tracked per tenant. When the limit is reached, the least recently seen fingerprint is evicted.")
+	f.IntVar(&cfg.MetricsTopN, "frontend.query-stats.metrics-top-n", 10, "Number of fingerprints per tenant, ordered by bytes processed, exposed as Prometheus metrics. 0 to disable the metrics.")
+}
+
+// Validate validates the config.
+func (cfg *QueryStatsConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MaxFingerprintsPerTenant <= 0 {
+		return errors.New("max_fingerprints_per_tenant must be greater than 0")
+	}
+	if cfg.MetricsTopN < 0 {
+		return errors.New("metrics_top_n must not be negative")
+	}
+	return nil
+}
+
+// QueryShapeStats holds the aggregated statistics of all queries sharing the same fingerprint.
+type QueryShapeStats struct {
+	Fingerprint string    `json:"fingerprint"`
+	Query       string    `json:"query"`
+	LastQuery   string    `json:"lastQuery"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+
+	Count                 int64   `json:"count"`
+	Failures              int64   `json:"failures"`
+	BytesProcessed        int64   `json:"bytesProcessed"`
+	LinesProcessed        int64   `json:"linesProcessed"`
+	ChunksRef             int64   `json:"chunksRef"`
+	ChunksDownloaded      int64   `json:"chunksDownloaded"`
+	ExecTimeSeconds       float64 `json:"execTimeSeconds"`
+	QueueTimeSeconds      float64 `json:"queueTimeSeconds"`
+	CacheEntriesFound     int64   `json:"cacheEntriesFound"`
+	CacheEntriesRequested int64   `json:"cacheEntriesRequested"`
+}
+
+func (s *QueryShapeStats) add(r stats.Result, failed bool) {
+	s.Count++
+	if failed {
+		s.Failures++
+	}
+	s.BytesProcessed += r.Summary.TotalBytesProcessed
+	s.LinesProcessed += r.Summary.TotalLinesProcessed
+	s.ChunksRef += r.TotalChunksRef()
+	s.ChunksDownloaded += r.TotalChunksDownloaded()
+	s.ExecTimeSeconds += r.Summary.ExecTime
+	s.QueueTimeSeconds += r.Summary.QueueTime
+
+	for _, c := range []stats.Cache{
+		r.Caches.Chunk,
+		r.Caches.Index,
+		r.Caches.Result,
+		r.Caches.StatsResult,
+		r.Caches.VolumeResult,
+		r.Caches.SeriesResult,
+		r.Caches.LabelResult,
+		r.Caches.InstantMetricResult,
+	} {
+		s.CacheEntriesFound += int64(c.EntriesFound)
+		s.CacheEntriesRequested += int64(c.EntriesRequested)
+	}
+}
+
+// QueryStatsTracker aggregates query statistics per tenant and query fingerprint.
+// The number of fingerprints is bounded per tenant, and only the top N fingerprints
+// of each tenant are exposed as metrics to keep their cardinality bounded.
+type QueryStatsTracker struct {
+	cfg    QueryStatsConfig
+	logger log.Logger
+
+	mtx     sync.Mutex
+	tenants map[string]map[uint64]*QueryShapeStats
+
+	evictions prometheus.Counter
+
+	queriesDesc          *prometheus.Desc
+	failuresDesc         *prometheus.Desc
+	bytesProcessedDesc   *prometheus.Desc
+	chunksDownloadedDesc *prometheus.Desc
+	execTimeDesc         *prometheus.Desc
+	queueTimeDesc        *prometheus.Desc
+	cacheHitsDesc        *prometheus.Desc
+
+	now func() time.Time
+}
+
+// NewQueryStatsTracker creates a new QueryStatsTracker and registers its metrics.
+func NewQueryStatsTracker(cfg QueryStatsConfig, registerer prometheus.Registerer, metricsNamespace string, logger log.Logger) *QueryStatsTracker {
+	labels := []string{"tenant", "fingerprint"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "query_frontend", name), help, labels, nil)
+	}
+
+	t := &QueryStatsTracker{
+		cfg:     cfg,
+		logger:  logger,
+		tenants: map[string]map[uint64]*QueryShapeStats{},
+		evictions: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_query_shape_evictions_total",
+			Help:      "Total number of query fingerprints evicted because the per tenant limit was reached.",
+		}),
+		queriesDesc:          desc("query_shape_queries_total", "Total number of queries per query fingerprint, for the top fingerprints by bytes processed."),
+		failuresDesc:         desc("query_shape_failures_total", "Total number of failed queries per query fingerprint, for the top fingerprints by bytes processed."),
+		bytesProcessedDesc:   desc("query_shape_bytes_processed_total", "Total bytes processed per query fingerprint, for the top fingerprints by bytes processed."),
+		chunksDownloadedDesc: desc("query_shape_chunks_downloaded_total", "Total chunks downloaded per query fingerprint, for the top fingerprints by bytes processed."),
+		execTimeDesc:         desc("query_shape_exec_time_seconds_total", "Total execution time per query fingerprint, for the top fingerprints by bytes processed."),
+		queueTimeDesc:        desc("query_shape_queue_time_seconds_total", "Total queue time per query fingerprint, for the top fingerprints by bytes processed."),
+		cacheHitsDesc:        desc("query_shape_cache_hits_total", "Total cache hits per query fingerprint, for the top fingerprints by bytes processed."),
+		now:                  time.Now,
+	}
+
+	if registerer != nil && cfg.MetricsTopN > 0 {
+		registerer.MustRegister(t)
+	}
+	return t
+}
+
+// Observe records the statistics of a query for the given tenant.
+func (t *QueryStatsTracker) Observe(tenantID string, expr syntax.Expr, r stats.Result, failed bool) error {
+	fp, err := syntax.Fingerprint(expr)
+	if err != nil {
+		return err
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	shapes, ok := t.tenants[tenantID]
+	if !ok {
+		shapes = map[uint64]*QueryShapeStats{}
+		t.tenants[tenantID] = shapes
+	}
+
+	now := t.now()
+	shape, ok := shapes[fp]
+	if !ok {
+		normalized, err := syntax.Normalize(expr)
+		if err != nil {
+			return err
+		}
+		if len(shapes) >= t.cfg.MaxFingerprintsPerTenant {
+			t.evictOldest(shapes)
+		}
+		shape = &QueryShapeStats{
+			Fingerprint: fmt.Sprintf("%016x", fp),
+			Query:       normalized.String(),
+			FirstSeen:   now,
+		}
+		shapes[fp] = shape
+	}
+
+	shape.LastQuery = expr.String()
+	shape.LastSeen = now
+	shape.add(r, failed)
+	return nil
+}
+
+// evictOldest removes the least recently seen fingerprint. It must be called with the lock held.
+func (t *QueryStatsTracker) evictOldest(shapes map[uint64]*QueryShapeStats) {
+	var (
+		oldestFp uint64
+		oldest   *QueryShapeStats
+	)
+	for fp, s := range shapes {
+		if oldest == nil || s.LastSeen.Before(oldest.LastSeen) {
+			oldestFp, oldest = fp, s
+		}
+	}
+	if oldest != nil {
+		delete(shapes, oldestFp)
+		t.evictions.Inc()
+	}
+}
+
+// TopN returns up to n query shapes of the tenant with the highest value for the given sort key.
+func (t *QueryStatsTracker) TopN(tenantID string, n int, sortBy string) ([]QueryShapeStats, error) {
+	less, err := queryShapeLessFn(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mtx.Lock()
+	res := make([]QueryShapeStats, 0, len(t.tenants[tenantID]))
+	for _, s := range t.tenants[tenantID] {
+		res = append(res, *s)
+	}
+	t.mtx.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if less(res[j], res[i]) {
+			return true
+		}
+		if less(res[i], res[j]) {
+			return false
+		}
+		return res[i].Fingerprint < res[j].Fingerprint
+	})
+
+	if n > 0 && len(res) > n {
+		res = res[:n]
+	}
+	return res, nil
+}
+
+func queryShapeLessFn(sortBy string) (func(a, b QueryShapeStats) bool, error) {
+	switch sortBy {
+	case "", QueryStatsSortByBytes:
+		return func(a, b QueryShapeStats) bool { return a.BytesProcessed < b.BytesProcessed }, nil
+	case QueryStatsSortByCount:
+		return func(a, b QueryShapeStats) bool { return a.Count < b.Count }, nil
+	case QueryStatsSortByExecTime:
+		return func(a, b QueryShapeStats) bool { return a.ExecTimeSeconds < b.ExecTimeSeconds }, nil
+	case QueryStatsSortByQueueTime:
+		return func(a, b QueryShapeStats) bool { return a.QueueTimeSeconds < b.QueueTimeSeconds }, nil
+	case QueryStatsSortByChunks:
+		return func(a, b QueryShapeStats) bool { return a.ChunksDownloaded < b.ChunksDownloaded }, nil
+	default:
+		return nil, fmt.Errorf("invalid sort_by %q, expected one of %s, %s, %s, %s, %s", sortBy,
+			QueryStatsSortByBytes, QueryStatsSortByCount, QueryStatsSortByExecTime, QueryStatsSortByQueueTime, QueryStatsSortByChunks)
+	}
+}
+
+// ServeHTTP serves the top query shapes of the tenant of the request.
+// Supported parameters are `limit` (default 20) and `sort_by` (default bytes).
+func (t *QueryStatsTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	limit := defaultQueryStatsLimit
+	if v := r.FormValue("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := t.TopN(tenant.JoinTenantIDs(tenantIDs), limit, r.FormValue("sort_by"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	util.WriteJSONResponse(w, struct {
+		Status string            `json:"status"`
+		Data   []QueryShapeStats `json:"data"`
+	}{
+		Status: "success",
+		Data:   res,
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (t *QueryStatsTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.queriesDesc
+	ch <- t.failuresDesc
+	ch <- t.bytesProcessedDesc
+	ch <- t.chunksDownloadedDesc
+	ch <- t.execTimeDesc
+	ch <- t.queueTimeDesc
+	ch <- t.cacheHitsDesc
+}
+
+// Collect implements prometheus.Collector.
+// Only the top fingerprints by bytes processed of each tenant are exposed.
+func (t *QueryStatsTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mtx.Lock()
+	tenantIDs := make([]string, 0, len(t.tenants))
+	for tenantID := range t.tenants {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	t.mtx.Unlock()
+
+	for _, tenantID := range tenantIDs {
+		top, err := t.TopN(tenantID, t.cfg.MetricsTopN, QueryStatsSortByBytes)
+		if err != nil {
+			continue
+		}
+		for _, s := range top {
+			ch <- prometheus.MustNewConstMetric(t.queriesDesc, prometheus.CounterValue, float64(s.Count), tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.failuresDesc, prometheus.CounterValue, float64(s.Failures), tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.bytesProcessedDesc, prometheus.CounterValue, float64(s.BytesProcessed), tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.chunksDownloadedDesc, prometheus.CounterValue, float64(s.ChunksDownloaded), tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.execTimeDesc, prometheus.CounterValue, s.ExecTimeSeconds, tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.queueTimeDesc, prometheus.CounterValue, s.QueueTimeSeconds, tenantID, s.Fingerprint)
+			ch <- prometheus.MustNewConstMetric(t.cacheHitsDesc, prometheus.CounterValue, float64(s.CacheEntriesFound), tenantID, s.Fingerprint)
+		}
+	}
+}
+
+// record is called from the stats HTTP middleware for every query handled by the frontend.
+func (t *QueryStatsTracker) record(data *queryData) {
+	if data.queryType != queryTypeLog && data.queryType != queryTypeMetric {
+		return
+	}
+	if data.params == nil || data.params.GetExpression() == nil || data.statistics == nil {
+		return
+	}
+
+	tenantIDs, err := tenant.TenantIDs(data.ctx)
+	if err != nil {
+		return
+	}
+
+	failed := data.status != strconv.Itoa(http.StatusOK)
+	if err := t.Observe(tenant.JoinTenantIDs(tenantIDs), data.params.GetExpression(), *data.statistics, failed); err != nil {
+		level.Warn(t.logger).Log("msg", "failed to record query stats", "err", err)
+	}
+}
+
+// NewStatsHTTPMiddleware returns a middleware like StatsHTTPMiddleware which additionally
+// aggregates the statistics of each query in the given tracker.
+func NewStatsHTTPMiddleware(tracker *QueryStatsTracker) middleware.Interface {
+	if tracker == nil {
+		return StatsHTTPMiddleware
+	}
+	return statsHTTPMiddleware(metricRecorderFn(func(data *queryData) {
+		recordQueryMetrics(data)
+		tracker.record(data)
+	}))
+}
@@ -0,0 +1,160 @@
+package queryrange
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+func newTestQueryStatsTracker(t *testing.T, maxFingerprints int, reg prometheus.Registerer) *QueryStatsTracker {
+	t.Helper()
+	tracker := NewQueryStatsTracker(QueryStatsConfig{
+		Enabled:                  true,
+		MaxFingerprintsPerTenant: maxFingerprints,
+		MetricsTopN:              1,
+	}, reg, "loki", log.NewNopLogger())
+
+	now := time.Unix(0, 0)
+	tracker.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return tracker
+}
+
+func TestQueryStatsTracker_Observe(t *testing.T) {
+	tracker := newTestQueryStatsTracker(t, 10, nil)
+
+	observe := func(tenantID, query string, bytes int64, queueTime float64) {
+		err := tracker.Observe(tenantID, syntax.MustParseExpr(query), stats.Result{
+			Summary: stats.Summary{TotalBytesProcessed: bytes, QueueTime: queueTime},
+			Caches:  stats.Caches{Chunk: stats.Cache{EntriesFound: 1, EntriesRequested: 2}},
+		}, false)
+		require.NoError(t, err)
+	}
+
+	observe("a", `sum(rate({app="foo"} |= "err" [5m]))`, 100, 1)
+	observe("a", `sum(rate({app="bar"} |= "panic" [1h]))`, 50, 2)
+	observe("a", `{app="foo"} | json`, 200, 0)
+	observe("b", `{app="foo"} | json`, 10, 0)
+
+	top, err := tracker.TopN("a", 10, "")
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+
+	require.Equal(t, `{app="?"} | json`, top[0].Query)
+	require.Equal(t, int64(200), top[0].BytesProcessed)
+
+	require.Equal(t, `sum(rate({app="?"} |= "?"[0s]))`, top[1].Query)
+	require.Equal(t, `sum(rate({app="bar"} |= "panic"[1h]))`, top[1].LastQuery)
+	require.Equal(t, int64(2), top[1].Count)
+	require.Equal(t, int64(150), top[1].BytesProcessed)
+	require.Equal(t, float64(3), top[1].QueueTimeSeconds)
+	require.Equal(t, int64(2), top[1].CacheEntriesFound)
+	require.Equal(t, int64(4), top[1].CacheEntriesRequested)
+
+	top, err = tracker.TopN("a", 1, QueryStatsSortByQueueTime)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	require.Equal(t, `sum(rate({app="?"} |= "?"[0s]))`, top[0].Query)
+
+	top, err = tracker.TopN("b", 10, QueryStatsSortByCount)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	require.Equal(t, int64(10), top[0].BytesProcessed)
+
+	_, err = tracker.TopN("a", 10, "foo")
+	require.Error(t, err)
+}
+
+func TestQueryStatsTracker_Eviction(t *testing.T) {
+	tracker := newTestQueryStatsTracker(t, 2, nil)
+
+	for _, q := range []string{`{app="foo"}`, `{app="foo"} | json`, `{app="foo"} | logfmt`} {
+		require.NoError(t, tracker.Observe("a", syntax.MustParseExpr(q), stats.Result{}, false))
+	}
+
+	top, err := tracker.TopN("a", 10, QueryStatsSortByCount)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	for _, s := range top {
+		require.NotEqual(t, `{app="?"}`, s.Query)
+	}
+	require.Equal(t, float64(1), testutil.ToFloat64(tracker.evictions))
+}
+
+func TestQueryStatsTracker_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	tracker := newTestQueryStatsTracker(t, 10, reg)
+
+	require.NoError(t, tracker.Observe("a", syntax.MustParseExpr(`{app="foo"}`), stats.Result{Summary: stats.Summary{TotalBytesProcessed: 10}}, false))
+	require.NoError(t, tracker.Observe("a", syntax.MustParseExpr(`{app="foo"} | json`), stats.Result{Summary: stats.Summary{TotalBytesProcessed: 20}}, true))
+
+	// only the top fingerprint by bytes processed is exposed.
+	top, err := tracker.TopN("a", 1, QueryStatsSortByBytes)
+	require.NoError(t, err)
+	expected := `
+# HELP loki_query_frontend_query_shape_bytes_processed_total Total bytes processed per query fingerprint, for the top fingerprints by bytes processed.
+# TYPE loki_query_frontend_query_shape_bytes_processed_total counter
+loki_query_frontend_query_shape_bytes_processed_total{fingerprint="` + top[0].Fingerprint + `",tenant="a"} 20
+# HELP loki_query_frontend_query_shape_failures_total Total number of failed queries per query fingerprint, for the top fingerprints by bytes processed.
+# TYPE loki_query_frontend_query_shape_failures_total counter
+loki_query_frontend_query_shape_failures_total{fingerprint="` + top[0].Fingerprint + `",tenant="a"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"loki_query_frontend_query_shape_bytes_processed_total",
+		"loki_query_frontend_query_shape_failures_total",
+	))
+}
+
+func TestQueryStatsTracker_ServeHTTP(t *testing.T) {
+	tracker := newTestQueryStatsTracker(t, 10, nil)
+	require.NoError(t, tracker.Observe("a", syntax.MustParseExpr(`{app="foo"}`), stats.Result{}, false))
+	require.NoError(t, tracker.Observe("a", syntax.MustParseExpr(`{app="foo"} | json`), stats.Result{}, false))
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		tenantID string
+		status   int
+		results  int
+	}{
+		{name: "default", url: "/loki/api/v1/query_stats", tenantID: "a", status: http.StatusOK, results: 2},
+		{name: "limit", url: "/loki/api/v1/query_stats?limit=1&sort_by=count", tenantID: "a", status: http.StatusOK, results: 1},
+		{name: "other tenant", url: "/loki/api/v1/query_stats", tenantID: "b", status: http.StatusOK, results: 0},
+		{name: "invalid limit", url: "/loki/api/v1/query_stats?limit=-1", tenantID: "a", status: http.StatusBadRequest},
+		{name: "invalid sort", url: "/loki/api/v1/query_stats?sort_by=foo", tenantID: "a", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), tc.tenantID))
+			rec := httptest.NewRecorder()
+
+			tracker.ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Status string            `json:"status"`
+				Data   []QueryShapeStats `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Equal(t, "success", resp.Status)
+			require.Len(t, resp.Data, tc.results)
+		})
+	}
+}
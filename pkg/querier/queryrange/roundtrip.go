@@ -60,6 +60,8 @@ type Config struct {
 	SeriesCacheConfig            SeriesCacheConfig        `yaml:"series_results_cache" doc:"description=If series_results_cache is not configured and cache_series_results is true, the config for the results cache is used."`
 	CacheLabelResults            bool                     `yaml:"cache_label_results"`
 	LabelsCacheConfig            LabelsCacheConfig        `yaml:"label_results_cache" doc:"description=If label_results_cache is not configured and cache_label_results is true, the config for the results cache is used."`
+	QueryStats                   QueryStatsConfig         `yaml:"query_stats"`
+	Federation                   FederationConfig         `yaml:"federation"`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
@@ -76,6 +78,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.SeriesCacheConfig.RegisterFlags(f)
 	f.BoolVar(&cfg.CacheLabelResults, "querier.cache-label-results", true, "Cache label query results.")
 	cfg.LabelsCacheConfig.RegisterFlags(f)
+	cfg.QueryStats.RegisterFlags(f)
+	cfg.Federation.RegisterFlags(f)
 }
 
 // Validate validates the config.
@@ -89,6 +93,14 @@ func (cfg *Config) Validate() error {
 			return errors.Wrap(err, "invalid index_stats_results_cache config")
 		}
 	}
+
+	if err := cfg.QueryStats.Validate(); err != nil {
+		return errors.Wrap(err, "invalid query_stats config")
+	}
+
+	if err := cfg.Federation.Validate(); err != nil {
+		return errors.Wrap(err, "invalid federation config")
+	}
 	return nil
 }
 
@@ -264,6 +276,12 @@ func NewMiddleware(
 	if err != nil {
 		return nil, nil, err
 	}
+
+	federationMiddleware, err := NewFederationMiddleware(cfg.Federation, engineOpts, log, limits, metrics.MiddlewareMapperMetrics.federationMapper, metrics.FederationMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
 		var (
 			metricRT         = metricsTripperware.Wrap(next)
@@ -278,7 +296,16 @@ func NewMiddleware(
 			detectedLabelsRT = detectedLabelsTripperware.Wrap(next)
 		)
 
-		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, limits)
+		// The enforced label matchers are added first, so that caching, splitting and sharding
+		// all see the restricted request. The federated queries are then sent to the remote
+		// clusters, and go through the whole local pipeline for the local cluster.
+		return base.MergeMiddlewares(
+			NewAccessPolicyMiddleware(limits),
+			NewFieldMaskingMiddleware(limits),
+			federationMiddleware,
+		).Wrap(
+			newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, limits),
+		)
 	}), StopperWrapper{resultsCache, statsCache, volumeCache}, nil
 }
 
@@ -557,6 +584,7 @@ func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Lo
 		queryRangeMiddleware := []base.Middleware{
 			QueryMetricsMiddleware(metrics.QueryMetrics),
 			StatsCollectorMiddleware(),
+			NewQueryRewriteMiddleware(log, limits, metrics.QueryRewriteMetrics),
 			NewLimitsMiddleware(limits),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
 			base.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
@@ -619,6 +647,7 @@ func NewLimitedTripperware(cfg Config, log log.Logger, limits Limits, schema con
 	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
 		queryRangeMiddleware := []base.Middleware{
 			StatsCollectorMiddleware(),
+			NewQueryRewriteMiddleware(log, limits, metrics.QueryRewriteMetrics),
 			NewLimitsMiddleware(limits),
 			base.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
 			SplitByIntervalMiddleware(schema.Configs, WithMaxParallelism(limits, limitedQuerySplits), merger, newDefaultSplitter(limits, iqo), metrics.SplitByMetrics),
@@ -837,6 +866,7 @@ func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logge
 		queryRangeMiddleware := []base.Middleware{
 			QueryMetricsMiddleware(metrics.QueryMetrics),
 			StatsCollectorMiddleware(),
+			NewQueryRewriteMiddleware(log, limits, metrics.QueryRewriteMetrics),
 			NewLimitsMiddleware(limits),
 		}
 
@@ -958,6 +988,7 @@ func NewInstantMetricTripperware(
 
 		queryRangeMiddleware := []base.Middleware{
 			StatsCollectorMiddleware(),
+			NewQueryRewriteMiddleware(log, limits, metrics.QueryRewriteMetrics),
 			NewLimitsMiddleware(limits),
 			NewQuerySizeLimiterMiddleware(schema.Configs, engineOpts, log, limits, statsHandler),
 			NewSplitByRangeMiddleware(log, engineOpts, limits, cfg.InstantMetricQuerySplitAlign, metrics.MiddlewareMapperMetrics.rangeMapper),
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/rewrite"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
@@ -35,6 +36,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/constants"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 	"github.com/grafana/loki/v3/pkg/util/validation"
 	valid "github.com/grafana/loki/v3/pkg/validation"
 )
@@ -1401,6 +1403,10 @@ type fakeLimits struct {
 	maxStatsCacheFreshness      time.Duration
 	maxMetadataCacheFreshness   time.Duration
 	volumeEnabled               bool
+	queryRewriteBuiltinRules    []string
+	queryRewriteRules           []rewrite.SelectorRuleConfig
+	queryEnforcedMatchers       []string
+	queryFieldMaskingRules      []masking.RuleConfig
 }
 
 func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
@@ -1520,6 +1526,22 @@ func (f fakeLimits) VolumeEnabled(_ string) bool {
 	return f.volumeEnabled
 }
 
+func (f fakeLimits) QueryEnforcedMatchers(_ context.Context, _ string) []string {
+	return f.queryEnforcedMatchers
+}
+
+func (f fakeLimits) QueryFieldMaskingRules(_ context.Context, _ string) []masking.RuleConfig {
+	return f.queryFieldMaskingRules
+}
+
+func (f fakeLimits) QueryRewriteBuiltinRules(_ context.Context, _ string) []string {
+	return f.queryRewriteBuiltinRules
+}
+
+func (f fakeLimits) QueryRewriteRules(_ context.Context, _ string) []rewrite.SelectorRuleConfig {
+	return f.queryRewriteRules
+}
+
 func (f fakeLimits) TSDBMaxBytesPerShard(_ string) int {
 	return valid.DefaultTSDBMaxBytesPerShard
 }
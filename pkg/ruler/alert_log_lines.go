@@ -0,0 +1,388 @@
+package ruler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	// LogLinesLimitAnnotation is the annotation of an alerting rule enabling log lines on its alerts,
+	// its value is the number of log lines to attach. It is removed from the alerts sent.
+	LogLinesLimitAnnotation = "__log_lines__"
+	// LogLinesAnnotation is the annotation holding the log lines attached to an alert.
+	LogLinesAnnotation = "log_lines"
+
+	redactedLogLine = "<redacted>"
+
+	// logLinesCacheTTL is how long the log lines of an alert are kept after they were last sent.
+	logLinesCacheTTL = time.Hour
+	// logLinesQueryConcurrency is the number of log queries run at once for the alerts sent together.
+	logLinesQueryConcurrency = 8
+)
+
+// LogLineRedactor redacts a log line of the given tenant before it is attached to an alert.
+type LogLineRedactor func(userID, line string) string
+
+type AlertLogLinesConfig struct {
+	MaxLines       int                    `yaml:"max_lines"`
+	MaxBytes       int                    `yaml:"max_bytes"`
+	QueryTimeout   time.Duration          `yaml:"query_timeout"`
+	RedactPatterns flagext.StringSliceCSV `yaml:"redact_patterns"`
+
+	// Redactor is an optional hook applied to the log lines after the redact patterns.
+	Redactor LogLineRedactor `yaml:"-"`
+}
+
+func (c *AlertLogLinesConfig) RegisterFlags(f *flag.FlagSet) {
+	f.IntVar(&c.MaxLines, "ruler.alert-log-lines.max-lines", 10, "Maximum number of log lines attached to an alert whose rule sets the '__log_lines__' annotation. The lines are the last lines matching the selector and pipeline of the rule when the alert fired. 0 to disable attaching log lines.")
+	f.IntVar(&c.MaxBytes, "ruler.alert-log-lines.max-bytes", 4096, "Maximum size in bytes of the log lines attached to an alert. The oldest lines are dropped to fit.")
+	f.DurationVar(&c.QueryTimeout, "ruler.alert-log-lines.query-timeout", 5*time.Second, "Timeout of the log queries retrieving the log lines of the alerts of a rule, which run concurrently before the alerts are sent. Alerts are sent without log lines if their query fails or times out.")
+	f.Var(&c.RedactPatterns, "ruler.alert-log-lines.redact-patterns", "Comma separated list of regular expressions whose matches are replaced with '<redacted>' in the log lines attached to alerts.")
+}
+
+func (c *AlertLogLinesConfig) Validate() error {
+	_, err := c.redactRegexps()
+	return err
+}
+
+func (c *AlertLogLinesConfig) redactRegexps() ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(c.RedactPatterns))
+	for _, p := range c.RedactPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+type alertLogLinesMetrics struct {
+	queries *prometheus.CounterVec
+}
+
+func newAlertLogLinesMetrics(r prometheus.Registerer) *alertLogLinesMetrics {
+	return &alertLogLinesMetrics{
+		queries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_alert_log_lines_queries_total",
+			Help:      "Total number of log queries retrieving the log lines attached to alerts.",
+		}, []string{"status"}),
+	}
+}
+
+// logLinesQuery is the log query retrieving the log lines of the alerts of a rule.
+type logLinesQuery struct {
+	selector string
+	// grouping are the labels the alerts are grouped by, matched against the log lines.
+	grouping []string
+	interval time.Duration
+	offset   time.Duration
+}
+
+// parseLogLinesQuery derives the log query of the alerts of a rule from the first log range of its expression.
+func parseLogLinesQuery(expr string) (*logLinesQuery, error) {
+	sampleExpr, err := syntax.ParseSampleExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		grouping *syntax.Grouping
+		q        *logLinesQuery
+	)
+	sampleExpr.Walk(func(e syntax.Expr) {
+		if q != nil {
+			return
+		}
+		switch e := e.(type) {
+		case *syntax.VectorAggregationExpr:
+			if grouping == nil {
+				grouping = e.Grouping
+			}
+		case *syntax.RangeAggregationExpr:
+			if grouping == nil {
+				grouping = e.Grouping
+			}
+		case *syntax.LogRange:
+			q = &logLinesQuery{
+				selector: e.Left.String(),
+				interval: e.Interval,
+				offset:   e.Offset,
+			}
+			if grouping != nil && !grouping.Without {
+				q.grouping = grouping.Groups
+			}
+		}
+	})
+	if q == nil {
+		return nil, fmt.Errorf("expression %q has no log selector", expr)
+	}
+	return q, nil
+}
+
+// query returns the log query matching the log lines of the alert with the given labels.
+func (q *logLinesQuery) query(lbls labels.Labels) string {
+	var sb strings.Builder
+	sb.WriteString(q.selector)
+	for _, name := range q.grouping {
+		sb.WriteString(" | ")
+		sb.WriteString(name)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(lbls.Get(name)))
+	}
+	return sb.String()
+}
+
+// AlertLogLines attaches log lines to the alerts of the rules setting the '__log_lines__' annotation.
+type AlertLogLines struct {
+	cfg           AlertLogLinesConfig
+	querier       LogQuerier
+	redactRegexps []*regexp.Regexp
+	metrics       *alertLogLinesMetrics
+	logger        log.Logger
+}
+
+func NewAlertLogLines(cfg AlertLogLinesConfig, querier LogQuerier, reg prometheus.Registerer, logger log.Logger) (*AlertLogLines, error) {
+	redactRegexps, err := cfg.redactRegexps()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AlertLogLines{
+		cfg:           cfg,
+		querier:       querier,
+		redactRegexps: redactRegexps,
+		metrics:       newAlertLogLinesMetrics(reg),
+		logger:        logger,
+	}, nil
+}
+
+type logLinesKey struct {
+	expr   string
+	labels uint64
+}
+
+type logLinesEntry struct {
+	firedAt time.Time
+	lines   string
+	usedAt  time.Time
+}
+
+// tenantAlertLogLines caches the log lines of the alerts of a tenant, so that they are queried once per alert
+// and not every time the alert is resent.
+type tenantAlertLogLines struct {
+	*AlertLogLines
+	userID string
+
+	mtx       sync.Mutex
+	entries   map[logLinesKey]*logLinesEntry
+	sweptAt   time.Time
+	parsed    map[string]*logLinesQuery
+	parseErrs map[string]error
+}
+
+func newTenantAlertLogLines(a *AlertLogLines, userID string) *tenantAlertLogLines {
+	return &tenantAlertLogLines{
+		AlertLogLines: a,
+		userID:        userID,
+		entries:       map[logLinesKey]*logLinesEntry{},
+		parsed:        map[string]*logLinesQuery{},
+		parseErrs:     map[string]error{},
+	}
+}
+
+// notifyFunc attaches the log lines to the alerts before sending them with next.
+func (t *tenantAlertLogLines) notifyFunc(next rules.NotifyFunc) rules.NotifyFunc {
+	return func(ctx context.Context, expr string, alerts ...*rules.Alert) {
+		now := time.Now()
+
+		var (
+			withLines []*rules.Alert
+			limits    []int
+		)
+		for _, a := range alerts {
+			limitValue := a.Annotations.Get(LogLinesLimitAnnotation)
+			if limitValue == "" {
+				continue
+			}
+			a.Annotations = labels.NewBuilder(a.Annotations).Del(LogLinesLimitAnnotation).Labels()
+
+			limit, err := strconv.Atoi(limitValue)
+			if err != nil || limit <= 0 || t.cfg.MaxLines <= 0 {
+				continue
+			}
+			withLines = append(withLines, a)
+			limits = append(limits, min(limit, t.cfg.MaxLines))
+		}
+
+		if len(withLines) > 0 {
+			// the queries of all the alerts share the timeout, so that sending the alerts is delayed by it at most
+			queryCtx, cancel := context.WithTimeout(user.InjectOrgID(ctx, t.userID), t.cfg.QueryTimeout)
+			_ = concurrency.ForEachJob(queryCtx, len(withLines), logLinesQueryConcurrency, func(ctx context.Context, idx int) error {
+				a := withLines[idx]
+				if lines := t.logLines(ctx, expr, a, limits[idx], now); lines != "" {
+					a.Annotations = labels.NewBuilder(a.Annotations).Set(LogLinesAnnotation, lines).Labels()
+				}
+				return nil
+			})
+			cancel()
+		}
+		t.sweep(now)
+
+		next(ctx, expr, alerts...)
+	}
+}
+
+func (t *tenantAlertLogLines) logLines(ctx context.Context, expr string, a *rules.Alert, limit int, now time.Time) string {
+	key := logLinesKey{expr: expr, labels: a.Labels.Hash()}
+
+	t.mtx.Lock()
+	entry, ok := t.entries[key]
+	if !a.ResolvedAt.IsZero() {
+		delete(t.entries, key)
+	}
+	if ok && entry.firedAt.Equal(a.FiredAt) {
+		entry.usedAt = now
+		t.mtx.Unlock()
+		return entry.lines
+	}
+	t.mtx.Unlock()
+
+	if !a.ResolvedAt.IsZero() {
+		// the alert resolved before its log lines could be retrieved
+		return ""
+	}
+
+	lines, err := t.queryLogLines(ctx, expr, a, limit)
+	if err != nil {
+		t.metrics.queries.WithLabelValues("failure").Inc()
+		level.Warn(t.logger).Log("msg", "failed to retrieve the log lines of the alert", "user", t.userID, "expr", expr, "alert", a.Labels, "err", err)
+		return ""
+	}
+	t.metrics.queries.WithLabelValues("success").Inc()
+
+	t.mtx.Lock()
+	t.entries[key] = &logLinesEntry{firedAt: a.FiredAt, lines: lines, usedAt: now}
+	t.mtx.Unlock()
+	return lines
+}
+
+func (t *tenantAlertLogLines) queryLogLines(ctx context.Context, expr string, a *rules.Alert, limit int) (string, error) {
+	q, err := t.logLinesQuery(expr)
+	if err != nil {
+		return "", err
+	}
+
+	end := a.FiredAt.Add(-q.offset)
+	start := end.Add(-q.interval)
+
+	streams, err := t.querier.QueryLogs(ctx, q.query(a.Labels), start, end, uint32(limit))
+	if err != nil {
+		return "", err
+	}
+	return t.format(streams, limit), nil
+}
+
+func (t *tenantAlertLogLines) logLinesQuery(expr string) (*logLinesQuery, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if q, ok := t.parsed[expr]; ok {
+		return q, nil
+	}
+	if err, ok := t.parseErrs[expr]; ok {
+		return nil, err
+	}
+
+	q, err := parseLogLinesQuery(expr)
+	if err != nil {
+		t.parseErrs[expr] = err
+		return nil, err
+	}
+	t.parsed[expr] = q
+	return q, nil
+}
+
+// format returns the newest log lines up to the limit and the max size, in chronological order.
+func (t *tenantAlertLogLines) format(streams logqlmodel.Streams, limit int) string {
+	var entries []logproto.Entry
+	for _, s := range streams {
+		entries = append(entries, s.Entries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+
+	var (
+		lines []string
+		size  int
+	)
+	for _, e := range entries {
+		if len(lines) == limit {
+			break
+		}
+		line := e.Timestamp.UTC().Format(time.RFC3339Nano) + " " + t.redact(e.Line)
+		if t.cfg.MaxBytes > 0 && size+len(line)+len(lines) > t.cfg.MaxBytes {
+			break
+		}
+		size += len(line)
+		lines = append(lines, line)
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (t *tenantAlertLogLines) redact(line string) string {
+	for _, re := range t.redactRegexps {
+		line = re.ReplaceAllLiteralString(line, redactedLogLine)
+	}
+	if t.cfg.Redactor != nil {
+		line = t.cfg.Redactor(t.userID, line)
+	}
+	return line
+}
+
+// sweep removes the log lines of the alerts which are not sent anymore, e.g. after their rule was removed.
+func (t *tenantAlertLogLines) sweep(now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if now.Sub(t.sweptAt) < logLinesCacheTTL {
+		return
+	}
+	t.sweptAt = now
+
+	for key, entry := range t.entries {
+		if now.Sub(entry.usedAt) > logLinesCacheTTL {
+			delete(t.entries, key)
+		}
+	}
+	clear(t.parsed)
+	clear(t.parseErrs)
+}
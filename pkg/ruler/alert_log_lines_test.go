@@ -0,0 +1,273 @@
+package ruler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+type logQuery struct {
+	query      string
+	start, end time.Time
+	limit      uint32
+}
+
+type fakeLogQuerier struct {
+	mtx     sync.Mutex
+	queries []logQuery
+	streams logqlmodel.Streams
+	err     error
+}
+
+func (q *fakeLogQuerier) QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error) {
+	if _, err := user.ExtractOrgID(ctx); err != nil {
+		return nil, err
+	}
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.queries = append(q.queries, logQuery{query: qs, start: start, end: end, limit: limit})
+	return q.streams, q.err
+}
+
+func TestParseLogLinesQuery(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		lbls     labels.Labels
+		query    string
+		interval time.Duration
+		offset   time.Duration
+		err      bool
+	}{
+		{
+			expr:     `sum by (app, level) (count_over_time({app="foo"} |= "error" | logfmt [5m])) > 10`,
+			lbls:     labels.FromStrings("app", "foo", "level", "error", "severity", "page"),
+			query:    `{app="foo"} |= "error" | logfmt | app="foo" | level="error"`,
+			interval: 5 * time.Minute,
+		},
+		{
+			expr:     `rate({app="foo"} | json | unwrap duration [1m] offset 10m) > 1`,
+			lbls:     labels.FromStrings("app", "foo"),
+			query:    `{app="foo"} | json`,
+			interval: time.Minute,
+			offset:   10 * time.Minute,
+		},
+		{
+			expr:     `sum without (pod) (rate({app="foo"}[1m])) > 1`,
+			lbls:     labels.FromStrings("app", "foo"),
+			query:    `{app="foo"}`,
+			interval: time.Minute,
+		},
+		{
+			expr: `vector(1)`,
+			err:  true,
+		},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			q, err := parseLogLinesQuery(tc.expr)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.query, q.query(tc.lbls))
+			require.Equal(t, tc.interval, q.interval)
+			require.Equal(t, tc.offset, q.offset)
+		})
+	}
+}
+
+func TestAlertLogLines_NotifyFunc(t *testing.T) {
+	firedAt := time.Unix(3600, 0)
+	querier := &fakeLogQuerier{
+		streams: logqlmodel.Streams{
+			{Labels: `{app="foo", pod="a"}`, Entries: []logproto.Entry{
+				{Timestamp: firedAt.Add(-3 * time.Second), Line: "oldest"},
+				{Timestamp: firedAt.Add(-time.Second), Line: "error password=secret"},
+			}},
+			{Labels: `{app="foo", pod="b"}`, Entries: []logproto.Entry{
+				{Timestamp: firedAt.Add(-2 * time.Second), Line: "error user=bob"},
+			}},
+		},
+	}
+
+	cfg := AlertLogLinesConfig{
+		MaxLines:       10,
+		MaxBytes:       4096,
+		QueryTimeout:   time.Second,
+		RedactPatterns: []string{`password=\S+`},
+		Redactor: func(userID, line string) string {
+			return strings.ReplaceAll(line, "bob", userID)
+		},
+	}
+	reg := prometheus.NewRegistry()
+	a, err := NewAlertLogLines(cfg, querier, reg, log.NewNopLogger())
+	require.NoError(t, err)
+
+	var sent []*rules.Alert
+	notify := newTenantAlertLogLines(a, "user").notifyFunc(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = alerts
+	})
+
+	expr := `sum by (app) (count_over_time({app="foo"} |= "error" [1m])) > 1`
+	newAlert := func() *rules.Alert {
+		return &rules.Alert{
+			State:       rules.StateFiring,
+			Labels:      labels.FromStrings("alertname", "errors", "app", "foo"),
+			Annotations: labels.FromStrings(LogLinesLimitAnnotation, "2", "summary", "errors"),
+			FiredAt:     firedAt,
+		}
+	}
+
+	// the newest lines are attached in chronological order, and the setting is removed from the annotations
+	notify(context.Background(), expr, newAlert())
+	require.Equal(t, []logQuery{{
+		query: `{app="foo"} |= "error" | app="foo"`,
+		start: firedAt.Add(-time.Minute),
+		end:   firedAt,
+		limit: 2,
+	}}, querier.queries)
+	expected := labels.FromStrings(
+		LogLinesAnnotation, "1970-01-01T00:59:58Z error user=user\n1970-01-01T00:59:59Z error <redacted>",
+		"summary", "errors",
+	)
+	require.Equal(t, expected, sent[0].Annotations)
+	require.Equal(t, 1.0, testutil.ToFloat64(a.metrics.queries.WithLabelValues("success")))
+
+	// the lines are not queried again when the alert is resent, nor when it resolves
+	notify(context.Background(), expr, newAlert())
+	require.Equal(t, expected, sent[0].Annotations)
+	resolved := newAlert()
+	resolved.ResolvedAt = firedAt.Add(time.Hour)
+	notify(context.Background(), expr, resolved)
+	require.Equal(t, expected, sent[0].Annotations)
+	require.Len(t, querier.queries, 1)
+
+	// failed queries are retried when the alert is resent
+	querier.err = errors.New("query failed")
+	notify(context.Background(), expr, newAlert())
+	require.Equal(t, labels.FromStrings("summary", "errors"), sent[0].Annotations)
+	querier.err = nil
+	notify(context.Background(), expr, newAlert())
+	require.Equal(t, expected, sent[0].Annotations)
+	require.Len(t, querier.queries, 3)
+	require.Equal(t, 1.0, testutil.ToFloat64(a.metrics.queries.WithLabelValues("failure")))
+}
+
+// blockingLogQuerier blocks the log queries until they are canceled.
+type blockingLogQuerier struct {
+	started chan struct{}
+}
+
+func (q *blockingLogQuerier) QueryLogs(ctx context.Context, _ string, _, _ time.Time, _ uint32) (logqlmodel.Streams, error) {
+	q.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAlertLogLines_QueryTimeout(t *testing.T) {
+	querier := &blockingLogQuerier{started: make(chan struct{}, 3)}
+	cfg := AlertLogLinesConfig{MaxLines: 10, QueryTimeout: 200 * time.Millisecond}
+	reg := prometheus.NewRegistry()
+	a, err := NewAlertLogLines(cfg, querier, reg, log.NewNopLogger())
+	require.NoError(t, err)
+
+	var sent []*rules.Alert
+	notify := newTenantAlertLogLines(a, "user").notifyFunc(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = alerts
+	})
+
+	var alerts []*rules.Alert
+	for _, app := range []string{"foo", "bar", "baz"} {
+		alerts = append(alerts, &rules.Alert{
+			State:       rules.StateFiring,
+			Labels:      labels.FromStrings("alertname", "errors", "app", app),
+			Annotations: labels.FromStrings(LogLinesLimitAnnotation, "2"),
+			FiredAt:     time.Unix(3600, 0),
+		})
+	}
+
+	// the queries of the alerts run concurrently, the alerts are sent without log lines once they time out
+	start := time.Now()
+	notify(context.Background(), `sum by (app) (count_over_time({app=~".+"}[1m])) > 1`, alerts...)
+	require.Less(t, time.Since(start), 2*cfg.QueryTimeout)
+	require.Len(t, querier.started, 3)
+	require.Len(t, sent, 3)
+	for _, alert := range sent {
+		require.Equal(t, labels.EmptyLabels(), alert.Annotations)
+	}
+	require.Equal(t, 3.0, testutil.ToFloat64(a.metrics.queries.WithLabelValues("failure")))
+}
+
+func TestAlertLogLines_MaxBytes(t *testing.T) {
+	now := time.Unix(0, 0)
+	querier := &fakeLogQuerier{
+		streams: logqlmodel.Streams{{Labels: `{app="foo"}`, Entries: []logproto.Entry{
+			{Timestamp: now, Line: strings.Repeat("a", 20)},
+			{Timestamp: now.Add(time.Second), Line: strings.Repeat("b", 20)},
+		}}},
+	}
+
+	a, err := NewAlertLogLines(AlertLogLinesConfig{MaxLines: 10, MaxBytes: 50, QueryTimeout: time.Second}, querier, nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	var sent []*rules.Alert
+	notify := newTenantAlertLogLines(a, "user").notifyFunc(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = alerts
+	})
+	notify(context.Background(), `count_over_time({app="foo"}[1m]) > 1`, &rules.Alert{
+		Labels:      labels.FromStrings("alertname", "errors"),
+		Annotations: labels.FromStrings(LogLinesLimitAnnotation, "5"),
+		FiredAt:     now.Add(time.Minute),
+	})
+
+	// only the newest line fits
+	require.Equal(t, "1970-01-01T00:00:01Z "+strings.Repeat("b", 20), sent[0].Annotations.Get(LogLinesAnnotation))
+}
+
+func TestInvalidLogLinesAnnotation(t *testing.T) {
+	for _, tc := range []struct {
+		expr, value, err string
+	}{
+		{
+			expr:  `sum(rate({app="foo"}[5m])) > 1`,
+			value: "10",
+		},
+		{
+			expr:  `sum(rate({app="foo"}[5m])) > 1`,
+			value: "ten",
+			err:   `invalid annotation __log_lines__: "ten" is not a positive number of log lines`,
+		},
+		{
+			expr:  `vector(1) > 0`,
+			value: "10",
+			err:   "could not derive the log query of alert 'alert-1-name' in group 'test'",
+		},
+	} {
+		err := validateRuleNode(&rulefmt.RuleNode{
+			Alert:       yaml.Node{Value: "alert-1-name"},
+			Expr:        yaml.Node{Value: tc.expr},
+			Annotations: map[string]string{LogLinesLimitAnnotation: tc.value},
+		}, "test")
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorContains(t, err, tc.err)
+	}
+}
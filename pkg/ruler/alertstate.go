@@ -0,0 +1,426 @@
+package ruler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	// Object Name: "ruler-alert-state/<user_id>/<base64 URL Encoded: namespace>/<base64 URL Encoded: group_name>"
+	alertStatePrefix = "ruler-alert-state/"
+
+	// alertStateLoadInterval is how long the loaded alert states of a tenant are reused,
+	// as all the groups of a tenant are usually restored at the same time.
+	alertStateLoadInterval = time.Minute
+)
+
+type AlertStateConfig struct {
+	ObjectStore string `yaml:"object_store" category:"experimental"`
+}
+
+func (c *AlertStateConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.ObjectStore, "ruler.alert-state.object-store", "", "Name of the object store, as configured in storage_config, to persist the state of the active alerts of every rule group in. The ruler evaluating a rule group after a restart or when rule groups are resharded restores the time its pending and firing alerts became active from the persisted state, instead of resetting their 'for' duration. If empty, the 'for' state of alerts is only restored on startup, by evaluating the alerting rules at the time the alerts would have become active.")
+}
+
+type alertStateMetrics struct {
+	writes   *prometheus.CounterVec
+	restored prometheus.Counter
+}
+
+func newAlertStateMetrics(r prometheus.Registerer) *alertStateMetrics {
+	return &alertStateMetrics{
+		writes: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_alert_state_writes_total",
+			Help:      "Total number of writes of the state of the alerts of a rule group.",
+		}, []string{"status"}),
+		restored: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_alert_state_restored_alerts_total",
+			Help:      "Total number of alerts whose 'for' state was restored from the persisted alert state.",
+		}),
+	}
+}
+
+// groupAlertState is the persisted state of the active alerts of a rule group.
+type groupAlertState struct {
+	UpdatedAt time.Time    `json:"updated_at"`
+	Alerts    []alertState `json:"alerts"`
+}
+
+type alertState struct {
+	// Labels are the labels of the ALERTS_FOR_STATE series of the alert.
+	Labels   labels.Labels `json:"labels"`
+	ActiveAt time.Time     `json:"active_at"`
+}
+
+type writtenAlertState struct {
+	hash uint64
+	at   time.Time
+}
+
+type tenantAlertStates struct {
+	loadedAt time.Time
+	// the most recently updated state of every alert, by hash of its labels
+	alerts map[uint64]alertStateSample
+}
+
+type alertStateSample struct {
+	activeAt, updatedAt time.Time
+}
+
+// AlertStateStore persists the state of the active alerts of every rule group
+// in an object store, analogous to the ALERTS_FOR_STATE series of Prometheus.
+//
+// The state of a group is written after its evaluations, when the active
+// alerts or the time they became active change, and refreshed once half the
+// outage tolerance elapsed. Alerts restored from a state updated before the
+// outage tolerance are considered inactive in the meantime and are not
+// restored.
+//
+// States that were not updated for longer than the outage tolerance are
+// deleted, which removes the states of deleted rule groups and removed
+// tenants. The states of groups a ruler stops evaluating are kept until then,
+// since the groups may have moved to another ruler which restores them.
+type AlertStateStore struct {
+	client          client.ObjectClient
+	outageTolerance time.Duration
+	forGracePeriod  time.Duration
+	metrics         *alertStateMetrics
+	logger          log.Logger
+
+	mtx sync.Mutex
+	// the last state written for every group, to only write the states that changed
+	written map[string]writtenAlertState
+	loaded  map[string]*tenantAlertStates
+	// when the stale states were last deleted
+	cleanedUpAt time.Time
+	cleaningUp  bool
+
+	loadMtx sync.Mutex
+}
+
+func NewAlertStateStore(client client.ObjectClient, outageTolerance, forGracePeriod time.Duration, reg prometheus.Registerer, logger log.Logger) *AlertStateStore {
+	return &AlertStateStore{
+		client:          client,
+		outageTolerance: outageTolerance,
+		forGracePeriod:  forGracePeriod,
+		metrics:         newAlertStateMetrics(reg),
+		logger:          log.With(logger, "component", "alert-state-store"),
+		written:         map[string]writtenAlertState{},
+		loaded:          map[string]*tenantAlertStates{},
+	}
+}
+
+func alertStateObjectKey(userID, file, groupName string) string {
+	namespace := namespaceFromFile(file)
+	return alertStatePrefix + userID + "/" + base64.URLEncoding.EncodeToString([]byte(namespace)) + "/" + base64.URLEncoding.EncodeToString([]byte(groupName))
+}
+
+// save persists the state of the active alerts of the group.
+func (s *AlertStateStore) save(ctx context.Context, userID string, g *rules.Group) {
+	state := groupAlertState{UpdatedAt: time.Now()}
+	for _, r := range g.Rules() {
+		ar, ok := r.(*rules.AlertingRule)
+		if !ok {
+			continue
+		}
+		// the state of the alerts is only saved once restored, to not overwrite the persisted state
+		if !ar.Restored() {
+			return
+		}
+		for _, a := range ar.ActiveAlerts() {
+			state.Alerts = append(state.Alerts, alertState{Labels: ForStateMetric(a.Labels, ar.Name()), ActiveAt: a.ActiveAt})
+		}
+	}
+	sort.Slice(state.Alerts, func(i, j int) bool {
+		return labels.Compare(state.Alerts[i].Labels, state.Alerts[j].Labels) < 0
+	})
+
+	h := xxhash.New()
+	var buf []byte
+	for _, a := range state.Alerts {
+		buf = a.Labels.Bytes(buf)
+		_, _ = h.Write(buf)
+		buf = binary.BigEndian.AppendUint64(buf[:0], uint64(a.ActiveAt.UnixNano()))
+		_, _ = h.Write(buf)
+	}
+	hash := h.Sum64()
+
+	key := alertStateObjectKey(userID, g.File(), g.Name())
+	s.mtx.Lock()
+	prev, ok := s.written[key]
+	s.mtx.Unlock()
+	if ok && prev.hash == hash && (len(state.Alerts) == 0 || time.Since(prev.at) < s.outageTolerance/2) {
+		return
+	}
+
+	var err error
+	if len(state.Alerts) == 0 {
+		// groups without active alerts have no state
+		if err = s.client.DeleteObject(ctx, key); s.client.IsObjectNotFoundErr(err) {
+			err = nil
+		}
+	} else {
+		var b []byte
+		if b, err = json.Marshal(state); err == nil {
+			err = s.client.PutObject(ctx, key, bytes.NewReader(b))
+		}
+	}
+	if err != nil {
+		s.metrics.writes.WithLabelValues(statusFailure).Inc()
+		level.Warn(s.logger).Log("msg", "failed to save the state of alerts", "user", userID, "group", g.Name(), "err", err)
+		return
+	}
+	s.metrics.writes.WithLabelValues(statusSuccess).Inc()
+
+	s.mtx.Lock()
+	s.written[key] = writtenAlertState{hash: hash, at: state.UpdatedAt}
+	cleanup := !s.cleaningUp && time.Since(s.cleanedUpAt) >= s.outageTolerance
+	if cleanup {
+		s.cleaningUp = true
+	}
+	s.mtx.Unlock()
+
+	if cleanup {
+		go s.cleanup(context.WithoutCancel(ctx))
+	}
+}
+
+// cleanup deletes the states that were not updated within the outage tolerance,
+// as they are never restored anymore.
+func (s *AlertStateStore) cleanup(ctx context.Context) {
+	defer func() {
+		s.mtx.Lock()
+		s.cleanedUpAt = time.Now()
+		s.cleaningUp = false
+		s.mtx.Unlock()
+	}()
+
+	objects, _, err := s.client.List(ctx, alertStatePrefix, "")
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to list the states of alerts", "err", err)
+		return
+	}
+	staleBefore := time.Now().Add(-s.outageTolerance)
+	for _, obj := range objects {
+		// object stores that don't report modification times never have stale states
+		if obj.ModifiedAt.IsZero() || !obj.ModifiedAt.Before(staleBefore) {
+			continue
+		}
+		if err := s.client.DeleteObject(ctx, obj.Key); err != nil && !s.client.IsObjectNotFoundErr(err) {
+			level.Warn(s.logger).Log("msg", "failed to delete stale state of alerts", "key", obj.Key, "err", err)
+		}
+	}
+}
+
+// forget drops the written state of the group, so that it is not kept in memory
+// after the group is no longer evaluated.
+func (s *AlertStateStore) forget(userID, file, groupName string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.written, alertStateObjectKey(userID, file, groupName))
+}
+
+// forgetTenant drops the loaded states of the tenant.
+func (s *AlertStateStore) forgetTenant(userID string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.loaded, userID)
+}
+
+// activeAt returns the time the alert with the given ALERTS_FOR_STATE labels became active,
+// if its state was updated after minUpdatedAt.
+func (s *AlertStateStore) activeAt(ctx context.Context, userID string, lbls labels.Labels, minUpdatedAt time.Time) (time.Time, bool, error) {
+	states, err := s.load(ctx, userID)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	smpl, ok := states.alerts[lbls.Hash()]
+	if !ok || smpl.updatedAt.Before(minUpdatedAt) {
+		return time.Time{}, false, nil
+	}
+	return smpl.activeAt, true, nil
+}
+
+func (s *AlertStateStore) load(ctx context.Context, userID string) (*tenantAlertStates, error) {
+	s.loadMtx.Lock()
+	defer s.loadMtx.Unlock()
+
+	s.mtx.Lock()
+	states, ok := s.loaded[userID]
+	s.mtx.Unlock()
+	if ok && time.Since(states.loadedAt) < alertStateLoadInterval {
+		return states, nil
+	}
+
+	objects, _, err := s.client.List(ctx, alertStatePrefix+userID+"/", "")
+	if err != nil {
+		return nil, err
+	}
+
+	states = &tenantAlertStates{loadedAt: time.Now(), alerts: map[uint64]alertStateSample{}}
+	for _, obj := range objects {
+		state, err := s.get(ctx, obj.Key)
+		if s.client.IsObjectNotFoundErr(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range state.Alerts {
+			h := a.Labels.Hash()
+			if prev, ok := states.alerts[h]; ok && prev.updatedAt.After(state.UpdatedAt) {
+				continue
+			}
+			states.alerts[h] = alertStateSample{activeAt: a.ActiveAt, updatedAt: state.UpdatedAt}
+		}
+	}
+
+	s.mtx.Lock()
+	s.loaded[userID] = states
+	s.mtx.Unlock()
+	return states, nil
+}
+
+func (s *AlertStateStore) get(ctx context.Context, key string) (groupAlertState, error) {
+	var state groupAlertState
+	rc, _, err := s.client.GetObject(ctx, key)
+	if err != nil {
+		return state, err
+	}
+	defer rc.Close()
+	err = json.NewDecoder(rc).Decode(&state)
+	return state, err
+}
+
+// restore restores the 'for' state of the active alerts of the group from the persisted state,
+// the same way the rules manager restores the 'for' state of the groups it loads on startup.
+func (s *AlertStateStore) restore(ctx context.Context, userID string, g *rules.Group, ts time.Time) {
+	for _, r := range g.Rules() {
+		ar, ok := r.(*rules.AlertingRule)
+		if !ok {
+			continue
+		}
+		hold := ar.HoldDuration()
+		if hold < s.forGracePeriod {
+			continue
+		}
+
+		ar.ForEachActiveAlert(func(a *rules.Alert) {
+			activeAt, ok, err := s.activeAt(ctx, userID, ForStateMetric(a.Labels, ar.Name()), ts.Add(-s.outageTolerance))
+			if err != nil {
+				level.Warn(s.logger).Log("msg", "failed to load the state of alerts", "user", userID, "err", err)
+				return
+			}
+			if !ok || !activeAt.Before(a.ActiveAt) {
+				return
+			}
+
+			// alerts close to firing wait for the grace period before firing
+			if remaining := hold - ts.Sub(activeAt); remaining > 0 && remaining < s.forGracePeriod {
+				activeAt = ts.Add(s.forGracePeriod).Add(-hold)
+			}
+			a.ActiveAt = activeAt
+			s.metrics.restored.Inc()
+		})
+	}
+}
+
+// tenantAlertState persists and restores the state of the alerts of the rule groups of a tenant.
+type tenantAlertState struct {
+	store  *AlertStateStore
+	userID string
+
+	mtx sync.Mutex
+	// the evaluated groups, to restore the groups on their first evaluation
+	groups map[string]*rules.Group
+}
+
+func newTenantAlertState(store *AlertStateStore, userID string) *tenantAlertState {
+	return &tenantAlertState{
+		store:  store,
+		userID: userID,
+		groups: map[string]*rules.Group{},
+	}
+}
+
+// evalIterationFunc wraps the evaluation of the groups to save the state of their alerts after every evaluation.
+//
+// The rules manager only restores the 'for' state of the groups loaded on
+// startup, the groups loaded afterwards, e.g. when rule groups are resharded
+// between rulers, are restored after their first evaluation instead.
+func (t *tenantAlertState) evalIterationFunc(next rules.GroupEvalIterationFunc) rules.GroupEvalIterationFunc {
+	if next == nil {
+		next = rules.DefaultEvalIterationFunc
+	}
+	return func(ctx context.Context, g *rules.Group, evalTimestamp time.Time) {
+		next(ctx, g, evalTimestamp)
+
+		if t.firstEvaluation(g) && restored(g) {
+			t.store.restore(ctx, t.userID, g, evalTimestamp)
+		}
+		t.store.save(ctx, t.userID, g)
+	}
+}
+
+func (t *tenantAlertState) firstEvaluation(g *rules.Group) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	key := rules.GroupKey(g.File(), g.Name())
+	prev := t.groups[key]
+	t.groups[key] = g
+	return prev != g
+}
+
+// prune forgets the groups of the files no longer evaluated.
+func (t *tenantAlertState) prune(files []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	keep := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		keep[f] = struct{}{}
+	}
+	for key, g := range t.groups {
+		if _, ok := keep[g.File()]; !ok {
+			delete(t.groups, key)
+			t.store.forget(t.userID, g.File(), g.Name())
+		}
+	}
+}
+
+// stop forgets all the groups of the tenant, once the ruler stops evaluating its rules.
+func (t *tenantAlertState) stop() {
+	t.prune(nil)
+	t.store.forgetTenant(t.userID)
+}
+
+// restored returns whether the rules manager will not restore the 'for' state of the alerts of the group.
+func restored(g *rules.Group) bool {
+	for _, r := range g.Rules() {
+		if ar, ok := r.(*rules.AlertingRule); ok && !ar.Restored() {
+			return false
+		}
+	}
+	return true
+}
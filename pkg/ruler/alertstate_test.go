@@ -0,0 +1,244 @@
+package ruler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+	"github.com/grafana/loki/v3/pkg/util"
+)
+
+type noopAppendable struct{}
+
+func (noopAppendable) Appender(_ context.Context) storage.Appender { return noopAppender{} }
+
+type noopAppender struct {
+	storage.Appender
+}
+
+func (noopAppender) Append(_ storage.SeriesRef, _ labels.Labels, _ int64, _ float64) (storage.SeriesRef, error) {
+	return 0, nil
+}
+func (noopAppender) Commit() error   { return nil }
+func (noopAppender) Rollback() error { return nil }
+
+// newTestAlertGroup returns a group with an alerting rule firing after 30m while active is true.
+func newTestAlertGroup(t *testing.T, active *bool, restored bool) *rules.Group {
+	expr, err := parser.ParseExpr("condition")
+	require.NoError(t, err)
+
+	queryFn := func(_ context.Context, _ string, ts time.Time) (promql.Vector, error) {
+		if !*active {
+			return nil, nil
+		}
+		return promql.Vector{{Metric: labels.FromStrings("app", "foo"), T: util.TimeToMillis(ts), F: 1}}, nil
+	}
+
+	return rules.NewGroup(rules.GroupOptions{
+		Name:     "group",
+		File:     "/rules/user/my%2Fnamespace",
+		Interval: time.Minute,
+		Rules: []rules.Rule{
+			rules.NewAlertingRule(ruleName, expr, 30*time.Minute, 0, labels.FromStrings("severity", "page"), labels.EmptyLabels(), labels.EmptyLabels(), "", restored, log.NewNopLogger()),
+		},
+		Opts: &rules.ManagerOptions{
+			Appendable: noopAppendable{},
+			QueryFunc:  queryFn,
+			NotifyFunc: func(context.Context, string, ...*rules.Alert) {},
+			Context:    context.Background(),
+			Logger:     log.NewNopLogger(),
+		},
+	})
+}
+
+func alertStateObjects(t *testing.T, objectClient client.ObjectClient) []client.StorageObject {
+	objects, _, err := objectClient.List(context.Background(), alertStatePrefix, "")
+	require.NoError(t, err)
+	return objects
+}
+
+func TestAlertStateStore_RestoreResharded(t *testing.T) {
+	objectClient := testutils.NewInMemoryObjectClient()
+	active := true
+	now := time.Now()
+	activeAt := now.Add(-20 * time.Minute)
+
+	// the alert becomes active on a first ruler
+	tenant := newTenantAlertState(NewAlertStateStore(objectClient, time.Hour, 5*time.Minute, nil, log.NewNopLogger()), "user")
+	iter := tenant.evalIterationFunc(nil)
+	g := newTestAlertGroup(t, &active, true)
+	iter(context.Background(), g, activeAt)
+	iter(context.Background(), g, activeAt.Add(time.Minute))
+	require.Len(t, alertStateObjects(t, objectClient), 1)
+	require.Equal(t, alertStateObjectKey("user", "/rules/user/my%2Fnamespace", "group"), alertStateObjects(t, objectClient)[0].Key)
+
+	// the group is resharded to a second ruler, which restores the time the alert became active
+	tenant = newTenantAlertState(NewAlertStateStore(objectClient, time.Hour, 5*time.Minute, nil, log.NewNopLogger()), "user")
+	iter = tenant.evalIterationFunc(nil)
+	g = newTestAlertGroup(t, &active, true)
+	iter(context.Background(), g, now)
+	alerts := g.AlertingRules()[0].ActiveAlerts()
+	require.Len(t, alerts, 1)
+	require.True(t, activeAt.Equal(alerts[0].ActiveAt), alerts[0].ActiveAt)
+	require.Equal(t, rules.StatePending, alerts[0].State)
+
+	// the alert fires once active for 30m
+	iter(context.Background(), g, activeAt.Add(30*time.Minute))
+	require.Equal(t, rules.StateFiring, g.AlertingRules()[0].ActiveAlerts()[0].State)
+
+	// the state is removed once the alert resolves
+	active = false
+	iter(context.Background(), g, activeAt.Add(31*time.Minute))
+	require.Empty(t, alertStateObjects(t, objectClient))
+}
+
+func TestAlertStateStore_Restore(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name        string
+		activeAt    time.Time
+		updatedAt   time.Time
+		expActiveAt time.Time
+	}{
+		{
+			name:        "pending",
+			activeAt:    now.Add(-10 * time.Minute),
+			updatedAt:   now.Add(-time.Minute),
+			expActiveAt: now.Add(-10 * time.Minute),
+		},
+		{
+			name:      "outdated",
+			activeAt:  now.Add(-3 * time.Hour),
+			updatedAt: now.Add(-2 * time.Hour),
+			// the alert was not active during the outage tolerance, it becomes active now
+			expActiveAt: now,
+		},
+		{
+			name:      "about to fire",
+			activeAt:  now.Add(-28 * time.Minute),
+			updatedAt: now.Add(-time.Minute),
+			// the alert fires after the grace period
+			expActiveAt: now.Add(5 * time.Minute).Add(-30 * time.Minute),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewAlertStateStore(testutils.NewInMemoryObjectClient(), time.Hour, 5*time.Minute, nil, log.NewNopLogger())
+			active := true
+			g := newTestAlertGroup(t, &active, true)
+
+			lbls := ForStateMetric(labels.FromStrings("app", "foo", "severity", "page", labels.AlertName, ruleName), ruleName)
+			store.loaded["user"] = &tenantAlertStates{
+				loadedAt: now,
+				alerts:   map[uint64]alertStateSample{lbls.Hash(): {activeAt: tc.activeAt, updatedAt: tc.updatedAt}},
+			}
+
+			newTenantAlertState(store, "user").evalIterationFunc(nil)(context.Background(), g, now)
+			require.True(t, tc.expActiveAt.Equal(g.AlertingRules()[0].ActiveAlerts()[0].ActiveAt))
+		})
+	}
+}
+
+func TestAlertStateStore_NotRestoredNotSaved(t *testing.T) {
+	objectClient := testutils.NewInMemoryObjectClient()
+	active := true
+
+	// the alerts of groups loaded on startup are restored by the rules manager,
+	// their state is not saved until then
+	tenant := newTenantAlertState(NewAlertStateStore(objectClient, time.Hour, 5*time.Minute, nil, log.NewNopLogger()), "user")
+	g := newTestAlertGroup(t, &active, false)
+	tenant.evalIterationFunc(nil)(context.Background(), g, time.Now())
+	require.Empty(t, alertStateObjects(t, objectClient))
+}
+
+func TestAlertStateStore_Prune(t *testing.T) {
+	objectClient := testutils.NewInMemoryObjectClient()
+	store := NewAlertStateStore(objectClient, time.Hour, 5*time.Minute, nil, log.NewNopLogger())
+	active := true
+
+	tenant := newTenantAlertState(store, "user")
+	iter := tenant.evalIterationFunc(nil)
+	g := newTestAlertGroup(t, &active, true)
+	iter(context.Background(), g, time.Now())
+	require.Len(t, store.written, 1)
+
+	// groups synced away are forgotten, their state is kept for the ruler they moved to
+	tenant.prune([]string{"/rules/user/other"})
+	require.Empty(t, store.written)
+	require.Len(t, alertStateObjects(t, objectClient), 1)
+
+	// removed tenants are forgotten
+	iter(context.Background(), g, time.Now())
+	require.NotNil(t, store.loaded["user"])
+	tenant.stop()
+	require.Empty(t, store.written)
+	require.Nil(t, store.loaded["user"])
+}
+
+func TestAlertStateStore_Cleanup(t *testing.T) {
+	dir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: dir})
+	require.NoError(t, err)
+	store := NewAlertStateStore(objectClient, time.Hour, 5*time.Minute, nil, log.NewNopLogger())
+
+	ctx := context.Background()
+	stale := alertStateObjectKey("removed", "/rules/removed/namespace", "group")
+	fresh := alertStateObjectKey("user", "/rules/user/namespace", "group")
+	for _, key := range []string{stale, fresh} {
+		require.NoError(t, objectClient.PutObject(ctx, key, strings.NewReader("{}")))
+	}
+	updatedAt := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, filepath.FromSlash(stale)), updatedAt, updatedAt))
+
+	// states not updated within the outage tolerance are never restored, they are deleted
+	store.cleanup(ctx)
+	objects := alertStateObjects(t, objectClient)
+	require.Len(t, objects, 1)
+	require.Equal(t, fresh, objects[0].Key)
+	require.False(t, store.cleanedUpAt.IsZero())
+}
+
+func TestMemStore_RestoresAlertState(t *testing.T) {
+	now := time.Now()
+	activeAt := now.Add(-10 * time.Minute)
+
+	alertState := NewAlertStateStore(testutils.NewInMemoryObjectClient(), time.Hour, 5*time.Minute, nil, log.NewNopLogger())
+	lbls := ForStateMetric(labels.FromStrings("app", "foo", labels.AlertName, ruleName), ruleName)
+	alertState.loaded["test"] = &tenantAlertStates{
+		loadedAt: now,
+		alerts:   map[uint64]alertStateSample{lbls.Hash(): {activeAt: activeAt, updatedAt: now.Add(-time.Minute)}},
+	}
+
+	store := NewMemStore("test", func(context.Context, string, time.Time) (promql.Vector, error) {
+		t.Fatal("the state of the alert is restored without evaluating the rule")
+		return nil, nil
+	}, newMemstoreMetrics(nil), time.Minute, alertState, log.NewNopLogger())
+	store.Start(MockRuleIter([]rulefmt.Rule{{Alert: ruleName, Expr: "unused"}}))
+	defer store.Stop()
+
+	q, err := store.Querier(util.TimeToMillis(now.Add(-time.Hour)), util.TimeToMillis(now))
+	require.NoError(t, err)
+	set := q.Select(context.Background(), false, nil, labelsToMatchers(lbls)...)
+	require.True(t, set.Next())
+	it := set.At().Iterator(nil)
+	require.Equal(t, chunkenc.ValFloat, it.Next())
+	ts, v := it.At()
+	require.Equal(t, util.TimeToMillis(now), ts)
+	require.Equal(t, float64(activeAt.Unix()), v)
+	require.False(t, set.Next())
+}
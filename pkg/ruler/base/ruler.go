@@ -58,6 +58,7 @@ const (
 	rulerSyncReasonInitial    = "initial"
 	rulerSyncReasonPeriodic   = "periodic"
 	rulerSyncReasonRingChange = "ring-change"
+	rulerSyncReasonRuleChange = "rule-change"
 
 	// Limit errors
 	errMaxRuleGroupsPerUserLimitExceeded        = "per-user rule groups limit (limit: %d actual: %d) exceeded"
@@ -545,6 +546,14 @@ func (r *Ruler) run(ctx context.Context) error {
 		ringTickerChan = ringTicker.C
 	}
 
+	var ruleChangesChan <-chan struct{}
+	if watcher, ok := r.store.(rulestore.Watcher); ok {
+		var err error
+		if ruleChangesChan, err = watcher.Watch(ctx); err != nil {
+			return errors.Wrap(err, "unable to watch rule changes")
+		}
+	}
+
 	r.syncRules(ctx, rulerSyncReasonInitial)
 	for {
 		select {
@@ -552,6 +561,8 @@ func (r *Ruler) run(ctx context.Context) error {
 			return nil
 		case <-tick.C:
 			r.syncRules(ctx, rulerSyncReasonPeriodic)
+		case <-ruleChangesChan:
+			r.syncRules(ctx, rulerSyncReasonRuleChange)
 		case <-ringTickerChan:
 			// We ignore the error because in case of error it will return an empty
 			// replication set which we use to compare with the previous state.
@@ -962,6 +973,24 @@ func (r *Ruler) getLocalRules(userID string, req *RulesRequest) ([]*GroupStateDe
 	return groupDescs, nil
 }
 
+// TenantShardCount returns the number of healthy rulers the rule groups of the tenant are sharded across.
+func (r *Ruler) TenantShardCount(userID string) int {
+	if !r.cfg.EnableSharding {
+		return 1
+	}
+
+	ring := ring.ReadRing(r.ring)
+	if shardSize := r.limits.RulerTenantShardSize(userID); shardSize > 0 && r.cfg.ShardingStrategy == util.ShardingStrategyShuffle {
+		ring = r.ring.ShuffleShard(userID, shardSize)
+	}
+
+	rulers, err := ring.GetReplicationSetForOperation(RingOp)
+	if err != nil || len(rulers.Instances) == 0 {
+		return 1
+	}
+	return len(rulers.Instances)
+}
+
 func (r *Ruler) getShardedRules(ctx context.Context, userID string, rulesReq *RulesRequest) ([]*GroupStateDesc, error) {
 	ring := ring.ReadRing(r.ring)
 
@@ -598,6 +598,15 @@ func TestGetRules(t *testing.T) {
 							}
 							mockPoolClient.numberOfCalls.Store(0)
 						}
+
+						switch {
+						case tc.shardingStrategy == util.ShardingStrategyShuffle:
+							require.Equal(t, tc.shuffleShardSize, r.TenantShardCount(u))
+						case tc.sharding:
+							require.Equal(t, len(rulerAddrMap), r.TenantShardCount(u))
+						default:
+							require.Equal(t, 1, r.TenantShardCount(u))
+						}
 					})
 				}
 
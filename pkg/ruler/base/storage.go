@@ -110,7 +110,7 @@ func NewLegacyRuleStore(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetr
 	case "alibabacloud":
 		client, err = alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaCloud)
 	case "local":
-		return local.NewLocalRulesClient(cfg.Local, loader)
+		return local.NewLocalRulesClient(cfg.Local, loader, logger)
 	default:
 		return nil, fmt.Errorf("unrecognized rule storage mode %v, choose one of: configdb, gcs, s3, swift, azure, local", cfg.Type)
 	}
@@ -134,7 +134,7 @@ func NewRuleStore(ctx context.Context, cfg rulestore.Config, cfgProvider bucket.
 	}
 
 	if cfg.Backend == local.Name {
-		return local.NewLocalRulesClient(cfg.Local, loader)
+		return local.NewLocalRulesClient(cfg.Local, loader, logger)
 	}
 
 	bucketClient, err := bucket.NewClient(ctx, cfg.Config, "ruler-storage", logger, reg)
@@ -3,6 +3,7 @@ package ruler
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,11 +55,15 @@ type RulesLimits interface {
 
 	RulerRemoteEvaluationTimeout(userID string) time.Duration
 	RulerRemoteEvaluationMaxResponseSize(userID string) int64
+
+	RulerEvaluationTimeBudget(userID string) time.Duration
+	RulerEvaluationBytesBudget(userID string) int
+	RulerEvaluationBudgetPeriod(userID string) time.Duration
 }
 
 // queryFunc returns a new query function using the rules.EngineQueryFunc function
 // and passing an altered timestamp.
-func queryFunc(evaluator Evaluator, checker readyChecker, userID string, logger log.Logger) rules.QueryFunc {
+func queryFunc(evaluator Evaluator, checker readyChecker, userID string, budget *tenantEvaluationBudget, logger log.Logger) rules.QueryFunc {
 	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
 		hash := util.HashedQuery(qs)
 		detail := rules.FromOriginContext(ctx)
@@ -78,6 +83,10 @@ func queryFunc(evaluator Evaluator, checker readyChecker, userID string, logger
 			level.Error(detailLog).Log("msg", "rule evaluation failed", "err", err)
 			return nil, fmt.Errorf("rule evaluation failed: %w", err)
 		}
+		if budget != nil {
+			budget.record(ctx, detail, res.Statistics, time.Now())
+		}
+
 		switch v := res.Data.(type) {
 		case promql.Vector:
 			return v, nil
@@ -129,7 +138,7 @@ const MetricsPrefix = "loki_ruler_wal_"
 
 var registry storageRegistry
 
-func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimits, logger log.Logger, reg prometheus.Registerer) ruler.ManagerFactory {
+func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimits, alertState *AlertStateStore, alertLogLines *AlertLogLines, budgets *EvaluationBudgets, logger log.Logger, reg prometheus.Registerer) ruler.ManagerFactory {
 	reg = prometheus.WrapRegistererWithPrefix(MetricsPrefix, reg)
 
 	registry = newWALRegistry(log.With(logger, "storage", "registry"), reg, cfg, overrides)
@@ -144,20 +153,29 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 		registry.configureTenantStorage(userID)
 
 		logger = log.With(logger, "user", userID)
-		queryFn := queryFunc(evaluator, registry, userID, logger)
-		memStore := NewMemStore(userID, queryFn, newMemstoreMetrics(reg), 5*time.Minute, log.With(logger, "subcomponent", "MemStore"))
+		var budget *tenantEvaluationBudget
+		if budgets != nil {
+			budget = budgets.tenant(userID)
+		}
+		queryFn := queryFunc(evaluator, registry, userID, budget, logger)
+		memStore := NewMemStore(userID, queryFn, newMemstoreMetrics(reg), 5*time.Minute, alertState, log.With(logger, "subcomponent", "MemStore"))
 
 		// GroupLoader builds a cache of the rules as they're loaded by the
 		// manager.This is used to back the memstore
 		groupLoader := NewCachingGroupLoader(GroupLoader{})
 
+		notifyFunc := ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID)
+		if alertLogLines != nil {
+			notifyFunc = newTenantAlertLogLines(alertLogLines, userID).notifyFunc(notifyFunc)
+		}
+
 		mgr := rules.NewManager(&rules.ManagerOptions{
 			Appendable:               registry,
 			Queryable:                memStore,
 			QueryFunc:                queryFn,
 			Context:                  user.InjectOrgID(ctx, userID),
 			ExternalURL:              cfg.ExternalURL.URL,
-			NotifyFunc:               ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID),
+			NotifyFunc:               notifyFunc,
 			Logger:                   logger,
 			Registerer:               reg,
 			OutageTolerance:          cfg.OutageTolerance,
@@ -171,6 +189,10 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 			manager:     mgr,
 			groupLoader: groupLoader,
 		}
+		if alertState != nil {
+			cachingManager.alertState = newTenantAlertState(alertState, userID)
+		}
+		cachingManager.budget = budget
 
 		memStore.Start(groupLoader)
 
@@ -184,18 +206,35 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 type CachingRulesManager struct {
 	manager     ruler.RulesManager
 	groupLoader *CachingGroupLoader
+	// alertState persists the state of the alerts of the groups, if enabled
+	alertState *tenantAlertState
+	// budget skips the evaluation of the groups while the tenant exceeds its evaluation budget
+	budget *tenantEvaluationBudget
 }
 
 // Update reconciles the state of the CachingGroupLoader after a manager.Update.
 // The GroupLoader is mutated as part of a call to Update but it might still
 // contain removed files. Update tells the loader which files to keep
 func (m *CachingRulesManager) Update(interval time.Duration, files []string, externalLabels labels.Labels, externalURL string, ruleGroupPostProcessFunc rules.GroupEvalIterationFunc) error {
+	if m.alertState != nil {
+		ruleGroupPostProcessFunc = m.alertState.evalIterationFunc(ruleGroupPostProcessFunc)
+	}
+	if m.budget != nil {
+		ruleGroupPostProcessFunc = m.budget.evalIterationFunc(ruleGroupPostProcessFunc)
+	}
+
 	err := m.manager.Update(interval, files, externalLabels, externalURL, ruleGroupPostProcessFunc)
 	if err != nil {
 		return err
 	}
 
 	m.groupLoader.Prune(files)
+	if m.alertState != nil {
+		m.alertState.prune(files)
+	}
+	if m.budget != nil {
+		m.budget.prune(files)
+	}
 	return nil
 }
 
@@ -205,6 +244,9 @@ func (m *CachingRulesManager) Run() {
 
 func (m *CachingRulesManager) Stop() {
 	m.manager.Stop()
+	if m.alertState != nil {
+		m.alertState.stop()
+	}
 }
 
 func (m *CachingRulesManager) RuleGroups() []*rules.Group {
@@ -249,11 +291,16 @@ func validateRuleNode(r *rulefmt.RuleNode, groupName string) error {
 
 	if r.Expr.Value == "" {
 		return errors.Errorf("field 'expr' must be set in rule")
-	} else if _, err := syntax.ParseExpr(r.Expr.Value); err != nil {
-		if r.Record.Value != "" {
-			return errors.Wrapf(err, fmt.Sprintf("could not parse expression for record '%s' in group '%s'", r.Record.Value, groupName))
+	}
+
+	// pattern rules are PromQL expressions evaluated against the pattern ingesters
+	if _, isPatternExpr := parsePatternExpr(r.Expr.Value); !isPatternExpr {
+		if _, err := syntax.ParseExpr(r.Expr.Value); err != nil {
+			if r.Record.Value != "" {
+				return errors.Wrapf(err, fmt.Sprintf("could not parse expression for record '%s' in group '%s'", r.Record.Value, groupName))
+			}
+			return errors.Wrapf(err, fmt.Sprintf("could not parse expression for alert '%s' in group '%s'", r.Alert.Value, groupName))
 		}
-		return errors.Wrapf(err, fmt.Sprintf("could not parse expression for alert '%s' in group '%s'", r.Alert.Value, groupName))
 	}
 
 	if r.Record.Value != "" {
@@ -284,6 +331,15 @@ func validateRuleNode(r *rulefmt.RuleNode, groupName string) error {
 		}
 	}
 
+	if v, ok := r.Annotations[LogLinesLimitAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			return errors.Errorf("invalid annotation %s: %q is not a positive number of log lines", LogLinesLimitAnnotation, v)
+		}
+		if _, err := parseLogLinesQuery(r.Expr.Value); err != nil {
+			return errors.Wrapf(err, "invalid annotation %s: could not derive the log query of alert '%s' in group '%s'", LogLinesLimitAnnotation, r.Alert.Value, groupName)
+		}
+	}
+
 	for _, err := range testTemplateParsing(r) {
 		return err
 	}
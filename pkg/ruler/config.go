@@ -24,6 +24,10 @@ type Config struct {
 	RemoteWrite RemoteWriteConfig `yaml:"remote_write,omitempty" doc:"description=Remote-write configuration to send rule samples to a Prometheus remote-write endpoint."`
 
 	Evaluation EvaluationConfig `yaml:"evaluation,omitempty" doc:"description=Configuration for rule evaluation."`
+
+	AlertState AlertStateConfig `yaml:"alert_state,omitempty" doc:"description=Configuration for persisting the state of alerts."`
+
+	AlertLogLines AlertLogLinesConfig `yaml:"alert_log_lines,omitempty" doc:"description=Configuration for attaching log lines to alerts."`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -32,6 +36,8 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.WAL.RegisterFlags(f)
 	c.WALCleaner.RegisterFlags(f)
 	c.Evaluation.RegisterFlags(f)
+	c.AlertState.RegisterFlags(f)
+	c.AlertLogLines.RegisterFlags(f)
 }
 
 // Validate overrides the embedded cortex variant which expects a cortex limits struct. Instead, copy the relevant bits over.
@@ -48,6 +54,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid ruler wal cleaner config: %w", err)
 	}
 
+	if err := c.AlertLogLines.Validate(); err != nil {
+		return fmt.Errorf("invalid ruler alert log lines config: %w", err)
+	}
+
 	return nil
 }
 
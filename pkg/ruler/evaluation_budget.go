@@ -0,0 +1,369 @@
+package ruler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	skipReasonTimeBudget  = "time_budget_exhausted"
+	skipReasonBytesBudget = "bytes_budget_exhausted"
+
+	defaultRuleStatsLimit = 10
+)
+
+type evaluationBudgetMetrics struct {
+	execTime       *prometheus.CounterVec
+	bytesProcessed *prometheus.CounterVec
+	skipped        *prometheus.CounterVec
+}
+
+func newEvaluationBudgetMetrics(r prometheus.Registerer) *evaluationBudgetMetrics {
+	return &evaluationBudgetMetrics{
+		execTime: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_evaluation_exec_time_seconds_total",
+			Help:      "Total query execution time of the rule evaluations, as reported by their query statistics.",
+		}, []string{"user"}),
+		bytesProcessed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_evaluation_bytes_processed_total",
+			Help:      "Total bytes processed by the rule evaluations, as reported by their query statistics.",
+		}, []string{"user"}),
+		skipped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ruler_skipped_group_evaluations_total",
+			Help:      "Total number of rule group evaluations skipped because the tenant exhausted its evaluation budget.",
+		}, []string{"user", "reason"}),
+	}
+}
+
+// RuleStats are the evaluation statistics of a rule over the current budget period.
+type RuleStats struct {
+	Namespace           string    `json:"namespace"`
+	Group               string    `json:"group"`
+	Name                string    `json:"name"`
+	Kind                string    `json:"kind"`
+	Query               string    `json:"query"`
+	Evaluations         int64     `json:"evaluations"`
+	TotalExecTime       float64   `json:"total_exec_time_seconds"`
+	MaxExecTime         float64   `json:"max_exec_time_seconds"`
+	LastExecTime        float64   `json:"last_exec_time_seconds"`
+	TotalBytesProcessed int64     `json:"total_bytes_processed"`
+	LastBytesProcessed  int64     `json:"last_bytes_processed"`
+	LastEvaluation      time.Time `json:"last_evaluation"`
+}
+
+// SkippedGroup is a rule group whose evaluation was skipped in the current budget period.
+type SkippedGroup struct {
+	Namespace   string    `json:"namespace"`
+	Group       string    `json:"group"`
+	Reason      string    `json:"reason"`
+	Skipped     int64     `json:"skipped"`
+	LastSkipped time.Time `json:"last_skipped"`
+}
+
+// EvaluationStats are the evaluation statistics of the rules of a tenant over the current budget period.
+type EvaluationStats struct {
+	PeriodStart    time.Time      `json:"period_start"`
+	ExecTime       float64        `json:"exec_time_seconds"`
+	ExecTimeBudget float64        `json:"exec_time_budget_seconds"`
+	BytesProcessed int64          `json:"bytes_processed"`
+	BytesBudget    int64          `json:"bytes_budget"`
+	SkippedGroups  []SkippedGroup `json:"skipped_groups"`
+	Rules          []RuleStats    `json:"rules"`
+}
+
+// EvaluationBudgets accounts for the query work of the rule evaluations of every tenant, and skips the evaluation
+// of the rule groups of the tenants exceeding their budgets. The rule groups of a tenant are sharded across rulers,
+// each ruler enforces its share of the budgets of the tenant.
+type EvaluationBudgets struct {
+	limits  RulesLimits
+	metrics *evaluationBudgetMetrics
+	logger  log.Logger
+	// shards returns the number of rulers the rule groups of a tenant are sharded across, if set.
+	shards func(userID string) int
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantEvaluationBudget
+}
+
+func NewEvaluationBudgets(limits RulesLimits, reg prometheus.Registerer, logger log.Logger) *EvaluationBudgets {
+	return &EvaluationBudgets{
+		limits:  limits,
+		metrics: newEvaluationBudgetMetrics(reg),
+		logger:  log.With(logger, "component", "evaluation-budgets"),
+		tenants: map[string]*tenantEvaluationBudget{},
+	}
+}
+
+func (b *EvaluationBudgets) tenant(userID string) *tenantEvaluationBudget {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	t, ok := b.tenants[userID]
+	if !ok {
+		t = &tenantEvaluationBudget{
+			EvaluationBudgets: b,
+			userID:            userID,
+			rules:             map[ruleStatsKey]*RuleStats{},
+			skipped:           map[string]*SkippedGroup{},
+		}
+		b.tenants[userID] = t
+	}
+	return t
+}
+
+// ServeHTTP lists the slowest or most expensive rules of the tenant evaluated by this ruler, sorted by 'sort'
+// (either 'time', the default, or 'bytes') and limited to 'limit' rules.
+func (b *EvaluationBudgets) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	userID, err := tenant.TenantID(req.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	sortBy := req.FormValue("sort")
+	if sortBy == "" {
+		sortBy = "time"
+	}
+	if sortBy != "time" && sortBy != "bytes" {
+		http.Error(w, "invalid sort: must be one of 'time' or 'bytes'", http.StatusBadRequest)
+		return
+	}
+	limit := defaultRuleStatsLimit
+	if v := req.FormValue("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, "invalid limit: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res := b.tenant(userID).stats(time.Now(), sortBy, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Status string           `json:"status"`
+		Data   *EvaluationStats `json:"data"`
+	}{Status: "success", Data: res}); err != nil {
+		level.Error(b.logger).Log("msg", "failed to write rule stats response", "err", err)
+	}
+}
+
+type ruleStatsKey struct {
+	file, group, kind, name, query string
+}
+
+type ruleGroupContextKey struct{}
+
+type ruleGroup struct {
+	file, name string
+}
+
+// tenantEvaluationBudget is the evaluation budget of a tenant.
+type tenantEvaluationBudget struct {
+	*EvaluationBudgets
+	userID string
+
+	mtx            sync.Mutex
+	periodStart    time.Time
+	execTime       time.Duration
+	bytesProcessed int64
+	rules          map[ruleStatsKey]*RuleStats
+	skipped        map[string]*SkippedGroup
+}
+
+// evalIterationFunc skips the evaluation of the rule groups while the tenant exceeds its budget.
+func (t *tenantEvaluationBudget) evalIterationFunc(next rules.GroupEvalIterationFunc) rules.GroupEvalIterationFunc {
+	if next == nil {
+		next = rules.DefaultEvalIterationFunc
+	}
+	return func(ctx context.Context, g *rules.Group, evalTimestamp time.Time) {
+		if reason := t.exhausted(time.Now()); reason != "" {
+			t.skip(g, reason, time.Now())
+			return
+		}
+
+		// the group of the rules is not part of their evaluation context
+		next(context.WithValue(ctx, ruleGroupContextKey{}, ruleGroup{file: g.File(), name: g.Name()}), g, evalTimestamp)
+	}
+}
+
+// roll starts a new budget period if the current one is over. It must be called with the lock held.
+func (t *tenantEvaluationBudget) roll(now time.Time) {
+	period := t.limits.RulerEvaluationBudgetPeriod(t.userID)
+	if period <= 0 {
+		period = time.Hour
+	}
+	if start := now.Truncate(period); start.After(t.periodStart) {
+		t.periodStart = start
+		t.execTime = 0
+		t.bytesProcessed = 0
+		clear(t.rules)
+		clear(t.skipped)
+	}
+}
+
+// shardCount returns the number of rulers sharing the budgets of the tenant.
+func (t *tenantEvaluationBudget) shardCount() int {
+	if t.shards == nil {
+		return 1
+	}
+	return max(t.shards(t.userID), 1)
+}
+
+// timeBudget returns the share of the time budget of the tenant of this ruler.
+func (t *tenantEvaluationBudget) timeBudget() time.Duration {
+	return t.limits.RulerEvaluationTimeBudget(t.userID) / time.Duration(t.shardCount())
+}
+
+// bytesBudget returns the share of the bytes budget of the tenant of this ruler.
+func (t *tenantEvaluationBudget) bytesBudget() int64 {
+	return int64(t.limits.RulerEvaluationBytesBudget(t.userID)) / int64(t.shardCount())
+}
+
+func (t *tenantEvaluationBudget) exhausted(now time.Time) string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.roll(now)
+
+	if budget := t.timeBudget(); budget > 0 && t.execTime >= budget {
+		return skipReasonTimeBudget
+	}
+	if budget := t.bytesBudget(); budget > 0 && t.bytesProcessed >= budget {
+		return skipReasonBytesBudget
+	}
+	return ""
+}
+
+func (t *tenantEvaluationBudget) skip(g *rules.Group, reason string, now time.Time) {
+	t.metrics.skipped.WithLabelValues(t.userID, reason).Inc()
+	level.Warn(t.logger).Log("msg", "skipping rule group evaluation", "user", t.userID, "file", g.File(), "group", g.Name(), "reason", reason)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	key := rules.GroupKey(g.File(), g.Name())
+	s, ok := t.skipped[key]
+	if !ok {
+		s = &SkippedGroup{Namespace: namespaceFromFile(g.File()), Group: g.Name()}
+		t.skipped[key] = s
+	}
+	s.Reason = reason
+	s.Skipped++
+	s.LastSkipped = now
+}
+
+// record accounts for the query statistics of the evaluation of a rule.
+func (t *tenantEvaluationBudget) record(ctx context.Context, detail rules.RuleDetail, res stats.Result, now time.Time) {
+	execTime := res.Summary.ExecTime
+	bytesProcessed := res.Summary.TotalBytesProcessed
+	t.metrics.execTime.WithLabelValues(t.userID).Add(execTime)
+	t.metrics.bytesProcessed.WithLabelValues(t.userID).Add(float64(bytesProcessed))
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.roll(now)
+
+	t.execTime += time.Duration(execTime * float64(time.Second))
+	t.bytesProcessed += bytesProcessed
+
+	// the rules evaluated outside of their group, to restore the state of alerts, only count toward the budget
+	g, ok := ctx.Value(ruleGroupContextKey{}).(ruleGroup)
+	if !ok {
+		return
+	}
+	key := ruleStatsKey{file: g.file, group: g.name, kind: detail.Kind, name: detail.Name, query: detail.Query}
+	s, ok := t.rules[key]
+	if !ok {
+		s = &RuleStats{Namespace: namespaceFromFile(g.file), Group: g.name, Name: detail.Name, Kind: detail.Kind, Query: detail.Query}
+		t.rules[key] = s
+	}
+	s.Evaluations++
+	s.TotalExecTime += execTime
+	s.MaxExecTime = max(s.MaxExecTime, execTime)
+	s.LastExecTime = execTime
+	s.TotalBytesProcessed += bytesProcessed
+	s.LastBytesProcessed = bytesProcessed
+	s.LastEvaluation = now
+}
+
+// prune forgets the rules of the files no longer evaluated.
+func (t *tenantEvaluationBudget) prune(files []string) {
+	keep := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		keep[f] = struct{}{}
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for key := range t.rules {
+		if _, ok := keep[key.file]; !ok {
+			delete(t.rules, key)
+		}
+	}
+}
+
+func (t *tenantEvaluationBudget) stats(now time.Time, sortBy string, limit int) *EvaluationStats {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.roll(now)
+
+	res := &EvaluationStats{
+		PeriodStart:    t.periodStart,
+		ExecTime:       t.execTime.Seconds(),
+		ExecTimeBudget: t.timeBudget().Seconds(),
+		BytesProcessed: t.bytesProcessed,
+		BytesBudget:    t.bytesBudget(),
+		SkippedGroups:  make([]SkippedGroup, 0, len(t.skipped)),
+		Rules:          make([]RuleStats, 0, len(t.rules)),
+	}
+	for _, s := range t.skipped {
+		res.SkippedGroups = append(res.SkippedGroups, *s)
+	}
+	sort.Slice(res.SkippedGroups, func(i, j int) bool {
+		return res.SkippedGroups[i].LastSkipped.After(res.SkippedGroups[j].LastSkipped)
+	})
+
+	for _, s := range t.rules {
+		res.Rules = append(res.Rules, *s)
+	}
+	sort.Slice(res.Rules, func(i, j int) bool {
+		if sortBy == "bytes" {
+			return res.Rules[i].TotalBytesProcessed > res.Rules[j].TotalBytesProcessed
+		}
+		return res.Rules[i].TotalExecTime > res.Rules[j].TotalExecTime
+	})
+	if len(res.Rules) > limit {
+		res.Rules = res.Rules[:limit]
+	}
+	return res
+}
+
+// namespaceFromFile returns the namespace of the rule groups of a rule file of the ruler.
+func namespaceFromFile(file string) string {
+	// the rule files of the ruler are named after the escaped namespace of their groups
+	namespace, err := url.PathUnescape(filepath.Base(file))
+	if err != nil {
+		return filepath.Base(file)
+	}
+	return namespace
+}
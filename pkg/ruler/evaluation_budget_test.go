@@ -0,0 +1,146 @@
+package ruler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestEvaluationBudget(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.RulerEvaluationTimeBudget = 2 * time.Second
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	budgets := NewEvaluationBudgets(limits, prometheus.NewRegistry(), log.NewNopLogger())
+	budget := budgets.tenant("user")
+
+	// every evaluation of the group takes 1.5s
+	evaluations := 0
+	iter := budget.evalIterationFunc(func(ctx context.Context, _ *rules.Group, _ time.Time) {
+		evaluations++
+		budget.record(ctx, rules.RuleDetail{Name: "slow", Kind: "alerting", Query: `sum(rate({app="foo"}[1h]))`}, stats.Result{
+			Summary: stats.Summary{ExecTime: 1.5, TotalBytesProcessed: 1000},
+		}, time.Now())
+		budget.record(ctx, rules.RuleDetail{Name: "expensive", Kind: "recording", Query: `sum(rate({app="bar"}[5m]))`}, stats.Result{
+			Summary: stats.Summary{ExecTime: 0.1, TotalBytesProcessed: 5000},
+		}, time.Now())
+	})
+
+	active := false
+	g := newTestAlertGroup(t, &active, true)
+
+	// the group is skipped once the tenant exhausted its budget
+	for i := 0; i < 3; i++ {
+		iter(context.Background(), g, time.Now())
+	}
+	require.Equal(t, 2, evaluations)
+	require.Equal(t, 1.0, testutil.ToFloat64(budgets.metrics.skipped.WithLabelValues("user", skipReasonTimeBudget)))
+
+	// the rules are listed by the ruler API
+	for _, tc := range []struct {
+		sort, first string
+	}{
+		{sort: "", first: "slow"},
+		{sort: "bytes", first: "expensive"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/rule_stats?sort="+tc.sort, nil)
+		req = req.WithContext(user.InjectOrgID(req.Context(), "user"))
+		rec := httptest.NewRecorder()
+		budgets.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Data EvaluationStats `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		require.InDelta(t, 3.2, resp.Data.ExecTime, 1e-9)
+		require.Equal(t, 2.0, resp.Data.ExecTimeBudget)
+		require.Equal(t, int64(12000), resp.Data.BytesProcessed)
+		require.Len(t, resp.Data.Rules, 2)
+		require.Equal(t, tc.first, resp.Data.Rules[0].Name)
+		require.Equal(t, "my/namespace", resp.Data.Rules[0].Namespace)
+		require.Equal(t, "group", resp.Data.Rules[0].Group)
+		require.Equal(t, int64(2), resp.Data.Rules[0].Evaluations)
+		require.Equal(t, []SkippedGroup{{
+			Namespace:   "my/namespace",
+			Group:       "group",
+			Reason:      skipReasonTimeBudget,
+			Skipped:     1,
+			LastSkipped: resp.Data.SkippedGroups[0].LastSkipped,
+		}}, resp.Data.SkippedGroups)
+	}
+
+	// the budget is renewed every period
+	next := time.Now().Add(time.Hour)
+	require.Empty(t, budget.exhausted(next))
+	require.Empty(t, budget.stats(next, "time", 10).Rules)
+}
+
+func TestEvaluationBudget_BytesBudget(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.RulerEvaluationBytesBudget = 1000
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	budget := NewEvaluationBudgets(limits, nil, log.NewNopLogger()).tenant("user")
+	now := time.Now()
+	require.Empty(t, budget.exhausted(now))
+
+	// the rules evaluated to restore the state of alerts count toward the budget
+	budget.record(context.Background(), rules.RuleDetail{Name: "rule"}, stats.Result{Summary: stats.Summary{TotalBytesProcessed: 1000}}, now)
+	require.Equal(t, skipReasonBytesBudget, budget.exhausted(now))
+	require.Empty(t, budget.stats(now, "bytes", 10).Rules)
+}
+
+func TestEvaluationBudget_InvalidRequest(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	budgets := NewEvaluationBudgets(limits, nil, log.NewNopLogger())
+
+	for _, target := range []string{"/loki/api/v1/rule_stats?sort=name", "/loki/api/v1/rule_stats?limit=0"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(user.InjectOrgID(req.Context(), "user"))
+		rec := httptest.NewRecorder()
+		budgets.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
+
+func TestEvaluationBudget_Sharded(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.RulerEvaluationTimeBudget = 4 * time.Second
+	defaultLimits.RulerEvaluationBytesBudget = 3000
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	// the rule groups of the tenant are sharded across 2 rulers, which each enforce half of its budgets
+	budgets := NewEvaluationBudgets(limits, prometheus.NewRegistry(), log.NewNopLogger())
+	budgets.shards = func(string) int { return 2 }
+	budget := budgets.tenant("user")
+	res := budget.stats(time.Now(), "time", 1)
+	require.Equal(t, 2.0, res.ExecTimeBudget)
+	require.Equal(t, int64(1500), res.BytesBudget)
+
+	now := time.Now()
+	require.Empty(t, budget.exhausted(now))
+	budget.record(context.Background(), rules.RuleDetail{}, stats.Result{Summary: stats.Summary{TotalBytesProcessed: 1500}}, now)
+	require.Equal(t, skipReasonBytesBudget, budget.exhausted(now))
+
+	// a single ruler enforces the whole budgets
+	budgets.shards = func(string) int { return 1 }
+	require.Empty(t, budget.exhausted(now))
+}
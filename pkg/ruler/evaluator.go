@@ -16,6 +16,12 @@ type Evaluator interface {
 	Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error)
 }
 
+// LogQuerier is implemented by the evaluators able to run log queries, used to attach log lines to alerts.
+type LogQuerier interface {
+	// QueryLogs returns the last limit log lines matching the log query between start and end.
+	QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error)
+}
+
 type EvaluationConfig struct {
 	Mode      string        `yaml:"mode,omitempty"`
 	MaxJitter time.Duration `yaml:"max_jitter"`
@@ -2,6 +2,7 @@ package ruler
 
 import (
 	"context"
+	"fmt"
 	"hash"
 	"math"
 	"sync"
@@ -55,6 +56,15 @@ func (e *EvaluatorWithJitter) Eval(ctx context.Context, qs string, now time.Time
 	return e.inner.Eval(ctx, qs, now)
 }
 
+// QueryLogs runs the log query with the wrapped evaluator, without jitter.
+func (e *EvaluatorWithJitter) QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error) {
+	q, ok := e.inner.(LogQuerier)
+	if !ok {
+		return nil, fmt.Errorf("evaluator %T cannot run log queries", e.inner)
+	}
+	return q.QueryLogs(ctx, qs, start, end, limit)
+}
+
 func (e *EvaluatorWithJitter) calculateJitter(qs string, logger log.Logger) time.Duration {
 	var h uint32
 
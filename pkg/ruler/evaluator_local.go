@@ -51,3 +51,31 @@ func (l *LocalEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*l
 
 	return &res, nil
 }
+
+func (l *LocalEvaluator) QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error) {
+	params, err := logql.NewLiteralParams(
+		qs,
+		start,
+		end,
+		0,
+		0,
+		logproto.BACKWARD,
+		limit,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := l.engine.Query(params).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	streams, ok := res.Data.(logqlmodel.Streams)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %q, the query must be a log query", res.Data.Type())
+	}
+	return streams, nil
+}
@@ -0,0 +1,185 @@
+package ruler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/querier/series"
+)
+
+const (
+	// PatternMetricName is the name of the series of the number of log lines matching the patterns detected by
+	// the pattern ingesters, which the expressions of pattern rules select.
+	PatternMetricName = "pattern_count"
+	// PatternLabel is the label of the pattern of a pattern_count series.
+	PatternLabel = "pattern"
+
+	patternQueryTimeout = 2 * time.Minute
+)
+
+// PatternQuerier queries the patterns detected by the pattern ingesters.
+type PatternQuerier interface {
+	Patterns(ctx context.Context, req *logproto.QueryPatternsRequest) (*logproto.QueryPatternsResponse, error)
+}
+
+// parsePatternExpr parses the expression of a pattern rule, a PromQL expression only selecting pattern_count series.
+func parsePatternExpr(qs string) (parser.Expr, bool) {
+	expr, err := parser.ParseExpr(qs)
+	if err != nil {
+		return nil, false
+	}
+
+	selectors := 0
+	ok := true
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if vs, isSelector := node.(*parser.VectorSelector); isSelector {
+			selectors++
+			ok = ok && vs.Name == PatternMetricName
+		}
+		return nil
+	})
+	return expr, ok && selectors > 0
+}
+
+// PatternEvaluator evaluates the pattern rules against the pattern ingesters, and the other rules with the inner evaluator.
+type PatternEvaluator struct {
+	inner   Evaluator
+	engine  *promql.Engine
+	querier PatternQuerier
+}
+
+func NewPatternEvaluator(inner Evaluator, querier PatternQuerier, logger log.Logger) *PatternEvaluator {
+	return &PatternEvaluator{
+		inner: inner,
+		engine: promql.NewEngine(promql.EngineOpts{
+			Logger:     log.With(logger, "component", "pattern-rules-engine"),
+			MaxSamples: 1e6,
+			Timeout:    patternQueryTimeout,
+		}),
+		querier: querier,
+	}
+}
+
+func (e *PatternEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error) {
+	if _, ok := parsePatternExpr(qs); !ok {
+		return e.inner.Eval(ctx, qs, now)
+	}
+
+	q, err := e.engine.NewInstantQuery(ctx, &patternQueryable{querier: e.querier}, nil, qs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	switch v := res.Value.(type) {
+	case promql.Vector, promql.Scalar:
+		return &logqlmodel.Result{Data: v}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result type: %q", res.Value.Type())
+	}
+}
+
+func (e *PatternEvaluator) QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error) {
+	querier, ok := e.inner.(LogQuerier)
+	if !ok {
+		return nil, fmt.Errorf("evaluator %T does not support log queries", e.inner)
+	}
+	return querier.QueryLogs(ctx, qs, start, end, limit)
+}
+
+// patternQueryable exposes the patterns detected by the pattern ingesters as pattern_count series, labelled with
+// their pattern and the label values the stream selector of the query matches.
+type patternQueryable struct {
+	querier PatternQuerier
+}
+
+func (q *patternQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
+	return &patternStorageQuerier{querier: q.querier, mint: mint, maxt: maxt}, nil
+}
+
+type patternStorageQuerier struct {
+	querier    PatternQuerier
+	mint, maxt int64
+}
+
+func (q *patternStorageQuerier) Select(ctx context.Context, _ bool, _ *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	var (
+		streamMatchers  []*labels.Matcher
+		patternMatchers []*labels.Matcher
+	)
+	b := labels.NewBuilder(labels.FromStrings(labels.MetricName, PatternMetricName))
+	for _, m := range matchers {
+		switch m.Name {
+		case labels.MetricName:
+		case PatternLabel:
+			patternMatchers = append(patternMatchers, m)
+		default:
+			streamMatchers = append(streamMatchers, m)
+			if m.Type == labels.MatchEqual {
+				b.Set(m.Name, m.Value)
+			}
+		}
+	}
+	if len(streamMatchers) == 0 {
+		return storage.ErrSeriesSet(fmt.Errorf("the selectors of %s series require a stream selector", PatternMetricName))
+	}
+
+	resp, err := q.querier.Patterns(ctx, &logproto.QueryPatternsRequest{
+		Query: syntax.MatchersString(streamMatchers),
+		Start: time.UnixMilli(q.mint),
+		End:   time.UnixMilli(q.maxt),
+	})
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+
+	res := make([]storage.Series, 0, len(resp.Series))
+	for _, s := range resp.Series {
+		if !matchesAll(patternMatchers, s.Pattern) {
+			continue
+		}
+
+		samples := make([]model.SamplePair, 0, len(s.Samples))
+		for _, sample := range s.Samples {
+			samples = append(samples, model.SamplePair{Timestamp: sample.Timestamp, Value: model.SampleValue(sample.Value)})
+		}
+		res = append(res, series.NewConcreteSeries(b.Set(PatternLabel, s.Pattern).Labels(), samples))
+	}
+	return series.NewConcreteSeriesSet(res)
+}
+
+func matchesAll(matchers []*labels.Matcher, v string) bool {
+	for _, m := range matchers {
+		if !m.Matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *patternStorageQuerier) LabelValues(context.Context, string, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (q *patternStorageQuerier) LabelNames(context.Context, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (q *patternStorageQuerier) Close() error { return nil }
@@ -0,0 +1,143 @@
+package ruler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// fakePatternQuerier returns the samples of its patterns between the start and the end of the queries.
+type fakePatternQuerier struct {
+	queries  []string
+	patterns map[string][]*logproto.PatternSample
+}
+
+func (q *fakePatternQuerier) Patterns(_ context.Context, req *logproto.QueryPatternsRequest) (*logproto.QueryPatternsResponse, error) {
+	q.queries = append(q.queries, req.Query)
+
+	resp := &logproto.QueryPatternsResponse{}
+	for pattern, samples := range q.patterns {
+		s := &logproto.PatternSeries{Pattern: pattern}
+		for _, sample := range samples {
+			if sample.Timestamp.Time().Before(req.Start) || sample.Timestamp.Time().After(req.End) {
+				continue
+			}
+			s.Samples = append(s.Samples, sample)
+		}
+		resp.Series = append(resp.Series, s)
+	}
+	return resp, nil
+}
+
+type fakeEvaluator struct {
+	queries []string
+}
+
+func (e *fakeEvaluator) Eval(_ context.Context, qs string, _ time.Time) (*logqlmodel.Result, error) {
+	e.queries = append(e.queries, qs)
+	return &logqlmodel.Result{Data: promql.Vector{}}, nil
+}
+
+func TestParsePatternExpr(t *testing.T) {
+	for _, tc := range []struct {
+		expr      string
+		isPattern bool
+	}{
+		{expr: `sum_over_time(pattern_count{service_name="foo"}[5m]) > 10`, isPattern: true},
+		{expr: `count_over_time(pattern_count{service_name="foo"}[5m]) unless count_over_time(pattern_count{service_name="foo"}[1h] offset 5m)`, isPattern: true},
+		{expr: `sum(rate({service_name="foo"}[5m])) > 10`},
+		{expr: `sum(rate({service_name="foo"} |= "error" [5m])) > 10`},
+		{expr: `sum_over_time(pattern_count{service_name="foo"}[5m]) > on() up`},
+		{expr: `vector(1)`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, isPattern := parsePatternExpr(tc.expr)
+			require.Equal(t, tc.isPattern, isPattern)
+		})
+	}
+}
+
+func TestPatternEvaluator(t *testing.T) {
+	now := time.Unix(7200, 0)
+	samples := func(from, to time.Time, value int64) []*logproto.PatternSample {
+		var res []*logproto.PatternSample
+		for ts := from.Add(5 * time.Second); ts.Before(to); ts = ts.Add(10 * time.Second) {
+			res = append(res, &logproto.PatternSample{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: value})
+		}
+		return res
+	}
+
+	querier := &fakePatternQuerier{patterns: map[string][]*logproto.PatternSample{
+		// the frequency of the pattern rose 20x in the last 5m
+		"level=info msg=<_>": append(samples(now.Add(-time.Hour), now.Add(-5*time.Minute), 1), samples(now.Add(-5*time.Minute), now, 20)...),
+		// the pattern appeared in the last 5m
+		"level=error msg=<_>": samples(now.Add(-5*time.Minute), now, 1),
+	}}
+	inner := &fakeEvaluator{}
+	ev := NewPatternEvaluator(inner, querier, log.Logger)
+
+	for _, tc := range []struct {
+		name, expr, pattern string
+	}{
+		{
+			name:    "new pattern",
+			expr:    `count_over_time(pattern_count{service_name="foo"}[5m]) unless count_over_time(pattern_count{service_name="foo"}[1h] offset 5m)`,
+			pattern: "level=error msg=<_>",
+		},
+		{
+			name:    "pattern frequency rose 10x",
+			expr:    `sum_over_time(pattern_count{service_name="foo"}[5m]) > 10 * sum_over_time(pattern_count{service_name="foo"}[1h] offset 5m) / 12`,
+			pattern: "level=info msg=<_>",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := ev.Eval(context.Background(), tc.expr, now)
+			require.NoError(t, err)
+			vec := res.Data.(promql.Vector)
+			require.Len(t, vec, 1)
+			require.Equal(t, labels.FromStrings(PatternLabel, tc.pattern, "service_name", "foo"), vec[0].Metric)
+		})
+	}
+	require.Equal(t, `{service_name="foo"}`, querier.queries[0])
+
+	// the selectors of the pattern series match the patterns
+	res, err := ev.Eval(context.Background(), `count_over_time(pattern_count{service_name="foo", pattern=~"level=error.*"}[1h])`, now)
+	require.NoError(t, err)
+	require.Len(t, res.Data.(promql.Vector), 1)
+
+	// the pattern series require a stream selector
+	_, err = ev.Eval(context.Background(), `count_over_time(pattern_count[1h])`, now)
+	require.ErrorContains(t, err, "require a stream selector")
+
+	// the other rules are evaluated by the inner evaluator
+	_, err = ev.Eval(context.Background(), `sum(rate({service_name="foo"}[5m]))`, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{`sum(rate({service_name="foo"}[5m]))`}, inner.queries)
+}
+
+func TestPatternRuleValidation(t *testing.T) {
+	require.NoError(t, validateRuleNode(&rulefmt.RuleNode{
+		Alert: yaml.Node{Value: "NewPattern"},
+		Expr:  yaml.Node{Value: `count_over_time(pattern_count{service_name="foo"}[5m]) unless count_over_time(pattern_count{service_name="foo"}[1h] offset 5m)`},
+	}, "test"))
+
+	require.ErrorContains(t, validateRuleNode(&rulefmt.RuleNode{
+		Alert: yaml.Node{Value: "NotAPatternRule"},
+		Expr:  yaml.Node{Value: `count_over_time(up{service_name="foo"}[5m])`},
+	}, "test"), "could not parse expression for alert 'NotAPatternRule' in group 'test'")
+
+	expr, err := GroupLoader{}.Parse(`sum_over_time(pattern_count{service_name="foo"}[5m]) > 10`)
+	require.NoError(t, err)
+	require.Equal(t, `sum_over_time(pattern_count{service_name="foo"}[5m]) > 10`, expr.String())
+}
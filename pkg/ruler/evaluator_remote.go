@@ -48,9 +48,10 @@ const (
 	keepAlive        = time.Second * 10
 	keepAliveTimeout = time.Second * 5
 
-	serviceConfig     = `{"loadBalancingPolicy": "round_robin"}`
-	queryEndpointPath = "/loki/api/v1/query"
-	mimeTypeFormPost  = "application/x-www-form-urlencoded"
+	serviceConfig          = `{"loadBalancingPolicy": "round_robin"}`
+	queryEndpointPath      = "/loki/api/v1/query"
+	queryRangeEndpointPath = "/loki/api/v1/query_range"
+	mimeTypeFormPost       = "application/x-www-form-urlencoded"
 
 	EvalModeRemote = "remote"
 )
@@ -66,6 +67,26 @@ type metrics struct {
 
 	successfulEvals *prometheus.CounterVec
 	failedEvals     *prometheus.CounterVec
+
+	// the log queries retrieving the log lines of alerts are not rule evaluations
+	logQueries requestMetrics
+}
+
+// requestMetrics are the metrics of the requests sent to the query frontend.
+type requestMetrics struct {
+	duration   *prometheus.HistogramVec
+	size       *prometheus.HistogramVec
+	successful *prometheus.CounterVec
+	failed     *prometheus.CounterVec
+}
+
+func (m *metrics) evals() requestMetrics {
+	return requestMetrics{
+		duration:   m.reqDurationSecs,
+		size:       m.responseSizeBytes,
+		successful: m.successfulEvals,
+		failed:     m.failedEvals,
+	}
 }
 
 type RemoteEvaluator struct {
@@ -119,12 +140,45 @@ func newMetrics(registerer prometheus.Registerer) *metrics {
 		Name:      "failure_total",
 	}, []string{"reason", "user"})
 
+	logQueries := requestMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ruler_remote_eval",
+			Name:      "log_query_duration_seconds",
+			Help:      "Duration of the log queries retrieving the log lines attached to alerts.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 3, 9),
+		}, []string{"user"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ruler_remote_eval",
+			Name:      "log_query_response_bytes",
+			Help:      "Size of the responses of the log queries retrieving the log lines attached to alerts.",
+			Buckets:   prometheus.ExponentialBuckets(32, 4, 10),
+		}, []string{"user"}),
+		successful: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ruler_remote_eval",
+			Name:      "log_query_success_total",
+			Help:      "Total number of successful log queries retrieving the log lines attached to alerts.",
+		}, []string{"user"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ruler_remote_eval",
+			Name:      "log_query_failure_total",
+			Help:      "Total number of failed log queries retrieving the log lines attached to alerts.",
+		}, []string{"reason", "user"}),
+	}
+
 	registerer.MustRegister(
 		reqDurationSecs,
 		responseSizeBytes,
 		responseSizeSamples,
 		successfulEvals,
 		failedEvals,
+		logQueries.duration,
+		logQueries.size,
+		logQueries.successful,
+		logQueries.failed,
 	)
 
 	return &metrics{
@@ -134,6 +188,8 @@ func newMetrics(registerer prometheus.Registerer) *metrics {
 
 		successfulEvals: successfulEvals,
 		failedEvals:     failedEvals,
+
+		logQueries: logQueries,
 	}
 }
 
@@ -219,12 +275,61 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 	if !ts.IsZero() {
 		args.Set("time", ts.Format(time.RFC3339Nano))
 	}
+
+	resp, err := r.do(ctx, orgID, queryEndpointPath, args, r.metrics.evals(), log.With(logger, "instant", ts))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decodeResponse(ctx, resp, orgID)
+}
+
+// QueryLogs runs a log query through the query frontend.
+func (r *RemoteEvaluator) QueryLogs(ctx context.Context, qs string, start, end time.Time, limit uint32) (logqlmodel.Streams, error) {
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tenant ID from context: %w", err)
+	}
+
+	logger, ctx := spanlogger.NewWithLogger(ctx, r.logger, "ruler.remoteEvaluation.QueryLogs")
+	defer logger.Span.Finish()
+
+	args := make(url.Values)
+	args.Set("query", qs)
+	args.Set("direction", "backward")
+	args.Set("start", start.Format(time.RFC3339Nano))
+	args.Set("end", end.Format(time.RFC3339Nano))
+	args.Set("limit", strconv.FormatUint(uint64(limit), 10))
+
+	resp, err := r.do(ctx, orgID, queryRangeEndpointPath, args, r.metrics.logQueries, log.With(logger, "start", start, "end", end))
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded loghttp.QueryResponse
+	if err := json.NewDecoder(bytes.NewReader(resp.Body)).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("unexpected body encoding, not valid JSON: %w", err)
+	}
+	if decoded.Status != loghttp.QueryStatusSuccess {
+		return nil, fmt.Errorf("query response error: status %q", decoded.Status)
+	}
+
+	streams, ok := decoded.Data.Result.(loghttp.Streams)
+	if !ok {
+		return nil, fmt.Errorf("unsupported result type: %q, the query must be a log query", decoded.Data.ResultType)
+	}
+	return streams.ToProto(), nil
+}
+
+// do sends the query request to the query frontend and returns its successful response.
+func (r *RemoteEvaluator) do(ctx context.Context, orgID, path string, args url.Values, metrics requestMetrics, logger log.Logger) (*httpgrpc.HTTPResponse, error) {
+	query := args.Get("query")
 	body := []byte(args.Encode())
 	hash := util.HashedQuery(query)
 
 	req := httpgrpc.HTTPRequest{
 		Method: http.MethodPost,
-		Url:    queryEndpointPath,
+		Url:    path,
 		Body:   body,
 		Headers: []*httpgrpc.Header{
 			{Key: textproto.CanonicalMIMEHeaderKey("User-Agent"), Values: []string{userAgent}},
@@ -238,16 +343,16 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 	start := time.Now()
 	resp, err := r.client.Handle(ctx, &req)
 
-	instrument.ObserveWithExemplar(ctx, r.metrics.reqDurationSecs.WithLabelValues(orgID), time.Since(start).Seconds())
+	instrument.ObserveWithExemplar(ctx, metrics.duration.WithLabelValues(orgID), time.Since(start).Seconds())
 
 	if resp != nil {
-		instrument.ObserveWithExemplar(ctx, r.metrics.responseSizeBytes.WithLabelValues(orgID), float64(len(resp.Body)))
+		instrument.ObserveWithExemplar(ctx, metrics.size.WithLabelValues(orgID), float64(len(resp.Body)))
 	}
 
-	log := log.With(logger, "query_hash", hash, "query", query, "instant", ts, "response_time", time.Since(start).String())
+	log := log.With(logger, "query_hash", hash, "query", query, "response_time", time.Since(start).String())
 
 	if err != nil {
-		r.metrics.failedEvals.WithLabelValues("error", orgID).Inc()
+		metrics.failed.WithLabelValues("error", orgID).Inc()
 
 		level.Warn(log).Log("msg", "failed to evaluate rule", "err", err)
 		return nil, fmt.Errorf("rule evaluation failed: %w", err)
@@ -260,7 +365,7 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 	// TODO(dannyk): consider retrying if the rule has a very high interval, or the rule is very sensitive to missing samples
 	//   i.e. critical alerts or recording rules producing crucial RemoteEvaluatorMetrics series
 	if resp.Code/100 != 2 {
-		r.metrics.failedEvals.WithLabelValues("upstream_error", orgID).Inc()
+		metrics.failed.WithLabelValues("upstream_error", orgID).Inc()
 
 		respBod, _ := io.ReadAll(limitedBody)
 		level.Warn(log).Log("msg", "rule evaluation failed with non-2xx response", "response_code", resp.Code, "response_body", respBod)
@@ -269,16 +374,16 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 
 	maxSize := r.overrides.RulerRemoteEvaluationMaxResponseSize(orgID)
 	if maxSize > 0 && int64(len(fullBody)) >= maxSize {
-		r.metrics.failedEvals.WithLabelValues("max_size", orgID).Inc()
+		metrics.failed.WithLabelValues("max_size", orgID).Inc()
 
 		level.Error(log).Log("msg", "rule evaluation exceeded max size", "max_size", maxSize, "response_size", len(fullBody))
 		return nil, fmt.Errorf("%d bytes exceeds response size limit of %d (defined by ruler_remote_evaluation_max_response_size)", len(resp.Body), maxSize)
 	}
 
 	level.Debug(log).Log("msg", "rule evaluation succeeded")
-	r.metrics.successfulEvals.WithLabelValues(orgID).Inc()
+	metrics.successful.WithLabelValues(orgID).Inc()
 
-	return r.decodeResponse(ctx, resp, orgID)
+	return resp, nil
 }
 
 func (r *RemoteEvaluator) decodeResponse(ctx context.Context, resp *httpgrpc.HTTPResponse, orgID string) (*logqlmodel.Result, error) {
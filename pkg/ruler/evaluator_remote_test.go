@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/require"
@@ -416,3 +418,53 @@ func defaultLimitsTestConfig() validation.Limits {
 	flagext.DefaultValues(&limits)
 	return limits
 }
+
+func TestRemoteQueryLogs(t *testing.T) {
+	defaultLimits := defaultLimitsTestConfig()
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	var (
+		now  = time.Now()
+		args url.Values
+	)
+
+	cli := mockClient{
+		handleFn: func(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+			require.Equal(t, "/loki/api/v1/query_range", in.Url)
+			args, err = url.ParseQuery(string(in.Body))
+			require.NoError(t, err)
+
+			out := fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"foo"},"values":[["%d","error"]]}]}}`, now.UnixNano())
+
+			return &httpgrpc.HTTPResponse{
+				Code:    http.StatusOK,
+				Headers: nil,
+				Body:    []byte(out),
+			}, nil
+		},
+	}
+
+	ev, err := NewRemoteEvaluator(cli, limits, log.Logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ctx = user.InjectOrgID(ctx, "test")
+
+	streams, err := ev.QueryLogs(ctx, `{app="foo"} |= "error"`, now.Add(-time.Minute), now, 10)
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+	require.Equal(t, `{app="foo"}`, streams[0].Labels)
+	require.Len(t, streams[0].Entries, 1)
+	require.Equal(t, "error", streams[0].Entries[0].Line)
+	require.True(t, now.Equal(streams[0].Entries[0].Timestamp))
+
+	require.Equal(t, `{app="foo"} |= "error"`, args.Get("query"))
+	require.Equal(t, "backward", args.Get("direction"))
+	require.Equal(t, "10", args.Get("limit"))
+	require.Equal(t, now.Format(time.RFC3339Nano), args.Get("end"))
+
+	// log queries are not counted as rule evaluations
+	require.Equal(t, 1.0, testutil.ToFloat64(ev.metrics.logQueries.successful.WithLabelValues("test")))
+	require.Equal(t, 0.0, testutil.ToFloat64(ev.metrics.successfulEvals.WithLabelValues("test")))
+}
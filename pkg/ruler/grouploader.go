@@ -17,6 +17,10 @@ import (
 type GroupLoader struct{}
 
 func (GroupLoader) Parse(query string) (parser.Expr, error) {
+	if expr, ok := parsePatternExpr(query); ok {
+		return expr, nil
+	}
+
 	expr, err := syntax.ParseExpr(query)
 	if err != nil {
 		return nil, err
@@ -72,15 +72,18 @@ type MemStore struct {
 	mgr       RuleIter
 	logger    log.Logger
 	rules     map[string]*RuleCache
+	// alertState is the persisted state of the alerts, if any
+	alertState *AlertStateStore
 
 	initiated       chan struct{}
 	done            chan struct{}
 	cleanupInterval time.Duration
 }
 
-func NewMemStore(userID string, queryFunc rules.QueryFunc, metrics *memstoreMetrics, cleanupInterval time.Duration, logger log.Logger) *MemStore {
+func NewMemStore(userID string, queryFunc rules.QueryFunc, metrics *memstoreMetrics, cleanupInterval time.Duration, alertState *AlertStateStore, logger log.Logger) *MemStore {
 	s := &MemStore{
 		userID:          userID,
+		alertState:      alertState,
 		metrics:         metrics,
 		queryFunc:       queryFunc,
 		logger:          log.With(logger, "subcomponent", "MemStore", "user", userID),
@@ -167,10 +170,11 @@ func (m *MemStore) run() {
 
 // implement storage.Queryable. It is only called with the desired ts as maxtime. Mint is
 // parameterized via the outage tolerance, but since we're synthetically generating these,
-// we only care about the desired time.
-func (m *MemStore) Querier(_, maxt int64) (storage.Querier, error) {
+// we only care about the desired time, and mint only bounds the persisted alert states.
+func (m *MemStore) Querier(mint, maxt int64) (storage.Querier, error) {
 	<-m.initiated
 	return &memStoreQuerier{
+		mint:     util.TimeFromMillis(mint),
 		ts:       util.TimeFromMillis(maxt),
 		MemStore: m,
 	}, nil
@@ -178,7 +182,7 @@ func (m *MemStore) Querier(_, maxt int64) (storage.Querier, error) {
 }
 
 type memStoreQuerier struct {
-	ts time.Time
+	mint, ts time.Time
 	*MemStore
 }
 
@@ -208,6 +212,23 @@ func (m *memStoreQuerier) Select(ctx context.Context, _ bool, _ *storage.SelectH
 		return storage.NoopSeriesSet()
 	}
 
+	// Restore the time the alert became active from its persisted state, as if it was active until now.
+	if m.alertState != nil {
+		activeAt, ok, err := m.alertState.activeAt(ctx, m.userID, ls, m.mint)
+		if err != nil {
+			level.Warn(m.logger).Log("msg", "failed to load the state of alerts, restoring for state via evaluation", "rule", ruleKey, "err", err)
+		} else if ok {
+			m.alertState.metrics.restored.Inc()
+			return series.NewConcreteSeriesSet(
+				[]storage.Series{
+					series.NewConcreteSeries(ls, []model.SamplePair{
+						{Timestamp: model.Time(util.TimeToMillis(m.ts)), Value: model.SampleValue(activeAt.Unix())},
+					}),
+				},
+			)
+		}
+	}
+
 	level.Debug(m.logger).Log("msg", "restoring for state via evaluation", "rule", ruleKey)
 
 	m.mtx.Lock()
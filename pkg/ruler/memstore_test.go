@@ -32,7 +32,7 @@ type MockRuleIter []rulefmt.Rule
 func (xs MockRuleIter) AlertingRules() []rulefmt.Rule { return xs }
 
 func testStore(queryFunc rules.QueryFunc) *MemStore {
-	return NewMemStore("test", queryFunc, newMemstoreMetrics(nil), time.Minute, log.NewNopLogger())
+	return NewMemStore("test", queryFunc, newMemstoreMetrics(nil), time.Minute, nil, log.NewNopLogger())
 
 }
 
@@ -8,9 +8,10 @@ import (
 
 	ruler "github.com/grafana/loki/v3/pkg/ruler/base"
 	"github.com/grafana/loki/v3/pkg/ruler/rulestore"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 )
 
-func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, limits RulesLimits, metricsNamespace string) (*ruler.Ruler, error) {
+func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, alertStateClient client.ObjectClient, budgets *EvaluationBudgets, limits RulesLimits, metricsNamespace string) (*ruler.Ruler, error) {
 	// For backward compatibility, client and clients are defined in the remote_write config.
 	// When both are present, an error is thrown.
 	if len(cfg.RemoteWrite.Clients) > 0 && cfg.RemoteWrite.Client != nil {
@@ -25,9 +26,22 @@ func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger
 		cfg.RemoteWrite.Clients["default"] = *cfg.RemoteWrite.Client
 	}
 
+	var alertState *AlertStateStore
+	if alertStateClient != nil {
+		alertState = NewAlertStateStore(alertStateClient, cfg.OutageTolerance, cfg.ForGracePeriod, reg, logger)
+	}
+
+	var alertLogLines *AlertLogLines
+	if querier, ok := evaluator.(LogQuerier); ok {
+		var err error
+		if alertLogLines, err = NewAlertLogLines(cfg.AlertLogLines, querier, reg, logger); err != nil {
+			return nil, err
+		}
+	}
+
 	mgr, err := ruler.NewDefaultMultiTenantManager(
 		cfg.Config,
-		MultiTenantRuleManager(cfg, evaluator, limits, logger, reg),
+		MultiTenantRuleManager(cfg, evaluator, limits, alertState, alertLogLines, budgets, logger, reg),
 		reg,
 		logger,
 		limits,
@@ -36,7 +50,7 @@ func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger
 	if err != nil {
 		return nil, err
 	}
-	return ruler.NewRuler(
+	r, err := ruler.NewRuler(
 		cfg.Config,
 		MultiTenantManagerAdapter(mgr),
 		reg,
@@ -45,4 +59,11 @@ func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger
 		limits,
 		metricsNamespace,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if budgets != nil {
+		budgets.shards = r.TenantShardCount
+	}
+	return r, nil
 }
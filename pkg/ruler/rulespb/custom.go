@@ -1,6 +1,16 @@
 package rulespb
 
-import "github.com/prometheus/prometheus/model/rulefmt"
+import (
+	"errors"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+)
+
+// The errors of the rule stores, defined here to be returned by the stores the rulestore package depends on.
+var (
+	ErrGroupNotFound          = errors.New("group does not exist")
+	ErrGroupNamespaceNotFound = errors.New("group namespace does not exist")
+)
 
 // RuleGroupList contains a set of rule groups
 type RuleGroupList []*RuleGroupDesc
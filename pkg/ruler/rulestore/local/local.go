@@ -5,35 +5,58 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/rulefmt"
 	promRules "github.com/prometheus/prometheus/rules"
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/loki/v3/pkg/ruler/rulespb"
 )
 
 const (
 	Name = "local"
+
+	// lockFileName is the name of the file locked by the rulers writing to the directory. It is hidden,
+	// so that it is not listed as a user.
+	lockFileName = ".lock"
+	// tempFileInfix follows the namespace in the names of the hidden temporary files the rule files are written to.
+	tempFileInfix = ".tmp"
 )
 
 type Config struct {
-	Directory string `yaml:"directory"`
+	Directory    string `yaml:"directory"`
+	Watch        bool   `yaml:"watch"`
+	EnableWrites bool   `yaml:"enable_writes"`
 }
 
 // RegisterFlags registers flags.
 func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.StringVar(&cfg.Directory, prefix+"local.directory", "", "Directory to scan for rules")
+	f.BoolVar(&cfg.Watch, prefix+"local.watch", false, "Watch the directory for changes of the rule files, to reload the rules as soon as they change instead of at the next poll.")
+	f.BoolVar(&cfg.EnableWrites, prefix+"local.enable-writes", false, "Allow the ruler API to create and delete rule groups and namespaces, which are written to the rule files of the directory. The writes of the rulers sharing the directory are serialized by locking its .lock file, which requires a file system supporting file locks. The rule files that are symlinks, like the ones of mounted Kubernetes ConfigMaps, are never modified.")
 }
 
 // Client expects to load already existing rules located at:
 //
 //	cfg.Directory / userID / namespace
+//
+// Files and directories whose name starts with a dot are ignored.
 type Client struct {
 	cfg    Config
 	loader promRules.GroupLoader
+	logger log.Logger
+
+	// writeMtx serializes the read-modify-write cycles of the rule files within the ruler,
+	// and the lock file between the rulers sharing the directory.
+	writeMtx sync.Mutex
 }
 
-func NewLocalRulesClient(cfg Config, loader promRules.GroupLoader) (*Client, error) {
+func NewLocalRulesClient(cfg Config, loader promRules.GroupLoader, logger log.Logger) (*Client, error) {
 	if cfg.Directory == "" {
 		return nil, errors.New("directory required for local rules config")
 	}
@@ -41,6 +64,7 @@ func NewLocalRulesClient(cfg Config, loader promRules.GroupLoader) (*Client, err
 	return &Client{
 		cfg:    cfg,
 		loader: loader,
+		logger: logger,
 	}, nil
 }
 
@@ -55,6 +79,9 @@ func (l *Client) ListAllUsers(_ context.Context) ([]string, error) {
 	for _, entry := range dirEntries {
 		// After resolving link, entry.Name() may be different than user, so keep original name.
 		user := entry.Name()
+		if isHidden(user) {
+			continue
+		}
 
 		var isDir bool
 
@@ -113,23 +140,252 @@ func (l *Client) LoadRuleGroups(_ context.Context, _ map[string]rulespb.RuleGrou
 }
 
 // GetRuleGroup implements RuleStore
-func (l *Client) GetRuleGroup(_ context.Context, _, _, _ string) (*rulespb.RuleGroupDesc, error) {
-	return nil, errors.New("GetRuleGroup unsupported in rule local store")
+func (l *Client) GetRuleGroup(_ context.Context, userID, namespace, group string) (*rulespb.RuleGroupDesc, error) {
+	if err := validateNames(userID, namespace); err != nil {
+		return nil, err
+	}
+
+	groups, err := l.loadRuleFile(userID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.Name == group {
+			return rulespb.ToProto(userID, namespace, g), nil
+		}
+	}
+	return nil, rulespb.ErrGroupNotFound
 }
 
 // SetRuleGroup implements RuleStore
-func (l *Client) SetRuleGroup(_ context.Context, _, _ string, _ *rulespb.RuleGroupDesc) error {
-	return errors.New("SetRuleGroup unsupported in rule local store")
+func (l *Client) SetRuleGroup(_ context.Context, userID, namespace string, group *rulespb.RuleGroupDesc) error {
+	if err := l.checkWritable(userID, namespace); err != nil {
+		return err
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	groups, err := l.loadRuleFile(userID, namespace)
+	if err != nil && err != rulespb.ErrGroupNotFound {
+		return err
+	}
+
+	rg := rulespb.FromProto(group)
+	replaced := false
+	for i, g := range groups {
+		if g.Name == rg.Name {
+			groups[i] = rg
+			replaced = true
+		}
+	}
+	if !replaced {
+		groups = append(groups, rg)
+	}
+
+	return l.writeRuleFile(userID, namespace, groups)
 }
 
 // DeleteRuleGroup implements RuleStore
-func (l *Client) DeleteRuleGroup(_ context.Context, _, _ string, _ string) error {
-	return errors.New("DeleteRuleGroup unsupported in rule local store")
+func (l *Client) DeleteRuleGroup(_ context.Context, userID, namespace string, group string) error {
+	if err := l.checkWritable(userID, namespace); err != nil {
+		return err
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	groups, err := l.loadRuleFile(userID, namespace)
+	if err != nil {
+		return err
+	}
+
+	remaining := groups[:0]
+	for _, g := range groups {
+		if g.Name != group {
+			remaining = append(remaining, g)
+		}
+	}
+	if len(remaining) == len(groups) {
+		return rulespb.ErrGroupNotFound
+	}
+
+	if len(remaining) == 0 {
+		return os.Remove(filepath.Join(l.cfg.Directory, userID, namespace))
+	}
+	return l.writeRuleFile(userID, namespace, remaining)
 }
 
 // DeleteNamespace implements RulerStore
-func (l *Client) DeleteNamespace(_ context.Context, _, _ string) error {
-	return errors.New("DeleteNamespace unsupported in rule local store")
+func (l *Client) DeleteNamespace(ctx context.Context, userID, namespace string) error {
+	if namespace == "" {
+		return l.deleteAllNamespaces(ctx, userID)
+	}
+
+	if err := l.checkWritable(userID, namespace); err != nil {
+		return err
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = os.Remove(filepath.Join(l.cfg.Directory, userID, namespace))
+	if os.IsNotExist(err) {
+		return rulespb.ErrGroupNamespaceNotFound
+	}
+	return err
+}
+
+func (l *Client) deleteAllNamespaces(ctx context.Context, userID string) error {
+	list, err := l.loadAllRulesGroupsForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return rulespb.ErrGroupNamespaceNotFound
+	}
+
+	namespaces := make(map[string]struct{})
+	for _, rg := range list {
+		namespaces[rg.Namespace] = struct{}{}
+	}
+	for namespace := range namespaces {
+		if err := l.DeleteNamespace(ctx, userID, namespace); err != nil && err != rulespb.ErrGroupNamespaceNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock serializes the read-modify-write cycles of the rule files, and returns the function releasing the lock.
+func (l *Client) lock() (func(), error) {
+	l.writeMtx.Lock()
+
+	f, err := os.OpenFile(filepath.Join(l.cfg.Directory, lockFileName), os.O_RDWR|os.O_CREATE, 0o640)
+	if err != nil {
+		l.writeMtx.Unlock()
+		return nil, errors.Wrap(err, "unable to open rule dir lock file")
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		l.writeMtx.Unlock()
+		return nil, errors.Wrap(err, "unable to lock rule dir lock file")
+	}
+
+	return func() {
+		if err := unlockFile(f); err != nil {
+			level.Warn(l.logger).Log("msg", "unable to unlock rule dir lock file", "err", err)
+		}
+		_ = f.Close()
+		l.writeMtx.Unlock()
+	}, nil
+}
+
+// checkWritable returns an error if the rule file of the namespace cannot be modified by the ruler API.
+func (l *Client) checkWritable(userID, namespace string) error {
+	if !l.cfg.EnableWrites {
+		return errors.New("writes are disabled in rule local store")
+	}
+	if err := validateNames(userID, namespace); err != nil {
+		return err
+	}
+
+	fi, err := os.Lstat(filepath.Join(l.cfg.Directory, userID, namespace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return errors.Errorf("rule file of namespace %s of user %s is a symlink, which is managed outside of the ruler API", namespace, userID)
+	}
+	return nil
+}
+
+// loadRuleFile loads the rule groups of a namespace, or returns rulespb.ErrGroupNotFound if the namespace has no rule file.
+func (l *Client) loadRuleFile(userID, namespace string) ([]rulefmt.RuleGroup, error) {
+	filename := filepath.Join(l.cfg.Directory, userID, namespace)
+	if _, err := os.Stat(filename); err != nil {
+		if os.IsNotExist(err) {
+			return nil, rulespb.ErrGroupNotFound
+		}
+		return nil, err
+	}
+
+	rulegroups, allErrors := l.loader.Load(filename)
+	if len(allErrors) > 0 {
+		return nil, errors.Wrapf(allErrors[0], "error parsing %s", filename)
+	}
+	return rulegroups.Groups, nil
+}
+
+// writeRuleFile atomically replaces the rule file of a namespace, so that it is never read partially written.
+func (l *Client) writeRuleFile(userID, namespace string, groups []rulefmt.RuleGroup) error {
+	b, err := yaml.Marshal(rulefmt.RuleGroups{Groups: groups})
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(l.cfg.Directory, userID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	// the temporary file is hidden, to be ignored when listing the rules
+	tmp, err := os.CreateTemp(dir, "."+namespace+tempFileInfix)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, namespace)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// validateNames returns an error if the user or the namespace cannot be used as a file name in the directory.
+func validateNames(userID, namespace string) error {
+	for _, name := range []string{userID, namespace} {
+		if name == "" || isHidden(name) || strings.ContainsAny(name, `/\`) {
+			return errors.Errorf("invalid name %q in rule local store", name)
+		}
+	}
+	return nil
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// isTempFile returns whether the file is a temporary rule file written by the ruler. The namespaces are not hidden,
+// unlike the files of the mounted Kubernetes ConfigMaps, like '..data', starting with two dots.
+func isTempFile(name string) bool {
+	return isHidden(name) && !strings.HasPrefix(name, "..") && strings.Contains(name, tempFileInfix)
 }
 
 func (l *Client) loadAllRulesGroupsForUser(ctx context.Context, userID string) (rulespb.RuleGroupList, error) {
@@ -137,6 +393,9 @@ func (l *Client) loadAllRulesGroupsForUser(ctx context.Context, userID string) (
 
 	root := filepath.Join(l.cfg.Directory, userID)
 	dirEntries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to read rule dir %s", root)
 	}
@@ -144,6 +403,9 @@ func (l *Client) loadAllRulesGroupsForUser(ctx context.Context, userID string) (
 	for _, entry := range dirEntries {
 		// After resolving link, entry.Name() may be different than namespace, so keep original name.
 		namespace := entry.Name()
+		if isHidden(namespace) {
+			continue
+		}
 
 		var isDir bool
 
@@ -2,14 +2,18 @@ package local
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/rulefmt"
 	promRules "github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -66,7 +70,7 @@ func TestClient_LoadAllRuleGroups(t *testing.T) {
 
 	client, err := NewLocalRulesClient(Config{
 		Directory: dir,
-	}, promRules.FileLoader{})
+	}, promRules.FileLoader{}, log.NewNopLogger())
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -83,3 +87,188 @@ func TestClient_LoadAllRuleGroups(t *testing.T) {
 		require.Equal(t, rulespb.ToProto(u, namespace2, ruleGroups.Groups[0]), actual[1])
 	}
 }
+
+func TestClient_WriteRuleGroups(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	group := func(name, expr string) *rulespb.RuleGroupDesc {
+		return rulespb.ToProto("user", "ns", rulefmt.RuleGroup{
+			Name:     name,
+			Interval: model.Duration(time.Minute),
+			Rules: []rulefmt.RuleNode{
+				{
+					Record: yaml.Node{Kind: yaml.ScalarNode, Value: "test_rule"},
+					Expr:   yaml.Node{Kind: yaml.ScalarNode, Value: expr},
+				},
+			},
+		})
+	}
+
+	client, err := NewLocalRulesClient(Config{Directory: dir}, promRules.FileLoader{}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.ErrorContains(t, client.SetRuleGroup(ctx, "user", "ns", group("first", "up")), "writes are disabled")
+
+	client, err = NewLocalRulesClient(Config{Directory: dir, EnableWrites: true}, promRules.FileLoader{}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// groups are created and replaced in the rule file of the namespace
+	require.NoError(t, client.SetRuleGroup(ctx, "user", "ns", group("first", "up")))
+	require.NoError(t, client.SetRuleGroup(ctx, "user", "ns", group("second", "up")))
+	require.NoError(t, client.SetRuleGroup(ctx, "user", "ns", group("first", "down")))
+
+	list, err := client.ListRuleGroupsForUserAndNamespace(ctx, "user", "")
+	require.NoError(t, err)
+	require.Equal(t, rulespb.RuleGroupList{group("first", "down"), group("second", "up")}, list)
+
+	rg, err := client.GetRuleGroup(ctx, "user", "ns", "second")
+	require.NoError(t, err)
+	require.Equal(t, group("second", "up"), rg)
+
+	_, err = client.GetRuleGroup(ctx, "user", "ns", "third")
+	require.Equal(t, rulespb.ErrGroupNotFound, err)
+
+	// the temporary files are removed
+	entries, err := os.ReadDir(path.Join(dir, "user"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// the rule file is removed with its last group
+	require.NoError(t, client.DeleteRuleGroup(ctx, "user", "ns", "first"))
+	require.Equal(t, rulespb.ErrGroupNotFound, client.DeleteRuleGroup(ctx, "user", "ns", "first"))
+	require.NoError(t, client.DeleteRuleGroup(ctx, "user", "ns", "second"))
+	require.NoFileExists(t, path.Join(dir, "user", "ns"))
+
+	require.NoError(t, client.SetRuleGroup(ctx, "user", "ns", group("first", "up")))
+	require.NoError(t, client.DeleteNamespace(ctx, "user", "ns"))
+	require.Equal(t, rulespb.ErrGroupNamespaceNotFound, client.DeleteNamespace(ctx, "user", "ns"))
+
+	// the rule files that are symlinks are never modified
+	require.NoError(t, client.SetRuleGroup(ctx, "user", "ns", group("first", "up")))
+	require.NoError(t, os.Symlink("ns", path.Join(dir, "user", "linked")))
+	require.ErrorContains(t, client.SetRuleGroup(ctx, "user", "linked", group("first", "up")), "symlink")
+	require.ErrorContains(t, client.DeleteNamespace(ctx, "user", "linked"), "symlink")
+
+	// the names must be valid file names
+	require.ErrorContains(t, client.SetRuleGroup(ctx, "user", "../ns", group("first", "up")), "invalid name")
+	require.ErrorContains(t, client.SetRuleGroup(ctx, "user", ".ns", group("first", "up")), "invalid name")
+}
+
+func TestClient_ConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// the rulers sharing the directory don't overwrite the groups written by each other
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		client, err := NewLocalRulesClient(Config{Directory: dir, EnableWrites: true}, promRules.FileLoader{}, log.NewNopLogger())
+		require.NoError(t, err)
+		clients = append(clients, client)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			group := rulespb.ToProto("user", "ns", rulefmt.RuleGroup{
+				Name:     fmt.Sprintf("group-%d", i),
+				Interval: model.Duration(time.Minute),
+				Rules: []rulefmt.RuleNode{
+					{
+						Record: yaml.Node{Kind: yaml.ScalarNode, Value: "test_rule"},
+						Expr:   yaml.Node{Kind: yaml.ScalarNode, Value: "up"},
+					},
+				},
+			})
+			assert.NoError(t, clients[i%len(clients)].SetRuleGroup(ctx, "user", "ns", group))
+		}(i)
+	}
+	wg.Wait()
+
+	list, err := clients[0].ListRuleGroupsForUserAndNamespace(ctx, "user", "ns")
+	require.NoError(t, err)
+	require.Len(t, list, 20)
+
+	// the lock file is not listed as a user
+	users, err := clients[0].ListAllUsers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user"}, users)
+}
+
+func TestClient_Watch(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := NewLocalRulesClient(Config{Directory: dir}, promRules.FileLoader{}, log.NewNopLogger())
+	require.NoError(t, err)
+	changes, err := client.Watch(ctx)
+	require.NoError(t, err)
+	require.Nil(t, changes)
+
+	client, err = NewLocalRulesClient(Config{Directory: dir, Watch: true}, promRules.FileLoader{}, log.NewNopLogger())
+	require.NoError(t, err)
+	changes, err = client.Watch(ctx)
+	require.NoError(t, err)
+
+	b, err := yaml.Marshal(rulefmt.RuleGroups{})
+	require.NoError(t, err)
+
+	// the changes of the new users are watched too
+	require.NoError(t, os.Mkdir(path.Join(dir, "user"), 0o777))
+	requireChange(t, changes)
+	require.NoError(t, os.WriteFile(path.Join(dir, "user", "ns"), b, 0o666))
+	requireChange(t, changes)
+	require.NoError(t, os.Remove(path.Join(dir, "user", "ns")))
+	requireChange(t, changes)
+}
+
+func TestClient_WatchConfigMap(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	writeRules := func(version, record string) {
+		b, err := yaml.Marshal(rulefmt.RuleGroups{Groups: []rulefmt.RuleGroup{{
+			Name:  "group",
+			Rules: []rulefmt.RuleNode{{Record: yaml.Node{Kind: yaml.ScalarNode, Value: record}, Expr: yaml.Node{Kind: yaml.ScalarNode, Value: `sum(rate({app="foo"}[1m]))`}}},
+		}}})
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(path.Join(dir, "user", version), 0o777))
+		require.NoError(t, os.WriteFile(path.Join(dir, "user", version, "ns"), b, 0o666))
+	}
+
+	// the layout of a ConfigMap mounted by Kubernetes: the rule files are symlinks to the '..data' symlink
+	writeRules("..v1", "first")
+	require.NoError(t, os.Symlink("..v1", path.Join(dir, "user", "..data")))
+	require.NoError(t, os.Symlink(path.Join("..data", "ns"), path.Join(dir, "user", "ns")))
+	writeRules("..v2", "second")
+
+	client, err := NewLocalRulesClient(Config{Directory: dir, Watch: true}, promRules.FileLoader{}, log.NewNopLogger())
+	require.NoError(t, err)
+	changes, err := client.Watch(ctx)
+	require.NoError(t, err)
+
+	groups, err := client.ListRuleGroupsForUserAndNamespace(ctx, "user", "ns")
+	require.NoError(t, err)
+	require.Equal(t, "first", groups[0].Rules[0].Record)
+
+	// the ConfigMap is updated by swapping the '..data' symlink
+	require.NoError(t, os.Symlink("..v2", path.Join(dir, "user", "..data_tmp")))
+	require.NoError(t, os.Rename(path.Join(dir, "user", "..data_tmp"), path.Join(dir, "user", "..data")))
+	requireChange(t, changes)
+
+	groups, err = client.ListRuleGroupsForUserAndNamespace(ctx, "user", "ns")
+	require.NoError(t, err)
+	require.Equal(t, "second", groups[0].Rules[0].Record)
+}
+
+func requireChange(t *testing.T, changes <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not notified")
+	}
+}
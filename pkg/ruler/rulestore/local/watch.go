@@ -0,0 +1,93 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// watchDebounce is how long the watcher waits for the changes of the directory to settle before notifying them,
+// as replacing a file, or the update of a mounted Kubernetes ConfigMap, emits several events. The rule files of a
+// ConfigMap are symlinks to its '..data' symlink, only the swap of '..data' emits events when it is updated.
+const watchDebounce = time.Second
+
+// Watch implements rulestore.Watcher. It watches the directory and the directories of the users, and notifies
+// their changes until the context is done. It returns a nil channel if the watch is disabled.
+func (l *Client) Watch(ctx context.Context) (<-chan struct{}, error) {
+	if !l.cfg.Watch {
+		return nil, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create rule dir watcher")
+	}
+
+	users, err := l.ListAllUsers(ctx)
+	if err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	for _, dir := range append([]string{""}, users...) {
+		if err := watcher.Add(filepath.Join(l.cfg.Directory, dir)); err != nil {
+			_ = watcher.Close()
+			return nil, errors.Wrapf(err, "unable to watch rule dir %s", filepath.Join(l.cfg.Directory, dir))
+		}
+	}
+
+	changes := make(chan struct{}, 1)
+	go l.watch(ctx, watcher, changes)
+	return changes, nil
+}
+
+func (l *Client) watch(ctx context.Context, watcher *fsnotify.Watcher, changes chan<- struct{}) {
+	defer watcher.Close()
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// the lock file and the temporary rule files are written by the rulers and are not rule files
+			if name := filepath.Base(event.Name); event.Has(fsnotify.Chmod) || name == lockFileName || isTempFile(name) {
+				continue
+			}
+
+			// watch the directories of the new users
+			if event.Has(fsnotify.Create) && filepath.Dir(event.Name) == filepath.Clean(l.cfg.Directory) && !isHidden(filepath.Base(event.Name)) {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					if err := watcher.Add(event.Name); err != nil {
+						level.Warn(l.logger).Log("msg", "unable to watch rule dir", "dir", event.Name, "err", err)
+					}
+				}
+			}
+			debounce.Reset(watchDebounce)
+
+		case <-debounce.C:
+			select {
+			case changes <- struct{}{}:
+			default:
+				// a notification is already pending
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			level.Warn(l.logger).Log("msg", "error watching rule dir", "dir", l.cfg.Directory, "err", err)
+		}
+	}
+}
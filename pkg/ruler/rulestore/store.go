@@ -9,9 +9,9 @@ import (
 
 var (
 	// ErrGroupNotFound is returned if a rule group does not exist
-	ErrGroupNotFound = errors.New("group does not exist")
+	ErrGroupNotFound = rulespb.ErrGroupNotFound
 	// ErrGroupNamespaceNotFound is returned if a namespace does not exist
-	ErrGroupNamespaceNotFound = errors.New("group namespace does not exist")
+	ErrGroupNamespaceNotFound = rulespb.ErrGroupNamespaceNotFound
 	// ErrUserNotFound is returned if the user does not currently exist
 	ErrUserNotFound = errors.New("no rule groups found for user")
 )
@@ -45,3 +45,11 @@ type RuleStore interface {
 	// If namespace is empty, deletes all rule groups for user.
 	DeleteNamespace(ctx context.Context, userID, namespace string) error
 }
+
+// Watcher is implemented by the rule stores able to notify the changes of their rule groups, for the ruler to sync
+// them without waiting for the next poll.
+type Watcher interface {
+	// Watch notifies the changes of the rule groups on the returned channel until the context is done.
+	// A nil channel is returned if the store does not watch its rule groups.
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
@@ -0,0 +1,412 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	// diskCacheHeaderSize is the size of the fixed header of every file in the
+	// disk cache: the CRC32 (Castagnoli) of the rest of the file followed by
+	// the length of the key.
+	diskCacheHeaderSize = 8
+	diskCacheTmpSuffix  = ".tmp"
+
+	corruptedReason = "corrupted"
+)
+
+var diskCacheCastagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// DiskCacheConfig represents the config of a cache that keeps its entries on local disk.
+type DiskCacheConfig struct {
+	Enabled   bool   `yaml:"enabled,omitempty"`
+	Directory string `yaml:"directory"`
+	MaxSizeMB int64  `yaml:"max_size_mb"`
+}
+
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, description+"Whether the local disk cache is enabled.")
+	f.StringVar(&cfg.Directory, prefix+"directory", "", description+"Directory to store the cache entries in. Must not be shared with other caches.")
+	f.Int64Var(&cfg.MaxSizeMB, prefix+"max-size-mb", 1024, description+"Maximum size of the cache on disk in MB. Least recently used entries are evicted when the limit is reached.")
+}
+
+func (cfg *DiskCacheConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+func (cfg *DiskCacheConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Directory == "" {
+		return errors.New("disk cache directory must be set")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return errors.New("disk cache max_size_mb must be greater than 0")
+	}
+	return nil
+}
+
+type diskCacheEntry struct {
+	key     string
+	size    uint64
+	updated time.Time
+}
+
+// DiskCache is a cache that stores each entry as a file on local disk and
+// evicts the least recently used entries once the configured size is reached.
+//
+// Every file carries a checksum of its contents, which is verified when the
+// entry is read back; corrupted entries are dropped and reported as misses.
+// The index of the cache is kept in memory and rebuilt from the files in the
+// directory on start-up, so that the cache survives restarts.
+type DiskCache struct {
+	name      string
+	dir       string
+	cacheType stats.CacheType
+	logger    log.Logger
+
+	lock          sync.Mutex
+	maxSizeBytes  uint64
+	currSizeBytes uint64
+	entries       map[string]*list.Element
+	lru           *list.List
+
+	entriesAddedNew prometheus.Counter
+	entriesEvicted  *prometheus.CounterVec
+	entriesCurrent  prometheus.Gauge
+	diskBytes       prometheus.Gauge
+}
+
+// NewDiskCache returns a new DiskCache and loads the entries already present in its directory.
+func NewDiskCache(name string, cfg DiskCacheConfig, reg prometheus.Registerer, logger log.Logger, cacheType stats.CacheType) (*DiskCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o750); err != nil {
+		return nil, errors.Wrap(err, "creating disk cache directory")
+	}
+
+	c := &DiskCache{
+		name:      name,
+		dir:       cfg.Directory,
+		cacheType: cacheType,
+		logger:    log.With(logger, "cache", name),
+
+		maxSizeBytes: uint64(cfg.MaxSizeMB * 1e6),
+		entries:      make(map[string]*list.Element),
+		lru:          list.New(),
+
+		entriesAddedNew: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "added_new_total",
+			Help:        "The total number of new entries added to the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		entriesEvicted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "evicted_total",
+			Help:        "The total number of evicted entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, []string{"reason"}),
+
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "entries",
+			Help:        "Current number of entries in the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		diskBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "disk_bytes",
+			Help:        "The current cache size on disk in bytes",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load rebuilds the index of the cache from the files in its directory.
+// Entries are ordered by their modification time, which is updated on every
+// hit, so the least recently used entries are still evicted first.
+func (c *DiskCache) load() error {
+	var entries []*diskCacheEntry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(path, diskCacheTmpSuffix) {
+			// Left over from an interrupted write.
+			return os.Remove(path)
+		}
+
+		entry, err := readDiskCacheEntry(path)
+		if err != nil || c.path(entry.key) != path {
+			level.Warn(c.logger).Log("msg", "removing invalid disk cache file", "path", path, "err", err)
+			return os.Remove(path)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "loading disk cache")
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].updated.Before(entries[j].updated)
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, entry := range entries {
+		c.entries[entry.key] = c.lru.PushFront(entry)
+		c.currSizeBytes += entry.size
+		c.entriesCurrent.Inc()
+	}
+	c.evict(0)
+	c.diskBytes.Set(float64(c.currSizeBytes))
+
+	level.Info(c.logger).Log("msg", "loaded disk cache", "entries", len(c.entries), "bytes", c.currSizeBytes)
+	return nil
+}
+
+// readDiskCacheEntry reads the key and size of the entry stored in the file at path.
+func readDiskCacheEntry(path string) (*diskCacheEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, diskCacheHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, err
+	}
+	keyLen := int64(binary.BigEndian.Uint32(header[4:]))
+	if diskCacheHeaderSize+keyLen > info.Size() {
+		return nil, errors.New("truncated disk cache file")
+	}
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(f, key); err != nil {
+		return nil, err
+	}
+
+	return &diskCacheEntry{
+		key:     string(key),
+		size:    uint64(info.Size()),
+		updated: info.ModTime(),
+	}, nil
+}
+
+// path returns the location of the file of the given key. Files are spread
+// across sub-directories to keep the directories reasonably small.
+func (c *DiskCache) path(key string) string {
+	var sum [8]byte
+	binary.BigEndian.PutUint64(sum[:], xxhash.Sum64String(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// Store implements Cache.
+func (c *DiskCache) Store(_ context.Context, keys []string, bufs [][]byte) error {
+	var lastErr error
+	for i := range keys {
+		if err := c.put(keys[i], bufs[i]); err != nil {
+			level.Warn(c.logger).Log("msg", "failed to store entry in disk cache", "key", keys[i], "err", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (c *DiskCache) put(key string, buf []byte) error {
+	size := uint64(diskCacheHeaderSize + len(key) + len(buf))
+	if size > c.maxSizeBytes {
+		c.entriesEvicted.WithLabelValues(tooBigReason).Inc()
+		return nil
+	}
+
+	contents := make([]byte, size)
+	binary.BigEndian.PutUint32(contents[4:], uint32(len(key)))
+	copy(contents[diskCacheHeaderSize:], key)
+	copy(contents[diskCacheHeaderSize+len(key):], buf)
+	binary.BigEndian.PutUint32(contents, crc32.Checksum(contents[4:], diskCacheCastagnoli))
+
+	// Write the entry to a temporary file first, so that readers never see partially written entries.
+	tmp, err := os.CreateTemp(c.dir, "*"+diskCacheTmpSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(contents); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, replaced := c.entries[key]
+	if replaced {
+		c.remove(element, replacedReason)
+	}
+	c.evict(size)
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	c.entries[key] = c.lru.PushFront(&diskCacheEntry{key: key, size: size, updated: time.Now()})
+	c.currSizeBytes += size
+	if !replaced {
+		c.entriesAddedNew.Inc()
+	}
+	c.entriesCurrent.Inc()
+	c.diskBytes.Set(float64(c.currSizeBytes))
+	return nil
+}
+
+// evict removes the least recently used entries until an entry of the given size fits into the cache.
+func (c *DiskCache) evict(size uint64) {
+	for c.currSizeBytes+size > c.maxSizeBytes {
+		element := c.lru.Back()
+		if element == nil {
+			return
+		}
+		c.remove(element, fullReason)
+	}
+}
+
+func (c *DiskCache) remove(element *list.Element, reason string) {
+	entry := c.lru.Remove(element).(*diskCacheEntry)
+	delete(c.entries, entry.key)
+	if err := os.Remove(c.path(entry.key)); err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "failed to remove disk cache file", "key", entry.key, "err", err)
+	}
+	c.currSizeBytes -= entry.size
+	c.entriesCurrent.Dec()
+	c.entriesEvicted.WithLabelValues(reason).Inc()
+	c.diskBytes.Set(float64(c.currSizeBytes))
+}
+
+// Fetch implements Cache.
+func (c *DiskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	found, bufs, missing = make([]string, 0, len(keys)), make([][]byte, 0, len(keys)), make([]string, 0, len(keys))
+	for _, key := range keys {
+		buf, ok := c.get(key)
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+	return
+}
+
+func (c *DiskCache) get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	element, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(element)
+	}
+	c.lock.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	path := c.path(key)
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		// The entry got evicted concurrently.
+		return nil, false
+	}
+
+	if err := verifyDiskCacheFile(contents, key); err != nil {
+		level.Warn(c.logger).Log("msg", "dropping corrupted disk cache entry", "key", key, "err", err)
+		c.lock.Lock()
+		if element, ok := c.entries[key]; ok {
+			c.remove(element, corruptedReason)
+		}
+		c.lock.Unlock()
+		return nil, false
+	}
+
+	// Keep track of the recency of the entry across restarts.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return contents[diskCacheHeaderSize+len(key):], true
+}
+
+func verifyDiskCacheFile(contents []byte, key string) error {
+	if len(contents) < diskCacheHeaderSize {
+		return errors.New("truncated disk cache file")
+	}
+	if crc32.Checksum(contents[4:], diskCacheCastagnoli) != binary.BigEndian.Uint32(contents) {
+		return errors.New("checksum mismatch")
+	}
+	keyLen := int(binary.BigEndian.Uint32(contents[4:]))
+	if diskCacheHeaderSize+keyLen > len(contents) || string(contents[diskCacheHeaderSize:diskCacheHeaderSize+keyLen]) != key {
+		return errors.New("key mismatch")
+	}
+	return nil
+}
+
+// Stop implements Cache. The entries are kept on disk so they can be reused after a restart.
+func (c *DiskCache) Stop() {}
+
+func (c *DiskCache) GetCacheType() stats.CacheType {
+	return c.cacheType
+}
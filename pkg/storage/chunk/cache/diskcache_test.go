@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskCache(t *testing.T, dir string, maxSizeMB int64) *DiskCache {
+	c, err := NewDiskCache("test", DiskCacheConfig{Enabled: true, Directory: dir, MaxSizeMB: maxSizeMB}, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	return c
+}
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, t.TempDir(), 1)
+
+	require.NoError(t, c.Store(ctx, []string{"a", "b"}, [][]byte{[]byte("foo"), []byte("bar")}))
+	found, bufs, missing, err := c.Fetch(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, found)
+	require.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, bufs)
+	require.Equal(t, []string{"c"}, missing)
+
+	// replace an existing entry
+	require.NoError(t, c.Store(ctx, []string{"a"}, [][]byte{[]byte("baz")}))
+	found, bufs, _, err = c.Fetch(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, found)
+	require.Equal(t, [][]byte{[]byte("baz")}, bufs)
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesCurrent))
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(replacedReason)))
+
+	// entries larger than the cache are not stored
+	require.NoError(t, c.Store(ctx, []string{"big"}, [][]byte{make([]byte, 1e6)}))
+	_, _, missing, err = c.Fetch(ctx, []string{"big"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"big"}, missing)
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, t.TempDir(), 1)
+
+	// every entry takes up a bit more than a quarter of the cache
+	value := make([]byte, 250e3)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Store(ctx, []string{fmt.Sprint(i)}, [][]byte{value}))
+	}
+
+	// mark the first entry as recently used
+	found, _, _, err := c.Fetch(ctx, []string{"0"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"0"}, found)
+
+	require.NoError(t, c.Store(ctx, []string{"3"}, [][]byte{value}))
+	found, _, missing, err := c.Fetch(ctx, []string{"0", "1", "2", "3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "2", "3"}, found)
+	require.Equal(t, []string{"1"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(fullReason)))
+	require.LessOrEqual(t, c.currSizeBytes, c.maxSizeBytes)
+
+	_, err = os.Stat(c.path("1"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestDiskCacheRecovery(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	c := newTestDiskCache(t, dir, 1)
+	require.NoError(t, c.Store(ctx, []string{"a", "b", "c"}, [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")}))
+	c.Stop()
+
+	// leftover of an interrupted write
+	require.NoError(t, os.WriteFile(dir+"/123"+diskCacheTmpSuffix, []byte("partial"), 0o640))
+	// a corrupted entry
+	contents, err := os.ReadFile(c.path("c"))
+	require.NoError(t, err)
+	contents[len(contents)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(c.path("c"), contents, 0o640))
+
+	c = newTestDiskCache(t, dir, 1)
+	require.Equal(t, float64(3), testutil.ToFloat64(c.entriesCurrent))
+	_, err = os.Stat(dir + "/123" + diskCacheTmpSuffix)
+	require.True(t, os.IsNotExist(err))
+
+	found, bufs, missing, err := c.Fetch(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, found)
+	require.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, bufs)
+	require.Equal(t, []string{"c"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(corruptedReason)))
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheConfig_Validate(t *testing.T) {
+	require.NoError(t, (&DiskCacheConfig{}).Validate())
+	require.NoError(t, (&DiskCacheConfig{Enabled: true, Directory: "/tmp/cache", MaxSizeMB: 1}).Validate())
+	require.Error(t, (&DiskCacheConfig{Enabled: true, MaxSizeMB: 1}).Validate())
+	require.Error(t, (&DiskCacheConfig{Enabled: true, Directory: "/tmp/cache"}).Validate())
+}
@@ -31,6 +31,25 @@ var (
 
 var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
 
+// DecodeError is returned when fetched chunk bytes fail to decode, meaning
+// the stored chunk is corrupted as opposed to not being fetchable.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string { return e.Err.Error() }
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// Cause lets errors.Cause look through the DecodeError.
+func (e *DecodeError) Cause() error { return e.Err }
+
+// IsDecodeError returns whether the chain of err has a DecodeError.
+func IsDecodeError(err error) bool {
+	var decodeErr *DecodeError
+	return errs.As(err, &decodeErr)
+}
+
 func errInvalidChunkID(s string) error {
 	return errors.Errorf("invalid chunk ID %q", s)
 }
@@ -3,6 +3,7 @@ package alibaba
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -108,6 +109,28 @@ func (s *OssObjectClient) GetObject(ctx context.Context, objectKey string) (io.R
 	return resp.Response.Body, int64(size), err
 }
 
+// GetObjectRange returns a reader for the specified range of the object from the configured OSS bucket.
+func (s *OssObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	option := oss.NormalizedRange(fmt.Sprintf("%d-", offset))
+	if length > 0 {
+		option = oss.Range(offset, offset+length-1)
+	}
+	var resp *oss.GetObjectResult
+	err := instrument.CollectedRequest(ctx, "OSS.GetObjectRange", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var requestErr error
+		resp, requestErr = s.defaultBucket.DoGetObject(&oss.GetObjectRequest{ObjectKey: objectKey}, []oss.Option{option})
+		return requestErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.Body, nil
+}
+
 // PutObject puts the specified bytes into the configured OSS bucket at the provided key
 func (s *OssObjectClient) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
 	return instrument.CollectedRequest(ctx, "OSS.PutObject", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
@@ -118,6 +141,16 @@ func (s *OssObjectClient) PutObject(ctx context.Context, objectKey string, objec
 	})
 }
 
+// PutObjectIf is not supported by OSS.
+func (*OssObjectClient) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
+// ObjectVersion is not supported by OSS.
+func (*OssObjectClient) ObjectVersion(context.Context, string) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
 // List implements chunk.ObjectClient.
 func (s *OssObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	var storageObjects []client.StorageObject
@@ -17,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
@@ -380,29 +381,130 @@ func (a *S3ObjectClient) GetObject(ctx context.Context, objectKey string) (io.Re
 	return nil, 0, errors.Wrap(lastErr, "failed to get s3 object")
 }
 
+// GetObjectRange returns a reader for the specified range of the object from the configured S3 bucket.
+func (a *S3ObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var resp *s3.GetObjectOutput
+
+	// Map the key into a bucket
+	bucket := a.bucketFromKey(objectKey)
+
+	var lastErr error
+
+	retries := backoff.New(ctx, a.cfg.BackoffConfig)
+	for retries.Ongoing() {
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(ctx.Err(), "ctx related error during s3 getObjectRange")
+		}
+
+		lastErr = loki_instrument.TimeRequest(ctx, "S3.GetObjectRange", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+			var requestErr error
+			resp, requestErr = a.hedgedS3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+				Bucket: aws.String(bucket),
+				Key:    aws.String(objectKey),
+				Range:  aws.String(client.HTTPRange(offset, length)),
+			})
+			return requestErr
+		})
+
+		if lastErr == nil && resp.Body != nil {
+			return resp.Body, nil
+		}
+		retries.Wait()
+	}
+
+	return nil, errors.Wrap(lastErr, "failed to get s3 object range")
+}
+
 // PutObject into the store
 func (a *S3ObjectClient) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
 	return loki_instrument.TimeRequest(ctx, "S3.PutObject", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
-		readSeeker, err := clientutil.ReadSeeker(object)
+		_, err := a.putObject(ctx, objectKey, object)
+		return err
+	})
+}
+
+// PutObjectIf puts the object into the store if cond holds, using the If-None-Match and If-Match
+// headers of PutObject, which are supported by general purpose and S3 Express One Zone buckets.
+func (a *S3ObjectClient) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	if err := cond.Validate(); err != nil {
+		return "", err
+	}
+
+	header := map[string]string{"If-None-Match": "*"}
+	if cond.IfMatch != "" {
+		header = map[string]string{"If-Match": cond.IfMatch}
+	}
+
+	var version string
+	err := loki_instrument.TimeRequest(ctx, "S3.PutObjectIf", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		output, err := a.putObject(ctx, objectKey, object, request.WithSetRequestHeaders(header))
 		if err != nil {
 			return err
 		}
-		putObjectInput := &s3.PutObjectInput{
-			Body:         readSeeker,
-			Bucket:       aws.String(a.bucketFromKey(objectKey)),
-			Key:          aws.String(objectKey),
-			StorageClass: aws.String(a.cfg.StorageClass),
-		}
+		version = aws.StringValue(output.ETag)
+		return nil
+	})
+	if isPreconditionFailedErr(err) {
+		return "", errors.Wrapf(client.ErrPreconditionFailed, "put s3 object %s", objectKey)
+	}
+	return version, err
+}
 
-		if a.sseConfig != nil {
-			putObjectInput.ServerSideEncryption = aws.String(a.sseConfig.ServerSideEncryption)
-			putObjectInput.SSEKMSKeyId = a.sseConfig.KMSKeyID
-			putObjectInput.SSEKMSEncryptionContext = a.sseConfig.KMSEncryptionContext
-		}
+func (a *S3ObjectClient) putObject(ctx context.Context, objectKey string, object io.Reader, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	readSeeker, err := clientutil.ReadSeeker(object)
+	if err != nil {
+		return nil, err
+	}
+	putObjectInput := &s3.PutObjectInput{
+		Body:         readSeeker,
+		Bucket:       aws.String(a.bucketFromKey(objectKey)),
+		Key:          aws.String(objectKey),
+		StorageClass: aws.String(a.cfg.StorageClass),
+	}
 
-		_, err = a.S3.PutObjectWithContext(ctx, putObjectInput)
-		return err
+	if a.sseConfig != nil {
+		putObjectInput.ServerSideEncryption = aws.String(a.sseConfig.ServerSideEncryption)
+		putObjectInput.SSEKMSKeyId = a.sseConfig.KMSKeyID
+		putObjectInput.SSEKMSEncryptionContext = a.sseConfig.KMSEncryptionContext
+	}
+
+	return a.S3.PutObjectWithContext(ctx, putObjectInput, opts...)
+}
+
+// isPreconditionFailedErr returns true if the precondition of a conditional write doesn't hold.
+// S3 returns 409 Conflict instead of 412 Precondition Failed when the object is written concurrently.
+func isPreconditionFailedErr(err error) bool {
+	var rerr awserr.RequestFailure
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	return rerr.StatusCode() == http.StatusPreconditionFailed || rerr.StatusCode() == http.StatusConflict
+}
+
+// ObjectVersion returns the ETag of the object.
+func (a *S3ObjectClient) ObjectVersion(ctx context.Context, objectKey string) (string, error) {
+	var version string
+	err := instrument.CollectedRequest(ctx, "S3.ObjectVersion", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		output, err := a.S3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(a.bucketFromKey(objectKey)),
+			Key:    aws.String(objectKey),
+		})
+		if err != nil {
+			return err
+		}
+		version = aws.StringValue(output.ETag)
+		return nil
 	})
+	// HeadObject responses have no body, so missing objects are reported with a generic NotFound code.
+	var rerr awserr.RequestFailure
+	if errors.As(err, &rerr) && rerr.StatusCode() == http.StatusNotFound {
+		return "", awserr.New(s3.ErrCodeNoSuchKey, "object not found", err)
+	}
+	return version, err
 }
 
 // List implements chunk.ObjectClient.
@@ -277,20 +277,111 @@ func (b *BlobStorage) getObject(ctx context.Context, objectKey string) (rc io.Re
 	return downloadResponse.Body(azblob.RetryReaderOptions{MaxRetryRequests: b.cfg.MaxRetries}), downloadResponse.ContentLength(), nil
 }
 
+// GetObjectRange returns a reader for the specified range of the object.
+func (b *BlobStorage) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var cancel context.CancelFunc = func() {}
+	if b.cfg.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, (time.Duration(b.cfg.MaxRetries)*b.cfg.RequestTimeout)+(time.Duration(b.cfg.MaxRetries-1)*b.cfg.MaxRetryDelay)) // timeout only after azure client's built in retries
+	}
+
+	count := int64(azblob.CountToEnd)
+	if length > 0 {
+		count = length
+	}
+	var (
+		size int64
+		rc   io.ReadCloser
+	)
+	err := loki_instrument.TimeRequest(ctx, "azure.GetObjectRange", instrument.NewHistogramCollector(b.metrics.requestDuration), instrument.ErrorCode, func(ctx context.Context) error {
+		blockBlobURL, err := b.getBlobURL(objectKey, true)
+		if err != nil {
+			return err
+		}
+
+		downloadResponse, err := blockBlobURL.Download(ctx, offset, count, azblob.BlobAccessConditions{}, false, noClientKey)
+		if err != nil {
+			return err
+		}
+		rc, size = downloadResponse.Body(azblob.RetryReaderOptions{MaxRetryRequests: b.cfg.MaxRetries}), downloadResponse.ContentLength()
+		return nil
+	})
+	b.metrics.egressBytesTotal.Add(float64(size))
+	if err != nil {
+		// cancel the context if there is an error.
+		cancel()
+		return nil, err
+	}
+	// else return a wrapped ReadCloser which cancels the context while closing the reader.
+	return client_util.NewReadCloserWithContextCancelFunc(rc, cancel), nil
+}
+
 func (b *BlobStorage) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
 	return loki_instrument.TimeRequest(ctx, "azure.PutObject", instrument.NewHistogramCollector(b.metrics.requestDuration), instrument.ErrorCode, func(ctx context.Context) error {
-		blockBlobURL, err := b.getBlobURL(objectKey, false)
+		_, err := b.putObject(ctx, objectKey, object, azblob.BlobAccessConditions{})
+		return err
+	})
+}
+
+// PutObjectIf puts the object if cond holds. The version of blobs is their ETag.
+func (b *BlobStorage) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	if err := cond.Validate(); err != nil {
+		return "", err
+	}
+
+	accessConditions := azblob.BlobAccessConditions{ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny}}
+	if cond.IfMatch != "" {
+		accessConditions = azblob.BlobAccessConditions{ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: azblob.ETag(cond.IfMatch)}}
+	}
+
+	var version string
+	err := loki_instrument.TimeRequest(ctx, "azure.PutObjectIf", instrument.NewHistogramCollector(b.metrics.requestDuration), instrument.ErrorCode, func(ctx context.Context) error {
+		resp, err := b.putObject(ctx, objectKey, object, accessConditions)
 		if err != nil {
 			return err
 		}
+		version = string(resp.ETag())
+		return nil
+	})
+	var e azblob.StorageError
+	if errors.As(err, &e) && (e.ServiceCode() == azblob.ServiceCodeConditionNotMet || e.ServiceCode() == azblob.ServiceCodeBlobAlreadyExists) {
+		return "", fmt.Errorf("put blob %s: %w", objectKey, client.ErrPreconditionFailed)
+	}
+	return version, err
+}
 
-		bufferSize := b.cfg.UploadBufferSize
-		maxBuffers := b.cfg.UploadBufferCount
-		_, err = azblob.UploadStreamToBlockBlob(ctx, object, blockBlobURL,
-			azblob.UploadStreamToBlockBlobOptions{BufferSize: bufferSize, MaxBuffers: maxBuffers})
+func (b *BlobStorage) putObject(ctx context.Context, objectKey string, object io.Reader, accessConditions azblob.BlobAccessConditions) (azblob.CommonResponse, error) {
+	blockBlobURL, err := b.getBlobURL(objectKey, false)
+	if err != nil {
+		return nil, err
+	}
 
-		return err
+	bufferSize := b.cfg.UploadBufferSize
+	maxBuffers := b.cfg.UploadBufferCount
+	return azblob.UploadStreamToBlockBlob(ctx, object, blockBlobURL,
+		azblob.UploadStreamToBlockBlobOptions{BufferSize: bufferSize, MaxBuffers: maxBuffers, AccessConditions: accessConditions})
+}
+
+// ObjectVersion returns the ETag of the blob.
+func (b *BlobStorage) ObjectVersion(ctx context.Context, objectKey string) (string, error) {
+	var version string
+	err := loki_instrument.TimeRequest(ctx, "azure.ObjectVersion", instrument.NewHistogramCollector(b.metrics.requestDuration), instrument.ErrorCode, func(ctx context.Context) error {
+		blockBlobURL, err := b.getBlobURL(objectKey, false)
+		if err != nil {
+			return err
+		}
+
+		props, err := blockBlobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, noClientKey)
+		if err != nil {
+			return err
+		}
+		version = string(props.ETag())
+		return nil
 	})
+	return version, err
 }
 
 func (b *BlobStorage) getBlobURL(blobID string, hedging bool) (azblob.BlockBlobURL, error) {
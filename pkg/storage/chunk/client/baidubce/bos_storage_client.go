@@ -117,6 +117,37 @@ func (b *BOSObjectStorage) GetObject(ctx context.Context, objectKey string) (io.
 	return res.Body, size, nil
 }
 
+func (b *BOSObjectStorage) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	ranges := []int64{offset}
+	if length > 0 {
+		ranges = append(ranges, offset+length-1)
+	}
+	var res *api.GetObjectResult
+	err := instrument.CollectedRequest(ctx, "BOS.GetObjectRange", bosRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var requestErr error
+		res, requestErr = b.client.GetObject(b.cfg.BucketName, objectKey, nil, ranges...)
+		return requestErr
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get BOS object range [ %s ]", objectKey)
+	}
+	return res.Body, nil
+}
+
+// PutObjectIf is not supported by BOS.
+func (*BOSObjectStorage) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
+// ObjectVersion is not supported by BOS.
+func (*BOSObjectStorage) ObjectVersion(context.Context, string) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
 func (b *BOSObjectStorage) List(ctx context.Context, prefix string, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	var storageObjects []client.StorageObject
 	var commonPrefixes []client.StorageCommonPrefix
@@ -13,6 +13,8 @@ var (
 	ErrMethodNotImplemented = errors.New("method is not implemented")
 	// ErrStorageObjectNotFound when object storage does not have requested object
 	ErrStorageObjectNotFound = errors.New("object not found in storage")
+	// ErrPreconditionFailed when the precondition of a conditional write does not hold
+	ErrPreconditionFailed = errors.New("object precondition failed")
 )
 
 // Client is for storing and retrieving chunks.
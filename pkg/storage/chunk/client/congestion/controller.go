@@ -87,9 +87,31 @@ func (a *AIMDController) PutObject(ctx context.Context, objectKey string, object
 	return a.inner.PutObject(ctx, objectKey, object)
 }
 
+func (a *AIMDController) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	return a.inner.PutObjectIf(ctx, objectKey, object, cond)
+}
+
+func (a *AIMDController) ObjectVersion(ctx context.Context, objectKey string) (string, error) {
+	return a.inner.ObjectVersion(ctx, objectKey)
+}
+
 func (a *AIMDController) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
-	// Only GetObject implements congestion avoidance; the other methods are either non-idempotent which means they
-	// cannot be retried, or are too low volume to care about
+	return a.getObject(ctx, func() (io.ReadCloser, int64, error) {
+		return a.inner.GetObject(ctx, objectKey)
+	})
+}
+
+func (a *AIMDController) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	rc, _, err := a.getObject(ctx, func() (io.ReadCloser, int64, error) {
+		rc, err := a.inner.GetObjectRange(ctx, objectKey, offset, length)
+		return rc, 0, err
+	})
+	return rc, err
+}
+
+func (a *AIMDController) getObject(ctx context.Context, get func() (io.ReadCloser, int64, error)) (io.ReadCloser, int64, error) {
+	// Only GetObject and GetObjectRange implement congestion avoidance; the other methods are either non-idempotent
+	// which means they cannot be retried, or are too low volume to care about
 
 	// TODO(dannyk): use hedging client to handle requests, do NOT hedge retries
 
@@ -119,7 +141,7 @@ func (a *AIMDController) GetObject(ctx context.Context, objectKey string) (io.Re
 
 			// It is vitally important that retries are DISABLED in the inner implementation.
 			// Some object storage clients implement retries internally, and this will interfere here.
-			return a.inner.GetObject(ctx, objectKey)
+			return get()
 		},
 		a.IsRetryableErr,
 		a.additiveIncrease,
@@ -213,6 +235,13 @@ func (n *NoopController) PutObject(context.Context, string, io.Reader) error { r
 func (n *NoopController) GetObject(context.Context, string) (io.ReadCloser, int64, error) {
 	return nil, 0, nil
 }
+func (n *NoopController) GetObjectRange(context.Context, string, int64, int64) (io.ReadCloser, error) {
+	return nil, nil
+}
+func (n *NoopController) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	return "", nil
+}
+func (n *NoopController) ObjectVersion(context.Context, string) (string, error) { return "", nil }
 
 func (n *NoopController) List(context.Context, string, string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	return nil, nil, nil
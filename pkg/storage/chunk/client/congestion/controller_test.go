@@ -260,6 +260,18 @@ func (m *mockObjectClient) GetObject(context.Context, string) (io.ReadCloser, in
 	return io.NopCloser(strings.NewReader("bar")), 3, nil
 }
 
+func (m *mockObjectClient) GetObjectRange(context.Context, string, int64, int64) (io.ReadCloser, error) {
+	panic("not implemented")
+}
+
+func (m *mockObjectClient) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	panic("not implemented")
+}
+
+func (m *mockObjectClient) ObjectVersion(context.Context, string) (string, error) {
+	panic("not implemented")
+}
+
 func (m *mockObjectClient) ObjectExists(context.Context, string) (bool, error) {
 	panic("not implemented")
 }
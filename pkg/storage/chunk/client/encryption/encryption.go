@@ -0,0 +1,412 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"flag"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+const (
+	KeyProviderFile = "file"
+
+	keySize = 32
+
+	// segmentSize is the size of the segments objects are encrypted in.
+	segmentSize = 64 << 10
+)
+
+// envelopeMagic prefixes every object encrypted by the ObjectClient. Objects
+// without it are returned as is, so that data written before encryption was
+// enabled stays readable.
+var envelopeMagic = []byte("LOKIENV1")
+
+var errTruncated = errors.New("truncated envelope")
+
+// ErrNoKey is returned by a KeyProvider for tenants without a key encryption key.
+// Objects of those tenants are stored unencrypted.
+var ErrNoKey = errors.New("no key encryption key for tenant")
+
+// Config for client-side encryption of objects.
+type Config struct {
+	Enabled     bool               `yaml:"enabled"`
+	KeyProvider string             `yaml:"key_provider"`
+	File        FileProviderConfig `yaml:"file"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"encryption.enabled", false, "Experimental. Encrypt chunks and per-tenant index files before uploading them to the object store. Only tenants that have a key in the key provider are encrypted.")
+	f.StringVar(&cfg.KeyProvider, prefix+"encryption.key-provider", KeyProviderFile, "Provider of the per-tenant key encryption keys. Supported values are: file.")
+	cfg.File.RegisterFlagsWithPrefix(prefix+"encryption.file.", f)
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	switch cfg.KeyProvider {
+	case KeyProviderFile:
+		return cfg.File.Validate()
+	default:
+		return errors.Errorf("unsupported encryption key provider: %s", cfg.KeyProvider)
+	}
+}
+
+// KeyProvider wraps and unwraps the data encryption keys of objects with the
+// key encryption key of their tenant.
+type KeyProvider interface {
+	// WrapKey encrypts dek with the current key encryption key of the tenant and
+	// returns the ID of that key alongside the wrapped key. It returns ErrNoKey
+	// if the tenant is not encrypted.
+	WrapKey(ctx context.Context, tenant string, dek []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data encryption key wrapped with the given key encryption key of the tenant.
+	UnwrapKey(ctx context.Context, tenant, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyProvider creates the KeyProvider selected in the config.
+func NewKeyProvider(cfg Config) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case KeyProviderFile:
+		return NewFileProvider(cfg.File)
+	default:
+		return nil, errors.Errorf("unsupported encryption key provider: %s", cfg.KeyProvider)
+	}
+}
+
+// ObjectClient encrypts objects before they are uploaded and decrypts them
+// when they are read back. Every object gets its own data encryption key,
+// which is stored alongside the object wrapped with the key encryption key
+// of its tenant. The ID of that key is stored as well, so key encryption keys
+// can be rotated without rewriting existing objects.
+//
+// Only the objects written with a context that carries their tenant, see
+// client.InjectObjectTenant, are encrypted. Objects are encrypted and
+// decrypted in segments while they are streamed, so they are never held in
+// memory as a whole.
+type ObjectClient struct {
+	client.ObjectClient
+
+	provider KeyProvider
+}
+
+// NewObjectClient wraps c with client-side encryption.
+func NewObjectClient(c client.ObjectClient, provider KeyProvider) *ObjectClient {
+	return &ObjectClient{
+		ObjectClient: c,
+		provider:     provider,
+	}
+}
+
+// GetDownstream returns the wrapped client.
+func (c *ObjectClient) GetDownstream() client.ObjectClient {
+	return c.ObjectClient
+}
+
+// PutObject implements client.ObjectClient.
+func (c *ObjectClient) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
+	object, err := c.encrypt(ctx, objectKey, object)
+	if err != nil {
+		return err
+	}
+	return c.ObjectClient.PutObject(ctx, objectKey, object)
+}
+
+// PutObjectIf implements client.ObjectClient. The versions of the objects are
+// the ones of their envelopes.
+func (c *ObjectClient) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	object, err := c.encrypt(ctx, objectKey, object)
+	if err != nil {
+		return "", err
+	}
+	return c.ObjectClient.PutObjectIf(ctx, objectKey, object, cond)
+}
+
+// encrypt returns a reader of the envelope of the object, or the object
+// itself if it has no tenant or its tenant has no key.
+func (c *ObjectClient) encrypt(ctx context.Context, objectKey string, object io.Reader) (io.Reader, error) {
+	tenant, ok := client.ExtractObjectTenant(ctx)
+	if !ok {
+		return object, nil
+	}
+
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	keyID, wrapped, err := c.provider.WrapKey(ctx, tenant, dek)
+	if errors.Is(err, ErrNoKey) {
+		return object, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "encrypting object %s", objectKey)
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := bytes.NewBuffer(make([]byte, 0, len(envelopeMagic)+6+len(keyID)+len(wrapped)+len(tenant)+len(nonce)))
+	header.Write(envelopeMagic)
+	writeField(header, []byte(keyID))
+	writeField(header, wrapped)
+	writeField(header, []byte(tenant))
+	header.Write(nonce)
+
+	return &sealReader{
+		segmenter: newSegmenter(object, aead, nonce, tenant, segmentSize),
+		out:       header.Bytes(),
+	}, nil
+}
+
+// GetObject implements client.ObjectClient.
+func (c *ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	rc, size, err := c.ObjectClient.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r := bufio.NewReader(rc)
+	if magic, _ := r.Peek(len(envelopeMagic)); !bytes.Equal(magic, envelopeMagic) {
+		return readCloser{r, rc}, size, nil
+	}
+
+	or, headerSize, err := c.open(ctx, r)
+	if err != nil {
+		_ = rc.Close()
+		return nil, 0, errors.Wrapf(err, "decrypting object %s", objectKey)
+	}
+	return readCloser{or, rc}, plaintextSize(size-headerSize, or.aead.Overhead()), nil
+}
+
+// GetObjectRange implements client.ObjectClient. Encrypted objects can only be
+// decrypted from their start, so the range is cut from the decrypted object.
+func (c *ObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetObjectRangeFallback(ctx, c, objectKey, offset, length)
+}
+
+// open reads the header of an envelope and returns a reader of the decrypted
+// object, along with the size of the header. The envelope is laid out as:
+//
+//	magic | key ID length (uint16) | key ID | wrapped key length (uint16) | wrapped key | tenant length (uint16) | tenant | nonce | segments
+//
+// Every segment holds up to segmentSize bytes of the object, sealed with the
+// data encryption key. See segmenter for how the segments are authenticated.
+func (c *ObjectClient) open(ctx context.Context, r *bufio.Reader) (*openReader, int64, error) {
+	if _, err := r.Discard(len(envelopeMagic)); err != nil {
+		return nil, 0, err
+	}
+	keyID, err := readField(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	wrapped, err := readField(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	tenant, err := readField(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Objects copied into another tenant must not be readable by that tenant.
+	if expected, ok := client.ExtractObjectTenant(ctx); ok && expected != string(tenant) {
+		return nil, 0, errors.Errorf("object belongs to tenant %s, not %s", tenant, expected)
+	}
+
+	dek, err := c.provider.UnwrapKey(ctx, string(tenant), string(keyID), wrapped)
+	if err != nil {
+		return nil, 0, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, 0, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, 0, errTruncated
+	}
+
+	headerSize := int64(len(envelopeMagic) + 6 + len(keyID) + len(wrapped) + len(tenant) + len(nonce))
+	return &openReader{
+		segmenter: newSegmenter(r, aead, nonce, string(tenant), segmentSize+aead.Overhead()),
+	}, headerSize, nil
+}
+
+// plaintextSize returns the size of the object sealed into segments of the
+// given total size, or -1 if the size is unknown.
+func plaintextSize(sealedSize int64, overhead int) int64 {
+	if sealedSize <= 0 {
+		return -1
+	}
+	sealedSegmentSize := int64(segmentSize + overhead)
+	segments := (sealedSize + sealedSegmentSize - 1) / sealedSegmentSize
+	return sealedSize - segments*int64(overhead)
+}
+
+// segmenter reads its source in segments of a fixed size and keeps track of
+// the nonce and the additional data of every segment. The nonce of a segment
+// is the nonce of the object XORed with the index of the segment. The
+// additional data is the tenant followed by a flag that marks the last
+// segment, so that segments cannot be reordered and objects cannot be
+// truncated or moved to another tenant without failing the decryption.
+type segmenter struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	ad    []byte
+
+	index   uint64
+	buf     []byte
+	pending int
+}
+
+func newSegmenter(src io.Reader, aead cipher.AEAD, nonce []byte, tenant string, size int) segmenter {
+	return segmenter{
+		src:   src,
+		aead:  aead,
+		nonce: append([]byte(nil), nonce...),
+		ad:    append([]byte(tenant), 0),
+		// one more byte than a segment, to find out whether a segment is the last one
+		buf: make([]byte, size+1),
+	}
+}
+
+// next returns the next segment of the source and whether it is the last one.
+func (s *segmenter) next() ([]byte, bool, error) {
+	size := len(s.buf) - 1
+	n, err := io.ReadFull(s.src, s.buf[s.pending:])
+	n += s.pending
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		s.pending = 0
+		return s.buf[:n], true, nil
+	case err != nil:
+		return nil, false, err
+	}
+	// keep the byte read ahead for the next segment
+	segment := make([]byte, size)
+	copy(segment, s.buf[:size])
+	s.buf[0] = s.buf[size]
+	s.pending = 1
+	return segment, false, nil
+}
+
+// segmentNonceAndAD returns the nonce and the additional data of the current
+// segment, and moves on to the next segment.
+func (s *segmenter) segmentNonceAndAD(last bool) ([]byte, []byte) {
+	nonce := append([]byte(nil), s.nonce...)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^s.index)
+	s.index++
+
+	ad := s.ad
+	if last {
+		ad = append(append([]byte(nil), s.ad[:len(s.ad)-1]...), 1)
+	}
+	return nonce, ad
+}
+
+// sealReader reads the envelope of its source: the header followed by the sealed segments.
+type sealReader struct {
+	segmenter
+	out []byte
+	err error
+}
+
+func (r *sealReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		segment, last, err := r.next()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		nonce, ad := r.segmentNonceAndAD(last)
+		r.out = r.aead.Seal(segment[:0:0], nonce, segment, ad)
+		if last {
+			r.err = io.EOF
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// openReader reads the decrypted segments of an envelope, after its header.
+type openReader struct {
+	segmenter
+	out []byte
+	err error
+}
+
+func (r *openReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		segment, last, err := r.next()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		nonce, ad := r.segmentNonceAndAD(last)
+		r.out, err = r.aead.Open(segment[:0], nonce, segment, ad)
+		if err != nil {
+			r.err = errors.Wrap(err, "decrypting segment")
+			continue
+		}
+		if last {
+			r.err = io.EOF
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeField(buf *bytes.Buffer, field []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(field)))
+	buf.Write(field)
+}
+
+func readField(r io.Reader) ([]byte, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, errTruncated
+	}
+	field := make([]byte, n)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, errTruncated
+	}
+	return field, nil
+}
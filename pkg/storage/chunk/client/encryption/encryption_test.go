@@ -0,0 +1,164 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+)
+
+func writeKeysFile(t *testing.T, path, activeKey string, keys ...string) {
+	content := fmt.Sprintf("tenants:\n  tenant-a:\n    active_key: %q\n    keys:\n", activeKey)
+	for _, id := range keys {
+		content += fmt.Sprintf("      %q: %s\n", id, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte(id[:1]), keySize)))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func newTestClient(t *testing.T, store client.ObjectClient, path string) *ObjectClient {
+	provider, err := NewKeyProvider(Config{Enabled: true, KeyProvider: KeyProviderFile, File: FileProviderConfig{Path: path}})
+	require.NoError(t, err)
+	return NewObjectClient(store, provider)
+}
+
+func readObject(t *testing.T, ctx context.Context, c client.ObjectClient, key string) []byte {
+	rc, size, err := c.GetObject(ctx, key)
+	require.NoError(t, err)
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), size)
+	return b
+}
+
+func TestObjectClient(t *testing.T) {
+	ctx := context.Background()
+	tenantA := client.InjectObjectTenant(ctx, "tenant-a")
+	tenantB := client.InjectObjectTenant(ctx, "tenant-b")
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	writeKeysFile(t, path, "1", "1")
+
+	store := testutils.NewInMemoryObjectClient()
+	c := newTestClient(t, store, path)
+
+	data := []byte("some chunk data")
+	for _, tc := range []struct {
+		ctx context.Context
+		key string
+	}{
+		{tenantA, "tenant-a/fp/0:1:2"},
+		{tenantA, "index/index_1/tenant-a/file.tsdb.gz"},
+		{tenantB, "tenant-b/fp/0:1:2"},
+		{ctx, "tenant-a/fp/0:1:3"},
+	} {
+		require.NoError(t, c.PutObject(tc.ctx, tc.key, bytes.NewReader(data)))
+		require.Equal(t, data, readObject(t, tc.ctx, c, tc.key))
+
+		rc, err := c.GetObjectRange(tc.ctx, tc.key, 5, 5)
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.Equal(t, data[5:10], b)
+	}
+
+	// objects of tenants with a key are encrypted, the others are not
+	require.True(t, bytes.HasPrefix(readObject(t, ctx, store, "tenant-a/fp/0:1:2"), envelopeMagic))
+	require.NotContains(t, string(readObject(t, ctx, store, "tenant-a/fp/0:1:2")), string(data))
+	require.True(t, bytes.HasPrefix(readObject(t, ctx, store, "index/index_1/tenant-a/file.tsdb.gz"), envelopeMagic))
+	require.Equal(t, data, readObject(t, ctx, store, "tenant-b/fp/0:1:2"))
+	// objects written without a tenant are never encrypted, whatever their key
+	require.Equal(t, data, readObject(t, ctx, store, "tenant-a/fp/0:1:3"))
+
+	// encrypted objects carry their tenant, so they can be read without one
+	require.Equal(t, data, readObject(t, ctx, c, "tenant-a/fp/0:1:2"))
+
+	// an object moved to another tenant cannot be read by that tenant
+	require.NoError(t, store.PutObject(ctx, "tenant-b/fp/0:1:3", bytes.NewReader(readObject(t, ctx, store, "tenant-a/fp/0:1:2"))))
+	_, _, err := c.GetObject(tenantB, "tenant-b/fp/0:1:3")
+	require.Error(t, err)
+}
+
+func TestObjectClient_Segments(t *testing.T) {
+	tenantA := client.InjectObjectTenant(context.Background(), "tenant-a")
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	writeKeysFile(t, path, "1", "1")
+
+	store := testutils.NewInMemoryObjectClient()
+	c := newTestClient(t, store, path)
+
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3*segmentSize + 5} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			data := make([]byte, size)
+			_, _ = rand.Read(data)
+			key := fmt.Sprintf("tenant-a/fp/0:1:%d", size)
+			require.NoError(t, c.PutObject(tenantA, key, bytes.NewReader(data)))
+			require.Equal(t, data, readObject(t, tenantA, c, key))
+
+			rc, err := c.GetObjectRange(tenantA, key, int64(size/2), 10)
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.Equal(t, data[size/2:min(size, size/2+10)], b)
+		})
+	}
+
+	// objects truncated at a segment boundary cannot be read
+	key := fmt.Sprintf("tenant-a/fp/0:1:%d", 3*segmentSize+5)
+	envelope := readObject(t, tenantA, store, key)
+	require.NoError(t, store.PutObject(tenantA, key, bytes.NewReader(envelope[:len(envelope)-21])))
+	rc, _, err := c.GetObject(tenantA, key)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.Error(t, err)
+}
+
+func TestObjectClient_KeyRotation(t *testing.T) {
+	ctx := client.InjectObjectTenant(context.Background(), "tenant-a")
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	store := testutils.NewInMemoryObjectClient()
+	data := []byte("some chunk data")
+
+	writeKeysFile(t, path, "1", "1")
+	require.NoError(t, newTestClient(t, store, path).PutObject(ctx, "tenant-a/fp/0:1:1", bytes.NewReader(data)))
+
+	// rotate the key, objects written with the previous key remain readable
+	writeKeysFile(t, path, "2", "1", "2")
+	c := newTestClient(t, store, path)
+	require.NoError(t, c.PutObject(ctx, "tenant-a/fp/0:1:2", bytes.NewReader(data)))
+	require.Equal(t, data, readObject(t, ctx, c, "tenant-a/fp/0:1:1"))
+	require.Equal(t, data, readObject(t, ctx, c, "tenant-a/fp/0:1:2"))
+
+	// retire the previous key
+	writeKeysFile(t, path, "2", "2")
+	c = newTestClient(t, store, path)
+	_, _, err := c.GetObject(ctx, "tenant-a/fp/0:1:1")
+	require.Error(t, err)
+	require.Equal(t, data, readObject(t, ctx, c, "tenant-a/fp/0:1:2"))
+}
+
+func TestNewFileProvider_Errors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"missing active key": "tenants:\n  a:\n    active_key: x\n    keys:\n      y: " + base64.StdEncoding.EncodeToString(make([]byte, keySize)),
+		"short key":          "tenants:\n  a:\n    active_key: x\n    keys:\n      x: " + base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"invalid base64":     "tenants:\n  a:\n    active_key: x\n    keys:\n      x: '!!'",
+		"unknown field":      "tenant:\n  a: {}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "keys.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			_, err := NewFileProvider(FileProviderConfig{Path: path})
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// FileProviderConfig configures the file based KeyProvider.
+type FileProviderConfig struct {
+	Path string `yaml:"path"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *FileProviderConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Path, prefix+"path", "", "Path to the YAML file with the key encryption keys of the tenants. Meant for testing, the keys are stored in plain text.")
+}
+
+// Validate the config.
+func (cfg *FileProviderConfig) Validate() error {
+	if cfg.Path == "" {
+		return errors.New("path of the encryption keys file must be set")
+	}
+	return nil
+}
+
+// tenantKeys are the key encryption keys of a tenant. New objects are
+// encrypted with the active key; the other keys are kept around to decrypt
+// objects written before the active key was rotated.
+type tenantKeys struct {
+	ActiveKey string            `yaml:"active_key"`
+	Keys      map[string]string `yaml:"keys"`
+}
+
+type keysFile struct {
+	Tenants map[string]tenantKeys `yaml:"tenants"`
+}
+
+// FileProvider is a KeyProvider reading the key encryption keys of the tenants
+// from a local YAML file of the form:
+//
+//	tenants:
+//	  tenant-a:
+//	    active_key: "2024-02"
+//	    keys:
+//	      "2024-01": <base64 encoded 256 bit key>
+//	      "2024-02": <base64 encoded 256 bit key>
+type FileProvider struct {
+	keys map[string]map[string][]byte
+	// active key ID per tenant
+	active map[string]string
+}
+
+// NewFileProvider loads the keys from the file in the config.
+func NewFileProvider(cfg FileProviderConfig) (*FileProvider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading encryption keys file")
+	}
+
+	var f keysFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, errors.Wrap(err, "parsing encryption keys file")
+	}
+
+	p := &FileProvider{
+		keys:   make(map[string]map[string][]byte, len(f.Tenants)),
+		active: make(map[string]string, len(f.Tenants)),
+	}
+	for tenant, tk := range f.Tenants {
+		if _, ok := tk.Keys[tk.ActiveKey]; !ok {
+			return nil, errors.Errorf("active key %q of tenant %s not found", tk.ActiveKey, tenant)
+		}
+		p.keys[tenant] = make(map[string][]byte, len(tk.Keys))
+		for id, encoded := range tk.Keys {
+			key, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding key %q of tenant %s", id, tenant)
+			}
+			if len(key) != keySize {
+				return nil, errors.Errorf("key %q of tenant %s must be %d bytes long", id, tenant, keySize)
+			}
+			p.keys[tenant][id] = key
+		}
+		p.active[tenant] = tk.ActiveKey
+	}
+	return p, nil
+}
+
+// WrapKey implements KeyProvider.
+func (p *FileProvider) WrapKey(_ context.Context, tenant string, dek []byte) (string, []byte, error) {
+	keyID, ok := p.active[tenant]
+	if !ok {
+		return "", nil, ErrNoKey
+	}
+
+	aead, err := newAEAD(p.keys[tenant][keyID])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return keyID, aead.Seal(nonce, nonce, dek, []byte(tenant)), nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (p *FileProvider) UnwrapKey(_ context.Context, tenant, keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[tenant][keyID]
+	if !ok {
+		return nil, errors.Errorf("key %q of tenant %s not found", keyID, tenant)
+	}
+
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("truncated wrapped key")
+	}
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(tenant))
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"cloud.google.com/go/storage"
@@ -160,9 +161,63 @@ func (s *GCSObjectClient) getObject(ctx context.Context, objectKey string) (rc i
 	return reader, reader.Attrs.Size, nil
 }
 
+// GetObjectRange returns a reader for the specified range of the object from the configured GCS bucket.
+func (s *GCSObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var cancel context.CancelFunc = func() {}
+	if s.cfg.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.RequestTimeout)
+	}
+
+	// A negative length reads until the end of the object, as expected by GetObjectRange.
+	rc, err := s.getsBuckets.Object(objectKey).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		// cancel the context if there is an error.
+		cancel()
+		return nil, err
+	}
+	// else return a wrapped ReadCloser which cancels the context while closing the reader.
+	return util.NewReadCloserWithContextCancelFunc(rc, cancel), nil
+}
+
 // PutObject puts the specified bytes into the configured GCS bucket at the provided key
 func (s *GCSObjectClient) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
-	writer := s.defaultBucket.Object(objectKey).NewWriter(ctx)
+	_, err := s.putObject(ctx, s.defaultBucket.Object(objectKey), object)
+	return err
+}
+
+// PutObjectIf puts the specified bytes into the configured GCS bucket at the provided key if cond holds.
+// The version of GCS objects is their generation.
+func (s *GCSObjectClient) PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	if err := cond.Validate(); err != nil {
+		return "", err
+	}
+
+	conds := storage.Conditions{DoesNotExist: true}
+	if cond.IfMatch != "" {
+		generation, err := strconv.ParseInt(cond.IfMatch, 10, 64)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid gcs object generation %q", cond.IfMatch)
+		}
+		conds = storage.Conditions{GenerationMatch: generation}
+	}
+
+	attrs, err := s.putObject(ctx, s.defaultBucket.Object(objectKey).If(conds), object)
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusPreconditionFailed {
+		return "", errors.Wrapf(client.ErrPreconditionFailed, "put gcs object %s", objectKey)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(attrs.Generation, 10), nil
+}
+
+func (s *GCSObjectClient) putObject(ctx context.Context, obj *storage.ObjectHandle, object io.Reader) (*storage.ObjectAttrs, error) {
+	writer := obj.NewWriter(ctx)
 	// Default GCSChunkSize is 8M and for each call, 8M is allocated xD
 	// By setting it to 0, we just upload the object in a single a request
 	// which should work for our chunk sizes.
@@ -170,9 +225,21 @@ func (s *GCSObjectClient) PutObject(ctx context.Context, objectKey string, objec
 
 	if _, err := io.Copy(writer, object); err != nil {
 		_ = writer.Close()
-		return err
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return writer.Attrs(), nil
+}
+
+// ObjectVersion returns the generation of the object.
+func (s *GCSObjectClient) ObjectVersion(ctx context.Context, objectKey string) (string, error) {
+	attrs, err := s.defaultBucket.Object(objectKey).Attrs(ctx)
+	if err != nil {
+		return "", err
 	}
-	return writer.Close()
+	return strconv.FormatInt(attrs.Generation, 10), nil
 }
 
 // List implements chunk.ObjectClient.
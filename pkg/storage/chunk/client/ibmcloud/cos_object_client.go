@@ -368,6 +368,40 @@ func (c *COSObjectClient) GetObject(ctx context.Context, objectKey string) (io.R
 	return nil, 0, errors.Wrap(err, "failed to get cos object")
 }
 
+// GetObjectRange returns a reader for the specified range of the object from the configured S3 bucket.
+func (c *COSObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var resp *cos.GetObjectOutput
+
+	// Map the key into a bucket
+	bucket := c.bucketFromKey(objectKey)
+
+	retries := backoff.New(ctx, c.cfg.BackoffConfig)
+	err := ctx.Err()
+	for retries.Ongoing() {
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(ctx.Err(), "ctx related error during cos getObjectRange")
+		}
+		err = instrument.CollectedRequest(ctx, "COS.GetObjectRange", cosRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+			var requestErr error
+			resp, requestErr = c.hedgedCOS.GetObjectWithContext(ctx, &cos.GetObjectInput{
+				Bucket: ibm.String(bucket),
+				Key:    ibm.String(objectKey),
+				Range:  ibm.String(client.HTTPRange(offset, length)),
+			})
+			return requestErr
+		})
+		if err == nil && resp.Body != nil {
+			return resp.Body, nil
+		}
+		retries.Wait()
+	}
+	return nil, errors.Wrap(err, "failed to get cos object range")
+}
+
 // PutObject into the store
 func (c *COSObjectClient) PutObject(ctx context.Context, objectKey string, object io.Reader) error {
 	return instrument.CollectedRequest(ctx, "COS.PutObject", cosRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
@@ -386,6 +420,16 @@ func (c *COSObjectClient) PutObject(ctx context.Context, objectKey string, objec
 	})
 }
 
+// PutObjectIf is not supported by COS.
+func (*COSObjectClient) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
+// ObjectVersion is not supported by COS.
+func (*COSObjectClient) ObjectVersion(context.Context, string) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
 // List implements chunk.ObjectClient.
 func (c *COSObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	var storageObjects []client.StorageObject
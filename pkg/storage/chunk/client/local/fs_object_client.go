@@ -2,11 +2,14 @@ package local
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -44,6 +47,10 @@ func (cfg *FSConfig) ToCortexLocalConfig() local.Config {
 type FSObjectClient struct {
 	cfg           FSConfig
 	pathSeparator string
+
+	// putIfMtx serialises the conditional writes, which check the version of
+	// the object before writing it.
+	putIfMtx sync.Mutex
 }
 
 // NewFSObjectClient makes a chunk.Client which stores chunks as files in the local filesystem.
@@ -63,7 +70,7 @@ func NewFSObjectClient(cfg FSConfig) (*FSObjectClient, error) {
 }
 
 // Stop implements ObjectClient
-func (FSObjectClient) Stop() {}
+func (*FSObjectClient) Stop() {}
 
 func (f *FSObjectClient) ObjectExists(_ context.Context, objectKey string) (bool, error) {
 	fullPath := filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey))
@@ -88,6 +95,29 @@ func (f *FSObjectClient) GetObject(_ context.Context, objectKey string) (io.Read
 	return fl, stats.Size(), nil
 }
 
+// GetObjectRange from the store
+func (f *FSObjectClient) GetObjectRange(_ context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	fl, err := os.Open(filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey)))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fl.Seek(offset, io.SeekStart); err != nil {
+		_ = fl.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return fl, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(fl, length), fl}, nil
+}
+
 // PutObject into the store
 func (f *FSObjectClient) PutObject(_ context.Context, objectKey string, object io.Reader) error {
 	fullPath := filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey))
@@ -116,6 +146,99 @@ func (f *FSObjectClient) PutObject(_ context.Context, objectKey string, object i
 	return fl.Close()
 }
 
+// PutObjectIf puts the object into the store if cond holds. The version of
+// the files is the SHA-256 of their content.
+// The object is written to a temporary file first, which then replaces the
+// file of the object, so readers never see partially written objects.
+// IfMatch conditions are only checked atomically against the writes of this
+// client, while IfNoneMatch conditions are also checked against other processes.
+func (f *FSObjectClient) PutObjectIf(_ context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	if err := cond.Validate(); err != nil {
+		return "", err
+	}
+
+	f.putIfMtx.Lock()
+	defer f.putIfMtx.Unlock()
+
+	fullPath := filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey))
+	if cond.IfMatch != "" {
+		version, err := fileVersion(fullPath)
+		if os.IsNotExist(err) {
+			return "", errors.Wrapf(client.ErrPreconditionFailed, "put file %s", objectKey)
+		}
+		if err != nil {
+			return "", err
+		}
+		if version != cond.IfMatch {
+			return "", errors.Wrapf(client.ErrPreconditionFailed, "put file %s", objectKey)
+		}
+	}
+
+	if err := util.EnsureDirectory(filepath.Dir(fullPath)); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), "."+filepath.Base(fullPath)+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// the temporary file is gone already if it replaced the file of the object
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), object); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if cond.IfNoneMatch {
+		// Linking fails if the file exists, even if another process created it.
+		err = os.Link(tmp.Name(), fullPath)
+		if os.IsExist(err) {
+			return "", errors.Wrapf(client.ErrPreconditionFailed, "put file %s", objectKey)
+		}
+	} else {
+		err = os.Rename(tmp.Name(), fullPath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ObjectVersion returns the SHA-256 of the content of the file.
+func (f *FSObjectClient) ObjectVersion(_ context.Context, objectKey string) (string, error) {
+	return fileVersion(filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey)))
+}
+
+func fileVersion(path string) (string, error) {
+	fl, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fl.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, fl); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // List implements chunk.ObjectClient.
 // FSObjectClient assumes that prefix is a directory, and only supports "" and "/" delimiters.
 func (f *FSObjectClient) List(_ context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
@@ -3,6 +3,7 @@ package local
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 )
 
@@ -203,3 +205,96 @@ func TestFSObjectClient_DeleteObject(t *testing.T) {
 	require.Len(t, commonPrefixes, 0)
 	require.Len(t, files, len(foldersWithFiles["folder2/"]))*/
 }
+
+func TestFSObjectClient_GetObjectRange(t *testing.T) {
+	bucketClient, err := NewFSObjectClient(FSConfig{
+		Directory: t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, bucketClient.PutObject(ctx, "folder/file", strings.NewReader("0123456789")))
+
+	for _, tc := range []struct {
+		name           string
+		offset, length int64
+		expected       string
+	}{
+		{name: "whole object", offset: 0, length: -1, expected: "0123456789"},
+		{name: "to the end", offset: 4, length: -1, expected: "456789"},
+		{name: "range", offset: 2, length: 3, expected: "234"},
+		{name: "range past the end", offset: 8, length: 5, expected: "89"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, get := range []func() (io.ReadCloser, error){
+				func() (io.ReadCloser, error) {
+					return bucketClient.GetObjectRange(ctx, "folder/file", tc.offset, tc.length)
+				},
+				func() (io.ReadCloser, error) {
+					return client.GetObjectRangeFallback(ctx, bucketClient, "folder/file", tc.offset, tc.length)
+				},
+			} {
+				rc, err := get()
+				require.NoError(t, err)
+				b, err := io.ReadAll(rc)
+				require.NoError(t, err)
+				require.NoError(t, rc.Close())
+				require.Equal(t, tc.expected, string(b))
+			}
+		})
+	}
+
+	_, err = bucketClient.GetObjectRange(ctx, "folder/file", 0, 0)
+	require.Error(t, err)
+	_, err = bucketClient.GetObjectRange(ctx, "folder/missing", 0, -1)
+	require.True(t, bucketClient.IsObjectNotFoundErr(err))
+	_, err = client.GetObjectRangeFallback(ctx, bucketClient, "folder/file", 20, -1)
+	require.Error(t, err)
+}
+
+func TestFSObjectClient_PutObjectIf(t *testing.T) {
+	dir := t.TempDir()
+	bucketClient, err := NewFSObjectClient(FSConfig{
+		Directory: dir,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = bucketClient.ObjectVersion(ctx, "folder/file")
+	require.True(t, bucketClient.IsObjectNotFoundErr(err))
+	_, err = bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v1"), client.WriteCondition{IfMatch: "missing"})
+	require.ErrorIs(t, err, client.ErrPreconditionFailed)
+
+	v1, err := bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v1"), client.WriteCondition{IfNoneMatch: true})
+	require.NoError(t, err)
+	version, err := bucketClient.ObjectVersion(ctx, "folder/file")
+	require.NoError(t, err)
+	require.Equal(t, v1, version)
+
+	// the file exists already
+	_, err = bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v2"), client.WriteCondition{IfNoneMatch: true})
+	require.ErrorIs(t, err, client.ErrPreconditionFailed)
+
+	v2, err := bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v2"), client.WriteCondition{IfMatch: v1})
+	require.NoError(t, err)
+	require.NotEqual(t, v1, v2)
+
+	// the file was modified since v1
+	_, err = bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v3"), client.WriteCondition{IfMatch: v1})
+	require.ErrorIs(t, err, client.ErrPreconditionFailed)
+
+	rc, _, err := bucketClient.GetObject(ctx, "folder/file")
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, "v2", string(b))
+
+	_, err = bucketClient.PutObjectIf(ctx, "folder/file", strings.NewReader("v3"), client.WriteCondition{})
+	require.Error(t, err)
+
+	// no temporary files are left behind
+	files, err := os.ReadDir(filepath.Join(dir, "folder"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"fmt"
 	"io"
 	"strings"
 	"time"
@@ -22,6 +23,19 @@ type ObjectClient interface {
 	PutObject(ctx context.Context, objectKey string, object io.Reader) error
 	// NOTE: The consumer of GetObject should always call the Close method when it is done reading which otherwise could cause a resource leak.
 	GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error)
+	// GetObjectRange returns a reader for length bytes of the object, starting at offset.
+	// A negative length reads the object until its end.
+	// NOTE: The consumer of GetObjectRange should always call the Close method when it is done reading which otherwise could cause a resource leak.
+	GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error)
+	// PutObjectIf puts the object only if cond holds for the object currently stored at objectKey,
+	// and returns the version of the written object.
+	// It returns ErrPreconditionFailed if cond doesn't hold, and ErrMethodNotImplemented
+	// if the object store doesn't support conditional writes.
+	PutObjectIf(ctx context.Context, objectKey string, object io.Reader, cond WriteCondition) (string, error)
+	// ObjectVersion returns the version of the object, which changes every time the object is written.
+	// Reading the version before the content of the object makes a later PutObjectIf
+	// with that version fail if the object was written in between.
+	ObjectVersion(ctx context.Context, objectKey string) (string, error)
 
 	// List objects with given prefix.
 	//
@@ -50,6 +64,85 @@ type StorageObject struct {
 // It is guaranteed to always end with delimiter passed to List method.
 type StorageCommonPrefix string
 
+// WriteCondition is the precondition of a conditional object write.
+// Exactly one of its fields must be set.
+type WriteCondition struct {
+	// IfNoneMatch only writes the object if it doesn't exist yet.
+	IfNoneMatch bool
+	// IfMatch only writes the object if its current version is IfMatch,
+	// as returned by ObjectVersion or PutObjectIf.
+	IfMatch string
+}
+
+// Validate returns an error if the condition is invalid.
+func (c WriteCondition) Validate() error {
+	if c.IfNoneMatch == (c.IfMatch != "") {
+		return errors.New("invalid write condition, exactly one of IfNoneMatch and IfMatch must be set")
+	}
+	return nil
+}
+
+type objectTenantKey struct{}
+
+// InjectObjectTenant returns a context which tells the object clients that the objects
+// read and written with it belong to tenant. Object clients that treat the objects of
+// every tenant differently, like client-side encryption, rely on it.
+func InjectObjectTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, objectTenantKey{}, tenant)
+}
+
+// ExtractObjectTenant returns the tenant injected with InjectObjectTenant.
+func ExtractObjectTenant(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(objectTenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// ValidateObjectRange returns an error if the range of an object read is invalid.
+func ValidateObjectRange(offset, length int64) error {
+	if offset < 0 {
+		return errors.Errorf("invalid object range offset %d, must not be negative", offset)
+	}
+	if length == 0 {
+		return errors.New("invalid object range length 0")
+	}
+	return nil
+}
+
+// HTTPRange returns the value of the HTTP Range header requesting a range of an object.
+func HTTPRange(offset, length int64) string {
+	if length < 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// GetObjectRangeFallback reads a range of an object by reading the whole object up to the end of the range.
+// It is used by the object clients whose backend doesn't support range reads.
+func GetObjectRangeFallback(ctx context.Context, c ObjectClient, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	rc, _, err := c.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+		_ = rc.Close()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Errorf("object range offset %d is past the end of object %s", offset, objectKey)
+		}
+		return nil, err
+	}
+	if length < 0 {
+		return rc, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, length), rc}, nil
+}
+
 // KeyEncoder is used to encode chunk keys before writing/retrieving chunks
 // from the underlying ObjectClient
 // Schema/Chunk are passed as arguments to allow this to improve over revisions
@@ -133,7 +226,7 @@ func (o *client) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
 	incomingErrors := make(chan error)
 	for i := range chunkBufs {
 		go func(i int) {
-			incomingErrors <- o.store.PutObject(ctx, chunkKeys[i], bytes.NewReader(chunkBufs[i]))
+			incomingErrors <- o.store.PutObject(InjectObjectTenant(ctx, chunks[i].UserID), chunkKeys[i], bytes.NewReader(chunkBufs[i]))
 		}(i)
 	}
 
@@ -166,7 +259,7 @@ func (o *client) getChunk(ctx context.Context, decodeContext *chunk.DecodeContex
 		key = o.keyEncoder(o.schema, c)
 	}
 
-	readCloser, size, err := o.store.GetObject(ctx, key)
+	readCloser, size, err := o.store.GetObject(InjectObjectTenant(ctx, c.UserID), key)
 	if err != nil {
 		return chunk.Chunk{}, errors.WithStack(errors.Wrapf(err, "failed to load chunk '%s'", key))
 	}
@@ -185,7 +278,7 @@ func (o *client) getChunk(ctx context.Context, decodeContext *chunk.DecodeContex
 	}
 
 	if err := c.Decode(decodeContext, buf.Bytes()); err != nil {
-		return chunk.Chunk{}, errors.WithStack(err)
+		return chunk.Chunk{}, errors.WithStack(&chunk.DecodeError{Err: err})
 	}
 	return c, nil
 }
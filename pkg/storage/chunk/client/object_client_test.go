@@ -81,3 +81,24 @@ func TestFSEncoder(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRange(t *testing.T) {
+	require.Equal(t, "bytes=0-", HTTPRange(0, -1))
+	require.Equal(t, "bytes=10-", HTTPRange(10, -1))
+	require.Equal(t, "bytes=10-19", HTTPRange(10, 10))
+	require.Equal(t, "bytes=0-0", HTTPRange(0, 1))
+}
+
+func TestValidateObjectRange(t *testing.T) {
+	require.NoError(t, ValidateObjectRange(0, -1))
+	require.NoError(t, ValidateObjectRange(5, 10))
+	require.Error(t, ValidateObjectRange(-1, 10))
+	require.Error(t, ValidateObjectRange(5, 0))
+}
+
+func TestWriteCondition_Validate(t *testing.T) {
+	require.NoError(t, WriteCondition{IfNoneMatch: true}.Validate())
+	require.NoError(t, WriteCondition{IfMatch: "v1"}.Validate())
+	require.Error(t, WriteCondition{}.Validate())
+	require.Error(t, WriteCondition{IfNoneMatch: true, IfMatch: "v1"}.Validate())
+}
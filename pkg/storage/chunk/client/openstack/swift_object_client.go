@@ -144,12 +144,37 @@ func (s *SwiftObjectClient) GetObject(_ context.Context, objectKey string) (io.R
 	return io.NopCloser(&buf), int64(buf.Len()), nil
 }
 
+// GetObjectRange returns a reader for the specified range of the object from the configured swift container.
+func (s *SwiftObjectClient) GetObjectRange(_ context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	if err := client.ValidateObjectRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	_, err := s.hedgingConn.ObjectGet(s.cfg.ContainerName, objectKey, &buf, false, swift.Headers{"Range": client.HTTPRange(offset, length)})
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
 // PutObject puts the specified bytes into the configured Swift container at the provided key
 func (s *SwiftObjectClient) PutObject(_ context.Context, objectKey string, object io.Reader) error {
 	_, err := s.conn.ObjectPut(s.cfg.ContainerName, objectKey, object, false, "", "", nil)
 	return err
 }
 
+// PutObjectIf is not supported by Swift.
+func (*SwiftObjectClient) PutObjectIf(context.Context, string, io.Reader, client.WriteCondition) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
+// ObjectVersion is not supported by Swift.
+func (*SwiftObjectClient) ObjectVersion(context.Context, string) (string, error) {
+	return "", client.ErrMethodNotImplemented
+}
+
 // List only objects from the store non-recursively
 func (s *SwiftObjectClient) List(_ context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
 	if len(delimiter) > 1 {
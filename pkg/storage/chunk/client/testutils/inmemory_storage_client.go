@@ -3,6 +3,8 @@ package testutils
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -425,6 +427,11 @@ func (m *InMemoryObjectClient) GetObject(_ context.Context, objectKey string) (i
 	return io.NopCloser(bytes.NewReader(buf)), int64(len(buf)), nil
 }
 
+// GetObjectRange implements client.ObjectClient.
+func (m *InMemoryObjectClient) GetObjectRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error) {
+	return client.GetObjectRangeFallback(ctx, m, objectKey, offset, length)
+}
+
 // PutObject implements client.ObjectClient.
 func (m *InMemoryObjectClient) PutObject(_ context.Context, objectKey string, object io.Reader) error {
 	buf, err := io.ReadAll(object)
@@ -443,6 +450,54 @@ func (m *InMemoryObjectClient) PutObject(_ context.Context, objectKey string, ob
 	return nil
 }
 
+// PutObjectIf implements client.ObjectClient. The version of the objects is the SHA-256 of their content.
+func (m *InMemoryObjectClient) PutObjectIf(_ context.Context, objectKey string, object io.Reader, cond client.WriteCondition) (string, error) {
+	if err := cond.Validate(); err != nil {
+		return "", err
+	}
+
+	buf, err := io.ReadAll(object)
+	if err != nil {
+		return "", err
+	}
+
+	if m.mode == MockStorageModeReadOnly {
+		return "", errPermissionDenied
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	current, ok := m.objects[objectKey]
+	if cond.IfNoneMatch && ok || cond.IfMatch != "" && (!ok || objectVersion(current) != cond.IfMatch) {
+		return "", client.ErrPreconditionFailed
+	}
+
+	m.objects[objectKey] = buf
+	return objectVersion(buf), nil
+}
+
+// ObjectVersion implements client.ObjectClient.
+func (m *InMemoryObjectClient) ObjectVersion(_ context.Context, objectKey string) (string, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if m.mode == MockStorageModeWriteOnly {
+		return "", errPermissionDenied
+	}
+
+	buf, ok := m.objects[objectKey]
+	if !ok {
+		return "", errStorageObjectNotFound
+	}
+	return objectVersion(buf), nil
+}
+
+func objectVersion(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
 // IsObjectNotFoundErr implements client.ObjectClient.
 func (m *InMemoryObjectClient) IsObjectNotFoundErr(err error) bool {
 	return errors.Is(err, errStorageObjectNotFound)
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+)
+
+// TieredClient reads chunks from two storage tiers: the hot tier new chunks
+// are written to, and the cold tier the compactor moves old chunks to.
+// Chunks are looked up in the tier they most likely live in first, and in the
+// other tier if they are not found there, so moving a chunk between the tiers
+// is transparent for readers.
+type TieredClient struct {
+	hot, cold Client
+
+	// coldFirst tells whether a chunk is expected to have been moved to the cold tier already.
+	coldFirst func(chunk.Chunk) bool
+}
+
+// NewTieredClient returns a Client reading from both the hot and the cold tier.
+// coldFirst may be nil, in which case chunks are always looked up in the hot tier first.
+func NewTieredClient(hot, cold Client, coldFirst func(chunk.Chunk) bool) *TieredClient {
+	return &TieredClient{
+		hot:       hot,
+		cold:      cold,
+		coldFirst: coldFirst,
+	}
+}
+
+func (t *TieredClient) Stop() {
+	t.hot.Stop()
+	t.cold.Stop()
+}
+
+// PutChunks implements Client. Chunks are always written to the hot tier.
+func (t *TieredClient) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
+	return t.hot.PutChunks(ctx, chunks)
+}
+
+// GetChunks implements Client.
+func (t *TieredClient) GetChunks(ctx context.Context, chunks []chunk.Chunk) ([]chunk.Chunk, error) {
+	var hotChunks, coldChunks []chunk.Chunk
+	for _, c := range chunks {
+		if t.coldFirst != nil && t.coldFirst(c) {
+			coldChunks = append(coldChunks, c)
+		} else {
+			hotChunks = append(hotChunks, c)
+		}
+	}
+
+	result, err := getChunksWithFallback(ctx, t.hot, t.cold, hotChunks)
+	if err != nil {
+		return result, err
+	}
+	fetched, err := getChunksWithFallback(ctx, t.cold, t.hot, coldChunks)
+	return append(result, fetched...), err
+}
+
+// getChunksWithFallback fetches chunks from the primary client and the ones not found there from the secondary client.
+func getChunksWithFallback(ctx context.Context, primary, secondary Client, chunks []chunk.Chunk) ([]chunk.Chunk, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	fetched, err := primary.GetChunks(ctx, chunks)
+	if err == nil || !isChunkNotFoundErr(primary, err) {
+		return fetched, err
+	}
+
+	found := make(map[logproto.ChunkRef]struct{}, len(fetched))
+	for _, c := range fetched {
+		found[c.ChunkRef] = struct{}{}
+	}
+	missing := make([]chunk.Chunk, 0, len(chunks)-len(fetched))
+	for _, c := range chunks {
+		if _, ok := found[c.ChunkRef]; !ok {
+			missing = append(missing, c)
+		}
+	}
+
+	fallback, err := secondary.GetChunks(ctx, missing)
+	return append(fetched, fallback...), err
+}
+
+// DeleteChunk implements Client. The chunk is deleted from both tiers.
+func (t *TieredClient) DeleteChunk(ctx context.Context, userID, chunkID string) error {
+	hotErr := t.hot.DeleteChunk(ctx, userID, chunkID)
+	coldErr := t.cold.DeleteChunk(ctx, userID, chunkID)
+
+	switch {
+	case hotErr != nil && !isChunkNotFoundErr(t.hot, hotErr):
+		return hotErr
+	case coldErr != nil && !isChunkNotFoundErr(t.cold, coldErr):
+		return coldErr
+	case hotErr != nil && coldErr != nil:
+		// not found in either tier
+		return hotErr
+	default:
+		return nil
+	}
+}
+
+func (t *TieredClient) IsChunkNotFoundErr(err error) bool {
+	return isChunkNotFoundErr(t.hot, err) || isChunkNotFoundErr(t.cold, err)
+}
+
+func (t *TieredClient) IsRetryableErr(err error) bool {
+	return t.hot.IsRetryableErr(err) || t.cold.IsRetryableErr(err)
+}
+
+// isChunkNotFoundErr also checks the cause of err, as fetch errors are wrapped with the key of the chunk.
+func isChunkNotFoundErr(c Client, err error) bool {
+	return c.IsChunkNotFoundErr(err) || c.IsChunkNotFoundErr(errors.Cause(err))
+}
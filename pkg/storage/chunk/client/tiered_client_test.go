@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+)
+
+type mockTierClient struct {
+	mtx    sync.Mutex
+	chunks map[logproto.ChunkRef]chunk.Chunk
+	gets   int
+}
+
+func newMockTierClient(chunks ...chunk.Chunk) *mockTierClient {
+	c := &mockTierClient{chunks: map[logproto.ChunkRef]chunk.Chunk{}}
+	for _, chk := range chunks {
+		c.chunks[chk.ChunkRef] = chk
+	}
+	return c
+}
+
+func (m *mockTierClient) Stop() {}
+
+func (m *mockTierClient) PutChunks(_ context.Context, chunks []chunk.Chunk) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for _, chk := range chunks {
+		m.chunks[chk.ChunkRef] = chk
+	}
+	return nil
+}
+
+func (m *mockTierClient) GetChunks(_ context.Context, chunks []chunk.Chunk) ([]chunk.Chunk, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.gets++
+
+	var (
+		result []chunk.Chunk
+		err    error
+	)
+	for _, chk := range chunks {
+		found, ok := m.chunks[chk.ChunkRef]
+		if !ok {
+			err = errors.Wrapf(ErrStorageObjectNotFound, "failed to load chunk %d", chk.Fingerprint)
+			continue
+		}
+		result = append(result, found)
+	}
+	return result, err
+}
+
+func (m *mockTierClient) DeleteChunk(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *mockTierClient) IsChunkNotFoundErr(err error) bool {
+	return err == ErrStorageObjectNotFound
+}
+
+func (m *mockTierClient) IsRetryableErr(_ error) bool {
+	return false
+}
+
+func testChunk(fp uint64) chunk.Chunk {
+	return chunk.Chunk{ChunkRef: logproto.ChunkRef{UserID: "fake", Fingerprint: fp, From: 1, Through: 2}}
+}
+
+func TestTieredClient_GetChunks(t *testing.T) {
+	hotChunk, coldChunk, missingChunk := testChunk(1), testChunk(2), testChunk(3)
+
+	for name, coldFirst := range map[string]func(chunk.Chunk) bool{
+		"hot first":  nil,
+		"cold first": func(chunk.Chunk) bool { return true },
+	} {
+		t.Run(name, func(t *testing.T) {
+			hot, cold := newMockTierClient(hotChunk), newMockTierClient(coldChunk)
+			c := NewTieredClient(hot, cold, coldFirst)
+
+			chunks, err := c.GetChunks(context.Background(), []chunk.Chunk{hotChunk, coldChunk})
+			require.NoError(t, err)
+			require.ElementsMatch(t, []chunk.Chunk{hotChunk, coldChunk}, chunks)
+			require.Equal(t, 1, hot.gets)
+			require.Equal(t, 1, cold.gets)
+
+			_, err = c.GetChunks(context.Background(), []chunk.Chunk{hotChunk, missingChunk})
+			require.Error(t, err)
+			require.True(t, c.IsChunkNotFoundErr(errors.Cause(err)))
+		})
+	}
+}
+
+func TestTieredClient_GetChunksFromPredictedTier(t *testing.T) {
+	hotChunk, coldChunk := testChunk(1), testChunk(2)
+	hot, cold := newMockTierClient(hotChunk), newMockTierClient(coldChunk)
+	c := NewTieredClient(hot, cold, func(c chunk.Chunk) bool { return c.Fingerprint == coldChunk.Fingerprint })
+
+	// chunks found in the tier they are expected in are not looked up in the other tier
+	chunks, err := c.GetChunks(context.Background(), []chunk.Chunk{hotChunk})
+	require.NoError(t, err)
+	require.Equal(t, []chunk.Chunk{hotChunk}, chunks)
+	chunks, err = c.GetChunks(context.Background(), []chunk.Chunk{coldChunk})
+	require.NoError(t, err)
+	require.Equal(t, []chunk.Chunk{coldChunk}, chunks)
+	require.Equal(t, 1, hot.gets)
+	require.Equal(t, 1, cold.gets)
+}
+
+func TestTieredClient_PutChunks(t *testing.T) {
+	hot, cold := newMockTierClient(), newMockTierClient()
+	c := NewTieredClient(hot, cold, nil)
+
+	require.NoError(t, c.PutChunks(context.Background(), []chunk.Chunk{testChunk(1)}))
+	require.Len(t, hot.chunks, 1)
+	require.Len(t, cold.chunks, 0)
+}
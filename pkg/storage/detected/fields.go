@@ -6,6 +6,7 @@ import (
 	"github.com/axiomhq/hyperloglog"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
 type UnmarshaledDetectedField struct {
@@ -97,3 +98,50 @@ func MergeFields(
 
 	return result, nil
 }
+
+// MaskFields applies the field masking rules to the detected fields.
+// Dropped fields are removed and redacted fields have a single value. Hashed fields
+// keep their cardinality. All masked fields are reported as strings.
+func MaskFields(fields []*logproto.DetectedField, m *log.FieldMasker) ([]*logproto.DetectedField, error) {
+	if m == nil {
+		return fields, nil
+	}
+
+	result := make([]*logproto.DetectedField, 0, len(fields))
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		action, ok := m.Action(field.Label)
+		if !ok {
+			result = append(result, field)
+			continue
+		}
+
+		switch action {
+		case log.MaskDrop:
+			continue
+		case log.MaskRedact:
+			sketch := hyperloglog.New()
+			sketch.Insert([]byte(log.RedactedValue))
+			marshaled, err := sketch.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			field = &logproto.DetectedField{
+				Label:       field.Label,
+				Type:        logproto.DetectedFieldString,
+				Cardinality: 1,
+				Parsers:     field.Parsers,
+				Sketch:      marshaled,
+			}
+		default:
+			masked := *field
+			masked.Type = logproto.DetectedFieldString
+			field = &masked
+		}
+		result = append(result, field)
+	}
+	return result, nil
+}
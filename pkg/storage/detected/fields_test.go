@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
 func Test_MergeFields(t *testing.T) {
@@ -113,3 +114,47 @@ func Test_MergeFields(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func Test_MaskFields(t *testing.T) {
+	sketch := hyperloglog.New()
+	sketch.Insert([]byte("a"))
+	sketch.Insert([]byte("b"))
+	marshalledSketch, err := sketch.MarshalBinary()
+	require.NoError(t, err)
+
+	fields := []*logproto.DetectedField{
+		{Label: "level", Type: logproto.DetectedFieldString, Cardinality: 2, Sketch: marshalledSketch},
+		{Label: "user_id", Type: logproto.DetectedFieldInt, Cardinality: 2, Sketch: marshalledSketch},
+		{Label: "email", Type: logproto.DetectedFieldString, Cardinality: 2, Sketch: marshalledSketch, Parsers: []string{"json"}},
+		{Label: "token", Type: logproto.DetectedFieldString, Cardinality: 2, Sketch: marshalledSketch},
+		nil,
+	}
+
+	unmasked, err := MaskFields(fields, nil)
+	require.NoError(t, err)
+	require.Equal(t, fields, unmasked)
+
+	m, err := log.NewFieldMasker([]log.FieldMaskRule{
+		{Field: "user_id", Action: log.MaskHash},
+		{Field: "email", Action: log.MaskRedact},
+		{Field: "token", Action: log.MaskDrop},
+	})
+	require.NoError(t, err)
+
+	masked, err := MaskFields(fields, m)
+	require.NoError(t, err)
+	require.Len(t, masked, 3)
+	require.Equal(t, fields[0], masked[0])
+
+	require.Equal(t, "user_id", masked[1].Label)
+	require.Equal(t, logproto.DetectedFieldString, masked[1].Type)
+	require.Equal(t, uint64(2), masked[1].Cardinality)
+	require.Equal(t, logproto.DetectedFieldInt, fields[1].Type)
+
+	require.Equal(t, "email", masked[2].Label)
+	require.Equal(t, uint64(1), masked[2].Cardinality)
+	require.Equal(t, []string{"json"}, masked[2].Parsers)
+	merged, err := UnmarshalDetectedField(masked[2])
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), merged.Sketch.Estimate())
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/baidubce"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/cassandra"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/congestion"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/encryption"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/gcp"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/grpc"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hedging"
@@ -69,6 +70,7 @@ type StoreLimits interface {
 	stores.StoreLimits
 	indexgateway.Limits
 	CardinalityLimit(string) int
+	StorageTieringAge(string) time.Duration
 }
 
 // Storage configs defined as Named stores don't get any defaults as they do not
@@ -288,6 +290,8 @@ type Config struct {
 	IndexCacheValidity     time.Duration             `yaml:"index_cache_validity"`
 	CongestionControl      congestion.Config         `yaml:"congestion_control,omitempty"`
 	ObjectPrefix           string                    `yaml:"object_prefix" doc:"description=Experimental. Sets a constant prefix for all keys inserted into object storage. Example: loki/"`
+	Encryption             encryption.Config         `yaml:"encryption" category:"experimental"`
+	TieringObjectStore     string                    `yaml:"tiering_object_store" category:"experimental"`
 
 	IndexQueriesCacheConfig  cache.Config `yaml:"index_queries_cache_config"`
 	DisableBroadIndexQueries bool         `yaml:"disable_broad_index_queries"`
@@ -319,6 +323,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.GrpcConfig.RegisterFlags(f)
 	cfg.Hedging.RegisterFlagsWithPrefix("store.", f)
 	cfg.CongestionControl.RegisterFlagsWithPrefix("store.", f)
+	cfg.Encryption.RegisterFlagsWithPrefix("store.", f)
+	f.StringVar(&cfg.TieringObjectStore, "store.tiering-object-store", "", "Experimental. Object store the compactor moves chunks older than the per-tenant storage_tiering_age to, for example a named store using a different bucket or a cheaper storage class. Chunks are read transparently from both the period's object store and the tiering object store. Chunks of periods whose object_store is the tiering object store are not moved. The compactor requires retention_enabled to move chunks.")
 
 	cfg.IndexQueriesCacheConfig.RegisterFlagsWithPrefix("store.index-cache-read.", "", f)
 	f.DurationVar(&cfg.IndexCacheValidity, "store.index-cache-validity", 5*time.Minute, "Cache validity for active index entries. Should be no higher than -ingester.max-chunk-idle.")
@@ -357,6 +363,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.BloomShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid bloom shipper config")
 	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid encryption config")
+	}
 
 	return cfg.NamedStores.Validate()
 }
@@ -602,12 +611,19 @@ func NewObjectClient(name string, cfg Config, clientMetrics ClientMetrics) (clie
 		return nil, err
 	}
 
-	if cfg.ObjectPrefix == "" {
-		return actual, nil
-	} else {
+	if cfg.ObjectPrefix != "" {
 		prefix := strings.Trim(cfg.ObjectPrefix, "/") + "/"
-		return client.NewPrefixedObjectClient(actual, prefix), nil
+		actual = client.NewPrefixedObjectClient(actual, prefix)
+	}
+
+	if cfg.Encryption.Enabled {
+		provider, err := encryption.NewKeyProvider(cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		actual = encryption.NewObjectClient(actual, provider)
 	}
+	return actual, nil
 }
 
 // internalNewObjectClient makes the underlying StorageClient of the desired types.
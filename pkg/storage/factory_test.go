@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
 	"os"
 	"path"
 	"testing"
@@ -281,6 +285,41 @@ func TestNewObjectClient_prefixing(t *testing.T) {
 	})
 }
 
+func TestNewObjectClient_encryptionWithPrefix(t *testing.T) {
+	dir := t.TempDir()
+	keysPath := path.Join(dir, "keys.yaml")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, os.WriteFile(keysPath, []byte("tenants:\n  tenant-a:\n    active_key: \"1\"\n    keys:\n      \"1\": "+key+"\n"), 0o600))
+
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	cfg.FSConfig.Directory = path.Join(dir, "chunks")
+	cfg.ObjectPrefix = "my/prefix"
+	cfg.Encryption.Enabled = true
+	cfg.Encryption.File.Path = keysPath
+
+	objectClient, err := NewObjectClient(types.StorageTypeFileSystem, cfg, cm)
+	require.NoError(t, err)
+
+	ctx := client.InjectObjectTenant(context.Background(), "tenant-a")
+	data := []byte("some chunk data")
+	for _, objectKey := range []string{"tenant-a/fp/0:1:2", "index/index_1/tenant-a/file.tsdb.gz"} {
+		require.NoError(t, objectClient.PutObject(ctx, objectKey, bytes.NewReader(data)))
+
+		// the object is stored encrypted under the prefix
+		stored, err := os.ReadFile(path.Join(cfg.FSConfig.Directory, "my/prefix", objectKey))
+		require.NoError(t, err)
+		require.NotContains(t, string(stored), string(data))
+
+		rc, _, err := objectClient.GetObject(ctx, objectKey)
+		require.NoError(t, err)
+		read, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.Equal(t, data, read)
+	}
+}
+
 // DefaultSchemaConfig creates a simple schema config for testing
 func DefaultSchemaConfig(store, schema string, from model.Time) config.SchemaConfig {
 	s := config.SchemaConfig{
@@ -41,6 +41,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/deletion"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 )
 
 var (
@@ -248,10 +249,26 @@ func (s *LokiStore) chunkClientForPeriod(p config.PeriodConfig) (client.Client,
 		return nil, errors.Wrap(err, "error creating object client")
 	}
 
+	if tieringStore := s.cfg.TieringObjectStore; tieringStore != "" && tieringStore != objectStoreType {
+		cold, err := NewChunkClient(tieringStore, s.cfg, s.schemaCfg, cc, chunkClientReg, s.clientMetrics, s.logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating tiering object client")
+		}
+		chunks = client.NewTieredClient(chunks, cold, TieredChunkPredicate(s.limits))
+	}
+
 	chunks = client.NewMetricsChunkClient(chunks, s.chunkClientMetrics)
 	return chunks, nil
 }
 
+// TieredChunkPredicate returns whether a chunk is old enough to have been moved to the tiering object store.
+func TieredChunkPredicate(limits interface{ StorageTieringAge(string) time.Duration }) func(chunk.Chunk) bool {
+	return func(c chunk.Chunk) bool {
+		age := limits.StorageTieringAge(c.UserID)
+		return age > 0 && c.Through.Time().Before(time.Now().Add(-age))
+	}
+}
+
 func shouldUseIndexGatewayClient(cfg indexshipper.Config) bool {
 	if cfg.Mode != indexshipper.ModeReadOnly || cfg.IndexGatewayClientConfig.Disabled {
 		return false
@@ -520,6 +537,11 @@ func (s *LokiStore) SelectLogs(ctx context.Context, req logql.SelectLogParams) (
 		return nil, err
 	}
 
+	pipeline, err = masking.SetupPipeline(req, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
 	if s.pipelineWrapper != nil && httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) != "true" {
 		userID, err := tenant.TenantID(ctx)
 		if err != nil {
@@ -567,6 +589,11 @@ func (s *LokiStore) SelectSamples(ctx context.Context, req logql.SelectSamplePar
 		return nil, err
 	}
 
+	extractor, err = masking.SetupExtractor(req, extractor)
+	if err != nil {
+		return nil, err
+	}
+
 	if s.extractorWrapper != nil && httpreq.ExtractHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader) != "true" {
 		userID, err := tenant.TenantID(ctx)
 		if err != nil {
@@ -320,15 +320,21 @@ func (b *BloomClient) GetBlock(ctx context.Context, ref BlockRef) (BlockDirector
 	}
 	defer rc.Close()
 
-	path := b.fsResolver.Block(ref).LocalPath()
+	return extractBlock(b.fsResolver, ref, key, rc)
+}
+
+// extractBlock extracts the block archive read from r into the block
+// directory that the given resolver assigns to the block.
+func extractBlock(resolver KeyResolver, ref BlockRef, key string, r io.Reader) (BlockDirectory, error) {
+	path := resolver.Block(ref).LocalPath()
 	// the block directory should not contain the .tar.gz extension
 	path = strings.TrimSuffix(path, ".tar.gz")
-	err = util.EnsureDirectory(path)
+	err := util.EnsureDirectory(path)
 	if err != nil {
 		return BlockDirectory{}, fmt.Errorf("failed to create block directory %s: %w", path, err)
 	}
 
-	err = v1.UnTarGz(path, rc)
+	err = v1.UnTarGz(path, r)
 	if err != nil {
 		return BlockDirectory{}, fmt.Errorf("failed to extract block file %s: %w", key, err)
 	}
@@ -52,6 +52,9 @@ func (c *Config) Validate() error {
 	if err := c.MemoryManagement.Validate(); err != nil {
 		return err
 	}
+	if err := c.BlocksCache.DiskCache.Validate(); err != nil {
+		return fmt.Errorf("invalid blocks_cache.disk_cache config: %w", err)
+	}
 	return nil
 }
 
@@ -61,6 +64,9 @@ type BlocksCacheConfig struct {
 	HardLimit flagext.Bytes `yaml:"hard_limit"`
 	TTL       time.Duration `yaml:"ttl"`
 
+	// DiskCache keeps the archives of downloaded blocks on local disk.
+	DiskCache cache.DiskCacheConfig `yaml:"disk_cache"`
+
 	// PurgeInterval tell how often should we remove keys that are expired.
 	// by default it takes `defaultPurgeInterval`
 	PurgeInterval time.Duration `yaml:"-"`
@@ -76,6 +82,7 @@ func (cfg *BlocksCacheConfig) RegisterFlagsWithPrefixAndDefaults(prefix, descrip
 	_ = cfg.HardLimit.Set("64GiB")
 	f.Var(&cfg.HardLimit, prefix+"hard-limit", description+"Hard limit of the cache in bytes. Exceeding this limit will block execution until soft limit is deceeded.")
 	f.DurationVar(&cfg.TTL, prefix+"ttl", defaultTTL, description+"The time to live for items in the cache before they get purged.")
+	cfg.DiskCache.RegisterFlagsWithPrefix(prefix+"disk-cache.", description+"Local disk cache for the archives of downloaded blocks, so that evicted blocks are not downloaded from object storage again. ", f)
 }
 
 func (cfg *BlocksCacheConfig) Validate() error {
@@ -1,8 +1,11 @@
 package bloomshipper
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -76,6 +79,11 @@ type Fetcher struct {
 	blocksCache     Cache
 	localFSResolver KeyResolver
 
+	// archivesCache keeps the downloaded block archives on local disk, so
+	// that blocks evicted from the blocks cache do not need to be downloaded
+	// from object storage again. It is nil if the disk cache is disabled.
+	archivesCache cache.Cache
+
 	q *downloadQueue[BlockRef, BlockDirectory]
 
 	cfg          bloomStoreConfig
@@ -89,6 +97,7 @@ func NewFetcher(
 	client Client,
 	metasCache cache.Cache,
 	blocksCache Cache,
+	archivesCache cache.Cache,
 	reg prometheus.Registerer,
 	logger log.Logger,
 	bloomMetrics *v1.Metrics,
@@ -103,6 +112,7 @@ func NewFetcher(
 		metasCache:      metasCache,
 		blocksCache:     blocksCache,
 		localFSResolver: localFSResolver,
+		archivesCache:   archivesCache,
 		metrics:         newFetcherMetrics(reg, constants.Loki, "bloom_store"),
 		bloomMetrics:    bloomMetrics,
 		logger:          logger,
@@ -393,6 +403,10 @@ func (f *Fetcher) fetchBlock(ctx context.Context, ref BlockRef) (BlockDirectory,
 		return zero, errors.Wrap(ctx.Err(), "fetch block")
 	}
 
+	if f.archivesCache != nil {
+		return f.fetchBlockArchive(ctx, ref)
+	}
+
 	fromStorage, err := f.client.GetBlock(ctx, ref)
 	if err != nil {
 		return zero, err
@@ -402,6 +416,58 @@ func (f *Fetcher) fetchBlock(ctx context.Context, ref BlockRef) (BlockDirectory,
 	return fromStorage, err
 }
 
+// fetchBlockArchive extracts the block from its archive in the archives cache,
+// or downloads the archive from storage and stores it in the archives cache.
+// Archives that are larger than the archives cache are extracted while they
+// are downloaded and never buffered in memory.
+func (f *Fetcher) fetchBlockArchive(ctx context.Context, ref BlockRef) (BlockDirectory, error) {
+	var zero BlockDirectory
+
+	key := f.client.Block(ref).Addr()
+	found, bufs, _, err := f.archivesCache.Fetch(ctx, []string{key})
+	if err != nil {
+		level.Warn(f.logger).Log("msg", "failed to fetch block archive from disk cache", "block", key, "err", err)
+	}
+	if len(found) == 1 {
+		fromDisk, err := extractBlock(f.localFSResolver, ref, key, bytes.NewReader(bufs[0]))
+		if err == nil {
+			f.metrics.blocksFetchedSize.WithLabelValues(sourceDiskCache).Observe(float64(fromDisk.Size()))
+			return fromDisk, nil
+		}
+		level.Warn(f.logger).Log("msg", "failed to extract block archive from disk cache", "block", key, "err", err)
+	}
+
+	rc, size, err := f.client.ObjectClient().GetObject(ctx, key)
+	if err != nil {
+		return zero, fmt.Errorf("failed to get block file %s: %w", key, err)
+	}
+	defer rc.Close()
+
+	if size > f.cfg.maxArchiveSize {
+		fromStorage, err := extractBlock(f.localFSResolver, ref, key, rc)
+		if err != nil {
+			return zero, err
+		}
+		f.metrics.blocksFetchedSize.WithLabelValues(sourceStorage).Observe(float64(fromStorage.Size()))
+		return fromStorage, nil
+	}
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return zero, fmt.Errorf("failed to read block file %s: %w", key, err)
+	}
+	fromStorage, err := extractBlock(f.localFSResolver, ref, key, bytes.NewReader(buf))
+	if err != nil {
+		return zero, err
+	}
+	if err := f.archivesCache.Store(ctx, []string{key}, [][]byte{buf}); err != nil {
+		level.Warn(f.logger).Log("msg", "failed to store block archive in disk cache", "block", key, "err", err)
+	}
+
+	f.metrics.blocksFetchedSize.WithLabelValues(sourceStorage).Observe(float64(fromStorage.Size()))
+	return fromStorage, nil
+}
+
 func (f *Fetcher) loadBlocksFromFS(_ context.Context, refs []BlockRef) ([]BlockDirectory, []BlockRef, error) {
 	blockDirs := make([]BlockDirectory, 0, len(refs))
 	missing := make([]BlockRef, 0, len(refs))
@@ -120,7 +120,7 @@ func TestMetasFetcher(t *testing.T) {
 			c, err := NewBloomClient(cfg, oc, logger)
 			require.NoError(t, err)
 
-			fetcher, err := NewFetcher(cfg, c, metasCache, nil, nil, logger, v1.NewMetrics(nil))
+			fetcher, err := NewFetcher(cfg, c, metasCache, nil, nil, nil, logger, v1.NewMetrics(nil))
 			require.NoError(t, err)
 
 			// prepare metas cache
@@ -351,7 +351,7 @@ func TestFetcher_LoadBlocksFromFS(t *testing.T) {
 	c, err := NewBloomClient(cfg, oc, log.NewNopLogger())
 	require.NoError(t, err)
 
-	fetcher, err := NewFetcher(cfg, c, nil, nil, nil, log.NewNopLogger(), v1.NewMetrics(nil))
+	fetcher, err := NewFetcher(cfg, c, nil, nil, nil, nil, log.NewNopLogger(), v1.NewMetrics(nil))
 	require.NoError(t, err)
 
 	found, missing, err := fetcher.loadBlocksFromFS(context.Background(), refs)
@@ -376,13 +376,67 @@ func createBlockDir(t *testing.T, path string) {
 	_ = fp.Close()
 }
 
+func TestFetcher_ArchivesCache(t *testing.T) {
+	ctx := context.Background()
+
+	c, dir := newMockBloomClient(t)
+	b, err := putBlock(t, c, "tenant", parseTime("2024-02-05 00:00"), 0x0000, 0xffff)
+	require.NoError(t, err)
+	key := c.Block(b.BlockRef).Addr()
+
+	newFetcher := func(t *testing.T, maxArchiveSize int64) (*Fetcher, *cache.DiskCache) {
+		archivesCache, err := cache.NewDiskCache("test", cache.DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 10}, nil, log.NewNopLogger(), "test")
+		require.NoError(t, err)
+		cfg := bloomStoreConfig{
+			workingDirs:    []string{dir},
+			numWorkers:     1,
+			maxArchiveSize: maxArchiveSize,
+		}
+		fetcher, err := NewFetcher(cfg, c, nil, nil, archivesCache, nil, log.NewNopLogger(), v1.NewMetrics(nil))
+		require.NoError(t, err)
+		t.Cleanup(fetcher.Close)
+		return fetcher, archivesCache
+	}
+
+	t.Run("archives larger than the cache are not stored", func(t *testing.T) {
+		fetcher, archivesCache := newFetcher(t, 0)
+
+		blockDir, err := fetcher.fetchBlock(ctx, b.BlockRef)
+		require.NoError(t, err)
+		require.DirExists(t, blockDir.Path)
+		require.NoError(t, os.RemoveAll(blockDir.Path))
+
+		_, _, missing, err := archivesCache.Fetch(ctx, []string{key})
+		require.NoError(t, err)
+		require.Equal(t, []string{key}, missing)
+	})
+
+	t.Run("evicted blocks are extracted from the cached archive", func(t *testing.T) {
+		fetcher, _ := newFetcher(t, 10e6)
+
+		blockDir, err := fetcher.fetchBlock(ctx, b.BlockRef)
+		require.NoError(t, err)
+		require.DirExists(t, blockDir.Path)
+
+		// remove the block from both the object storage and the working directory
+		require.NoError(t, c.client.DeleteObject(ctx, key))
+		require.NoError(t, os.RemoveAll(blockDir.Path))
+
+		blockDir, err = fetcher.fetchBlock(ctx, b.BlockRef)
+		require.NoError(t, err)
+		require.Equal(t, b.BlockRef, blockDir.BlockRef)
+		ok, _ := fetcher.isBlockDir(blockDir.Path)
+		require.True(t, ok)
+	})
+}
+
 func TestFetcher_IsBlockDir(t *testing.T) {
 	cfg := bloomStoreConfig{
 		numWorkers:  1,
 		workingDirs: []string{t.TempDir()},
 	}
 
-	fetcher, err := NewFetcher(cfg, nil, nil, nil, nil, log.NewNopLogger(), v1.NewMetrics(nil))
+	fetcher, err := NewFetcher(cfg, nil, nil, nil, nil, nil, log.NewNopLogger(), v1.NewMetrics(nil))
 	require.NoError(t, err)
 
 	t.Run("path does not exist", func(t *testing.T) {
@@ -9,6 +9,7 @@ const (
 	sourceCache      = "cache"
 	sourceStorage    = "storage"
 	sourceFilesystem = "filesystem"
+	sourceDiskCache  = "disk-cache"
 )
 
 type storeMetrics struct {
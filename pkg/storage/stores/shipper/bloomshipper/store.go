@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/common/model"
 	"golang.org/x/exp/slices"
 
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/storage"
 	v1 "github.com/grafana/loki/v3/pkg/storage/bloom/v1"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
@@ -52,6 +53,9 @@ type bloomStoreConfig struct {
 	workingDirs      []string
 	numWorkers       int
 	maxBloomPageSize int
+	// maxArchiveSize is the size of the largest block archive that is stored
+	// in the archives cache.
+	maxArchiveSize int64
 }
 
 // Compiler check to ensure bloomStoreEntry implements the Store interface
@@ -283,6 +287,7 @@ type BloomStore struct {
 	bloomMetrics       *v1.Metrics
 	logger             log.Logger
 	allocator          mempool.Allocator
+	archivesCache      cache.Cache
 	defaultKeyResolver // TODO(owen-d): impl schema aware resolvers
 }
 
@@ -324,6 +329,15 @@ func NewBloomStore(
 		maxBloomPageSize: int(storageConfig.BloomShipperConfig.MaxQueryPageSize),
 	}
 
+	if diskCacheCfg := storageConfig.BloomShipperConfig.BlocksCache.DiskCache; diskCacheCfg.IsEnabled() {
+		archivesCache, err := cache.NewDiskCache("bloom-blocks-disk-cache", diskCacheCfg, reg, logger, stats.BloomBlocksCache)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating disk cache for bloom block archives")
+		}
+		store.archivesCache = cache.CollectStats(cache.Instrument("bloom-blocks-disk-cache", archivesCache, reg))
+		cfg.maxArchiveSize = diskCacheCfg.MaxSizeMB * 1e6
+	}
+
 	for _, wd := range cfg.workingDirs {
 		if err := util.EnsureDirectory(wd); err != nil {
 			return nil, errors.Wrapf(err, "failed to create working directory for bloom store: '%s'", wd)
@@ -348,7 +362,7 @@ func NewBloomStore(
 		}
 
 		regWithLabels := prometheus.WrapRegistererWith(prometheus.Labels{"store": periodicConfig.From.String()}, reg)
-		fetcher, err := NewFetcher(cfg, bloomClient, metasCache, blocksCache, regWithLabels, logger, store.bloomMetrics)
+		fetcher, err := NewFetcher(cfg, bloomClient, metasCache, blocksCache, store.archivesCache, regWithLabels, logger, store.bloomMetrics)
 		if err != nil {
 			return nil, errors.Wrapf(err, "creating fetcher for period %s", periodicConfig.From)
 		}
@@ -563,6 +577,9 @@ func (b *BloomStore) Stop() {
 	for _, s := range b.stores {
 		s.Stop()
 	}
+	if b.archivesCache != nil {
+		b.archivesCache.Stop()
+	}
 }
 
 func (b *BloomStore) getStore(ts model.Time) *bloomStoreEntry {
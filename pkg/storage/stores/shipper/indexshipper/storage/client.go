@@ -25,6 +25,8 @@ type CommonIndexClient interface {
 	ListFiles(ctx context.Context, tableName string, bypassCache bool) ([]IndexFile, []string, error)
 	GetFile(ctx context.Context, tableName, fileName string) (io.ReadCloser, error)
 	PutFile(ctx context.Context, tableName, fileName string, file io.ReadSeeker) error
+	PutFileIf(ctx context.Context, tableName, fileName string, file io.ReadSeeker, cond client.WriteCondition) (string, error)
+	FileVersion(ctx context.Context, tableName, fileName string) (string, error)
 	DeleteFile(ctx context.Context, tableName, fileName string) error
 }
 
@@ -135,7 +137,7 @@ func (s *indexStorageClient) GetFile(ctx context.Context, tableName, fileName st
 }
 
 func (s *indexStorageClient) GetUserFile(ctx context.Context, tableName, userID, fileName string) (io.ReadCloser, error) {
-	readCloser, _, err := s.objectClient.GetObject(ctx, path.Join(tableName, userID, fileName))
+	readCloser, _, err := s.objectClient.GetObject(client.InjectObjectTenant(ctx, userID), path.Join(tableName, userID, fileName))
 	return readCloser, err
 }
 
@@ -144,7 +146,15 @@ func (s *indexStorageClient) PutFile(ctx context.Context, tableName, fileName st
 }
 
 func (s *indexStorageClient) PutUserFile(ctx context.Context, tableName, userID, fileName string, file io.ReadSeeker) error {
-	return s.objectClient.PutObject(ctx, path.Join(tableName, userID, fileName), file)
+	return s.objectClient.PutObject(client.InjectObjectTenant(ctx, userID), path.Join(tableName, userID, fileName), file)
+}
+
+func (s *indexStorageClient) PutFileIf(ctx context.Context, tableName, fileName string, file io.ReadSeeker, cond client.WriteCondition) (string, error) {
+	return s.objectClient.PutObjectIf(ctx, path.Join(tableName, fileName), file, cond)
+}
+
+func (s *indexStorageClient) FileVersion(ctx context.Context, tableName, fileName string) (string, error) {
+	return s.objectClient.ObjectVersion(ctx, path.Join(tableName, fileName))
 }
 
 func (s *indexStorageClient) DeleteFile(ctx context.Context, tableName, fileName string) error {
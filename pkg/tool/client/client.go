@@ -4,21 +4,26 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/grafana/dskit/crypto/tls"
 	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	rulerAPIPath  = "/api/v1/rules"
-	legacyAPIPath = "/api/prom/rules"
+	rulerAPIPath      = "/api/v1/rules"
+	legacyAPIPath     = "/api/prom/rules"
+	queryRangeAPIPath = "/loki/api/v1/query_range"
 )
 
 var (
@@ -111,6 +116,40 @@ func (r *LokiClient) Query(ctx context.Context, query string) (*http.Response, e
 	return res, nil
 }
 
+// QueryRange executes a LogQL metric query over a time range, with the start and end times inclusive.
+func (r *LokiClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (model.Matrix, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	res, err := r.doRequest(ctx, queryRangeAPIPath+"?"+params.Encode(), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var resp struct {
+		Data struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, errors.Wrap(err, "decoding query response")
+	}
+	if resp.Data.ResultType != string(parser.ValueTypeMatrix) {
+		return nil, fmt.Errorf("unexpected result type %q, the query must be a metric query", resp.Data.ResultType)
+	}
+
+	var matrix model.Matrix
+	if err := json.Unmarshal(resp.Data.Result, &matrix); err != nil {
+		return nil, errors.Wrap(err, "decoding query result")
+	}
+	return matrix, nil
+}
+
 func (r *LokiClient) doRequest(ctx context.Context, path, method string, payload []byte) (*http.Response, error) {
 	req, err := buildRequest(ctx, path, method, *r.endpoint, payload)
 	if err != nil {
@@ -217,5 +256,6 @@ func buildRequest(ctx context.Context, p, m string, endpoint url.URL, payload []
 		endpoint.RawPath = joinPath(endpoint.EscapedPath(), pURL.EscapedPath())
 	}
 	endpoint.Path = joinPath(endpoint.Path, pURL.Path)
+	endpoint.RawQuery = pURL.RawQuery
 	return http.NewRequestWithContext(ctx, m, endpoint.String(), bytes.NewBuffer(payload))
 }
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiClient_QueryRange(t *testing.T) {
+	requestCh := make(chan *http.Request, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCh <- r
+		fmt.Fprintln(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"app":"foo"},"values":[[60,"1"],[120,"2.5"]]}]}}`)
+	}))
+	defer ts.Close()
+
+	client, err := New(Config{
+		Address: ts.URL,
+		ID:      "my-id",
+	})
+	require.NoError(t, err)
+
+	matrix, err := client.QueryRange(context.Background(), `count_over_time({app="foo"}[1m])`, time.Unix(60, 0), time.Unix(120, 0), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, model.Matrix{{
+		Metric: model.Metric{"app": "foo"},
+		Values: []model.SamplePair{{Timestamp: 60000, Value: 1}, {Timestamp: 120000, Value: 2.5}},
+	}}, matrix)
+
+	req := <-requestCh
+	require.Equal(t, "/loki/api/v1/query_range", req.URL.Path)
+	require.Equal(t, `count_over_time({app="foo"}[1m])`, req.URL.Query().Get("query"))
+	require.Equal(t, "60000000000", req.URL.Query().Get("start"))
+	require.Equal(t, "120000000000", req.URL.Query().Get("end"))
+	require.Equal(t, "60", req.URL.Query().Get("step"))
+}
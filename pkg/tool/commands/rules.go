@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -83,6 +84,16 @@ type RuleCommand struct {
 
 	// Diff Rules Config
 	Verbose bool
+
+	// Test Rules Config
+	TestFilesList []string
+
+	// Backfill Rules Config
+	BackfillStart              string
+	BackfillEnd                string
+	BackfillOutputDir          string
+	BackfillBlockDuration      time.Duration
+	BackfillEvaluationInterval time.Duration
 }
 
 // Register rule related commands and flags with the kingpin application
@@ -121,11 +132,17 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 		Command("lint", "formats a set of rule files. It reorders keys alphabetically, uses 4 spaces as indentantion, and formats PromQL expressions to a single line.").
 		Action(r.lint)
 	checkCmd := rulesCmd.
-		Command("check", "runs various best practice checks against rules.").
+		Command("check", "runs various best practice checks against rules, and fails when rule groups depend on each other through the metrics they record.").
 		Action(r.checkRecordingRuleNames)
+	testCmd := rulesCmd.
+		Command("test", "unit tests rules against log streams, like promtool test rules.").
+		Action(r.testRules)
+	backfillCmd := rulesCmd.
+		Command("backfill", "evaluates recording rules over a past time range and writes the results as Prometheus TSDB blocks.").
+		Action(r.backfillRules)
 
 	// Require Loki cluster address and tentant ID on all these commands
-	for _, c := range []*kingpin.CmdClause{listCmd, printRulesCmd, getRuleGroupCmd, deleteRuleGroupCmd, loadRulesCmd, diffRulesCmd, syncRulesCmd} {
+	for _, c := range []*kingpin.CmdClause{listCmd, printRulesCmd, getRuleGroupCmd, deleteRuleGroupCmd, loadRulesCmd, diffRulesCmd, syncRulesCmd, backfillCmd} {
 		c.Flag("address", "Address of the loki cluster, alternatively set LOKI_ADDRESS.").
 			Envar("LOKI_ADDRESS").
 			Required().
@@ -227,6 +244,22 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	).StringVar(&r.RuleFilesPath)
 	checkCmd.Flag("strict", "fails rules checks that do not match best practices exactly").BoolVar(&r.Strict)
 
+	// Test Command
+	testCmd.Arg("test-files", "The rule test files to run.").Required().ExistingFilesVar(&r.TestFilesList)
+
+	// Backfill Command
+	backfillCmd.Arg("rule-files", "The rule files to backfill.").ExistingFilesVar(&r.RuleFilesList)
+	backfillCmd.Flag("rule-files", "The rule files to backfill. Flag can be reused to load multiple files.").StringVar(&r.RuleFiles)
+	backfillCmd.Flag(
+		"rule-dirs",
+		"Comma separated list of paths to directories containing rules yaml files. Each file in a directory with a .yml or .yaml suffix will be parsed.",
+	).StringVar(&r.RuleFilesPath)
+	backfillCmd.Flag("start", "The time to start backfilling the rules from, in RFC3339 format.").Required().StringVar(&r.BackfillStart)
+	backfillCmd.Flag("end", "The time to backfill the rules up to, in RFC3339 format. Defaults to 3 hours ago, to not overlap with the head block of the metrics backend.").StringVar(&r.BackfillEnd)
+	backfillCmd.Flag("output-dir", "The directory to write the blocks and the backfill progress to.").Default("data/").StringVar(&r.BackfillOutputDir)
+	backfillCmd.Flag("block-duration", "The time range of the written blocks.").Default("2h").DurationVar(&r.BackfillBlockDuration)
+	backfillCmd.Flag("eval-interval", "The evaluation interval of the rule groups without an interval.").Default("1m").DurationVar(&r.BackfillEvaluationInterval)
+
 	// List Command
 	listCmd.Flag("format", "Backend type to interact with: <json|yaml|table>").Default("table").EnumVar(&r.Format, formats...)
 	listCmd.Flag("disable-color", "disable colored output").BoolVar(&r.DisableColor)
@@ -717,9 +750,55 @@ func (r *RuleCommand) checkRecordingRuleNames(_ *kingpin.ParseContext) error {
 		}
 	}
 
+	deps := rules.Dependencies(namespaces)
+	order, err := deps.Order(namespaces)
+	if err != nil {
+		return errors.Wrap(err, "check operation unsuccessful")
+	}
+	if len(deps) != 0 {
+		fmt.Printf("%d rule group(s) depend on metrics recorded by other rule groups, evaluation order:\n", len(deps))
+		for _, g := range order {
+			fmt.Printf("\t%s\n", g)
+		}
+	}
+
 	return nil
 }
 
+func (r *RuleCommand) backfillRules(_ *kingpin.ParseContext) error {
+	start, err := time.Parse(time.RFC3339, r.BackfillStart)
+	if err != nil {
+		return errors.Wrap(err, "invalid start time")
+	}
+	end := time.Now().Add(-3 * time.Hour)
+	if r.BackfillEnd != "" {
+		end, err = time.Parse(time.RFC3339, r.BackfillEnd)
+		if err != nil {
+			return errors.Wrap(err, "invalid end time")
+		}
+	}
+
+	if err := r.setupFiles(); err != nil {
+		return errors.Wrap(err, "backfill operation unsuccessful, unable to load rules files")
+	}
+	namespaces, err := rules.ParseFiles(r.RuleFilesList)
+	if err != nil {
+		return errors.Wrap(err, "backfill operation unsuccessful, unable to parse rules files")
+	}
+
+	return rules.Backfill(context.Background(), r.cli, namespaces, rules.BackfillConfig{
+		Start:              start,
+		End:                end,
+		OutputDir:          r.BackfillOutputDir,
+		BlockDuration:      r.BackfillBlockDuration,
+		EvaluationInterval: r.BackfillEvaluationInterval,
+	})
+}
+
+func (r *RuleCommand) testRules(_ *kingpin.ParseContext) error {
+	return rules.RunUnitTests(os.Stdout, r.TestFilesList...)
+}
+
 // Taken from https://github.com/prometheus/prometheus/blob/8c8de46003d1800c9d40121b4a5e5de8582ef6e1/cmd/promtool/main.go#L403
 type compareRuleType struct {
 	metric string
@@ -0,0 +1,205 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	kitlog "github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/grafana/loki/v3/pkg/tool/rules/rwrulefmt"
+)
+
+const backfillProgressFile = "backfill-progress.json"
+
+// RangeQuerier runs LogQL metric range queries.
+type RangeQuerier interface {
+	QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (model.Matrix, error)
+}
+
+// BackfillConfig configures the backfill of recording rules.
+type BackfillConfig struct {
+	Start, End time.Time
+	// OutputDir is where the TSDB blocks and the backfill progress are written.
+	OutputDir string
+	// BlockDuration is the time range of the written blocks.
+	BlockDuration time.Duration
+	// EvaluationInterval is used for the groups without an interval.
+	EvaluationInterval time.Duration
+}
+
+// Backfill evaluates the recording rules of the namespaces over the past time
+// range of the config and writes the recorded samples as Prometheus TSDB
+// blocks, which can then be uploaded to the metrics backend of the ruler.
+//
+// The rules are evaluated with range queries whose step is the interval of
+// their group, which yields the samples the ruler would have recorded at each
+// evaluation. Alerting rules are skipped.
+//
+// Every group is backfilled one block at a time and the end of the last block
+// written for a group is saved in the output directory, so that an interrupted
+// backfill resumes where it stopped.
+func Backfill(ctx context.Context, q RangeQuerier, namespaces map[string]RuleNamespace, cfg BackfillConfig) error {
+	if !cfg.Start.Before(cfg.End) {
+		return fmt.Errorf("start %s must be before end %s", cfg.Start, cfg.End)
+	}
+	if cfg.BlockDuration <= 0 || cfg.EvaluationInterval <= 0 {
+		return errors.New("block duration and evaluation interval must be > 0")
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0o750); err != nil {
+		return err
+	}
+
+	progress, err := loadBackfillProgress(cfg.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to load backfill progress: %w", err)
+	}
+
+	nsNames := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		nsNames = append(nsNames, name)
+	}
+	sort.Strings(nsNames)
+
+	for _, ns := range nsNames {
+		for _, group := range namespaces[ns].Groups {
+			key := ns + "/" + group.Name
+			interval := time.Duration(group.Interval)
+			if interval == 0 {
+				interval = cfg.EvaluationInterval
+			}
+
+			start := cfg.Start
+			if done, ok := progress[key]; ok && done.After(start) {
+				start = done
+			}
+
+			for blockStart := start; blockStart.Before(cfg.End); {
+				blockEnd := blockStart.Truncate(cfg.BlockDuration).Add(cfg.BlockDuration)
+				if blockEnd.After(cfg.End) {
+					blockEnd = cfg.End
+				}
+
+				samples, err := backfillGroup(ctx, q, group, blockStart, blockEnd, interval, cfg)
+				if err != nil {
+					return fmt.Errorf("failed to backfill group %s from %s to %s: %w", key, blockStart, blockEnd, err)
+				}
+				log.WithFields(log.Fields{
+					"group":   key,
+					"start":   blockStart,
+					"end":     blockEnd,
+					"samples": samples,
+				}).Infoln("backfilled rule group")
+
+				progress[key] = blockEnd
+				if err := saveBackfillProgress(cfg.OutputDir, progress); err != nil {
+					return fmt.Errorf("failed to save backfill progress: %w", err)
+				}
+				blockStart = blockEnd
+			}
+		}
+	}
+	return nil
+}
+
+// backfillGroup writes a block with the samples recorded by the group at the evaluation times in [start, end).
+// It returns the number of samples written.
+func backfillGroup(ctx context.Context, q RangeQuerier, group rwrulefmt.RuleGroup, start, end time.Time, interval time.Duration, cfg BackfillConfig) (int, error) {
+	w, err := tsdb.NewBlockWriter(kitlog.NewNopLogger(), cfg.OutputDir, cfg.BlockDuration.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	defer w.Close()
+
+	// the start and end of range queries are inclusive
+	firstEval := start.Truncate(interval)
+	if firstEval.Before(start) {
+		firstEval = firstEval.Add(interval)
+	}
+	lastEval := end.Add(-time.Nanosecond).Truncate(interval)
+	if lastEval.Before(firstEval) {
+		return 0, nil
+	}
+
+	samples := 0
+	app := w.Appender(ctx)
+	for _, rule := range group.Rules {
+		if rule.Record.Value == "" {
+			continue
+		}
+
+		matrix, err := q.QueryRange(ctx, rule.Expr.Value, firstEval, lastEval, interval)
+		if err != nil {
+			return 0, fmt.Errorf("rule %s: %w", rule.Record.Value, err)
+		}
+
+		for _, series := range matrix {
+			lb := labels.NewBuilder(labels.EmptyLabels())
+			for name, value := range series.Metric {
+				lb.Set(string(name), string(value))
+			}
+			for name, value := range rule.Labels {
+				lb.Set(name, value)
+			}
+			lb.Set(labels.MetricName, rule.Record.Value)
+			lbls := lb.Labels()
+
+			for _, p := range series.Values {
+				if _, err := app.Append(0, lbls, int64(p.Timestamp), float64(p.Value)); err != nil {
+					return 0, fmt.Errorf("rule %s: %w", rule.Record.Value, err)
+				}
+				samples++
+			}
+		}
+	}
+	if err := app.Commit(); err != nil {
+		return 0, err
+	}
+
+	if samples == 0 {
+		return 0, nil
+	}
+	if _, err := w.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return samples, nil
+}
+
+// loadBackfillProgress returns the end of the last block written for every group.
+func loadBackfillProgress(dir string) (map[string]time.Time, error) {
+	progress := map[string]time.Time{}
+	b, err := os.ReadFile(filepath.Join(dir, backfillProgressFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return progress, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func saveBackfillProgress(dir string, progress map[string]time.Time) error {
+	b, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write the progress atomically, to never lose it
+	tmp := filepath.Join(dir, backfillProgressFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, backfillProgressFile))
+}
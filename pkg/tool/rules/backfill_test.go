@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+type rangeQuery struct {
+	query      string
+	start, end time.Time
+}
+
+// fakeRangeQuerier returns a series with a sample at every step of the queries.
+type fakeRangeQuerier struct {
+	queries []rangeQuery
+}
+
+func (q *fakeRangeQuerier) QueryRange(_ context.Context, query string, start, end time.Time, step time.Duration) (model.Matrix, error) {
+	q.queries = append(q.queries, rangeQuery{query: query, start: start, end: end})
+
+	series := &model.SampleStream{Metric: model.Metric{"app": "foo"}}
+	for ts := start; !ts.After(end); ts = ts.Add(step) {
+		series.Values = append(series.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: 1})
+	}
+	return model.Matrix{series}, nil
+}
+
+func TestBackfill(t *testing.T) {
+	namespaces, err := ParseFiles([]string{"testdata/unittest/rules.yaml"})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	start := time.Unix(0, 0).UTC()
+	cfg := BackfillConfig{
+		Start:              start,
+		End:                start.Add(3 * time.Hour),
+		OutputDir:          dir,
+		BlockDuration:      2 * time.Hour,
+		EvaluationInterval: time.Minute,
+	}
+
+	expr := `sum by (app) (count_over_time({app="foo"} |= "error" [5m]))`
+	q := &fakeRangeQuerier{}
+	require.NoError(t, Backfill(context.Background(), q, namespaces, cfg))
+
+	// the alerting rule is skipped and a block is written per block duration
+	require.Equal(t, []rangeQuery{
+		{query: expr, start: start, end: start.Add(2*time.Hour - time.Minute)},
+		{query: expr, start: start.Add(2 * time.Hour), end: start.Add(3*time.Hour - time.Minute)},
+	}, q.queries)
+
+	blocks := readBlocks(t, dir)
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint64(120), blocks[0].Stats.NumSamples)
+	require.Equal(t, uint64(60), blocks[1].Stats.NumSamples)
+
+	// the backfill resumes where it stopped
+	q = &fakeRangeQuerier{}
+	require.NoError(t, Backfill(context.Background(), q, namespaces, cfg))
+	require.Empty(t, q.queries)
+
+	cfg.End = start.Add(4 * time.Hour)
+	require.NoError(t, Backfill(context.Background(), q, namespaces, cfg))
+	require.Equal(t, []rangeQuery{
+		{query: expr, start: start.Add(3 * time.Hour), end: start.Add(4*time.Hour - time.Minute)},
+	}, q.queries)
+	require.Len(t, readBlocks(t, dir), 3)
+}
+
+func readBlocks(t *testing.T, dir string) []tsdb.BlockMeta {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var metas []tsdb.BlockMeta
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		b, err := tsdb.OpenBlock(log.NewNopLogger(), dir+"/"+e.Name(), nil)
+		require.NoError(t, err)
+		metas = append(metas, b.Meta())
+		require.NoError(t, b.Close())
+	}
+	return metas
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// GroupRef identifies a rule group by its namespace and name.
+type GroupRef struct {
+	Namespace string
+	Group     string
+}
+
+func (g GroupRef) String() string {
+	return g.Namespace + "/" + g.Group
+}
+
+// GroupDependencies maps rule groups to the rule groups recording the metrics their rules select.
+type GroupDependencies map[GroupRef][]GroupRef
+
+// Dependencies returns the dependencies between the rule groups of the namespaces. A group depends on
+// another when one of its rules selects a metric recorded by a rule of the other group. Only the
+// expressions that parse as PromQL, like the ones of pattern rules, select metrics; LogQL expressions
+// select log streams.
+func Dependencies(namespaces map[string]RuleNamespace) GroupDependencies {
+	recordedBy := map[string][]GroupRef{}
+	forEachGroup(namespaces, func(ref GroupRef, ns RuleNamespace, i int) {
+		for _, rule := range ns.Groups[i].Rules {
+			if rule.Record.Value != "" {
+				recordedBy[rule.Record.Value] = append(recordedBy[rule.Record.Value], ref)
+			}
+		}
+	})
+
+	deps := GroupDependencies{}
+	forEachGroup(namespaces, func(ref GroupRef, ns RuleNamespace, i int) {
+		seen := map[GroupRef]struct{}{}
+		for _, rule := range ns.Groups[i].Rules {
+			for _, metric := range selectedMetrics(rule.Expr.Value) {
+				for _, dep := range recordedBy[metric] {
+					// rules of a group are evaluated in order, they don't make the group depend on itself
+					if _, ok := seen[dep]; ok || dep == ref {
+						continue
+					}
+					seen[dep] = struct{}{}
+					deps[ref] = append(deps[ref], dep)
+				}
+			}
+		}
+	})
+	return deps
+}
+
+// Order returns the groups of the namespaces in the order to evaluate them, every group after the
+// groups it depends on and otherwise by namespace and name. It fails when groups depend on each other.
+func (d GroupDependencies) Order(namespaces map[string]RuleNamespace) ([]GroupRef, error) {
+	var groups []GroupRef
+	forEachGroup(namespaces, func(ref GroupRef, _ RuleNamespace, _ int) {
+		groups = append(groups, ref)
+	})
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		order []GroupRef
+		state = map[GroupRef]int{}
+		path  []GroupRef
+		visit func(GroupRef) error
+	)
+	visit = func(g GroupRef) error {
+		switch state[g] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := []string{g.String()}
+			for i := len(path) - 1; i >= 0 && path[i] != g; i-- {
+				cycle = append(cycle, path[i].String())
+			}
+			cycle = append(cycle, g.String())
+			// the path is walked from the dependent groups to their dependencies
+			for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+				cycle[i], cycle[j] = cycle[j], cycle[i]
+			}
+			return fmt.Errorf("rule groups depend on each other: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[g] = visiting
+		path = append(path, g)
+		for _, dep := range d[g] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[g] = visited
+		order = append(order, g)
+		return nil
+	}
+
+	for _, g := range groups {
+		if err := visit(g); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// forEachGroup calls f for each group of the namespaces, sorted by namespace and name.
+func forEachGroup(namespaces map[string]RuleNamespace, f func(ref GroupRef, ns RuleNamespace, i int)) {
+	names := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ns := namespaces[name]
+		idx := make([]int, len(ns.Groups))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(i, j int) bool { return ns.Groups[idx[i]].Name < ns.Groups[idx[j]].Name })
+		for _, i := range idx {
+			f(GroupRef{Namespace: name, Group: ns.Groups[i].Name}, ns, i)
+		}
+	}
+}
+
+// selectedMetrics returns the names of the metrics the PromQL expression selects.
+func selectedMetrics(expr string) []string {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil
+	}
+
+	var metrics []string
+	parser.Inspect(e, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		if vs.Name != "" {
+			metrics = append(metrics, vs.Name)
+			return nil
+		}
+		for _, m := range vs.LabelMatchers {
+			if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+				metrics = append(metrics, m.Value)
+			}
+		}
+		return nil
+	})
+	return metrics
+}
@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/tool/rules/rwrulefmt"
+)
+
+func testGroup(name string, rules ...rulefmt.RuleNode) rwrulefmt.RuleGroup {
+	return rwrulefmt.RuleGroup{RuleGroup: rulefmt.RuleGroup{Name: name, Rules: rules}}
+}
+
+func testRecord(record, expr string) rulefmt.RuleNode {
+	return rulefmt.RuleNode{Record: yaml.Node{Value: record}, Expr: yaml.Node{Value: expr}}
+}
+
+func testAlert(alert, expr string) rulefmt.RuleNode {
+	return rulefmt.RuleNode{Alert: yaml.Node{Value: alert}, Expr: yaml.Node{Value: expr}}
+}
+
+func TestDependencies(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		namespaces map[string]RuleNamespace
+		deps       GroupDependencies
+		order      []GroupRef
+		err        string
+	}{
+		{
+			name: "log queries",
+			namespaces: map[string]RuleNamespace{
+				"ns": {Groups: []rwrulefmt.RuleGroup{
+					testGroup("b", testAlert("Errors", `sum(rate({app="foo"} |= "error" [5m])) > 1`)),
+					testGroup("a", testRecord("app:lines:rate5m", `sum by (app) (rate({app="foo"}[5m]))`)),
+				}},
+			},
+			deps:  GroupDependencies{},
+			order: []GroupRef{{"ns", "a"}, {"ns", "b"}},
+		},
+		{
+			name: "across namespaces",
+			namespaces: map[string]RuleNamespace{
+				"alerts": {Groups: []rwrulefmt.RuleGroup{
+					testGroup("a", testAlert("TooManyErrors", `pattern_count{pattern="<_> error <_>"} > 10`)),
+				}},
+				"recording": {Groups: []rwrulefmt.RuleGroup{
+					testGroup("b", testRecord("pattern_count", `sum by (pattern) (pattern_count{app="foo"})`)),
+				}},
+			},
+			deps:  GroupDependencies{{"alerts", "a"}: {{"recording", "b"}}},
+			order: []GroupRef{{"recording", "b"}, {"alerts", "a"}},
+		},
+		{
+			name: "within a group",
+			namespaces: map[string]RuleNamespace{
+				"ns": {Groups: []rwrulefmt.RuleGroup{
+					testGroup("a",
+						testRecord("pattern_count", `sum(pattern_count{app="foo"})`),
+						testAlert("Pattern", `{__name__="pattern_count"} > 10`),
+					),
+				}},
+			},
+			deps:  GroupDependencies{},
+			order: []GroupRef{{"ns", "a"}},
+		},
+		{
+			name: "cycle",
+			namespaces: map[string]RuleNamespace{
+				"ns": {Groups: []rwrulefmt.RuleGroup{
+					testGroup("a", testRecord("a:pattern_count", `sum(b:pattern_count)`)),
+					testGroup("b", testRecord("b:pattern_count", `sum(c:pattern_count)`)),
+					testGroup("c", testRecord("c:pattern_count", `sum(a:pattern_count)`)),
+				}},
+			},
+			deps: GroupDependencies{
+				{"ns", "a"}: {{"ns", "b"}},
+				{"ns", "b"}: {{"ns", "c"}},
+				{"ns", "c"}: {{"ns", "a"}},
+			},
+			err: "rule groups depend on each other: ns/a -> ns/b -> ns/c -> ns/a",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deps := Dependencies(tc.namespaces)
+			require.Equal(t, tc.deps, deps)
+
+			order, err := deps.Order(tc.namespaces)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.order, order)
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/prometheus/prometheus/storage"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/ruler"
+)
+
+const (
+	defaultTestInterval = model.Duration(time.Minute)
+
+	// unitTestTenant is the tenant the rules are evaluated for.
+	unitTestTenant = "fake"
+)
+
+// UnitTestFile is the format of the files of `lokitool rules test`. It mirrors
+// the format of `promtool test rules`, with the input series replaced by log
+// streams and the PromQL expressions by LogQL expressions.
+type UnitTestFile struct {
+	RuleFiles          []string       `yaml:"rule_files"`
+	EvaluationInterval model.Duration `yaml:"evaluation_interval,omitempty"`
+	Tests              []TestGroup    `yaml:"tests"`
+}
+
+// TestGroup is a set of input log streams and the tests evaluated against them.
+// The time of the tests starts at 0, the Unix epoch.
+type TestGroup struct {
+	Name string `yaml:"name,omitempty"`
+	// Interval is the interval between the lines of the input streams.
+	Interval           model.Duration          `yaml:"interval,omitempty"`
+	InputStreams       []InputStream           `yaml:"input_streams"`
+	AlertRuleTests     []AlertRuleTestCase     `yaml:"alert_rule_test,omitempty"`
+	RecordingRuleTests []RecordingRuleTestCase `yaml:"recording_rule_test,omitempty"`
+	LogQLExprTests     []LogQLExprTestCase     `yaml:"logql_expr_test,omitempty"`
+	ExternalLabels     map[string]string       `yaml:"external_labels,omitempty"`
+	ExternalURL        string                  `yaml:"external_url,omitempty"`
+}
+
+// InputStream is a log stream the rules are evaluated against.
+type InputStream struct {
+	// Labels is the stream selector of the stream, e.g. {app="foo"}.
+	Labels string `yaml:"labels"`
+	// Lines are logged one per interval of the test group, starting at 0.
+	Lines []string `yaml:"lines,omitempty"`
+	// Entries are logged at the given times, in addition to the lines.
+	Entries []InputEntry `yaml:"entries,omitempty"`
+}
+
+// InputEntry is a line logged at the given time.
+type InputEntry struct {
+	At   model.Duration `yaml:"at"`
+	Line string         `yaml:"line"`
+}
+
+// AlertRuleTestCase asserts the alerts firing at the given time.
+type AlertRuleTestCase struct {
+	EvalTime  model.Duration `yaml:"eval_time"`
+	Alertname string         `yaml:"alertname"`
+	ExpAlerts []ExpAlert     `yaml:"exp_alerts"`
+}
+
+// ExpAlert is an expected firing alert. The alertname label does not need to be set.
+type ExpAlert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+// RecordingRuleTestCase asserts the samples written by a recording rule at the given time.
+type RecordingRuleTestCase struct {
+	EvalTime   model.Duration `yaml:"eval_time"`
+	Record     string         `yaml:"record"`
+	ExpSamples []ExpSample    `yaml:"exp_samples"`
+}
+
+// LogQLExprTestCase asserts the result of a LogQL metric query at the given time.
+type LogQLExprTestCase struct {
+	Expr       string         `yaml:"expr"`
+	EvalTime   model.Duration `yaml:"eval_time"`
+	ExpSamples []ExpSample    `yaml:"exp_samples"`
+}
+
+// ExpSample is an expected sample. Labels are in the Prometheus text format, e.g. {app="foo"}.
+type ExpSample struct {
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+}
+
+// RunUnitTests runs the rule unit tests of the files and writes their outcome to w.
+// It returns an error if any test failed.
+func RunUnitTests(w io.Writer, files ...string) error {
+	failed := 0
+	for _, f := range files {
+		fmt.Fprintln(w, "Unit Testing: ", f)
+		errs := runUnitTestFile(f)
+		if len(errs) > 0 {
+			failed++
+			fmt.Fprintln(w, "  FAILED:")
+			for _, err := range errs {
+				fmt.Fprintln(w, err.Error())
+			}
+		} else {
+			fmt.Fprintln(w, "  SUCCESS")
+		}
+		fmt.Fprintln(w)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test files failed", failed, len(files))
+	}
+	return nil
+}
+
+func runUnitTestFile(filename string) []error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+
+	var utf UnitTestFile
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&utf); err != nil {
+		return []error{err}
+	}
+	if utf.EvaluationInterval == 0 {
+		utf.EvaluationInterval = defaultTestInterval
+	}
+
+	// rule files are relative to the test file
+	for i, rf := range utf.RuleFiles {
+		if !filepath.IsAbs(rf) {
+			utf.RuleFiles[i] = filepath.Join(filepath.Dir(filename), rf)
+		}
+	}
+
+	var errs []error
+	for _, tg := range utf.Tests {
+		errs = append(errs, tg.test(time.Duration(utf.EvaluationInterval), utf.RuleFiles...)...)
+	}
+	return errs
+}
+
+// test evaluates the rules every evalInterval up to the last eval time of the tests of the group.
+func (tg *TestGroup) test(evalInterval time.Duration, ruleFiles ...string) []error {
+	if tg.Interval == 0 {
+		tg.Interval = defaultTestInterval
+	}
+
+	streams, err := tg.streams()
+	if err != nil {
+		return []error{tg.wrap(err)}
+	}
+	engine := logql.NewEngine(logql.EngineOpts{}, logql.NewMockQuerier(0, streams), logql.NoLimits, log.NewNopLogger())
+	evaluator, err := ruler.NewLocalEvaluator(engine, log.NewNopLogger())
+	if err != nil {
+		return []error{tg.wrap(err)}
+	}
+
+	ctx := user.InjectOrgID(context.Background(), unitTestTenant)
+	appendable := &recordingAppendable{}
+	mgr := rules.NewManager(&rules.ManagerOptions{
+		QueryFunc:                unitTestQueryFunc(evaluator),
+		Appendable:               appendable,
+		Context:                  ctx,
+		NotifyFunc:               func(context.Context, string, ...*rules.Alert) {},
+		Logger:                   log.NewNopLogger(),
+		GroupLoader:              ruler.GroupLoader{},
+		RuleDependencyController: noopRuleDependencyController{},
+	})
+	groupsMap, errs := mgr.LoadGroups(evalInterval, labels.FromMap(tg.ExternalLabels), tg.ExternalURL, nil, ruleFiles...)
+	if len(errs) > 0 {
+		return errs
+	}
+	groups := make([]*rules.Group, 0, len(groupsMap))
+	for _, g := range groupsMap {
+		for _, r := range g.Rules() {
+			if ar, ok := r.(*rules.AlertingRule); ok {
+				// there is no previous state to restore the alerts from
+				ar.SetRestored(true)
+			}
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].File() != groups[j].File() {
+			return groups[i].File() < groups[j].File()
+		}
+		return groups[i].Name() < groups[j].Name()
+	})
+
+	mint := time.Unix(0, 0).UTC()
+	maxt := mint.Add(tg.maxEvalTime())
+	for ts := mint; !ts.After(maxt); ts = ts.Add(evalInterval) {
+		appendable.reset()
+		var evalErrs []error
+		for _, g := range groups {
+			g.Eval(ctx, ts)
+			for _, r := range g.Rules() {
+				if r.LastError() != nil {
+					evalErrs = append(evalErrs, tg.wrap(fmt.Errorf("rule: %s, time: %s, err: %w", r.Name(), ts.Sub(mint), r.LastError())))
+				}
+			}
+		}
+		if len(evalErrs) > 0 {
+			return evalErrs
+		}
+
+		// the tests of the times up to the next evaluation are checked against this evaluation
+		inStep := func(evalTime model.Duration) bool {
+			d := time.Duration(evalTime)
+			return ts.Sub(mint) <= d && d < ts.Add(evalInterval).Sub(mint)
+		}
+		for _, tc := range tg.AlertRuleTests {
+			if inStep(tc.EvalTime) {
+				errs = append(errs, tg.checkAlerts(tc, groups)...)
+			}
+		}
+		for _, tc := range tg.RecordingRuleTests {
+			if inStep(tc.EvalTime) {
+				errs = append(errs, tg.checkRecordedSamples(tc, appendable.samples(tc.Record))...)
+			}
+		}
+	}
+
+	for _, tc := range tg.LogQLExprTests {
+		got, err := evalExpr(ctx, evaluator, tc.Expr, mint.Add(time.Duration(tc.EvalTime)))
+		if err != nil {
+			errs = append(errs, tg.wrap(fmt.Errorf("expr: %q, time: %s, err: %w", tc.Expr, tc.EvalTime, err)))
+			continue
+		}
+		exp, err := parseExpSamples(tc.ExpSamples)
+		if err == nil {
+			err = compareSamples(exp, got)
+		}
+		if err != nil {
+			errs = append(errs, tg.wrap(fmt.Errorf("expr: %q, time: %s, %w", tc.Expr, tc.EvalTime, err)))
+		}
+	}
+	return errs
+}
+
+// wrap adds the name of the test group to the error.
+func (tg *TestGroup) wrap(err error) error {
+	if tg.Name == "" {
+		return fmt.Errorf("    %w", err)
+	}
+	return fmt.Errorf("    name: %s, %w", tg.Name, err)
+}
+
+// streams returns the input streams in the format of the querier.
+func (tg *TestGroup) streams() ([]logproto.Stream, error) {
+	streams := make([]logproto.Stream, 0, len(tg.InputStreams))
+	for _, is := range tg.InputStreams {
+		lbls, err := syntax.ParseLabels(is.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels of input stream %q: %w", is.Labels, err)
+		}
+
+		stream := logproto.Stream{Labels: lbls.String()}
+		for i, line := range is.Lines {
+			stream.Entries = append(stream.Entries, logproto.Entry{
+				Timestamp: time.Unix(0, 0).Add(time.Duration(i) * time.Duration(tg.Interval)),
+				Line:      line,
+			})
+		}
+		for _, e := range is.Entries {
+			stream.Entries = append(stream.Entries, logproto.Entry{
+				Timestamp: time.Unix(0, 0).Add(time.Duration(e.At)),
+				Line:      e.Line,
+			})
+		}
+		sort.SliceStable(stream.Entries, func(i, j int) bool {
+			return stream.Entries[i].Timestamp.Before(stream.Entries[j].Timestamp)
+		})
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// maxEvalTime returns the last eval time of the tests evaluated with the rules.
+func (tg *TestGroup) maxEvalTime() time.Duration {
+	var maxd model.Duration
+	for _, tc := range tg.AlertRuleTests {
+		maxd = max(maxd, tc.EvalTime)
+	}
+	for _, tc := range tg.RecordingRuleTests {
+		maxd = max(maxd, tc.EvalTime)
+	}
+	return time.Duration(maxd)
+}
+
+func (tg *TestGroup) checkAlerts(tc AlertRuleTestCase, groups []*rules.Group) []error {
+	if tc.Alertname == "" {
+		return []error{tg.wrap(fmt.Errorf("an item under alert_rule_test misses required attribute alertname at eval_time %v", tc.EvalTime))}
+	}
+
+	var got []string
+	for _, g := range groups {
+		for _, r := range g.Rules() {
+			ar, ok := r.(*rules.AlertingRule)
+			if !ok || ar.Name() != tc.Alertname {
+				continue
+			}
+			for _, a := range ar.ActiveAlerts() {
+				if a.State == rules.StateFiring {
+					got = append(got, alertString(a.Labels, a.Annotations))
+				}
+			}
+		}
+	}
+
+	exp := make([]string, 0, len(tc.ExpAlerts))
+	for _, a := range tc.ExpAlerts {
+		lbls := labels.NewBuilder(labels.FromMap(a.ExpLabels)).Set(labels.AlertName, tc.Alertname).Labels()
+		exp = append(exp, alertString(lbls, labels.FromMap(a.ExpAnnotations)))
+	}
+
+	sort.Strings(got)
+	sort.Strings(exp)
+	if strings.Join(exp, "\n") == strings.Join(got, "\n") {
+		return nil
+	}
+	return []error{tg.wrap(fmt.Errorf("alertname: %s, time: %s,\n        exp: %v\n        got: %v", tc.Alertname, tc.EvalTime, exp, got))}
+}
+
+func (tg *TestGroup) checkRecordedSamples(tc RecordingRuleTestCase, got promql.Vector) []error {
+	if tc.Record == "" {
+		return []error{tg.wrap(fmt.Errorf("an item under recording_rule_test misses required attribute record at eval_time %v", tc.EvalTime))}
+	}
+
+	exp, err := parseExpSamples(tc.ExpSamples)
+	if err != nil {
+		return []error{tg.wrap(fmt.Errorf("record: %s, time: %s, %w", tc.Record, tc.EvalTime, err))}
+	}
+	for i := range exp {
+		// the metric name is the name of the recording rule
+		exp[i].Metric = labels.NewBuilder(exp[i].Metric).Set(labels.MetricName, tc.Record).Labels()
+	}
+	if err := compareSamples(exp, got); err != nil {
+		return []error{tg.wrap(fmt.Errorf("record: %s, time: %s, %w", tc.Record, tc.EvalTime, err))}
+	}
+	return nil
+}
+
+func alertString(lbls, annotations labels.Labels) string {
+	return fmt.Sprintf("labels:%s annotations:%s", lbls, annotations)
+}
+
+func parseExpSamples(expSamples []ExpSample) (promql.Vector, error) {
+	res := make(promql.Vector, 0, len(expSamples))
+	for _, s := range expSamples {
+		lbls, err := parser.ParseMetric(s.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("labels %q: %w", s.Labels, err)
+		}
+		res = append(res, promql.Sample{Metric: lbls, F: s.Value})
+	}
+	return res, nil
+}
+
+// compareSamples returns an error describing the difference between the expected and actual samples.
+func compareSamples(exp, got promql.Vector) error {
+	expStrs := make([]string, 0, len(exp))
+	for _, s := range exp {
+		expStrs = append(expStrs, sampleString(s.Metric, s.F))
+	}
+	gotStrs := make([]string, 0, len(got))
+	for _, s := range got {
+		gotStrs = append(gotStrs, sampleString(s.Metric, s.F))
+	}
+
+	sort.Strings(expStrs)
+	sort.Strings(gotStrs)
+	if strings.Join(expStrs, "\n") == strings.Join(gotStrs, "\n") {
+		return nil
+	}
+	return fmt.Errorf("samples differ:\n        exp: %v\n        got: %v", expStrs, gotStrs)
+}
+
+func sampleString(lbls labels.Labels, v float64) string {
+	return fmt.Sprintf("%s %v", lbls, v)
+}
+
+// unitTestQueryFunc evaluates the rules with the evaluator.
+func unitTestQueryFunc(evaluator ruler.Evaluator) rules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		return evalExpr(ctx, evaluator, qs, t)
+	}
+}
+
+func evalExpr(ctx context.Context, evaluator ruler.Evaluator, qs string, t time.Time) (promql.Vector, error) {
+	res, err := evaluator.Eval(ctx, qs, t)
+	if err != nil {
+		return nil, err
+	}
+	switch v := res.Data.(type) {
+	case promql.Vector:
+		return v, nil
+	case promql.Scalar:
+		return promql.Vector{promql.Sample{T: v.T, F: v.V, Metric: labels.Labels{}}}, nil
+	default:
+		return nil, errors.New("rule result is not a vector or scalar")
+	}
+}
+
+// recordingAppendable keeps the samples written by the rules since the last reset.
+type recordingAppendable struct {
+	mtx    sync.Mutex
+	series map[string]promql.Sample
+}
+
+func (a *recordingAppendable) Appender(_ context.Context) storage.Appender {
+	return &recordingAppender{appendable: a}
+}
+
+func (a *recordingAppendable) reset() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.series = map[string]promql.Sample{}
+}
+
+// samples returns the samples of the metric, stale markers excluded.
+func (a *recordingAppendable) samples(metricName string) promql.Vector {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var res promql.Vector
+	for _, s := range a.series {
+		if s.Metric.Get(labels.MetricName) == metricName && !value.IsStaleNaN(s.F) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+type recordingAppender struct {
+	// only float samples are written by the rules
+	storage.Appender
+
+	appendable *recordingAppendable
+	pending    []promql.Sample
+}
+
+func (a *recordingAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.pending = append(a.pending, promql.Sample{Metric: l.Copy(), T: t, F: v})
+	return ref, nil
+}
+
+func (a *recordingAppender) Commit() error {
+	a.appendable.mtx.Lock()
+	defer a.appendable.mtx.Unlock()
+	for _, s := range a.pending {
+		a.appendable.series[s.Metric.String()] = s
+	}
+	a.pending = nil
+	return nil
+}
+
+func (a *recordingAppender) Rollback() error {
+	a.pending = nil
+	return nil
+}
+
+// noopRuleDependencyController runs the rules of a group sequentially, LogQL
+// expressions can't be analysed by the Prometheus rules manager.
+type noopRuleDependencyController struct{}
+
+func (noopRuleDependencyController) AnalyseRules([]rules.Rule) {}
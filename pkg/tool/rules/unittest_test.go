@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUnitTests(t *testing.T) {
+	var out bytes.Buffer
+	err := RunUnitTests(&out, "testdata/unittest/tests.yaml")
+	require.NoError(t, err, out.String())
+	require.Contains(t, out.String(), "SUCCESS")
+}
+
+func TestRunUnitTests_Failure(t *testing.T) {
+	rules, err := os.ReadFile("testdata/unittest/rules.yaml")
+	require.NoError(t, err)
+	tests, err := os.ReadFile("testdata/unittest/tests.yaml")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yaml"), rules, 0o600))
+	// the alert is expected before it fires
+	tests = []byte(strings.Replace(string(tests), "- eval_time: 5m\n        alertname: HighErrorRate", "- eval_time: 2m\n        alertname: HighErrorRate", 1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tests.yaml"), tests, 0o600))
+
+	var out bytes.Buffer
+	err = RunUnitTests(&out, filepath.Join(dir, "tests.yaml"))
+	require.Error(t, err)
+	require.Contains(t, out.String(), "FAILED")
+	require.Contains(t, out.String(), "alertname: HighErrorRate, time: 2m")
+}
@@ -0,0 +1,183 @@
+// Package masking masks the structured metadata and parsed labels of query results
+// with the field masking rules of the tenants.
+package masking
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/regexp"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/log/jsonexpr"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// RuleConfig configures a per-tenant field masking rule.
+type RuleConfig struct {
+	Field      string `yaml:"field,omitempty" json:"field,omitempty" doc:"description=Name of the fields masked by the rule."`
+	FieldRegex string `yaml:"field_regex,omitempty" json:"field_regex,omitempty" doc:"description=Regex matching the names of the fields masked by the rule. Mutually exclusive with field."`
+	Action     string `yaml:"action" json:"action" doc:"description=How the fields are masked: hash replaces their value with a hash of it, redact replaces it with <redacted> and drop removes the fields."`
+}
+
+// Validate validates the rule.
+func (c RuleConfig) Validate() error {
+	_, err := log.NewFieldMasker([]log.FieldMaskRule{{Field: c.Field, FieldRegex: c.FieldRegex, Action: c.Action}})
+	return err
+}
+
+// FieldMasks returns the field masks of the rules, sent along with the queries.
+func FieldMasks(rules []RuleConfig) []*logproto.FieldMask {
+	if len(rules) == 0 {
+		return nil
+	}
+	masks := make([]*logproto.FieldMask, 0, len(rules))
+	for _, r := range rules {
+		masks = append(masks, &logproto.FieldMask{
+			Field:      r.Field,
+			FieldRegex: r.FieldRegex,
+			Action:     r.Action,
+		})
+	}
+	return masks
+}
+
+// Masker returns the masker applying the field masks, or nil if there are none.
+func Masker(masks []*logproto.FieldMask) (*log.FieldMasker, error) {
+	if len(masks) == 0 {
+		return nil, nil
+	}
+	rules := make([]log.FieldMaskRule, 0, len(masks))
+	for _, m := range masks {
+		rules = append(rules, log.FieldMaskRule{
+			Field:      m.Field,
+			FieldRegex: m.FieldRegex,
+			Action:     m.Action,
+		})
+	}
+	return log.NewFieldMasker(rules)
+}
+
+// SetupPipeline masks the fields of the entries selected by the request.
+func SetupPipeline(req logql.SelectLogParams, p log.Pipeline) (log.Pipeline, error) {
+	return WrapPipeline(req.FieldMasks, p)
+}
+
+// SetupExtractor masks the fields of the samples selected by the request.
+func SetupExtractor(req logql.SelectSampleParams, se log.SampleExtractor) (log.SampleExtractor, error) {
+	m, err := Masker(req.FieldMasks)
+	if err != nil || m == nil {
+		return se, err
+	}
+	return log.NewMaskingSampleExtractor(m, se), nil
+}
+
+// WrapPipeline returns the pipeline masking the fields once they went through p.
+func WrapPipeline(masks []*logproto.FieldMask, p log.Pipeline) (log.Pipeline, error) {
+	m, err := Masker(masks)
+	if err != nil || m == nil {
+		return p, err
+	}
+	return log.NewMaskingPipeline(m, p), nil
+}
+
+// ValidateExpr returns an error if the expression reads masked fields before they are masked,
+// in label filters, formatters or unwraps, or extracts them under another name with parser
+// expressions or regexp and pattern captures. Otherwise, the values of the masked fields could
+// be inferred from the results of the query.
+func ValidateExpr(expr syntax.Expr, m *log.FieldMasker) error {
+	if m == nil {
+		return nil
+	}
+
+	var err error
+	setErr := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+	check := func(names ...string) {
+		for _, name := range names {
+			if m.Masked(name) {
+				setErr(fmt.Errorf("field %s is masked and cannot be used in queries", name))
+			}
+		}
+	}
+	expr.Walk(func(e syntax.Expr) {
+		switch e := e.(type) {
+		case *syntax.JSONExpressionParser:
+			for _, exp := range e.Expressions {
+				setErr(validateJSONExpression(exp.Expression, m))
+			}
+		case *syntax.LogfmtExpressionParser:
+			for _, exp := range e.Expressions {
+				check(exp.Expression, sanitizeFieldName(exp.Expression))
+			}
+		case *syntax.LabelParserExpr:
+			if e.Op == syntax.OpParserTypeRegexp || e.Op == syntax.OpParserTypePattern {
+				// the captures are named freely, so the expression must not mention a masked field
+				check(fieldNameRegexp.FindAllString(e.Param, -1)...)
+			}
+		}
+		switch e := e.(type) {
+		case syntax.StageExpr:
+			stage, stageErr := e.Stage()
+			if stageErr != nil {
+				setErr(stageErr)
+				return
+			}
+			check(stage.RequiredLabelNames()...)
+		case *syntax.LogRange:
+			if e.Unwrap == nil {
+				return
+			}
+			check(e.Unwrap.Identifier)
+			for _, f := range e.Unwrap.PostFilters {
+				check(f.RequiredLabelNames()...)
+			}
+		}
+	})
+	return err
+}
+
+var fieldNameRegexp = regexp.MustCompile(`[a-zA-Z0-9_]+`)
+
+// validateJSONExpression returns an error if the json expression extracts a masked field, or an
+// object or array containing one. The json parser names the nested fields by joining their keys with _.
+func validateJSONExpression(expr string, m *log.FieldMasker) error {
+	path, err := jsonexpr.Parse(expr, false)
+	if err != nil {
+		return err
+	}
+	var name string
+	for _, p := range path {
+		key, ok := p.(string)
+		if !ok {
+			// elements of arrays are extracted under the name of the array
+			continue
+		}
+		if name != "" {
+			name += "_"
+		}
+		name += sanitizeFieldName(key)
+		if m.Masked(name) {
+			return fmt.Errorf("field %s is masked and cannot be used in queries", name)
+		}
+	}
+	if name == "" || m.MaskedWithPrefix(name+"_") {
+		return fmt.Errorf("json expression %s extracts masked fields and cannot be used in queries", expr)
+	}
+	return nil
+}
+
+// sanitizeFieldName returns the name of the label the parsers extract a field into.
+func sanitizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(name))
+}
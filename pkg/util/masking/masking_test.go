@@ -0,0 +1,95 @@
+package masking
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+)
+
+func TestRuleConfig_Validate(t *testing.T) {
+	require.NoError(t, RuleConfig{Field: "email", Action: log.MaskHash}.Validate())
+	require.NoError(t, RuleConfig{FieldRegex: "secret_.+", Action: log.MaskDrop}.Validate())
+	require.Error(t, RuleConfig{Field: "email"}.Validate())
+	require.Error(t, RuleConfig{Action: log.MaskDrop}.Validate())
+}
+
+func TestValidateExpr(t *testing.T) {
+	m, err := Masker(FieldMasks([]RuleConfig{
+		{Field: "email", Action: log.MaskHash},
+		{FieldRegex: "secret_.+", Action: log.MaskDrop},
+	}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		query string
+		err   bool
+	}{
+		{query: `{app="foo"} | json`},
+		{query: `{app="foo"} | json | level="error"`},
+		{query: `sum by (email) (count_over_time({app="foo"} | json [5m]))`},
+		{query: `{app="foo"} | drop email`},
+		{query: `{app="foo"} | json | email="bob@example.com"`, err: true},
+		{query: `{app="foo"} | json | level="error" or secret_token=~"abc.*"`, err: true},
+		{query: `{app="foo"} | json | line_format "{{.email}}"`, err: true},
+		{query: `{app="foo"} | json | label_format user=email`, err: true},
+		{query: `sum(sum_over_time({app="foo"} | json | unwrap secret_amount [5m]))`, err: true},
+		{query: `{app="foo"} | json level="level", code="response.code"`},
+		{query: `{app="foo"} | json e="email"`, err: true},
+		{query: `{app="foo"} | json e="user.email"`},
+		{query: `{app="foo"} | json e="email[0]"`, err: true},
+		{query: `{app="foo"} | json t="secret.token"`, err: true},
+		{query: `{app="foo"} | json t="secret"`, err: true},
+		{query: `{app="foo"} | json t="[\"secret_token\"]"`, err: true},
+		{query: `{app="foo"} | logfmt level`},
+		{query: `{app="foo"} | logfmt e="email"`, err: true},
+		{query: `{app="foo"} | logfmt t="secret_token"`, err: true},
+		{query: `{app="foo"} | regexp "level=(?P<level>\\w+)"`},
+		{query: `{app="foo"} | regexp "email=(?P<e>\\S+)"`, err: true},
+		{query: `{app="foo"} | pattern "<_> level=<level>"`},
+		{query: `{app="foo"} | pattern "<_> email=<e> <_>"`, err: true},
+		{query: `{app="foo"} | json | label_format e="{{.email}}"`, err: true},
+		{query: `sum by (e) (count_over_time({app="foo"} | json | label_format e=email [5m]))`, err: true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			err := ValidateExpr(syntax.MustParseExpr(tc.query), m)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	require.NoError(t, ValidateExpr(syntax.MustParseExpr(`{app="foo"} | json | email="bob@example.com"`), nil))
+}
+
+func TestSetupPipeline(t *testing.T) {
+	expr, err := syntax.ParseLogSelector(`{app="foo"} | logfmt`, true)
+	require.NoError(t, err)
+
+	req := logql.SelectLogParams{QueryRequest: &logproto.QueryRequest{
+		Plan:       &plan.QueryPlan{AST: expr},
+		FieldMasks: []*logproto.FieldMask{{Field: "email", Action: log.MaskRedact}},
+	}}
+	p, err := expr.Pipeline()
+	require.NoError(t, err)
+	p, err = SetupPipeline(req, p)
+	require.NoError(t, err)
+
+	_, lr, ok := p.ForStream(labels.FromStrings("app", "foo")).ProcessString(0, "email=bob@example.com level=info")
+	require.True(t, ok)
+	require.Equal(t, `{app="foo", email="<redacted>", level="info"}`, lr.String())
+
+	p, err = expr.Pipeline()
+	require.NoError(t, err)
+	unmasked, err := SetupPipeline(logql.SelectLogParams{QueryRequest: &logproto.QueryRequest{}}, p)
+	require.NoError(t, err)
+	require.Equal(t, p, unmasked)
+}
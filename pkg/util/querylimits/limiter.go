@@ -2,6 +2,7 @@ package querylimits
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/util/limiter"
 	logutil "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 )
 
 type Limiter struct {
@@ -124,3 +126,27 @@ func (l *Limiter) MaxQueryBytesRead(ctx context.Context, userID string) int {
 	level.Debug(logutil.WithContext(ctx, l.logger)).Log("msg", "using request limit", "limit", "MaxQueryBytesRead", "tenant", userID, "query-limit", requestLimits.MaxQueryBytesRead.Val(), "original-limit", original)
 	return requestLimits.MaxQueryBytesRead.Val()
 }
+
+// QueryEnforcedMatchers returns the stream selectors whose matchers are added to the queries.
+// The selector of the request is enforced in addition to the ones of the tenant.
+func (l *Limiter) QueryEnforcedMatchers(ctx context.Context, userID string) []string {
+	original := l.CombinedLimits.QueryEnforcedMatchers(ctx, userID)
+	requestLimits := ExtractQueryLimitsContext(ctx)
+	if requestLimits == nil || requestLimits.EnforcedMatchers == "" {
+		return original
+	}
+	level.Debug(logutil.WithContext(ctx, l.logger)).Log("msg", "using request limit", "limit", "QueryEnforcedMatchers", "tenant", userID, "query-limit", requestLimits.EnforcedMatchers, "original-limit", strings.Join(original, ", "))
+	return append(slices.Clip(original), requestLimits.EnforcedMatchers)
+}
+
+// QueryFieldMaskingRules returns the rules masking the fields of the query results.
+// Requests allowed to read the masked fields get no rules.
+func (l *Limiter) QueryFieldMaskingRules(ctx context.Context, userID string) []masking.RuleConfig {
+	original := l.CombinedLimits.QueryFieldMaskingRules(ctx, userID)
+	requestLimits := ExtractQueryLimitsContext(ctx)
+	if requestLimits == nil || !requestLimits.UnmaskFields || len(original) == 0 {
+		return original
+	}
+	level.Debug(logutil.WithContext(ctx, l.logger)).Log("msg", "using request limit", "limit", "QueryFieldMaskingRules", "tenant", userID, "query-limit", "unmasked", "original-limit", len(original))
+	return nil
+}
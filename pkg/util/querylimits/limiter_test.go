@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/util/masking"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
 
@@ -209,3 +210,39 @@ func TestLimiter_MergeLimits(t *testing.T) {
 
 	require.ElementsMatch(t, []string{"one", "two", "three"}, l.RequiredLabels(ctx, "fake"))
 }
+
+func TestLimiter_MergeEnforcedMatchers(t *testing.T) {
+	tLimits := make(map[string]*validation.Limits)
+	tLimits["fake"] = &validation.Limits{
+		QueryEnforcedMatchers: `{cluster="eu"}`,
+	}
+
+	overrides, _ := validation.NewOverrides(validation.Limits{}, newMockTenantLimits(tLimits))
+	l := NewLimiter(log.NewNopLogger(), overrides)
+
+	require.Equal(t, []string{`{cluster="eu"}`}, l.QueryEnforcedMatchers(context.Background(), "fake"))
+	require.Empty(t, l.QueryEnforcedMatchers(context.Background(), "other"))
+
+	ctx := InjectQueryLimitsContext(context.Background(), QueryLimits{EnforcedMatchers: `{namespace=~"team-a.*"}`})
+	require.Equal(t, []string{`{cluster="eu"}`, `{namespace=~"team-a.*"}`}, l.QueryEnforcedMatchers(ctx, "fake"))
+	require.Equal(t, []string{`{namespace=~"team-a.*"}`}, l.QueryEnforcedMatchers(ctx, "other"))
+}
+
+func TestLimiter_UnmaskFields(t *testing.T) {
+	rules := []masking.RuleConfig{{Field: "email", Action: "redact"}}
+	tLimits := make(map[string]*validation.Limits)
+	tLimits["fake"] = &validation.Limits{
+		QueryFieldMaskingRules: rules,
+	}
+
+	overrides, _ := validation.NewOverrides(validation.Limits{}, newMockTenantLimits(tLimits))
+	l := NewLimiter(log.NewNopLogger(), overrides)
+
+	require.Equal(t, rules, l.QueryFieldMaskingRules(context.Background(), "fake"))
+
+	ctx := InjectQueryLimitsContext(context.Background(), QueryLimits{MaxQueryLength: model.Duration(time.Hour)})
+	require.Equal(t, rules, l.QueryFieldMaskingRules(ctx, "fake"))
+
+	ctx = InjectQueryLimitsContext(context.Background(), QueryLimits{UnmaskFields: true})
+	require.Empty(t, l.QueryFieldMaskingRules(ctx, "fake"))
+}
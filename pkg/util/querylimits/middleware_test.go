@@ -54,6 +54,8 @@ func Test_MiddlewareWithHeader(t *testing.T) {
 		[]string{"foo", "bar"},
 		10,
 		10,
+		`{namespace="foo"}`,
+		true,
 	}
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -72,3 +74,43 @@ func Test_MiddlewareWithHeader(t *testing.T) {
 	response := rr.Result()
 	require.Equal(t, http.StatusOK, response.StatusCode)
 }
+
+func Test_MiddlewareUnmaskFields(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		unmask  bool
+	}{
+		{
+			name:    "query limits header",
+			headers: map[string]string{HTTPHeaderQueryLimitsKey: `{"maxQueryLength":"1h","unmaskFields":true}`},
+		},
+		{
+			name:    "unmask fields header",
+			headers: map[string]string{HTTPHeaderUnmaskFieldsKey: "true"},
+			unmask:  true,
+		},
+		{
+			name:    "both headers",
+			headers: map[string]string{HTTPHeaderQueryLimitsKey: `{"maxQueryLength":"1h"}`, HTTPHeaderUnmaskFieldsKey: "true"},
+			unmask:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual *QueryLimits
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actual = ExtractQueryLimitsContext(r.Context())
+			})
+			wrapped := NewQueryLimitsMiddleware(log.NewNopLogger()).Wrap(nextHandler)
+
+			r, err := http.NewRequest("GET", "/example", nil)
+			require.NoError(t, err)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			wrapped.ServeHTTP(httptest.NewRecorder(), r)
+			require.NotNil(t, actual)
+			require.Equal(t, tc.unmask, actual.UnmaskFields)
+		})
+	}
+}
@@ -17,6 +17,11 @@ const (
 	queryLimitsContextKey key = 1
 
 	HTTPHeaderQueryLimitsKey = "X-Loki-Query-Limits"
+
+	// HTTPHeaderUnmaskFieldsKey allows the request to read the fields masked for the tenant when set to true.
+	// Unlike the other query limits, it must only be set by the proxy authenticating the requests, which must
+	// remove it from the requests of the clients.
+	HTTPHeaderUnmaskFieldsKey = "X-Loki-Unmask-Fields"
 )
 
 // NOTE: we use custom `model.Duration` instead of standard `time.Duration` because,
@@ -30,6 +35,9 @@ type QueryLimits struct {
 	RequiredLabels          []string         `json:"requiredLabels,omitempty"`
 	RequiredNumberLabels    int              `json:"minimumLabelsNumber,omitempty"`
 	MaxQueryBytesRead       flagext.ByteSize `json:"maxQueryBytesRead,omitempty"`
+	EnforcedMatchers        string           `json:"enforcedMatchers,omitempty"`
+	// UnmaskFields is only read from the HTTPHeaderUnmaskFieldsKey header of the HTTP requests.
+	UnmaskFields bool `json:"unmaskFields,omitempty"`
 }
 
 func UnmarshalQueryLimits(data []byte) (*QueryLimits, error) {
@@ -51,6 +59,10 @@ func InjectQueryLimitsHTTP(r *http.Request, limits *QueryLimits) error {
 func InjectQueryLimitsHeader(h *http.Header, limits *QueryLimits) error {
 	// Ensure any existing policy sets are erased
 	h.Del(HTTPHeaderQueryLimitsKey)
+	h.Del(HTTPHeaderUnmaskFieldsKey)
+	if limits.UnmaskFields {
+		h.Set(HTTPHeaderUnmaskFieldsKey, "true")
+	}
 
 	encodedLimits, err := MarshalQueryLimits(limits)
 	if err != nil {
@@ -61,16 +73,29 @@ func InjectQueryLimitsHeader(h *http.Header, limits *QueryLimits) error {
 }
 
 // ExtractQueryLimitsHTTP retrieves the query limit policy from the HTTP header and returns it.
+// The masked fields can only be unmasked by the HTTPHeaderUnmaskFieldsKey header.
 func ExtractQueryLimitsHTTP(r *http.Request) (*QueryLimits, error) {
+	var limits *QueryLimits
 	headerValues := r.Header.Values(HTTPHeaderQueryLimitsKey)
 
 	// Iterate through each set header value
 	for _, headerValue := range headerValues {
-		return UnmarshalQueryLimits([]byte(headerValue))
-
+		var err error
+		limits, err = UnmarshalQueryLimits([]byte(headerValue))
+		if err != nil {
+			return nil, err
+		}
+		break
 	}
 
-	return nil, nil
+	unmask := r.Header.Get(HTTPHeaderUnmaskFieldsKey) == "true"
+	if limits == nil && unmask {
+		limits = &QueryLimits{}
+	}
+	if limits != nil {
+		limits.UnmaskFields = unmask
+	}
+	return limits, nil
 }
 
 // ExtractQueryLimitsContext gets the embedded limits from the context
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -24,12 +25,14 @@ import (
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/rewrite"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	ruler_config "github.com/grafana/loki/v3/pkg/ruler/config"
 	"github.com/grafana/loki/v3/pkg/ruler/util"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/sharding"
 	"github.com/grafana/loki/v3/pkg/util/flagext"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/util/masking"
 	"github.com/grafana/loki/v3/pkg/util/validation"
 )
 
@@ -128,6 +131,9 @@ type Limits struct {
 	VolumeEnabled                    bool             `yaml:"volume_enabled" json:"volume_enabled" doc:"description=Enable log-volume endpoints."`
 	VolumeMaxSeries                  int              `yaml:"volume_max_series" json:"volume_max_series" doc:"description=The maximum number of aggregated series in a log-volume response"`
 
+	QueryRewriteBuiltinRules dskit_flagext.StringSliceCSV `yaml:"query_rewrite_builtin_rules" json:"query_rewrite_builtin_rules" category:"experimental"`
+	QueryRewriteRules        []rewrite.SelectorRuleConfig `yaml:"query_rewrite_rules,omitempty" json:"query_rewrite_rules,omitempty" category:"experimental" doc:"description=Stream selector rewrite rules applied by the query frontend before the built-in rules.\nExample:\n query_rewrite_rules:\n - name: legacy_app\n selector: '{app=\"legacy\"}'\n replacement: '{app=\"modern\", env=\"prod\"}'\nThe matchers of the selector are replaced by the matchers of the replacement in every stream selector containing all of them."`
+
 	// Ruler defaults and limits.
 	RulerMaxRulesPerRuleGroup   int                              `yaml:"ruler_max_rules_per_rule_group" json:"ruler_max_rules_per_rule_group"`
 	RulerMaxRuleGroupsPerTenant int                              `yaml:"ruler_max_rule_groups_per_tenant" json:"ruler_max_rule_groups_per_tenant"`
@@ -174,6 +180,10 @@ type Limits struct {
 	RulerRemoteEvaluationTimeout         time.Duration `yaml:"ruler_remote_evaluation_timeout" json:"ruler_remote_evaluation_timeout" doc:"description=Timeout for a remote rule evaluation. Defaults to the value of 'querier.query-timeout'."`
 	RulerRemoteEvaluationMaxResponseSize int64         `yaml:"ruler_remote_evaluation_max_response_size" json:"ruler_remote_evaluation_max_response_size" doc:"description=Maximum size (in bytes) of the allowable response size from a remote rule evaluation. Set to 0 to allow any response size (default)."`
 
+	RulerEvaluationTimeBudget   time.Duration    `yaml:"ruler_evaluation_time_budget" json:"ruler_evaluation_time_budget"`
+	RulerEvaluationBytesBudget  flagext.ByteSize `yaml:"ruler_evaluation_bytes_budget" json:"ruler_evaluation_bytes_budget"`
+	RulerEvaluationBudgetPeriod time.Duration    `yaml:"ruler_evaluation_budget_period" json:"ruler_evaluation_budget_period"`
+
 	// Global and per tenant deletion mode
 	DeletionMode string `yaml:"deletion_mode" json:"deletion_mode"`
 
@@ -181,6 +191,9 @@ type Limits struct {
 	RetentionPeriod model.Duration    `yaml:"retention_period" json:"retention_period"`
 	StreamRetention []StreamRetention `yaml:"retention_stream,omitempty" json:"retention_stream,omitempty" doc:"description=Per-stream retention to apply, if the retention is enable on the compactor side.\nExample:\n retention_stream:\n - selector: '{namespace=\"dev\"}'\n priority: 1\n period: 24h\n- selector: '{container=\"nginx\"}'\n priority: 1\n period: 744h\nSelector is a Prometheus labels matchers that will apply the 'period' retention only if the stream is matching. In case multiple stream are matching, the highest priority will be picked. If no rule is matched the 'retention_period' is used."`
 
+	// Per tenant storage tiering
+	StorageTieringAge model.Duration `yaml:"storage_tiering_age" json:"storage_tiering_age" category:"experimental"`
+
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
@@ -195,6 +208,9 @@ type Limits struct {
 	RequiredLabels       []string `yaml:"required_labels,omitempty" json:"required_labels,omitempty" doc:"description=Define a list of required selector labels."`
 	RequiredNumberLabels int      `yaml:"minimum_labels_number,omitempty" json:"minimum_labels_number,omitempty" doc:"description=Minimum number of label matchers a query should contain."`
 
+	QueryEnforcedMatchers  string               `yaml:"query_enforced_matchers,omitempty" json:"query_enforced_matchers,omitempty" doc:"description=Stream selector whose matchers are added to every stream selector of the queries, to restrict the streams the tenant can read. For example: '{namespace=~\"team-a.*\"}'. It applies to log, metric, series, labels, volume, patterns, detected fields and tail requests."`
+	QueryFieldMaskingRules []masking.RuleConfig `yaml:"query_field_masking_rules,omitempty" json:"query_field_masking_rules,omitempty" category:"experimental" doc:"description=Rules masking the structured metadata and parsed labels of the query results. The first rule matching a field is applied, at the end of the log pipeline. Masked fields cannot be used in label filters, formatters or unwraps, nor extracted by json or logfmt parameters and regexp or pattern parsers. Requests with the X-Loki-Unmask-Fields: true header skip the masking when per-request limits are enabled. The header must only be set by the proxy authenticating the requests, which must remove it from the requests of the clients.\nExample:\n query_field_masking_rules:\n - field: user_email\n action: hash\n - field_regex: 'secret_.+'\n action: drop"`
+
 	IndexGatewayShardSize int `yaml:"index_gateway_shard_size" json:"index_gateway_shard_size"`
 
 	BloomGatewayShardSize        int           `yaml:"bloom_gateway_shard_size" json:"bloom_gateway_shard_size" category:"experimental"`
@@ -338,12 +354,18 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.IntVar(&l.RulerMaxRulesPerRuleGroup, "ruler.max-rules-per-rule-group", 0, "Maximum number of rules per rule group per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerMaxRuleGroupsPerTenant, "ruler.max-rule-groups-per-tenant", 0, "Maximum number of rule groups per-tenant. 0 to disable.")
+	f.DurationVar(&l.RulerEvaluationTimeBudget, "ruler.evaluation-time-budget", 0, "Total query execution time the rules of a tenant can use per budget period, as reported by the query statistics of their evaluations. The budget is divided between the rulers the rule groups of the tenant are sharded across. The evaluation of the rule groups of a tenant exceeding its budget on a ruler is skipped by that ruler until the next period. 0 to disable.")
+	f.Var(&l.RulerEvaluationBytesBudget, "ruler.evaluation-bytes-budget", "Total bytes the rules of a tenant can process per budget period, as reported by the query statistics of their evaluations. The budget is divided between the rulers the rule groups of the tenant are sharded across. The evaluation of the rule groups of a tenant exceeding its budget on a ruler is skipped by that ruler until the next period. 0 to disable.")
+	f.DurationVar(&l.RulerEvaluationBudgetPeriod, "ruler.evaluation-budget-period", time.Hour, "Period of the rule evaluation time and bytes budgets of a tenant.")
 	f.IntVar(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when shuffle-sharding is enabled in the ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "Feature renamed to 'runtime configuration', flag deprecated in favor of -runtime-config.file (runtime_config.file in YAML).")
 	_ = l.RetentionPeriod.Set("0s")
 	f.Var(&l.RetentionPeriod, "store.retention", "Retention period to apply to stored data, only applies if retention_enabled is true in the compactor config. As of version 2.8.0, a zero value of 0 or 0s disables retention. In previous releases, Loki did not properly honor a zero value to disable retention and a really large value should be used instead.")
 
+	_ = l.StorageTieringAge.Set("0s")
+	f.Var(&l.StorageTieringAge, "store.tiering-age", "Experimental. Age after which the compactor moves chunks to the tiering object store configured in -store.tiering-object-store. Only applies if retention_enabled is true in the compactor config. 0 disables tiering.")
+
 	_ = l.PerTenantOverridePeriod.Set("10s")
 	f.Var(&l.PerTenantOverridePeriod, "limits.per-user-override-period", "Feature renamed to 'runtime configuration'; flag deprecated in favor of -runtime-config.reload-period (runtime_config.period in YAML).")
 
@@ -405,6 +427,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	l.ShardStreams.RegisterFlagsWithPrefix("shard-streams", f)
 
 	f.IntVar(&l.VolumeMaxSeries, "limits.volume-max-series", 1000, "The default number of aggregated series or labels that can be returned from a log-volume endpoint")
+	f.Var(&l.QueryRewriteBuiltinRules, "frontend.query-rewrite-builtin-rules", fmt.Sprintf("Comma-separated list of built-in rules the query frontend applies, in order, to rewrite queries into cheaper equivalent queries. Supported values: %s.", strings.Join(rewrite.BuiltinRuleNames(), ", ")))
 
 	f.BoolVar(&l.AllowStructuredMetadata, "validation.allow-structured-metadata", true, "Allow user to send structured metadata (non-indexed labels) in push payload.")
 	_ = l.MaxStructuredMetadataSize.Set(defaultMaxStructuredMetadataSize)
@@ -463,6 +486,28 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if l.QueryEnforcedMatchers != "" {
+		if _, err := syntax.ParseMatchers(l.QueryEnforcedMatchers, true); err != nil {
+			return fmt.Errorf("invalid query enforced matchers: %w", err)
+		}
+	}
+
+	if _, err := rewrite.BuiltinRules(l.QueryRewriteBuiltinRules); err != nil {
+		return err
+	}
+	for i := range l.QueryRewriteRules {
+		// populate matchers during validation
+		if err := l.QueryRewriteRules[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range l.QueryFieldMaskingRules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid query field masking rule: %w", err)
+		}
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
@@ -899,11 +944,31 @@ func (o *Overrides) RulerRemoteEvaluationMaxResponseSize(userID string) int64 {
 	return o.getOverridesForUser(userID).RulerRemoteEvaluationMaxResponseSize
 }
 
+// RulerEvaluationTimeBudget returns the query execution time the rules of a given user can use per budget period.
+func (o *Overrides) RulerEvaluationTimeBudget(userID string) time.Duration {
+	return o.getOverridesForUser(userID).RulerEvaluationTimeBudget
+}
+
+// RulerEvaluationBytesBudget returns the bytes the rules of a given user can process per budget period.
+func (o *Overrides) RulerEvaluationBytesBudget(userID string) int {
+	return o.getOverridesForUser(userID).RulerEvaluationBytesBudget.Val()
+}
+
+// RulerEvaluationBudgetPeriod returns the period of the rule evaluation budgets of a given user.
+func (o *Overrides) RulerEvaluationBudgetPeriod(userID string) time.Duration {
+	return o.getOverridesForUser(userID).RulerEvaluationBudgetPeriod
+}
+
 // RetentionPeriod returns the retention period for a given user.
 func (o *Overrides) RetentionPeriod(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).RetentionPeriod)
 }
 
+// StorageTieringAge returns the age after which chunks of a given user are moved to the tiering object store.
+func (o *Overrides) StorageTieringAge(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).StorageTieringAge)
+}
+
 // StreamRetention returns the retention period for a given user.
 func (o *Overrides) StreamRetention(userID string) []StreamRetention {
 	return o.getOverridesForUser(userID).StreamRetention
@@ -933,6 +998,19 @@ func (o *Overrides) RequiredNumberLabels(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).RequiredNumberLabels
 }
 
+// QueryEnforcedMatchers returns the stream selectors whose matchers are added to the queries of a user.
+func (o *Overrides) QueryEnforcedMatchers(_ context.Context, userID string) []string {
+	if selector := o.getOverridesForUser(userID).QueryEnforcedMatchers; selector != "" {
+		return []string{selector}
+	}
+	return nil
+}
+
+// QueryFieldMaskingRules returns the rules masking the fields of the query results of a user.
+func (o *Overrides) QueryFieldMaskingRules(_ context.Context, userID string) []masking.RuleConfig {
+	return o.getOverridesForUser(userID).QueryFieldMaskingRules
+}
+
 func (o *Overrides) DefaultLimits() *Limits {
 	return o.defaultLimits
 }
@@ -967,6 +1045,16 @@ func (o *Overrides) VolumeMaxSeries(userID string) int {
 	return o.getOverridesForUser(userID).VolumeMaxSeries
 }
 
+// QueryRewriteBuiltinRules returns the names of the built-in query rewrite rules enabled for a user.
+func (o *Overrides) QueryRewriteBuiltinRules(_ context.Context, userID string) []string {
+	return o.getOverridesForUser(userID).QueryRewriteBuiltinRules
+}
+
+// QueryRewriteRules returns the stream selector rewrite rules of a user.
+func (o *Overrides) QueryRewriteRules(_ context.Context, userID string) []rewrite.SelectorRuleConfig {
+	return o.getOverridesForUser(userID).QueryRewriteRules
+}
+
 func (o *Overrides) IndexGatewayShardSize(userID string) int {
 	return o.getOverridesForUser(userID).IndexGatewayShardSize
 }